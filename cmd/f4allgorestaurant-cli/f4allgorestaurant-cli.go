@@ -3,8 +3,11 @@ package main
 import (
 	"f4allgo-restaurant/internal/adapter/primary/cli"
 	"f4allgo-restaurant/internal/adapter/secondary/eventpublisher"
+	"f4allgo-restaurant/internal/adapter/secondary/geocoder"
+	"f4allgo-restaurant/internal/adapter/secondary/policy"
 	"f4allgo-restaurant/internal/adapter/secondary/storage"
 	"f4allgo-restaurant/internal/boot"
+	"f4allgo-restaurant/internal/core/port"
 	"f4allgo-restaurant/internal/core/service"
 	"fmt"
 	"os"
@@ -21,14 +24,47 @@ func main() {
 	trManager := boot.GetTransactionManager(gormDB)
 
 	// Secondary adapters
-	restaurantRepository := storage.NewRestaurantPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, nil)
+	var restaurantRepository port.RestaurantRepository
+	switch boot.GetConfig().AppRestaurantRepository {
+	case "postgres":
+		restaurantRepository = storage.NewRestaurantPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, nil)
+	case "eventsourced":
+		restaurantRepository = storage.NewRestaurantEventSourcedRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetConfig().AppEventStoreSnapshotInterval, nil)
+	default:
+		panic("unknown restaurant repository: " + boot.GetConfig().AppRestaurantRepository)
+	}
+	ticketRepository := storage.NewTicketPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, nil)
+	scheduledMenuRepository := storage.NewScheduledMenuPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, nil)
+	auditLogRepository := storage.NewAuditLogPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, nil)
 	outboxPublisher := eventpublisher.NewDomainEventOutboxPublisher(gormDB, trmgorm.DefaultCtxGetter, boot.GetLogger(), boot.GetConfig(), nil)
 
-	// Core service
-	restaurantService := service.NewDefaultRestaurantService(restaurantRepository, outboxPublisher, trManager)
+	// Core services
+	restaurantService := service.NewDefaultRestaurantService(restaurantRepository, outboxPublisher, trManager).WithAuditLog(auditLogRepository)
+	ticketService := service.NewDefaultTicketService(ticketRepository, restaurantRepository, outboxPublisher, trManager)
+	menuScheduleService := service.NewDefaultMenuScheduleService(scheduledMenuRepository, restaurantRepository, outboxPublisher, trManager).WithAuditLog(auditLogRepository)
+
+	// Optional secondary adapter for Geocoder port.
+	if boot.GetConfig().AppGeocoderFile != "" {
+		csvGeocoder, err := geocoder.NewCsvGeocoder(boot.GetConfig().AppGeocoderFile)
+		if err != nil {
+			panic("failed to load the geocoder file: " + err.Error())
+		}
+		restaurantService.WithGeocoder(csvGeocoder)
+	}
+
+	// Optional secondary adapter for AuthorizationPolicy port.
+	if boot.GetConfig().AppAuthPolicyFile != "" {
+		rolePolicy, err := policy.NewYamlRolePolicy(boot.GetConfig().AppAuthPolicyFile, boot.GetLogger(), boot.GetTallyScope())
+		if err != nil {
+			panic("failed to load the authorization policy file: " + err.Error())
+		}
+		restaurantService.WithAuthorizationPolicy(rolePolicy)
+		ticketService.WithAuthorizationPolicy(rolePolicy)
+		menuScheduleService.WithAuthorizationPolicy(rolePolicy)
+	}
 
 	// Primary adapters
-	restaurantCli := cli.NewRestaurantCli(restaurantService)
+	restaurantCli := cli.NewRestaurantCli(restaurantService, ticketService, menuScheduleService)
 
 	if err := restaurantCli.Execute(); err != nil {
 		fmt.Println(err)
@@ -4,11 +4,18 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"time"
 
+	"f4allgo-restaurant/internal/adapter/primary/auth"
 	pb "f4allgo-restaurant/internal/adapter/primary/grpc"
+	"f4allgo-restaurant/internal/adapter/primary/saga"
 	"f4allgo-restaurant/internal/adapter/secondary/eventpublisher"
+	"f4allgo-restaurant/internal/adapter/secondary/eventpublisher/outbox"
+	"f4allgo-restaurant/internal/adapter/secondary/geocoder"
+	"f4allgo-restaurant/internal/adapter/secondary/policy"
 	"f4allgo-restaurant/internal/adapter/secondary/storage"
 	"f4allgo-restaurant/internal/boot"
+	"f4allgo-restaurant/internal/core/port"
 	"f4allgo-restaurant/internal/core/service"
 
 	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
@@ -32,22 +39,107 @@ func main() {
 	// Inits health checks and gets the handler.
 	h := boot.GetHealthHandler(sqlDB)
 
-	// Secondary adapter for RestaurantRepository port.
-	restaurantRepository := storage.NewRestaurantPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+	// Secondary adapter for RestaurantRepository port, the relational one or the
+	// event-sourced one as configured.
+	var restaurantRepository port.RestaurantRepository
+	switch boot.GetConfig().AppRestaurantRepository {
+	case "postgres":
+		restaurantRepository = storage.NewRestaurantPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+	case "eventsourced":
+		restaurantRepository = storage.NewRestaurantEventSourcedRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetConfig().AppEventStoreSnapshotInterval, boot.GetTallyScope())
+	default:
+		panic("unknown restaurant repository: " + boot.GetConfig().AppRestaurantRepository)
+	}
+
+	// Secondary adapter for TicketRepository port.
+	ticketRepository := storage.NewTicketPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+
+	// Secondary adapter for ScheduledMenuRepository port.
+	scheduledMenuRepository := storage.NewScheduledMenuPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+
+	// Secondary adapter for IdempotencyKeyRepository port.
+	idempotencyKeyRepository := storage.NewIdempotencyKeyPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+
+	// Secondary adapter for AuditLogRepository port.
+	auditLogRepository := storage.NewAuditLogPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
 
 	// Secondary adapter for DomainEventPublisher port.
 	outboxPublisher := eventpublisher.NewDomainEventOutboxPublisher(gormDB, trmgorm.DefaultCtxGetter, boot.GetLogger(), boot.GetConfig(), boot.GetTallyScope())
 
-	// Core service
-	restaurantService := service.NewDefaultRestaurantService(restaurantRepository, outboxPublisher, trManager)
+	// Core services
+	restaurantService := service.NewDefaultRestaurantService(restaurantRepository, outboxPublisher, trManager).WithAuditLog(auditLogRepository)
+	ticketService := service.NewDefaultTicketService(ticketRepository, restaurantRepository, outboxPublisher, trManager)
+	menuScheduleService := service.NewDefaultMenuScheduleService(scheduledMenuRepository, restaurantRepository, outboxPublisher, trManager).WithAuditLog(auditLogRepository)
+	idempotencyService := service.NewDefaultIdempotencyService(idempotencyKeyRepository, trManager)
+
+	// Optional secondary adapter for Geocoder port.
+	if boot.GetConfig().AppGeocoderFile != "" {
+		csvGeocoder, err := geocoder.NewCsvGeocoder(boot.GetConfig().AppGeocoderFile)
+		if err != nil {
+			panic("failed to load the geocoder file: " + err.Error())
+		}
+		restaurantService.WithGeocoder(csvGeocoder)
+	}
+
+	// Optional secondary adapter for AuthorizationPolicy port.
+	if boot.GetConfig().AppAuthPolicyFile != "" {
+		rolePolicy, err := policy.NewYamlRolePolicy(boot.GetConfig().AppAuthPolicyFile, boot.GetLogger(), boot.GetTallyScope())
+		if err != nil {
+			panic("failed to load the authorization policy file: " + err.Error())
+		}
+		restaurantService.WithAuthorizationPolicy(rolePolicy)
+		ticketService.WithAuthorizationPolicy(rolePolicy)
+		menuScheduleService.WithAuthorizationPolicy(rolePolicy)
+	}
+
+	// Optional background process activating the scheduled menus.
+	if boot.GetConfig().AppInitMenuScheduler {
+		menuScheduleService.InitMenuScheduler(boot.GetConfig().AppMenuSchedulerInterval)
+	}
+
+	// Optional background process purging the restaurants deleted long ago.
+	if boot.GetConfig().AppInitRestaurantPurger {
+		retention := time.Duration(boot.GetConfig().AppRestaurantPurgeAfterDays) * 24 * time.Hour
+		restaurantService.InitRestaurantPurger(retention, boot.GetConfig().AppRestaurantPurgeInterval)
+	}
+
+	// Optional background process purging the idempotency keys stored long ago.
+	if boot.GetConfig().AppInitIdempotencyPurger {
+		idempotencyService.InitIdempotencyKeyPurger(boot.GetConfig().AppIdempotencyKeyRetention, boot.GetConfig().AppIdempotencyKeyPurgeInterval)
+	}
 
-	// Primary adapter
+	// Optional primary adapter to take part in the sagas of other services. The
+	// replies to the saga commands are written to the outbox.
+	if boot.GetConfig().AppInitSagaConsumer {
+		replyWriter := outbox.NewOutboxPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetConfig(), boot.GetLogger())
+		commandHandler := saga.NewCommandHandler(ticketService, replyWriter, trManager, boot.GetLogger())
+		commandConsumer, err := saga.NewCommandConsumer(commandHandler, boot.GetLogger(), boot.GetConfig())
+		if err != nil {
+			panic("failed to create the saga command consumer: " + err.Error())
+		}
+		if err := commandConsumer.InitCommandConsumer(); err != nil {
+			panic("failed to start the saga command consumer: " + err.Error())
+		}
+	}
+
+	// Optional authentication of the callers with JWTs.
+	var verifier *auth.Verifier
+	if boot.GetConfig().AppAuthEnabled {
+		v, err := auth.NewVerifierFromConfig(boot.GetConfig())
+		if err != nil {
+			panic("failed to create the token verifier: " + err.Error())
+		}
+		verifier = v
+	}
+
+	// Primary adapters
 	rsServer := pb.NewRestaurantServiceServer(restaurantService)
+	tsServer := pb.NewTicketServiceServer(ticketService)
 
-	startServers(rsServer, r.HTTPHandler(), h)
+	startServers(rsServer, tsServer, idempotencyService, r.HTTPHandler(), h, verifier)
 }
 
-func startServers(server pb.RestaurantServiceServer, metricsHandler http.Handler, healthHandler http.Handler) {
+func startServers(server pb.RestaurantServiceServer, ticketServer pb.TicketServiceServer, idempotencyService port.IdempotencyService, metricsHandler http.Handler, healthHandler http.Handler, verifier *auth.Verifier) {
 	gRPCPort := boot.GetConfig().AppPort + 1
 	httpPport := boot.GetConfig().AppPort
 	go func() {
@@ -56,8 +148,19 @@ func startServers(server pb.RestaurantServiceServer, metricsHandler http.Handler
 			panic(fmt.Sprintf("failed to listen on port %d", gRPCPort))
 		}
 
-		grpcServer := grpc.NewServer()
+		// When authentication is enabled the identity of the caller is taken from
+		// the token, otherwise it may be propagated by a trusted API gateway (and
+		// the callers are anonymous if there's none).
+		interceptors := []grpc.UnaryServerInterceptor{pb.RequestMetadataInterceptor}
+		if verifier != nil {
+			interceptors = append(interceptors, pb.AuthInterceptor(verifier))
+		} else if boot.GetConfig().AppAuthTrustGatewayHeaders {
+			interceptors = append(interceptors, pb.IdentityInterceptor)
+		}
+		interceptors = append(interceptors, pb.ErrorInterceptor, pb.ExpectedVersionInterceptor, pb.IdempotencyInterceptor(idempotencyService))
+		grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
 		pb.RegisterRestaurantServiceServer(grpcServer, server)
+		pb.RegisterTicketServiceServer(grpcServer, ticketServer)
 		err = grpcServer.Serve(lis)
 		if err != nil {
 			panic("failed to start the gRPC service")
@@ -67,7 +170,11 @@ func startServers(server pb.RestaurantServiceServer, metricsHandler http.Handler
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", metricsHandler)
 	mux.Handle("/health", healthHandler)
-	err := http.ListenAndServe(fmt.Sprintf(":%d", httpPport), mux)
+	var handler http.Handler = mux
+	if verifier != nil && !boot.GetConfig().AppAuthExemptProbes {
+		handler = verifier.Handler(mux)
+	}
+	err := http.ListenAndServe(fmt.Sprintf(":%d", httpPport), handler)
 	if err != nil {
 		panic(fmt.Sprintf("failed to listen on port %d", httpPport))
 	}
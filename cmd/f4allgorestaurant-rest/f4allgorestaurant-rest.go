@@ -1,13 +1,20 @@
 package main
 
 import (
+	"f4allgo-restaurant/internal/adapter/primary/auth"
 	"f4allgo-restaurant/internal/adapter/primary/rest"
+	"f4allgo-restaurant/internal/adapter/primary/saga"
 	"f4allgo-restaurant/internal/adapter/secondary/eventpublisher"
+	"f4allgo-restaurant/internal/adapter/secondary/eventpublisher/outbox"
+	"f4allgo-restaurant/internal/adapter/secondary/geocoder"
+	"f4allgo-restaurant/internal/adapter/secondary/policy"
 	"f4allgo-restaurant/internal/adapter/secondary/storage"
 	"f4allgo-restaurant/internal/boot"
+	"f4allgo-restaurant/internal/core/port"
 	"f4allgo-restaurant/internal/core/service"
 	"fmt"
 	"net/http"
+	"time"
 
 	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
 	"github.com/gin-gonic/gin"
@@ -30,35 +37,165 @@ func main() {
 	// Inits health checks and gets the handler.
 	h := boot.GetHealthHandler(sqlDB)
 
-	// Secondary adapter for RestaurantRepository port.
-	restaurantRepository := storage.NewRestaurantPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+	// Secondary adapter for RestaurantRepository port, the relational one or the
+	// event-sourced one as configured.
+	var restaurantRepository port.RestaurantRepository
+	switch boot.GetConfig().AppRestaurantRepository {
+	case "postgres":
+		restaurantRepository = storage.NewRestaurantPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+	case "eventsourced":
+		restaurantRepository = storage.NewRestaurantEventSourcedRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetConfig().AppEventStoreSnapshotInterval, boot.GetTallyScope())
+	default:
+		panic("unknown restaurant repository: " + boot.GetConfig().AppRestaurantRepository)
+	}
+
+	// Secondary adapter for TicketRepository port.
+	ticketRepository := storage.NewTicketPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+
+	// Secondary adapter for ScheduledMenuRepository port.
+	scheduledMenuRepository := storage.NewScheduledMenuPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+
+	// Secondary adapter for IdempotencyKeyRepository port.
+	idempotencyKeyRepository := storage.NewIdempotencyKeyPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+
+	// Secondary adapter for AuditLogRepository port.
+	auditLogRepository := storage.NewAuditLogPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
 
 	// Secondary adapter for DomainEventPublisher port.
 	outboxPublisher := eventpublisher.NewDomainEventOutboxPublisher(gormDB, trmgorm.DefaultCtxGetter, boot.GetLogger(), boot.GetConfig(), boot.GetTallyScope())
 
-	// Core service
-	restaurantService := service.NewDefaultRestaurantService(restaurantRepository, outboxPublisher, trManager)
+	// Core services
+	restaurantService := service.NewDefaultRestaurantService(restaurantRepository, outboxPublisher, trManager).WithAuditLog(auditLogRepository)
+	ticketService := service.NewDefaultTicketService(ticketRepository, restaurantRepository, outboxPublisher, trManager)
+	menuScheduleService := service.NewDefaultMenuScheduleService(scheduledMenuRepository, restaurantRepository, outboxPublisher, trManager).WithAuditLog(auditLogRepository)
+	idempotencyService := service.NewDefaultIdempotencyService(idempotencyKeyRepository, trManager)
+
+	// Optional secondary adapter for Geocoder port.
+	if boot.GetConfig().AppGeocoderFile != "" {
+		csvGeocoder, err := geocoder.NewCsvGeocoder(boot.GetConfig().AppGeocoderFile)
+		if err != nil {
+			panic("failed to load the geocoder file: " + err.Error())
+		}
+		restaurantService.WithGeocoder(csvGeocoder)
+	}
+
+	// Optional secondary adapter for AuthorizationPolicy port.
+	if boot.GetConfig().AppAuthPolicyFile != "" {
+		rolePolicy, err := policy.NewYamlRolePolicy(boot.GetConfig().AppAuthPolicyFile, boot.GetLogger(), boot.GetTallyScope())
+		if err != nil {
+			panic("failed to load the authorization policy file: " + err.Error())
+		}
+		restaurantService.WithAuthorizationPolicy(rolePolicy)
+		ticketService.WithAuthorizationPolicy(rolePolicy)
+		menuScheduleService.WithAuthorizationPolicy(rolePolicy)
+	}
+
+	// Optional background process activating the scheduled menus.
+	if boot.GetConfig().AppInitMenuScheduler {
+		menuScheduleService.InitMenuScheduler(boot.GetConfig().AppMenuSchedulerInterval)
+	}
+
+	// Optional background process purging the restaurants deleted long ago.
+	if boot.GetConfig().AppInitRestaurantPurger {
+		retention := time.Duration(boot.GetConfig().AppRestaurantPurgeAfterDays) * 24 * time.Hour
+		restaurantService.InitRestaurantPurger(retention, boot.GetConfig().AppRestaurantPurgeInterval)
+	}
+
+	// Optional background process purging the idempotency keys stored long ago.
+	if boot.GetConfig().AppInitIdempotencyPurger {
+		idempotencyService.InitIdempotencyKeyPurger(boot.GetConfig().AppIdempotencyKeyRetention, boot.GetConfig().AppIdempotencyKeyPurgeInterval)
+	}
+
+	// Optional primary adapter to take part in the sagas of other services. The
+	// replies to the saga commands are written to the outbox.
+	if boot.GetConfig().AppInitSagaConsumer {
+		replyWriter := outbox.NewOutboxPostgresRepository(gormDB, trmgorm.DefaultCtxGetter, boot.GetConfig(), boot.GetLogger())
+		commandHandler := saga.NewCommandHandler(ticketService, replyWriter, trManager, boot.GetLogger())
+		commandConsumer, err := saga.NewCommandConsumer(commandHandler, boot.GetLogger(), boot.GetConfig())
+		if err != nil {
+			panic("failed to create the saga command consumer: " + err.Error())
+		}
+		if err := commandConsumer.InitCommandConsumer(); err != nil {
+			panic("failed to start the saga command consumer: " + err.Error())
+		}
+	}
+
+	// Optional authentication of the callers with JWTs.
+	var verifier *auth.Verifier
+	if boot.GetConfig().AppAuthEnabled {
+		v, err := auth.NewVerifierFromConfig(boot.GetConfig())
+		if err != nil {
+			panic("failed to create the token verifier: " + err.Error())
+		}
+		verifier = v
+	}
 
-	// Primary adapter
-	restaurantHandler := rest.NewRestaurantHandler(restaurantService)
+	// Primary adapters
+	restaurantHandler := rest.NewRestaurantHandler(restaurantService, menuScheduleService)
+	ticketHandler := rest.NewTicketHandler(ticketService)
 
-	startGinServer(restaurantHandler, r.HTTPHandler(), h)
+	startGinServer(restaurantHandler, ticketHandler, idempotencyService, r.HTTPHandler(), h, verifier)
 }
 
-func startGinServer(restaurantHandler *rest.RestaurantHandler, metricsHandler http.Handler, healthHandler http.Handler) {
+func startGinServer(restaurantHandler *rest.RestaurantHandler, ticketHandler *rest.TicketHandler, idempotencyService port.IdempotencyService, metricsHandler http.Handler, healthHandler http.Handler, verifier *auth.Verifier) {
 	gin.SetMode(boot.GetConfig().GinMode)
 	router := gin.New()
 	router.Use(gin.Recovery())
+	// The handlers pass the gin context to the core, which reads the identity of the
+	// caller from the request context.
+	router.ContextWithFallback = true
+
+	// When authentication is enabled the identity of the caller is taken from the
+	// token, otherwise it may be propagated by a trusted API gateway.
+	if verifier != nil {
+		var exemptPaths []string
+		if boot.GetConfig().AppAuthExemptProbes {
+			exemptPaths = []string{"/metrics", "/health"}
+		}
+		router.Use(rest.AuthMiddleware(verifier, exemptPaths...))
+	}
 
 	router.GET("/metrics", gin.WrapH(metricsHandler))
 	router.GET("/health", gin.WrapH(healthHandler))
 
 	api := router.Group("/api/v1")
+	api.Use(rest.RequestMetadataMiddleware())
+	if verifier == nil && boot.GetConfig().AppAuthTrustGatewayHeaders {
+		api.Use(rest.IdentityMiddleware())
+	}
+	api.Use(rest.PreconditionMiddleware())
+	// The idempotency keys are scoped to the caller, so the identity must be known (the
+	// keys of the anonymous callers are rejected).
+	api.Use(rest.IdempotencyMiddleware(idempotencyService))
 	api.GET("/restaurants", restaurantHandler.GetRestaurants)
 	api.POST("/restaurants", restaurantHandler.CreateRestaurant)
+	api.POST("/restaurants:method", restaurantHandler.BatchCreateRestaurants)
+	api.GET("/restaurants/search", restaurantHandler.SearchRestaurants)
 	api.DELETE("/restaurants/:restaurantId", restaurantHandler.DeleteRestaurant)
 	api.GET("/restaurants/:restaurantId", restaurantHandler.GetRestaurant)
+	api.PATCH("/restaurants/:restaurantId", restaurantHandler.UpdateRestaurant)
+	api.POST("/restaurants/:restaurantId/restore", restaurantHandler.RestoreRestaurant)
+	api.GET("/restaurants/:restaurantId/audit", restaurantHandler.GetAuditEntries)
+	api.GET("/restaurants/:restaurantId/menu", restaurantHandler.GetMenu)
+	api.GET("/restaurants/:restaurantId/menu/versions", restaurantHandler.GetMenuVersions)
+	api.GET("/restaurants/:restaurantId/menu/scheduled", restaurantHandler.GetScheduledMenus)
+	api.DELETE("/restaurants/:restaurantId/menu/scheduled/:scheduledMenuId", restaurantHandler.CancelScheduledMenu)
 	api.PUT("/restaurants/:restaurantId/menu", restaurantHandler.UpdateMenu)
+	api.PUT("/restaurants/:restaurantId/dayparts", restaurantHandler.UpdateDayparts)
+	api.POST("/restaurants/:restaurantId/menu/items", restaurantHandler.AddMenuItem)
+	api.PATCH("/restaurants/:restaurantId/menu/items/:menuItemId", restaurantHandler.UpdateMenuItem)
+	api.DELETE("/restaurants/:restaurantId/menu/items/:menuItemId", restaurantHandler.RemoveMenuItem)
+	api.PUT("/restaurants/:restaurantId/menu/items/:menuItemId/availability", restaurantHandler.SetItemAvailability)
+	api.POST("/restaurants/:restaurantId/quote", restaurantHandler.ValidateOrder)
+	api.GET("/restaurants/:restaurantId/tickets", ticketHandler.GetTickets)
+	api.POST("/restaurants/:restaurantId/tickets", ticketHandler.CreateTicket)
+	api.GET("/tickets/:ticketId", ticketHandler.GetTicket)
+	api.POST("/tickets/:ticketId/accept", ticketHandler.AcceptTicket)
+	api.POST("/tickets/:ticketId/reject", ticketHandler.RejectTicket)
+	api.POST("/tickets/:ticketId/start-preparing", ticketHandler.StartPreparingTicket)
+	api.POST("/tickets/:ticketId/ready-for-pickup", ticketHandler.MarkTicketReadyForPickup)
+	api.POST("/tickets/:ticketId/pick-up", ticketHandler.PickUpTicket)
+	api.POST("/tickets/:ticketId/cancel", ticketHandler.CancelTicket)
 
 	err := router.Run(fmt.Sprintf(":%d", boot.GetConfig().AppPort))
 	if err != nil {
@@ -0,0 +1,6 @@
+// Package auth includes types and functions to authenticate the callers of the
+// primary adapters that expose network interfaces (REST and gRPC). Callers present
+// a JWT (usually issued by an OIDC provider) that is validated against a JSON Web
+// Key Set, loaded from a static file (handy for offline environments and tests) or
+// from the JWKS endpoint of the provider.
+package auth
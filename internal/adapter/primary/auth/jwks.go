@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval limits how often a remote key set is fetched again when a
+// token references an unknown key, so forged key ids can't flood the provider.
+const minRefreshInterval = 10 * time.Second
+
+// KeySet provides the public keys used to verify the signature of the tokens.
+type KeySet interface {
+	// Key returns the public key identified by kid. An empty kid is only resolved
+	// when the key set holds a single key.
+	Key(kid string) (any, error)
+}
+
+// jwk is the JSON representation of a public key (RFC 7517). Only the members
+// needed to build RSA, EC and Ed25519 public keys are decoded.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// staticKeySet is a key set that never changes once loaded.
+type staticKeySet struct {
+	keys map[string]any
+}
+
+var _ KeySet = (*staticKeySet)(nil)
+
+// NewFileKeySet loads a key set from a JWKS file.
+func NewFileKeySet(path string) (*staticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return nil, err
+	}
+	return &staticKeySet{keys: keys}, nil
+}
+
+func (s *staticKeySet) Key(kid string) (any, error) {
+	return lookupKey(s.keys, kid)
+}
+
+// remoteKeySet is a key set fetched from a JWKS endpoint. The keys are fetched
+// again once they are older than the refresh interval, or earlier when a token
+// references an unknown key (the provider may have rotated them).
+type remoteKeySet struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+var _ KeySet = (*remoteKeySet)(nil)
+
+// NewRemoteKeySet creates a key set backed by a JWKS endpoint. The keys are
+// fetched eagerly, so a misconfigured endpoint is detected at startup.
+func NewRemoteKeySet(url string, refreshInterval time.Duration) (*remoteKeySet, error) {
+	s := &remoteKeySet{
+		url:             url,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		refreshInterval: refreshInterval,
+	}
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *remoteKeySet) Key(kid string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	age := time.Since(s.fetchedAt)
+	if age > s.refreshInterval {
+		if err := s.refresh(); err != nil {
+			return nil, err
+		}
+	}
+	key, err := lookupKey(s.keys, kid)
+	if err != nil && age > minRefreshInterval && age <= s.refreshInterval {
+		if err := s.refresh(); err != nil {
+			return nil, err
+		}
+		return lookupKey(s.keys, kid)
+	}
+	return key, err
+}
+
+// refresh fetches the key set from the endpoint. It must be called holding the
+// lock (or before the key set is shared).
+func (s *remoteKeySet) refresh() error {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status fetching the key set: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+// parseKeySet decodes a JWKS document into public keys indexed by key id. Keys
+// not meant for signatures or of unsupported types are ignored.
+func parseKeySet(data []byte) (map[string]any, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid key set: %w", err)
+	}
+	keys := make(map[string]any)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key '%s': %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("the key set has no signing keys")
+	}
+	return keys, nil
+}
+
+func lookupKey(keys map[string]any, kid string) (any, error) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key '%s'", kid)
+}
+
+// publicKey builds the public key described by the JWK, or returns nil if the key
+// type is not supported.
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("the point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("missing key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"f4allgo-restaurant/internal/boot"
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrMissingToken is returned when the caller does not present a bearer token.
+var ErrMissingToken = errors.New("missing bearer token")
+
+// signingMethods are the accepted signing algorithms. Symmetric algorithms are
+// excluded since the tokens are verified with public keys.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Claims are the claims of a verified token.
+type Claims map[string]any
+
+type claimsKey struct{}
+
+// ContextWithClaims returns a copy of ctx carrying the claims of the caller.
+func ContextWithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the caller carried by ctx, or nil if the
+// caller was not authenticated.
+func ClaimsFromContext(ctx context.Context) Claims {
+	claims, _ := ctx.Value(claimsKey{}).(Claims)
+	return claims
+}
+
+// Options configure the validation of the tokens and the claims the identity of
+// the caller is read from. Nested claims are referenced with dotted names (e.g.
+// 'realm_access.roles').
+type Options struct {
+	Issuer      string
+	Audience    string
+	TenantClaim string
+	RolesClaim  string
+	Leeway      time.Duration
+}
+
+// Verifier validates the tokens presented by the callers.
+type Verifier struct {
+	keySet  KeySet
+	parser  *jwt.Parser
+	options Options
+}
+
+// NewVerifier creates a verifier checking the signature of the tokens against the
+// key set. Besides the signature, the expiration time is required and the issuer
+// and audience are checked if configured.
+func NewVerifier(keySet KeySet, options Options) *Verifier {
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(options.Leeway),
+	}
+	if options.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(options.Issuer))
+	}
+	if options.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(options.Audience))
+	}
+	return &Verifier{keySet: keySet, parser: jwt.NewParser(parserOptions...), options: options}
+}
+
+// NewVerifierFromConfig creates a verifier from the application configuration. The
+// key set is loaded from the configured JWKS file or, if none, from the JWKS URL.
+func NewVerifierFromConfig(config *boot.Config) (*Verifier, error) {
+	var keySet KeySet
+	var err error
+	switch {
+	case config.AppAuthJwksFile != "":
+		keySet, err = NewFileKeySet(config.AppAuthJwksFile)
+	case config.AppAuthJwksUrl != "":
+		keySet, err = NewRemoteKeySet(config.AppAuthJwksUrl, config.AppAuthJwksRefreshInterval)
+	default:
+		err = errors.New("either a JWKS file or a JWKS URL must be configured")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifier(keySet, Options{
+		Issuer:      config.AppAuthIssuer,
+		Audience:    config.AppAuthAudience,
+		TenantClaim: config.AppAuthTenantClaim,
+		RolesClaim:  config.AppAuthRolesClaim,
+		Leeway:      config.AppAuthLeeway,
+	}), nil
+}
+
+// Verify validates the token and returns its claims.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keySet.Key(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("the token has no subject")
+	}
+	return Claims(claims), nil
+}
+
+// Authenticate validates the token and returns a copy of ctx carrying both the
+// claims and the identity of the caller.
+func (v *Verifier) Authenticate(ctx context.Context, token string) (context.Context, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	claims, err := v.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	ctx = ContextWithClaims(ctx, claims)
+	return domain.ContextWithIdentity(ctx, v.Identity(claims)), nil
+}
+
+// Identity maps the claims of a verified token to the identity of the caller.
+func (v *Verifier) Identity(claims Claims) *domain.Identity {
+	subject, _ := claims["sub"].(string)
+	tenantId, _ := claims.lookup(v.options.TenantClaim).(string)
+	roles := []domain.Role{}
+	for _, role := range claims.strings(v.options.RolesClaim) {
+		roles = append(roles, domain.Role(role))
+	}
+	return domain.NewIdentity(subject, tenantId, roles)
+}
+
+// Handler wraps an HTTP handler so that it is only served to authenticated callers.
+func (v *Verifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := BearerToken(r.Header.Get("Authorization"))
+		if err == nil {
+			var ctx context.Context
+			if ctx, err = v.Authenticate(r.Context(), token); err == nil {
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	})
+}
+
+// BearerToken extracts the token from the value of an Authorization header.
+func BearerToken(authorization string) (string, error) {
+	if authorization == "" {
+		return "", ErrMissingToken
+	}
+	scheme, token, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("unsupported authorization scheme")
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// lookup returns the value of a (possibly nested) claim, or nil if not present.
+func (c Claims) lookup(name string) any {
+	if name == "" {
+		return nil
+	}
+	var value any = map[string]any(c)
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	return value
+}
+
+// strings returns the values of a claim holding either an array of strings or a
+// string with values separated by spaces or commas (like the 'scope' claim).
+func (c Claims) strings(name string) []string {
+	values := []string{}
+	switch value := c.lookup(name).(type) {
+	case string:
+		values = append(values, strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == ',' })...)
+	case []any:
+		for _, v := range value {
+			if s, ok := v.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/test"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	keySet, err := NewFileKeySet(test.JwksFile())
+	assert.NoError(t, err)
+	verifier := NewVerifier(keySet, Options{Issuer: "https://issuer", Audience: "f4allgo-restaurant"})
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "user1",
+			"iss": "https://issuer",
+			"aud": "f4allgo-restaurant",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("secret"))
+
+	testcases := []struct {
+		name    string
+		token   func() string
+		wantErr bool
+	}{
+		{
+			name:  "verify a token signed with an RSA key",
+			token: func() string { return sign(t, test.RsaKeyId, validClaims()) },
+		},
+		{
+			name:  "verify a token signed with an EC key",
+			token: func() string { return sign(t, test.EcKeyId, validClaims()) },
+		},
+		{
+			name: "reject an expired token",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return sign(t, test.RsaKeyId, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "reject a token without expiration time",
+			token: func() string {
+				claims := validClaims()
+				delete(claims, "exp")
+				return sign(t, test.RsaKeyId, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "reject a token from another issuer",
+			token: func() string {
+				claims := validClaims()
+				claims["iss"] = "https://another-issuer"
+				return sign(t, test.RsaKeyId, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "reject a token for another audience",
+			token: func() string {
+				claims := validClaims()
+				claims["aud"] = "another-service"
+				return sign(t, test.RsaKeyId, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "reject a token without subject",
+			token: func() string {
+				claims := validClaims()
+				delete(claims, "sub")
+				return sign(t, test.RsaKeyId, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "reject a token signed with a symmetric key",
+			token:   func() string { return hmacToken },
+			wantErr: true,
+		},
+		{
+			name:    "reject a malformed token",
+			token:   func() string { return "not-a-token" },
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := verifier.Verify(tc.token())
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "user1", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	keySet, err := NewFileKeySet(test.JwksFile())
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		name         string
+		options      Options
+		claims       jwt.MapClaims
+		wantIdentity *domain.Identity
+	}{
+		{
+			name:         "map the claims of a tenant user",
+			options:      Options{TenantClaim: "tenant_id", RolesClaim: "roles"},
+			claims:       jwt.MapClaims{"sub": "user1", "tenant_id": "tenant1"},
+			wantIdentity: domain.NewIdentity("user1", "tenant1", []domain.Role{}),
+		},
+		{
+			name:         "map the roles from an array",
+			options:      Options{TenantClaim: "tenant_id", RolesClaim: "roles"},
+			claims:       jwt.MapClaims{"sub": "admin1", "roles": []string{"admin", "operator"}},
+			wantIdentity: domain.NewIdentity("admin1", "", []domain.Role{domain.RoleAdmin, "operator"}),
+		},
+		{
+			name:         "map the roles from a space separated string",
+			options:      Options{TenantClaim: "tenant_id", RolesClaim: "scope"},
+			claims:       jwt.MapClaims{"sub": "admin1", "scope": "admin operator"},
+			wantIdentity: domain.NewIdentity("admin1", "", []domain.Role{domain.RoleAdmin, "operator"}),
+		},
+		{
+			name:    "map nested claims",
+			options: Options{TenantClaim: "org.tenant", RolesClaim: "realm_access.roles"},
+			claims: jwt.MapClaims{
+				"sub":          "user1",
+				"org":          map[string]any{"tenant": "tenant1"},
+				"realm_access": map[string]any{"roles": []string{"admin"}},
+			},
+			wantIdentity: domain.NewIdentity("user1", "tenant1", []domain.Role{domain.RoleAdmin}),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			ctx, err := NewVerifier(keySet, tc.options).Authenticate(context.Background(), sign(t, test.RsaKeyId, tc.claims))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantIdentity, domain.IdentityFromContext(ctx))
+			assert.Equal(t, tc.claims["sub"], ClaimsFromContext(ctx)["sub"])
+		})
+	}
+
+	_, err = NewVerifier(keySet, Options{}).Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, ErrMissingToken)
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	data, err := os.ReadFile(test.JwksFile())
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	keySet, err := NewRemoteKeySet(server.URL, time.Minute)
+	assert.NoError(t, err)
+	claims := jwt.MapClaims{"sub": "user1", "exp": time.Now().Add(time.Hour).Unix()}
+	_, err = NewVerifier(keySet, Options{}).Verify(sign(t, test.EcKeyId, claims))
+	assert.NoError(t, err)
+
+	_, err = NewRemoteKeySet("http://127.0.0.1:0", time.Minute)
+	assert.Error(t, err)
+}
+
+func TestBearerToken(t *testing.T) {
+	testcases := []struct {
+		name          string
+		authorization string
+		wantToken     string
+		wantErr       bool
+	}{
+		{name: "extract a bearer token", authorization: "Bearer token", wantToken: "token"},
+		{name: "extract a bearer token ignoring the case of the scheme", authorization: "bearer token", wantToken: "token"},
+		{name: "reject a missing token", authorization: "", wantErr: true},
+		{name: "reject another scheme", authorization: "Basic dXNlcjpwYXNz", wantErr: true},
+		{name: "reject an empty bearer token", authorization: "Bearer ", wantErr: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := BearerToken(tc.authorization)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantToken, token)
+			}
+		})
+	}
+}
+
+func sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	token, err := test.SignToken(kid, claims)
+	assert.NoError(t, err)
+	return token
+}
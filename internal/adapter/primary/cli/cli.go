@@ -1,34 +1,69 @@
 package cli
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"f4allgo-restaurant/internal/core/domain"
 	"f4allgo-restaurant/internal/core/port"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
 
 const RESTAURANT_ID_DESC string = "the restaurant id"
+const MENU_ITEM_ID_DESC string = "the menu item id"
+const EXCLUDE_ALLERGENS_DESC string = "comma separated list of allergens to exclude from the menus (e.g. nuts,milk)"
+const TICKET_ID_DESC string = "the ticket id"
+const SCHEDULED_MENU_ID_DESC string = "the scheduled menu id"
+const AT_DESC string = "the instant (RFC3339) whose daypart menu is returned instead of the default one"
+const LOCALE_DESC string = "comma separated list of preferred locales for the texts, by decreasing preference (e.g. es-ES,en)"
+const INCLUDE_DELETED_DESC string = "whether the deleted restaurants not purged yet are included (only allowed to admins)"
 
 type RestaurantCli struct {
-	mapper            Mapper
-	restaurantService port.RestaurantService
-	ctx               context.Context
-	validate          *validator.Validate
+	mapper              Mapper
+	restaurantService   port.RestaurantService
+	ticketService       port.TicketService
+	menuScheduleService port.MenuScheduleService
+	ctx                 context.Context
+	validate            *validator.Validate
 }
 
 // NewRestaurantHandler builds a new RestaurantHandler struct.
-func NewRestaurantCli(s port.RestaurantService) *RestaurantCli {
+func NewRestaurantCli(s port.RestaurantService, ts port.TicketService, ms port.MenuScheduleService) *RestaurantCli {
 	validator := validator.New()
 	validator.SetTagName("binding")
-	return &RestaurantCli{mapper: DefaultMapper{}, restaurantService: s, ctx: context.Background(), validate: validator}
+	return &RestaurantCli{mapper: DefaultMapper{}, restaurantService: s, ticketService: ts, menuScheduleService: ms, ctx: context.Background(), validate: validator}
 }
 
 func (rc *RestaurantCli) Execute() error {
-	var rootCmd = &cobra.Command{Use: "f4allgorestaurant-cli"}
+	var rootCmd = &cobra.Command{
+		Use: "f4allgorestaurant-cli",
+		// The identity of the caller is propagated to the core, which authorizes the
+		// operations modifying restaurants. Every run of a command is a request on its
+		// own for the audit log.
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			rc.ctx = domain.ContextWithRequestMetadata(rc.ctx, domain.NewRequestMetadata(uuid.NewString(), domain.SourceCli))
+			userId, _ := cmd.Flags().GetString("userId")
+			if userId == "" {
+				return
+			}
+			tenantId, _ := cmd.Flags().GetString("tenantId")
+			roles, _ := cmd.Flags().GetString("roles")
+			rc.ctx = domain.ContextWithIdentity(rc.ctx, domain.NewIdentity(userId, tenantId, parseRoles(roles)))
+		},
+	}
+	rootCmd.PersistentFlags().String("userId", os.Getenv("USER"), "the id of the user running the command")
+	rootCmd.PersistentFlags().String("tenantId", "", "the tenant of the user running the command")
+	rootCmd.PersistentFlags().String("roles", "", "comma separated list of roles of the user running the command (e.g. admin)")
 
 	// Level 1 subcomands.
 	var getCmd = &cobra.Command{
@@ -51,6 +86,21 @@ func (rc *RestaurantCli) Execute() error {
 		Short: "Delete resources",
 		Long:  "Delete various types of resources",
 	}
+	var restoreCmd = &cobra.Command{
+		Use:   "restore",
+		Short: "Restore resources",
+		Long:  "Restore various types of deleted resources",
+	}
+	var importCmd = &cobra.Command{
+		Use:   "import",
+		Short: "Import resources",
+		Long:  "Import various types of resources from files",
+	}
+	var exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export resources",
+		Long:  "Export various types of resources to files",
+	}
 
 	// Level 2 subcommands.
 	var getRestaurantsCmd = &cobra.Command{
@@ -58,11 +108,48 @@ func (rc *RestaurantCli) Execute() error {
 		Short: "Get restaurants",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			offset, limit := getOffsetAndLimit(cmd)
-			return rc.getRestaurants(offset, limit)
+			excludedAllergens, err := getExcludedAllergens(cmd)
+			if err != nil {
+				return err
+			}
+			near, _ := cmd.Flags().GetString("near")
+			if near != "" {
+				radius, _ := cmd.Flags().GetString("radius")
+				point, meters, err := parseNear(near, radius)
+				if err != nil {
+					return err
+				}
+				return rc.getNearbyRestaurants(point, meters, offset, limit, excludedAllergens, getLocales(cmd))
+			}
+			query, err := getRestaurantQuery(cmd)
+			if err != nil {
+				return err
+			}
+			pagination, err := getPagination(cmd, offset, limit)
+			if err != nil {
+				return err
+			}
+			return rc.getRestaurants(query, pagination, excludedAllergens, getLocales(cmd))
 		},
 	}
 	getRestaurantsCmd.PersistentFlags().String("offset", "", "the offset to use in pagination")
 	getRestaurantsCmd.PersistentFlags().String("limit", "", "the limit to use in pagination")
+	getRestaurantsCmd.PersistentFlags().String("excludeAllergens", "", EXCLUDE_ALLERGENS_DESC)
+	getRestaurantsCmd.PersistentFlags().String("near", "", "only the restaurants near a point given as 'latitude,longitude', sorted by distance")
+	getRestaurantsCmd.PersistentFlags().String("radius", "5000", "the radius (in meters) used with --near")
+	getRestaurantsCmd.PersistentFlags().String("locale", "", LOCALE_DESC)
+	getRestaurantsCmd.PersistentFlags().String("name", "", "only the restaurants whose name starts with this prefix (ignoring case)")
+	getRestaurantsCmd.PersistentFlags().String("city", "", "only the restaurants in this city (ignoring case)")
+	getRestaurantsCmd.PersistentFlags().String("state", "", "only the restaurants in this state (ignoring case)")
+	getRestaurantsCmd.PersistentFlags().String("zip", "", "only the restaurants with this zip code")
+	getRestaurantsCmd.PersistentFlags().String("menuItem", "", "only the restaurants with a menu item whose name contains this text (ignoring case)")
+	getRestaurantsCmd.PersistentFlags().String("minPrice", "", "only the restaurants with a menu item priced at least at this amount")
+	getRestaurantsCmd.PersistentFlags().String("maxPrice", "", "only the restaurants with a menu item priced at most at this amount")
+	getRestaurantsCmd.PersistentFlags().String("sort", "", "the field to sort the restaurants by (id, name, city, state or zip)")
+	getRestaurantsCmd.PersistentFlags().String("direction", "", "the sort direction (asc or desc)")
+	getRestaurantsCmd.PersistentFlags().Bool("includeDeleted", false, INCLUDE_DELETED_DESC)
+	getRestaurantsCmd.PersistentFlags().String("cursor", "", "the cursor of the page to get (the nextCursor or prevCursor of a previous page), used instead of the offset")
+	getRestaurantsCmd.PersistentFlags().String("count", "", "how the total is computed (exact, estimated or none)")
 
 	var getRestaurantCmd = &cobra.Command{
 		Use:   "restaurant",
@@ -72,10 +159,96 @@ func (rc *RestaurantCli) Execute() error {
 			if err != nil {
 				return err
 			}
-			return rc.getRestaurant(restaurantId)
+			excludedAllergens, err := getExcludedAllergens(cmd)
+			if err != nil {
+				return err
+			}
+			atStr, err := cmd.Flags().GetString("at")
+			if err != nil {
+				return err
+			}
+			var at *time.Time
+			if atStr != "" {
+				t, err := time.Parse(time.RFC3339, atStr)
+				if err != nil {
+					return err
+				}
+				at = &t
+			}
+			return rc.getRestaurant(restaurantId, at, excludedAllergens, getLocales(cmd))
 		},
 	}
 	getRestaurantCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	getRestaurantCmd.PersistentFlags().String("excludeAllergens", "", EXCLUDE_ALLERGENS_DESC)
+	getRestaurantCmd.PersistentFlags().String("at", "", AT_DESC)
+	getRestaurantCmd.PersistentFlags().String("locale", "", LOCALE_DESC)
+
+	var getTicketsCmd = &cobra.Command{
+		Use:   "tickets",
+		Short: "Get the tickets of a restaurant",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			var state *domain.TicketState
+			if value, _ := cmd.Flags().GetString("state"); value != "" {
+				parsed, err := domain.ParseTicketState(value)
+				if err != nil {
+					return err
+				}
+				state = &parsed
+			}
+			offset, limit := getOffsetAndLimit(cmd)
+			return rc.getTickets(restaurantId, state, offset, limit)
+		},
+	}
+	getTicketsCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	getTicketsCmd.PersistentFlags().String("state", "", "only the tickets in this state (e.g. accepted)")
+	getTicketsCmd.PersistentFlags().String("offset", "", "the offset to use in pagination")
+	getTicketsCmd.PersistentFlags().String("limit", "", "the limit to use in pagination")
+
+	var getTicketCmd = &cobra.Command{
+		Use:   "ticket",
+		Short: "Get ticket",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ticketId, err := cmd.Flags().GetInt64("ticketId")
+			if err != nil {
+				return err
+			}
+			return rc.getTicket(ticketId)
+		},
+	}
+	getTicketCmd.PersistentFlags().Int64("ticketId", 0, TICKET_ID_DESC)
+
+	var getScheduledMenusCmd = &cobra.Command{
+		Use:   "scheduled-menus",
+		Short: "Get the pending scheduled menus of a restaurant",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			return rc.getScheduledMenus(restaurantId)
+		},
+	}
+	getScheduledMenusCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+
+	var getAuditLogCmd = &cobra.Command{
+		Use:   "audit-log",
+		Short: "Get the audit log of a restaurant",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			offset, limit := getOffsetAndLimit(cmd)
+			return rc.getAuditLog(restaurantId, offset, limit)
+		},
+	}
+	getAuditLogCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	getAuditLogCmd.PersistentFlags().String("offset", "", "the offset to use in pagination")
+	getAuditLogCmd.PersistentFlags().String("limit", "", "the limit to use in pagination")
 
 	var createRestaurantCmd = &cobra.Command{
 		Use:   "restaurant",
@@ -94,6 +267,103 @@ func (rc *RestaurantCli) Execute() error {
 	}
 	createRestaurantCmd.PersistentFlags().String("json", "", "the JSON payload")
 
+	var importRestaurantsCmd = &cobra.Command{
+		Use:   "restaurants",
+		Short: "Import restaurants from a CSV or JSONL file",
+		Long: "Import restaurants from a CSV file, with a menu item per row and consecutive rows of the same restaurant, " +
+			"or from a JSONL file, with a restaurant per line. A report with the result of each restaurant is printed.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			if file == "" {
+				return fmt.Errorf("the file to import is required")
+			}
+			mode, _ := cmd.Flags().GetString("mode")
+			batchMode, err := domain.ParseBatchMode(mode)
+			if err != nil {
+				return err
+			}
+			return rc.importRestaurants(file, batchMode)
+		},
+	}
+	importRestaurantsCmd.PersistentFlags().String("file", "", "the CSV (.csv) or JSONL (.jsonl) file to import")
+	importRestaurantsCmd.PersistentFlags().String("mode", string(domain.BatchBestEffort), "whether all the restaurants are created or none (all-or-nothing), or each one independently (best-effort)")
+
+	var exportRestaurantsCmd = &cobra.Command{
+		Use:   "restaurants",
+		Short: "Export restaurants with their menus to a JSONL, CSV or Parquet file",
+		Long: "Export the restaurants matching the filters to a JSONL file, with a restaurant per line, " +
+			"or to a CSV or Parquet file, with a menu item per row. The restaurants are read in batches, so the whole table is never loaded at once.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query, err := getRestaurantQuery(cmd)
+			if err != nil {
+				return err
+			}
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+			gzipped, _ := cmd.Flags().GetBool("gzip")
+			batchSize, _ := cmd.Flags().GetInt("batchSize")
+			return rc.exportRestaurants(query, format, output, gzipped, batchSize)
+		},
+	}
+	exportRestaurantsCmd.PersistentFlags().String("format", exportJsonl, "the format of the file (jsonl, csv or parquet)")
+	exportRestaurantsCmd.PersistentFlags().String("output", "", "the file to write (the standard output if empty)")
+	exportRestaurantsCmd.PersistentFlags().Bool("gzip", false, "whether the file is compressed with gzip")
+	exportRestaurantsCmd.PersistentFlags().Int("batchSize", 500, "the number of restaurants read at once")
+	exportRestaurantsCmd.PersistentFlags().String("name", "", "only the restaurants whose name starts with this prefix (ignoring case)")
+	exportRestaurantsCmd.PersistentFlags().String("city", "", "only the restaurants in this city (ignoring case)")
+	exportRestaurantsCmd.PersistentFlags().String("state", "", "only the restaurants in this state (ignoring case)")
+	exportRestaurantsCmd.PersistentFlags().String("zip", "", "only the restaurants with this zip code")
+	exportRestaurantsCmd.PersistentFlags().String("menuItem", "", "only the restaurants with a menu item whose name contains this text (ignoring case)")
+	exportRestaurantsCmd.PersistentFlags().String("minPrice", "", "only the restaurants with a menu item priced at least at this amount")
+	exportRestaurantsCmd.PersistentFlags().String("maxPrice", "", "only the restaurants with a menu item priced at most at this amount")
+	exportRestaurantsCmd.PersistentFlags().String("sort", "", "the field to sort the restaurants by (id, name, city, state or zip)")
+	exportRestaurantsCmd.PersistentFlags().String("direction", "", "the sort direction (asc or desc)")
+	exportRestaurantsCmd.PersistentFlags().Bool("includeDeleted", false, INCLUDE_DELETED_DESC)
+
+	var createMenuItemCmd = &cobra.Command{
+		Use:   "menu-item",
+		Short: "Add an item to a restaurant menu",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			jsonData, err := cmd.Flags().GetString("json")
+			if err != nil {
+				return err
+			}
+			var request AddMenuItemRequest
+			if err := json.Unmarshal([]byte(jsonData), &request); err != nil {
+				return err
+			}
+			return rc.addMenuItem(restaurantId, request)
+		},
+	}
+	createMenuItemCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	createMenuItemCmd.PersistentFlags().String("json", "", "the JSON payload")
+
+	var updateRestaurantCmd = &cobra.Command{
+		Use:   "restaurant",
+		Short: "Update restaurant name and/or address",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			jsonData, err := cmd.Flags().GetString("json")
+			if err != nil {
+				return err
+			}
+			var request UpdateRestaurantRequest
+			if err := json.Unmarshal([]byte(jsonData), &request); err != nil {
+				return err
+			}
+			return rc.updateRestaurant(restaurantId, request)
+		},
+	}
+	updateRestaurantCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	updateRestaurantCmd.PersistentFlags().String("json", "", "the JSON payload")
+
 	var updateRestaurantMenuCmd = &cobra.Command{
 		Use:   "menu",
 		Short: "Update restaurant menu",
@@ -116,6 +386,144 @@ func (rc *RestaurantCli) Execute() error {
 	updateRestaurantMenuCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
 	updateRestaurantMenuCmd.PersistentFlags().String("json", "", "the JSON payload")
 
+	var updateDaypartsCmd = &cobra.Command{
+		Use:   "dayparts",
+		Short: "Replace the dayparts of a restaurant",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			jsonData, err := cmd.Flags().GetString("json")
+			if err != nil {
+				return err
+			}
+			var request UpdateDaypartsRequest
+			if err := json.Unmarshal([]byte(jsonData), &request); err != nil {
+				return err
+			}
+			return rc.updateDayparts(restaurantId, request)
+		},
+	}
+	updateDaypartsCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	updateDaypartsCmd.PersistentFlags().String("json", "", "the JSON payload")
+
+	var updateItemAvailabilityCmd = &cobra.Command{
+		Use:   "availability",
+		Short: "Update the availability of a menu item",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			menuItemId, err := cmd.Flags().GetInt16("menuItemId")
+			if err != nil {
+				return err
+			}
+			available, err := cmd.Flags().GetBool("available")
+			if err != nil {
+				return err
+			}
+			untilStr, err := cmd.Flags().GetString("until")
+			if err != nil {
+				return err
+			}
+			var request = SetItemAvailabilityRequest{Available: &available}
+			if untilStr != "" {
+				until, err := time.Parse(time.RFC3339, untilStr)
+				if err != nil {
+					return err
+				}
+				request.AvailableUntil = &until
+			}
+			return rc.setItemAvailability(restaurantId, menuItemId, request)
+		},
+	}
+	updateItemAvailabilityCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	updateItemAvailabilityCmd.PersistentFlags().Int16("menuItemId", 0, MENU_ITEM_ID_DESC)
+	updateItemAvailabilityCmd.PersistentFlags().Bool("available", true, "whether the menu item is available or not")
+	updateItemAvailabilityCmd.PersistentFlags().String("until", "", "the instant (RFC3339) until which the menu item is not available")
+
+	var updateMenuItemCmd = &cobra.Command{
+		Use:   "menu-item",
+		Short: "Update an item of a restaurant menu",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			menuItemId, err := cmd.Flags().GetInt16("menuItemId")
+			if err != nil {
+				return err
+			}
+			jsonData, err := cmd.Flags().GetString("json")
+			if err != nil {
+				return err
+			}
+			var request UpdateMenuItemRequest
+			if err := json.Unmarshal([]byte(jsonData), &request); err != nil {
+				return err
+			}
+			return rc.updateMenuItem(restaurantId, menuItemId, request)
+		},
+	}
+	updateMenuItemCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	updateMenuItemCmd.PersistentFlags().Int16("menuItemId", 0, MENU_ITEM_ID_DESC)
+	updateMenuItemCmd.PersistentFlags().String("json", "", "the JSON payload")
+
+	var createTicketCmd = &cobra.Command{
+		Use:   "ticket",
+		Short: "Create a ticket for an order in a restaurant",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			jsonData, err := cmd.Flags().GetString("json")
+			if err != nil {
+				return err
+			}
+			var request CreateTicketRequest
+			if err := json.Unmarshal([]byte(jsonData), &request); err != nil {
+				return err
+			}
+			return rc.createTicket(restaurantId, request)
+		},
+	}
+	createTicketCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	createTicketCmd.PersistentFlags().String("json", "", "the JSON payload")
+
+	var updateTicketCmd = &cobra.Command{
+		Use:   "ticket",
+		Short: "Move a ticket forward in its lifecycle",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ticketId, err := cmd.Flags().GetInt64("ticketId")
+			if err != nil {
+				return err
+			}
+			action, err := cmd.Flags().GetString("action")
+			if err != nil {
+				return err
+			}
+			readyByStr, err := cmd.Flags().GetString("readyBy")
+			if err != nil {
+				return err
+			}
+			var readyBy *time.Time
+			if readyByStr != "" {
+				t, err := time.Parse(time.RFC3339, readyByStr)
+				if err != nil {
+					return err
+				}
+				readyBy = &t
+			}
+			return rc.changeTicketState(ticketId, action, readyBy)
+		},
+	}
+	updateTicketCmd.PersistentFlags().Int64("ticketId", 0, TICKET_ID_DESC)
+	updateTicketCmd.PersistentFlags().String("action", "", "one of accept, reject, start-preparing, ready-for-pickup, pick-up or cancel")
+	updateTicketCmd.PersistentFlags().String("readyBy", "", "the instant (RFC3339) by which the ticket will be ready (required to accept)")
+
 	var deleteRestaurantCmd = &cobra.Command{
 		Use:   "restaurant",
 		Short: "Delete restaurant",
@@ -129,24 +537,98 @@ func (rc *RestaurantCli) Execute() error {
 	}
 	deleteRestaurantCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
 
+	var restoreRestaurantCmd = &cobra.Command{
+		Use:   "restaurant",
+		Short: "Restore a deleted restaurant that hasn't been purged yet",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			return rc.restoreRestaurant(restaurantId)
+		},
+	}
+	restoreRestaurantCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+
+	var deleteMenuItemCmd = &cobra.Command{
+		Use:   "menu-item",
+		Short: "Remove an item from a restaurant menu",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			menuItemId, err := cmd.Flags().GetInt16("menuItemId")
+			if err != nil {
+				return err
+			}
+			return rc.removeMenuItem(restaurantId, menuItemId)
+		},
+	}
+	deleteMenuItemCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	deleteMenuItemCmd.PersistentFlags().Int16("menuItemId", 0, MENU_ITEM_ID_DESC)
+
+	var deleteScheduledMenuCmd = &cobra.Command{
+		Use:   "scheduled-menu",
+		Short: "Cancel a pending scheduled menu of a restaurant",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restaurantId, err := cmd.Flags().GetInt64("restaurantId")
+			if err != nil {
+				return err
+			}
+			scheduledMenuId, err := cmd.Flags().GetInt64("scheduledMenuId")
+			if err != nil {
+				return err
+			}
+			return rc.cancelScheduledMenu(restaurantId, scheduledMenuId)
+		},
+	}
+	deleteScheduledMenuCmd.PersistentFlags().Int64("restaurantId", 0, RESTAURANT_ID_DESC)
+	deleteScheduledMenuCmd.PersistentFlags().Int64("scheduledMenuId", 0, SCHEDULED_MENU_ID_DESC)
+
 	// Subcommands for 'get'.
 	getCmd.AddCommand(getRestaurantsCmd)
 	getCmd.AddCommand(getRestaurantCmd)
+	getCmd.AddCommand(getTicketsCmd)
+	getCmd.AddCommand(getTicketCmd)
+	getCmd.AddCommand(getScheduledMenusCmd)
+	getCmd.AddCommand(getAuditLogCmd)
 
 	// Subcommands for 'create'.
 	createCmd.AddCommand(createRestaurantCmd)
+	createCmd.AddCommand(createMenuItemCmd)
+	createCmd.AddCommand(createTicketCmd)
 
 	// Subcommands for 'update'.
+	updateCmd.AddCommand(updateRestaurantCmd)
 	updateCmd.AddCommand(updateRestaurantMenuCmd)
+	updateCmd.AddCommand(updateDaypartsCmd)
+	updateCmd.AddCommand(updateItemAvailabilityCmd)
+	updateCmd.AddCommand(updateMenuItemCmd)
+	updateCmd.AddCommand(updateTicketCmd)
 
 	// Subcommands for 'delete'.
 	deleteCmd.AddCommand(deleteRestaurantCmd)
+	deleteCmd.AddCommand(deleteMenuItemCmd)
+	deleteCmd.AddCommand(deleteScheduledMenuCmd)
+
+	// Subcommands for 'restore'.
+	restoreCmd.AddCommand(restoreRestaurantCmd)
+
+	// Import subcommands.
+	importCmd.AddCommand(importRestaurantsCmd)
+
+	// Export subcommands.
+	exportCmd.AddCommand(exportRestaurantsCmd)
 
 	// Register all the subcommands.
 	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
 
 	return rootCmd.Execute()
 }
@@ -163,39 +645,304 @@ func (rc *RestaurantCli) createRestaurant(request CreateRestaurantRequest) error
 	return printJSON(CreateRestaurantResponse{RestaurantId: restaurantId})
 }
 
+// importRestaurants creates the restaurants of a file, printing a report with the
+// result of each one. Best-effort imports are created in batches, reporting their
+// progress, while all-or-nothing imports aren't attempted if a restaurant is invalid.
+func (rc *RestaurantCli) importRestaurants(path string, mode domain.BatchMode) error {
+	rows, err := readImportFile(path)
+	if err != nil {
+		return err
+	}
+
+	response := ImportRestaurantsResponse{Results: make([]*ImportResult, len(rows))}
+	valid := []int{}
+	for i, row := range rows {
+		if row.err == nil {
+			row.err = rc.validate.Struct(row.restaurant)
+		}
+		if row.err == nil {
+			valid = append(valid, i)
+		} else {
+			response.Results[i] = &ImportResult{Line: row.line, Error: row.err.Error()}
+		}
+	}
+
+	batchSize := importBatchSize
+	if mode == domain.BatchAllOrNothing {
+		if len(valid) < len(rows) {
+			aborted := coreerrors.NewBatchAbortedError().Error()
+			for _, i := range valid {
+				response.Results[i] = &ImportResult{Line: rows[i].line, Error: aborted}
+			}
+			valid = nil
+		}
+		batchSize = len(valid)
+	}
+	for start := 0; start < len(valid); start += batchSize {
+		end := start + batchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		batch := valid[start:end]
+		restaurants := make([]*domain.Restaurant, len(batch))
+		for j, i := range batch {
+			restaurants[j] = rc.mapper.toDomainRestaurant(rows[i].restaurant)
+		}
+		results, err := rc.restaurantService.CreateMany(rc.ctx, restaurants, mode)
+		if err != nil {
+			return err
+		}
+		for j, i := range batch {
+			response.Results[i] = &ImportResult{Line: rows[i].line, RestaurantId: results[j].GetRestaurantId()}
+			if !results[j].IsCreated() {
+				response.Results[i].Error = results[j].GetError().Error()
+			}
+		}
+		fmt.Fprintf(os.Stderr, "imported %d/%d restaurants\n", start+len(batch), len(valid))
+	}
+
+	for _, result := range response.Results {
+		if result.Error == "" {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+	return printJSON(response)
+}
+
+// exportRestaurants writes the restaurants matching the query to a file (or to the
+// standard output), reporting the progress after each batch.
+func (rc *RestaurantCli) exportRestaurants(query *domain.RestaurantQuery, format string, output string, gzipped bool, batchSize int) (err error) {
+	var out io.Writer = os.Stdout
+	if output != "" {
+		var file *os.File
+		if file, err = os.Create(output); err != nil {
+			return err
+		}
+		defer closeOnError(file, &err)
+		out = file
+	}
+	if gzipped {
+		gzipWriter := gzip.NewWriter(out)
+		defer closeOnError(gzipWriter, &err)
+		out = gzipWriter
+	}
+	exportWriter, err := newRestaurantWriter(format, out)
+	if err != nil {
+		return err
+	}
+
+	exported := 0
+	if err := rc.restaurantService.Export(rc.ctx, query, batchSize, func(restaurants []*domain.Restaurant) error {
+		if err := exportWriter.write(rc.mapper.fromDomainRestaurants(restaurants)); err != nil {
+			return err
+		}
+		exported += len(restaurants)
+		fmt.Fprintf(os.Stderr, "exported %d restaurants\n", exported)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return exportWriter.close()
+}
+
+// closeOnError closes a resource, reporting the error of closing it unless there's
+// a previous one.
+func closeOnError(closer io.Closer, err *error) {
+	if closeErr := closer.Close(); *err == nil {
+		*err = closeErr
+	}
+}
+
 // deleteRestaurant deletes a restaurant.
 func (rc *RestaurantCli) deleteRestaurant(restaurantId int64) error {
 	return rc.restaurantService.Delete(rc.ctx, restaurantId)
 }
 
-// getRestaurants gets the list of all restaurants.
-func (rc *RestaurantCli) getRestaurants(offset int, limit int) error {
-	domainRestaurants, total, err := rc.restaurantService.FindAll(rc.ctx, offset, limit)
+// restoreRestaurant restores a deleted restaurant.
+func (rc *RestaurantCli) restoreRestaurant(restaurantId int64) error {
+	return rc.restaurantService.Restore(rc.ctx, restaurantId)
+}
+
+// getRestaurants gets a page of the restaurants matching the query, with the texts
+// in the locale that best matches the preferred ones.
+func (rc *RestaurantCli) getRestaurants(query *domain.RestaurantQuery, pagination *domain.Pagination, excludedAllergens []domain.Allergen, locales []string) error {
+	page, err := rc.restaurantService.FindAll(rc.ctx, query, pagination, excludedAllergens)
+	if err != nil {
+		return err
+	}
+	for i, domainRestaurant := range page.Restaurants {
+		page.Restaurants[i] = localize(domainRestaurant, locales)
+	}
+	return printJSON(rc.mapper.fromDomainRestaurantPage(page))
+}
+
+// getNearbyRestaurants gets the list of restaurants within a radius of a point.
+func (rc *RestaurantCli) getNearbyRestaurants(point *domain.GeoPoint, radius float64, offset int, limit int, excludedAllergens []domain.Allergen, locales []string) error {
+	domainRestaurants, total, err := rc.restaurantService.FindNearby(rc.ctx, point, radius, offset, limit, excludedAllergens)
 	if err != nil {
 		return err
 	}
+	for i, domainRestaurant := range domainRestaurants {
+		domainRestaurants[i] = localize(domainRestaurant, locales)
+	}
 	dtoRestaurants := rc.mapper.fromDomainRestaurants(domainRestaurants)
-	return printJSON(GetRestaurantsResponse{Restaurants: dtoRestaurants, Total: total})
+	return printJSON(GetRestaurantsResponse{Restaurants: dtoRestaurants, Total: &total})
 }
 
-// getRestaurant get a restaurant by its id.
-func (rc *RestaurantCli) getRestaurant(restaurantId int64) error {
-	domainRestaurant, err := rc.restaurantService.FindById(rc.ctx, restaurantId)
+// getRestaurant get a restaurant by its id, with the menu offered at the given
+// instant if present and the texts in the locale that best matches the preferred ones.
+func (rc *RestaurantCli) getRestaurant(restaurantId int64, at *time.Time, excludedAllergens []domain.Allergen, locales []string) error {
+	domainRestaurant, err := rc.restaurantService.FindById(rc.ctx, restaurantId, at, excludedAllergens)
 	if err != nil {
 		return err
 	}
-	dtoRestaurant := rc.mapper.fromDomainRestaurant(domainRestaurant)
+	dtoRestaurant := rc.mapper.fromDomainRestaurant(localize(domainRestaurant, locales))
 	return printJSON(GetRestaurantResponse{Restaurant: dtoRestaurant})
 }
 
-// updateMenu updates the menu of a restaurant.
+// updateRestaurant renames and/or relocates a restaurant.
+func (rc *RestaurantCli) updateRestaurant(restaurantId int64, request UpdateRestaurantRequest) error {
+	if err := rc.validate.Struct(request); err != nil {
+		return err
+	}
+	return rc.restaurantService.UpdateRestaurant(rc.ctx, restaurantId, rc.mapper.toDomainRestaurantChanges(&request))
+}
+
+// updateMenu updates the menu of a restaurant, or schedules it to replace the
+// current one if an effective instant is provided.
 func (rc *RestaurantCli) updateMenu(restaurantId int64, request UpdateMenuRequest) error {
 	if err := rc.validate.Struct(request); err != nil {
 		return err
 	}
+	if request.EffectiveFrom != nil {
+		scheduledMenuId, err := rc.menuScheduleService.Schedule(rc.ctx, restaurantId, rc.mapper.toDomainMenu(request.Menu), *request.EffectiveFrom)
+		if err != nil {
+			return err
+		}
+		return printJSON(ScheduleMenuResponse{ScheduledMenuId: scheduledMenuId})
+	}
 	return rc.restaurantService.UpdateMenu(rc.ctx, restaurantId, rc.mapper.toDomainMenu(request.Menu))
 }
 
+// updateDayparts replaces the dayparts of a restaurant.
+func (rc *RestaurantCli) updateDayparts(restaurantId int64, request UpdateDaypartsRequest) error {
+	if err := rc.validate.Struct(request); err != nil {
+		return err
+	}
+	domainDayparts, err := rc.mapper.toDomainDayparts(request.Dayparts)
+	if err != nil {
+		return err
+	}
+	return rc.restaurantService.UpdateDayparts(rc.ctx, restaurantId, domainDayparts)
+}
+
+// getScheduledMenus gets the pending scheduled menus of a restaurant.
+func (rc *RestaurantCli) getScheduledMenus(restaurantId int64) error {
+	domainScheduledMenus, err := rc.menuScheduleService.FindByRestaurant(rc.ctx, restaurantId)
+	if err != nil {
+		return err
+	}
+	return printJSON(GetScheduledMenusResponse{ScheduledMenus: rc.mapper.fromDomainScheduledMenus(domainScheduledMenus)})
+}
+
+// getAuditLog gets the audit entries of the mutations of a restaurant, newest first.
+func (rc *RestaurantCli) getAuditLog(restaurantId int64, offset int, limit int) error {
+	domainEntries, total, err := rc.restaurantService.FindAuditEntries(rc.ctx, restaurantId, offset, limit)
+	if err != nil {
+		return err
+	}
+	return printJSON(GetAuditEntriesResponse{Entries: rc.mapper.fromDomainAuditEntries(domainEntries), Total: total})
+}
+
+// cancelScheduledMenu cancels a pending scheduled menu of a restaurant.
+func (rc *RestaurantCli) cancelScheduledMenu(restaurantId int64, scheduledMenuId int64) error {
+	return rc.menuScheduleService.Cancel(rc.ctx, restaurantId, scheduledMenuId)
+}
+
+// setItemAvailability updates the availability of a single menu item.
+func (rc *RestaurantCli) setItemAvailability(restaurantId int64, menuItemId int16, request SetItemAvailabilityRequest) error {
+	if err := rc.validate.Struct(request); err != nil {
+		return err
+	}
+	return rc.restaurantService.SetItemAvailability(rc.ctx, restaurantId, menuItemId, *request.Available, request.AvailableUntil)
+}
+
+// addMenuItem adds a single item to the menu of a restaurant.
+func (rc *RestaurantCli) addMenuItem(restaurantId int64, request AddMenuItemRequest) error {
+	if err := rc.validate.Struct(request); err != nil {
+		return err
+	}
+	return rc.restaurantService.AddMenuItem(rc.ctx, restaurantId, rc.mapper.toDomainMenuItem(request.MenuItem))
+}
+
+// updateMenuItem applies partial changes to a single menu item.
+func (rc *RestaurantCli) updateMenuItem(restaurantId int64, menuItemId int16, request UpdateMenuItemRequest) error {
+	if err := rc.validate.Struct(request); err != nil {
+		return err
+	}
+	return rc.restaurantService.UpdateMenuItem(rc.ctx, restaurantId, menuItemId, rc.mapper.toDomainMenuItemChanges(&request))
+}
+
+// removeMenuItem removes a single item from the menu of a restaurant.
+func (rc *RestaurantCli) removeMenuItem(restaurantId int64, menuItemId int16) error {
+	return rc.restaurantService.RemoveMenuItem(rc.ctx, restaurantId, menuItemId)
+}
+
+// getTickets gets the tickets of a restaurant.
+func (rc *RestaurantCli) getTickets(restaurantId int64, state *domain.TicketState, offset int, limit int) error {
+	domainTickets, total, err := rc.ticketService.FindByRestaurant(rc.ctx, restaurantId, state, offset, limit)
+	if err != nil {
+		return err
+	}
+	return printJSON(GetTicketsResponse{Tickets: rc.mapper.fromDomainTickets(domainTickets), Total: total})
+}
+
+// getTicket gets a ticket by its id.
+func (rc *RestaurantCli) getTicket(ticketId int64) error {
+	domainTicket, err := rc.ticketService.FindById(rc.ctx, ticketId)
+	if err != nil {
+		return err
+	}
+	return printJSON(GetTicketResponse{Ticket: rc.mapper.fromDomainTicket(domainTicket)})
+}
+
+// createTicket creates a ticket for an order in a restaurant.
+func (rc *RestaurantCli) createTicket(restaurantId int64, request CreateTicketRequest) error {
+	if err := rc.validate.Struct(request); err != nil {
+		return err
+	}
+	ticketId, err := rc.ticketService.Create(rc.ctx, restaurantId, request.OrderId, rc.mapper.toDomainTicketLineItems(request.LineItems))
+	if err != nil {
+		return err
+	}
+	return printJSON(CreateTicketResponse{TicketId: ticketId})
+}
+
+// changeTicketState applies a transition of the ticket lifecycle.
+func (rc *RestaurantCli) changeTicketState(ticketId int64, action string, readyBy *time.Time) error {
+	switch action {
+	case "accept":
+		if readyBy == nil {
+			return fmt.Errorf("--readyBy is required to accept a ticket")
+		}
+		return rc.ticketService.Accept(rc.ctx, ticketId, *readyBy)
+	case "reject":
+		return rc.ticketService.Reject(rc.ctx, ticketId)
+	case "start-preparing":
+		return rc.ticketService.StartPreparing(rc.ctx, ticketId)
+	case "ready-for-pickup":
+		return rc.ticketService.MarkReadyForPickup(rc.ctx, ticketId)
+	case "pick-up":
+		return rc.ticketService.PickUp(rc.ctx, ticketId)
+	case "cancel":
+		return rc.ticketService.Cancel(rc.ctx, ticketId)
+	default:
+		return fmt.Errorf("unknown ticket action '%s'", action)
+	}
+}
+
 func printJSON(jsonStruct any) error {
 	jsonData, err := json.MarshalIndent(jsonStruct, "", "  ")
 	if err != nil {
@@ -228,3 +975,71 @@ func getOffsetAndLimit(cmd *cobra.Command) (int, int) {
 
 	return int(offset), int(limit)
 }
+
+func getExcludedAllergens(cmd *cobra.Command) ([]domain.Allergen, error) {
+	value, _ := cmd.Flags().GetString("excludeAllergens")
+	return parseAllergens(value)
+}
+
+func getRestaurantQuery(cmd *cobra.Command) (*domain.RestaurantQuery, error) {
+	query := &domain.RestaurantQuery{}
+	query.NamePrefix, _ = cmd.Flags().GetString("name")
+	query.City, _ = cmd.Flags().GetString("city")
+	query.State, _ = cmd.Flags().GetString("state")
+	query.Zip, _ = cmd.Flags().GetString("zip")
+	query.MenuItemName, _ = cmd.Flags().GetString("menuItem")
+	var err error
+	minPrice, _ := cmd.Flags().GetString("minPrice")
+	if query.MinPrice, err = parsePrice(minPrice); err != nil {
+		return nil, err
+	}
+	maxPrice, _ := cmd.Flags().GetString("maxPrice")
+	if query.MaxPrice, err = parsePrice(maxPrice); err != nil {
+		return nil, err
+	}
+	if sort, _ := cmd.Flags().GetString("sort"); sort != "" {
+		if query.SortBy, err = domain.ParseRestaurantSortField(sort); err != nil {
+			return nil, err
+		}
+	}
+	if direction, _ := cmd.Flags().GetString("direction"); direction != "" {
+		if query.SortDirection, err = domain.ParseSortDirection(direction); err != nil {
+			return nil, err
+		}
+	}
+	query.IncludeDeleted, _ = cmd.Flags().GetBool("includeDeleted")
+	return query, nil
+}
+
+func getPagination(cmd *cobra.Command, offset int, limit int) (*domain.Pagination, error) {
+	pagination := &domain.Pagination{Offset: offset, Limit: limit}
+	var err error
+	if cursor, _ := cmd.Flags().GetString("cursor"); cursor != "" {
+		if pagination.Cursor, err = domain.DecodeCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+	if count, _ := cmd.Flags().GetString("count"); count != "" {
+		if pagination.Count, err = domain.ParseCountMode(count); err != nil {
+			return nil, err
+		}
+	}
+	return pagination, nil
+}
+
+func getLocales(cmd *cobra.Command) []string {
+	value, _ := cmd.Flags().GetString("locale")
+	locales := []string{}
+	for _, locale := range strings.Split(value, ",") {
+		if locale = strings.TrimSpace(locale); locale != "" {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// localize returns the restaurant with the texts of the available locale that
+// best matches the preferred ones.
+func localize(restaurant *domain.Restaurant, preferred []string) *domain.Restaurant {
+	return restaurant.Localize(domain.NegotiateLocale(restaurant.Locales(), preferred))
+}
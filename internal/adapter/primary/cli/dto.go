@@ -1,21 +1,32 @@
 package cli
 
+import "time"
+
 // --------------------------------------------------------------------------------
 // OpenAPI components :: model
 // --------------------------------------------------------------------------------
 
 type Restaurant struct {
-	Id      int64    `json:"id"`
-	Name    string   `json:"name" binding:"required,max=255"`
-	Address *Address `json:"address" binding:"required"`
-	Menu    *Menu    `json:"menu" binding:"required"`
+	Id                      int64             `json:"id"`
+	TenantId                string            `json:"tenantId,omitempty" binding:"max=255"`
+	Name                    string            `json:"name" binding:"required,max=255"`
+	DefaultLocale           string            `json:"defaultLocale,omitempty" binding:"omitempty,bcp47_language_tag"`
+	TimeZone                string            `json:"timeZone,omitempty" binding:"omitempty,timezone"`
+	Description             string            `json:"description,omitempty" binding:"max=1000"`
+	DescriptionTranslations map[string]string `json:"descriptionTranslations,omitempty" binding:"omitempty,dive,keys,bcp47_language_tag,endkeys,max=1000"`
+	Address                 *Address          `json:"address" binding:"required"`
+	Menu                    *Menu             `json:"menu" binding:"required"`
+	Dayparts                []*Daypart        `json:"dayparts,omitempty"`
+	DeletedAt               *time.Time        `json:"deletedAt,omitempty"`
 }
 
 type Address struct {
-	Street string `json:"street" binding:"required,max=255"`
-	City   string `json:"city" binding:"required,max=255"`
-	State  string `json:"state" binding:"required,max=255"`
-	Zip    string `json:"zip" binding:"required,max=255"`
+	Street    string   `json:"street" binding:"required,max=255"`
+	City      string   `json:"city" binding:"required,max=255"`
+	State     string   `json:"state" binding:"required,max=255"`
+	Zip       string   `json:"zip" binding:"required,max=255"`
+	Latitude  *float64 `json:"latitude,omitempty" binding:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `json:"longitude,omitempty" binding:"required_with=Latitude,omitempty,longitude"`
 }
 
 type Menu struct {
@@ -23,9 +34,78 @@ type Menu struct {
 }
 
 type MenuItem struct {
-	Id    int32  `json:"id" binding:"required"`
-	Name  string `json:"name" binding:"required,max=255"`
-	Price string `json:"price" binding:"required,max=10"`
+	Id             int32                  `json:"id" binding:"required"`
+	Name           string                 `json:"name" binding:"required,max=255"`
+	Description    string                 `json:"description,omitempty" binding:"max=1000"`
+	Translations   map[string]Translation `json:"translations,omitempty" binding:"omitempty,dive,keys,bcp47_language_tag,endkeys"`
+	Price          string                 `json:"price" binding:"required,max=10"`
+	Available      *bool                  `json:"available,omitempty"`
+	AvailableUntil *time.Time             `json:"availableUntil,omitempty"`
+	Allergens      []string               `json:"allergens,omitempty" binding:"omitempty,unique,dive,oneof=celery gluten crustaceans eggs fish lupin milk molluscs mustard nuts peanuts sesame soybeans sulphites"`
+	DietaryTags    []string               `json:"dietaryTags,omitempty" binding:"omitempty,unique,dive,oneof=vegan vegetarian gluten-free"`
+}
+
+type Translation struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description,omitempty" binding:"max=1000"`
+}
+
+type Daypart struct {
+	Name    string       `json:"name" binding:"required,max=50"`
+	Windows []TimeWindow `json:"windows" binding:"required,min=1,dive"`
+	Menu    *Menu        `json:"menu" binding:"required"`
+}
+
+type TimeWindow struct {
+	Start string `json:"start" binding:"required,len=5"`
+	End   string `json:"end" binding:"required,len=5"`
+}
+
+type ScheduledMenu struct {
+	Id            int64     `json:"id"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	Menu          *Menu     `json:"menu"`
+}
+
+// AuditEntry is a mutation of a restaurant. The changes are keyed by the paths of the
+// fields of the restaurant (e.g. "menu.items.1.price"), with their values as text.
+type AuditEntry struct {
+	Id        int64                   `json:"id"`
+	Operation string                  `json:"operation"`
+	Actor     string                  `json:"actor,omitempty"`
+	TenantId  string                  `json:"tenantId,omitempty"`
+	Changes   map[string]*FieldChange `json:"changes"`
+	RequestId string                  `json:"requestId,omitempty"`
+	Source    string                  `json:"source,omitempty"`
+	CreatedAt time.Time               `json:"createdAt"`
+}
+
+// FieldChange has the values of a field before and after a change (null if the
+// field was empty).
+type FieldChange struct {
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+type Ticket struct {
+	Id               int64            `json:"id"`
+	RestaurantId     int64            `json:"restaurantId"`
+	OrderId          int64            `json:"orderId"`
+	State            string           `json:"state"`
+	LineItems        []TicketLineItem `json:"lineItems"`
+	ReadyBy          *time.Time       `json:"readyBy,omitempty"`
+	CreatedAt        *time.Time       `json:"createdAt,omitempty"`
+	AcceptedAt       *time.Time       `json:"acceptedAt,omitempty"`
+	PreparingAt      *time.Time       `json:"preparingAt,omitempty"`
+	ReadyForPickupAt *time.Time       `json:"readyForPickupAt,omitempty"`
+	PickedUpAt       *time.Time       `json:"pickedUpAt,omitempty"`
+	CancelledAt      *time.Time       `json:"cancelledAt,omitempty"`
+}
+
+type TicketLineItem struct {
+	MenuItemId int32  `json:"menuItemId" binding:"required"`
+	Name       string `json:"name,omitempty"`
+	Quantity   int32  `json:"quantity" binding:"required,min=1"`
 }
 
 // --------------------------------------------------------------------------------
@@ -40,15 +120,107 @@ type CreateRestaurantResponse struct {
 	RestaurantId int64 `json:"restaurantId"`
 }
 
+// ImportResult is the outcome of importing the restaurant starting at a line of the
+// file. The identifier is only present when it was created, and the error otherwise.
+type ImportResult struct {
+	Line         int    `json:"line"`
+	RestaurantId int64  `json:"restaurantId,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type ImportRestaurantsResponse struct {
+	Created int             `json:"created"`
+	Failed  int             `json:"failed"`
+	Results []*ImportResult `json:"results"`
+}
+
+// The menu is scheduled to replace the current one if the effective instant is
+// present, or it's updated right away otherwise.
 type UpdateMenuRequest struct {
-	Menu *Menu `json:"menu" binding:"required"`
+	Menu          *Menu      `json:"menu" binding:"required"`
+	EffectiveFrom *time.Time `json:"effectiveFrom,omitempty"`
 }
 
+type ScheduleMenuResponse struct {
+	ScheduledMenuId int64 `json:"scheduledMenuId"`
+}
+
+type UpdateDaypartsRequest struct {
+	Dayparts []*Daypart `json:"dayparts" binding:"max=10,dive"`
+}
+
+type SetItemAvailabilityRequest struct {
+	Available      *bool      `json:"available" binding:"required"`
+	AvailableUntil *time.Time `json:"availableUntil"`
+}
+
+// UpdateRestaurantRequest carries partial changes of a restaurant profile. Absent
+// fields are left untouched.
+type UpdateRestaurantRequest struct {
+	Name                    *string           `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Address                 *Address          `json:"address,omitempty"`
+	DefaultLocale           *string           `json:"defaultLocale,omitempty" binding:"omitempty,bcp47_language_tag"`
+	TimeZone                *string           `json:"timeZone,omitempty" binding:"omitempty,timezone"`
+	Description             *string           `json:"description,omitempty" binding:"omitempty,max=1000"`
+	DescriptionTranslations map[string]string `json:"descriptionTranslations,omitempty" binding:"omitempty,dive,keys,bcp47_language_tag,endkeys,max=1000"`
+}
+
+type AddMenuItemRequest struct {
+	MenuItem *MenuItem `json:"menuItem" binding:"required"`
+}
+
+// UpdateMenuItemRequest carries partial changes of a menu item. Absent fields are
+// left untouched, while empty label lists clear the current labels.
+type UpdateMenuItemRequest struct {
+	Name         *string                `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description  *string                `json:"description,omitempty" binding:"omitempty,max=1000"`
+	Translations map[string]Translation `json:"translations,omitempty" binding:"omitempty,dive,keys,bcp47_language_tag,endkeys"`
+	Price        *string                `json:"price,omitempty" binding:"omitempty,min=1,max=10"`
+	Allergens    []string               `json:"allergens,omitempty" binding:"omitempty,unique,dive,oneof=celery gluten crustaceans eggs fish lupin milk molluscs mustard nuts peanuts sesame soybeans sulphites"`
+	DietaryTags  []string               `json:"dietaryTags,omitempty" binding:"omitempty,unique,dive,oneof=vegan vegetarian gluten-free"`
+}
+
+// GetRestaurantsResponse is a page of restaurants. The total is omitted if it wasn't
+// requested, and the cursors if there are no more restaurants in their direction.
 type GetRestaurantsResponse struct {
-	Restaurants []*Restaurant `json:"restaurants"`
-	Total       int64         `json:"total"`
+	Restaurants    []*Restaurant `json:"restaurants"`
+	Total          *int64        `json:"total,omitempty"`
+	TotalEstimated bool          `json:"totalEstimated,omitempty"`
+	NextCursor     string        `json:"nextCursor,omitempty"`
+	PrevCursor     string        `json:"prevCursor,omitempty"`
 }
 
 type GetRestaurantResponse struct {
 	Restaurant *Restaurant `json:"restaurant"`
 }
+
+type GetScheduledMenusResponse struct {
+	ScheduledMenus []*ScheduledMenu `json:"scheduledMenus"`
+}
+
+type GetAuditEntriesResponse struct {
+	Entries []*AuditEntry `json:"entries"`
+	Total   int64         `json:"total"`
+}
+
+type CreateTicketRequest struct {
+	OrderId   int64            `json:"orderId" binding:"required,min=1"`
+	LineItems []TicketLineItem `json:"lineItems" binding:"required,min=1,max=100,dive"`
+}
+
+type CreateTicketResponse struct {
+	TicketId int64 `json:"ticketId"`
+}
+
+type AcceptTicketRequest struct {
+	ReadyBy *time.Time `json:"readyBy" binding:"required"`
+}
+
+type GetTicketsResponse struct {
+	Tickets []*Ticket `json:"tickets"`
+	Total   int64     `json:"total"`
+}
+
+type GetTicketResponse struct {
+	Ticket *Ticket `json:"ticket"`
+}
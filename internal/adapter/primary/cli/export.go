@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// The formats of an export file.
+const (
+	exportJsonl   = "jsonl"
+	exportCsv     = "csv"
+	exportParquet = "parquet"
+)
+
+// The columns of a CSV export file, which can be imported back since they include
+// the columns read by the import.
+var csvExportColumns = []string{
+	"id", "tenant_id", "name", "description", "default_locale", "street", "city", "state", "zip", "latitude", "longitude",
+	"item_id", "item_name", "item_description", "item_price", "item_available", "item_allergens", "item_dietary_tags",
+}
+
+// menuItemRow is a menu item of a restaurant flattened into a row of a CSV or Parquet
+// export. The item fields are empty for restaurants without menu items, and the lists
+// are semicolon separated as in the import.
+type menuItemRow struct {
+	Id              int64    `parquet:"name=id, type=INT64"`
+	TenantId        string   `parquet:"name=tenant_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Name            string   `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Description     string   `parquet:"name=description, type=BYTE_ARRAY, convertedtype=UTF8"`
+	DefaultLocale   string   `parquet:"name=default_locale, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Street          string   `parquet:"name=street, type=BYTE_ARRAY, convertedtype=UTF8"`
+	City            string   `parquet:"name=city, type=BYTE_ARRAY, convertedtype=UTF8"`
+	State           string   `parquet:"name=state, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Zip             string   `parquet:"name=zip, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Latitude        *float64 `parquet:"name=latitude, type=DOUBLE, repetitiontype=OPTIONAL"`
+	Longitude       *float64 `parquet:"name=longitude, type=DOUBLE, repetitiontype=OPTIONAL"`
+	ItemId          *int32   `parquet:"name=item_id, type=INT32, repetitiontype=OPTIONAL"`
+	ItemName        string   `parquet:"name=item_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ItemDescription string   `parquet:"name=item_description, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ItemPrice       string   `parquet:"name=item_price, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ItemAvailable   *bool    `parquet:"name=item_available, type=BOOLEAN, repetitiontype=OPTIONAL"`
+	ItemAllergens   string   `parquet:"name=item_allergens, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ItemDietaryTags string   `parquet:"name=item_dietary_tags, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// toMenuItemRows flattens a restaurant into a row per menu item.
+func toMenuItemRows(r *Restaurant) []*menuItemRow {
+	restaurant := menuItemRow{Id: r.Id, TenantId: r.TenantId, Name: r.Name, Description: r.Description, DefaultLocale: r.DefaultLocale}
+	if r.Address != nil {
+		restaurant.Street, restaurant.City, restaurant.State, restaurant.Zip = r.Address.Street, r.Address.City, r.Address.State, r.Address.Zip
+		restaurant.Latitude, restaurant.Longitude = r.Address.Latitude, r.Address.Longitude
+	}
+	if r.Menu == nil || len(r.Menu.Items) == 0 {
+		return []*menuItemRow{&restaurant}
+	}
+
+	rows := []*menuItemRow{}
+	for _, item := range r.Menu.Items {
+		row := restaurant
+		itemId := item.Id
+		row.ItemId = &itemId
+		row.ItemName = item.Name
+		row.ItemDescription = item.Description
+		row.ItemPrice = item.Price
+		row.ItemAvailable = item.Available
+		row.ItemAllergens = strings.Join(item.Allergens, ";")
+		row.ItemDietaryTags = strings.Join(item.DietaryTags, ";")
+		rows = append(rows, &row)
+	}
+	return rows
+}
+
+// record returns the values of the row in the order of the CSV export columns.
+func (r *menuItemRow) record() []string {
+	optional := func(present bool, value func() string) string {
+		if !present {
+			return ""
+		}
+		return value()
+	}
+	return []string{
+		strconv.FormatInt(r.Id, 10), r.TenantId, r.Name, r.Description, r.DefaultLocale, r.Street, r.City, r.State, r.Zip,
+		optional(r.Latitude != nil, func() string { return strconv.FormatFloat(*r.Latitude, 'f', -1, 64) }),
+		optional(r.Longitude != nil, func() string { return strconv.FormatFloat(*r.Longitude, 'f', -1, 64) }),
+		optional(r.ItemId != nil, func() string { return strconv.FormatInt(int64(*r.ItemId), 10) }),
+		r.ItemName, r.ItemDescription, r.ItemPrice,
+		optional(r.ItemAvailable != nil, func() string { return strconv.FormatBool(*r.ItemAvailable) }),
+		r.ItemAllergens, r.ItemDietaryTags,
+	}
+}
+
+// restaurantWriter writes the restaurants of an export in some format.
+type restaurantWriter interface {
+
+	// write writes a batch of restaurants.
+	write([]*Restaurant) error
+
+	// close flushes the pending restaurants, without closing the underlying writer.
+	close() error
+}
+
+// newRestaurantWriter builds a restaurantWriter for a format on top of a writer.
+func newRestaurantWriter(format string, w io.Writer) (restaurantWriter, error) {
+	switch format {
+	case exportJsonl:
+		return &jsonlWriter{encoder: json.NewEncoder(w)}, nil
+	case exportCsv:
+		csvWriter := &csvWriter{writer: csv.NewWriter(w)}
+		return csvWriter, csvWriter.writer.Write(csvExportColumns)
+	case exportParquet:
+		parquetWriter, err := writer.NewParquetWriterFromWriter(w, new(menuItemRow), 1)
+		if err != nil {
+			return nil, err
+		}
+		parquetWriter.CompressionType = parquet.CompressionCodec_SNAPPY
+		return &parquetRestaurantWriter{writer: parquetWriter}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %s, expected %s, %s or %s", format, exportJsonl, exportCsv, exportParquet)
+}
+
+// jsonlWriter writes a restaurant per line.
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func (jw *jsonlWriter) write(restaurants []*Restaurant) error {
+	for _, restaurant := range restaurants {
+		if err := jw.encoder.Encode(restaurant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*jsonlWriter) close() error {
+	return nil
+}
+
+// csvWriter writes a row per menu item, after a header with the column names.
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (cw *csvWriter) write(restaurants []*Restaurant) error {
+	for _, restaurant := range restaurants {
+		for _, row := range toMenuItemRows(restaurant) {
+			if err := cw.writer.Write(row.record()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cw *csvWriter) close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+// parquetRestaurantWriter writes a row per menu item, with the columns of the CSV
+// export.
+type parquetRestaurantWriter struct {
+	writer *writer.ParquetWriter
+}
+
+func (pw *parquetRestaurantWriter) write(restaurants []*Restaurant) error {
+	for _, restaurant := range restaurants {
+		for _, row := range toMenuItemRows(restaurant) {
+			if err := pw.writer.Write(*row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (pw *parquetRestaurantWriter) close() error {
+	return pw.writer.WriteStop()
+}
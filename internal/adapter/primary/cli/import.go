@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The required columns of a CSV import file, which may also have the description,
+// default_locale, item_description, item_allergens and item_dietary_tags columns.
+// Each row holds a menu item, and consecutive rows with the same restaurant name
+// and address make up the menu of a restaurant.
+var requiredCsvColumns = []string{"name", "street", "city", "state", "zip", "item_id", "item_name", "item_price"}
+
+// importBatchSize is the number of restaurants created at once by best-effort
+// imports, which report their progress after each batch.
+const importBatchSize = 100
+
+// maxJsonlLineSize is the maximum size of a restaurant in a JSONL import file.
+const maxJsonlLineSize = 1024 * 1024
+
+// importRow is a restaurant read from an import file, along with the line where it
+// starts and the error found when reading it, if any.
+type importRow struct {
+	line       int
+	restaurant *Restaurant
+	err        error
+}
+
+// readImportFile reads the restaurants of a CSV or JSONL file, depending on its
+// extension. Rows that can't be read are returned with their error, while errors
+// that prevent reading the rest of the file are returned instead.
+func readImportFile(path string) ([]*importRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCsv(file)
+	case ".jsonl", ".ndjson":
+		return readJsonl(file)
+	}
+	return nil, fmt.Errorf("unsupported import file %s, expected a .csv or .jsonl file", path)
+}
+
+// readJsonl reads a restaurant per line, skipping blank lines.
+func readJsonl(reader io.Reader) ([]*importRow, error) {
+	rows := []*importRow{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJsonlLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		row := &importRow{line: line, restaurant: &Restaurant{}}
+		if err := json.Unmarshal([]byte(text), row.restaurant); err != nil {
+			row.err = err
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// readCsv reads the restaurants of a CSV file with a header naming its columns.
+func readCsv(reader io.Reader) ([]*importRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	// Trailing optional columns may be omitted, and the missing required values are
+	// reported when validating the restaurants.
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return []*importRow{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range requiredCsvColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("missing column %s in the header of the CSV file", column)
+		}
+	}
+
+	rows := []*importRow{}
+	var row *importRow
+	var key string
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := csvReader.FieldPos(0)
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		// Consecutive items of the same restaurant are added to its menu.
+		recordKey := strings.Join([]string{value("name"), value("street"), value("city"), value("state"), value("zip")}, "\x00")
+		if row == nil || recordKey != key {
+			row = &importRow{line: line, restaurant: &Restaurant{
+				Name:          value("name"),
+				Description:   value("description"),
+				DefaultLocale: value("default_locale"),
+				Address:       &Address{Street: value("street"), City: value("city"), State: value("state"), Zip: value("zip")},
+				Menu:          &Menu{Items: []MenuItem{}},
+			}}
+			key = recordKey
+			rows = append(rows, row)
+		}
+		if row.err != nil {
+			continue
+		}
+		itemId, err := strconv.ParseInt(value("item_id"), 10, 32)
+		if err != nil {
+			row.err = fmt.Errorf("line %d: invalid item_id: %w", line, err)
+			continue
+		}
+		row.restaurant.Menu.Items = append(row.restaurant.Menu.Items, MenuItem{
+			Id:          int32(itemId),
+			Name:        value("item_name"),
+			Description: value("item_description"),
+			Price:       value("item_price"),
+			Allergens:   splitCsvList(value("item_allergens")),
+			DietaryTags: splitCsvList(value("item_dietary_tags")),
+		})
+	}
+	return rows, nil
+}
+
+// splitCsvList splits a semicolon separated list of a CSV column.
+func splitCsvList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	items := []string{}
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
 
 	"f4allgo-restaurant/internal/core/domain"
 )
@@ -22,17 +25,50 @@ type Mapper interface {
 	// toDomainMenu maps a Menu struct into a domain.Menu.
 	toDomainMenu(*Menu) *domain.Menu
 
+	// toDomainRestaurantChanges maps an UpdateRestaurantRequest struct into a domain.RestaurantChanges.
+	toDomainRestaurantChanges(*UpdateRestaurantRequest) *domain.RestaurantChanges
+
+	// toDomainMenuItem maps a MenuItem struct into a domain.MenuItem.
+	toDomainMenuItem(*MenuItem) *domain.MenuItem
+
+	// toDomainMenuItemChanges maps an UpdateMenuItemRequest struct into a domain.MenuItemChanges.
+	toDomainMenuItemChanges(*UpdateMenuItemRequest) *domain.MenuItemChanges
+
 	// fromDomainRestaurant maps a domain.Restaurant struct into a Restaurant.
 	fromDomainRestaurant(*domain.Restaurant) *Restaurant
 
 	// fromDomainRestaurants maps a slice of domain.Restaurant into a slice of Restaurant.
 	fromDomainRestaurants([]*domain.Restaurant) []*Restaurant
 
+	// fromDomainRestaurantPage maps a domain.RestaurantPage struct into a GetRestaurantsResponse.
+	fromDomainRestaurantPage(*domain.RestaurantPage) *GetRestaurantsResponse
+
 	// fromDomainAddress maps a domain.Address struct into an Address.
 	fromDomainAddress(*domain.Address) *Address
 
 	// fromDomainMenu maps a domain.Menu struct into a Menu.
 	fromDomainMenu(*domain.Menu) *Menu
+
+	// fromDomainScheduledMenus maps a slice of domain.ScheduledMenu into a slice of ScheduledMenu.
+	fromDomainScheduledMenus([]*domain.ScheduledMenu) []*ScheduledMenu
+
+	// fromDomainAuditEntries maps a slice of domain.AuditEntry into a slice of AuditEntry.
+	fromDomainAuditEntries([]*domain.AuditEntry) []*AuditEntry
+
+	// toDomainDayparts maps a slice of Daypart into a slice of domain.Daypart.
+	toDomainDayparts([]*Daypart) ([]*domain.Daypart, error)
+
+	// fromDomainDayparts maps a slice of domain.Daypart into a slice of Daypart.
+	fromDomainDayparts([]*domain.Daypart) []*Daypart
+
+	// toDomainTicketLineItems maps a slice of TicketLineItem into a slice of domain.TicketLineItem.
+	toDomainTicketLineItems([]TicketLineItem) []*domain.TicketLineItem
+
+	// fromDomainTicket maps a domain.Ticket struct into a Ticket.
+	fromDomainTicket(*domain.Ticket) *Ticket
+
+	// fromDomainTickets maps a slice of domain.Ticket into a slice of Ticket.
+	fromDomainTickets([]*domain.Ticket) []*Ticket
 }
 
 // DefaultMapper is the default implementation of Mapper.
@@ -45,7 +81,12 @@ var _ Mapper = (*DefaultMapper)(nil)
 // toDomainRestaurant maps a Restaurant struct into a domain.Restaurant.
 func (dm DefaultMapper) toDomainRestaurant(r *Restaurant) *domain.Restaurant {
 	domainRestaurant := domain.Restaurant{}
+	domainRestaurant.TenantId = r.TenantId
 	domainRestaurant.Name = r.Name
+	domainRestaurant.DefaultLocale = r.DefaultLocale
+	domainRestaurant.TimeZone = r.TimeZone
+	domainRestaurant.Description = r.Description
+	domainRestaurant.DescriptionTranslations = r.DescriptionTranslations
 	domainRestaurant.Address = dm.toDomainAddress(r.Address)
 	domainRestaurant.Menu = dm.toDomainMenu(r.Menu)
 	return &domainRestaurant
@@ -63,27 +104,85 @@ func (dm DefaultMapper) toDomainRestaurants(restaurants []*Restaurant) []*domain
 
 // ToDomainAddress maps a Address struct into a domain.Address.
 func (DefaultMapper) toDomainAddress(a *Address) *domain.Address {
-	return domain.NewAddress(a.Street, a.City, a.State, a.Zip)
+	address := domain.NewAddress(a.Street, a.City, a.State, a.Zip)
+	if a.Latitude != nil && a.Longitude != nil {
+		if location, err := domain.NewGeoPoint(*a.Latitude, *a.Longitude); err == nil {
+			address = address.WithLocation(location)
+		}
+	}
+	return address
 }
 
 // ToDomainMenu maps a Menu struct into a domain.Menu.
-func (DefaultMapper) toDomainMenu(m *Menu) *domain.Menu {
+func (dm DefaultMapper) toDomainMenu(m *Menu) *domain.Menu {
 	domainItems := []*domain.MenuItem{}
 	for _, item := range m.Items {
-		f := new(big.Float)
-		f.SetString(item.Price)
-		domainItems = append(domainItems, domain.NewMenuItem(int16(item.Id), item.Name, f))
+		domainItems = append(domainItems, dm.toDomainMenuItem(&item))
 	}
 	return domain.NewMenu(domainItems)
 }
 
+// ToDomainRestaurantChanges maps an UpdateRestaurantRequest struct into a domain.RestaurantChanges.
+func (dm DefaultMapper) toDomainRestaurantChanges(r *UpdateRestaurantRequest) *domain.RestaurantChanges {
+	changes := domain.RestaurantChanges{Name: r.Name, DefaultLocale: r.DefaultLocale, TimeZone: r.TimeZone, Description: r.Description}
+	if r.DescriptionTranslations != nil {
+		changes.DescriptionTranslations = &r.DescriptionTranslations
+	}
+	if r.Address != nil {
+		changes.Address = dm.toDomainAddress(r.Address)
+	}
+	return &changes
+}
+
+// ToDomainMenuItem maps a MenuItem struct into a domain.MenuItem.
+func (DefaultMapper) toDomainMenuItem(item *MenuItem) *domain.MenuItem {
+	f := new(big.Float)
+	f.SetString(item.Price)
+	available := item.Available == nil || *item.Available
+	return domain.NewMenuItem(int16(item.Id), item.Name, f).
+		WithAvailability(available, item.AvailableUntil).
+		WithLabels(toDomainAllergens(item.Allergens), toDomainDietaryTags(item.DietaryTags)).
+		WithTexts(item.Description, toDomainTranslations(item.Translations))
+}
+
+// ToDomainMenuItemChanges maps an UpdateMenuItemRequest struct into a domain.MenuItemChanges.
+func (DefaultMapper) toDomainMenuItemChanges(r *UpdateMenuItemRequest) *domain.MenuItemChanges {
+	changes := domain.MenuItemChanges{Name: r.Name, Description: r.Description}
+	if r.Price != nil {
+		changes.Price = new(big.Float)
+		changes.Price.SetString(*r.Price)
+	}
+	if r.Allergens != nil {
+		allergens := toDomainAllergens(r.Allergens)
+		changes.Allergens = &allergens
+	}
+	if r.DietaryTags != nil {
+		dietaryTags := toDomainDietaryTags(r.DietaryTags)
+		changes.DietaryTags = &dietaryTags
+	}
+	if r.Translations != nil {
+		translations := toDomainTranslations(r.Translations)
+		changes.Translations = &translations
+	}
+	return &changes
+}
+
 // FromDomainRestaurant maps a domain.Restaurant struct into a Restaurant.
 func (dm DefaultMapper) fromDomainRestaurant(r *domain.Restaurant) *Restaurant {
 	restRestaurant := Restaurant{}
 	restRestaurant.Id = r.Id
+	restRestaurant.TenantId = r.TenantId
 	restRestaurant.Name = r.Name
+	restRestaurant.DefaultLocale = r.DefaultLocale
+	restRestaurant.TimeZone = r.TimeZone
+	restRestaurant.Description = r.Description
+	if len(r.DescriptionTranslations) > 0 {
+		restRestaurant.DescriptionTranslations = r.DescriptionTranslations
+	}
 	restRestaurant.Address = dm.fromDomainAddress(r.Address)
 	restRestaurant.Menu = dm.fromDomainMenu(r.Menu)
+	restRestaurant.Dayparts = dm.fromDomainDayparts(r.Dayparts)
+	restRestaurant.DeletedAt = r.DeletedAt
 	return &restRestaurant
 }
 
@@ -97,16 +196,304 @@ func (dm DefaultMapper) fromDomainRestaurants(restaurants []*domain.Restaurant)
 	return items
 }
 
+// FromDomainRestaurantPage maps a domain.RestaurantPage struct into a GetRestaurantsResponse.
+func (dm DefaultMapper) fromDomainRestaurantPage(page *domain.RestaurantPage) *GetRestaurantsResponse {
+	response := &GetRestaurantsResponse{Restaurants: dm.fromDomainRestaurants(page.Restaurants), Total: page.Total, TotalEstimated: page.Estimated}
+	if page.NextCursor != nil {
+		response.NextCursor = page.NextCursor.Encode()
+	}
+	if page.PrevCursor != nil {
+		response.PrevCursor = page.PrevCursor.Encode()
+	}
+
+	return response
+}
+
 // FromDomainAddress maps a domain.Address struct into a Address.
 func (DefaultMapper) fromDomainAddress(a *domain.Address) *Address {
-	return &Address{Street: a.Street(), City: a.City(), State: a.State(), Zip: a.Zip()}
+	address := &Address{Street: a.Street(), City: a.City(), State: a.State(), Zip: a.Zip()}
+	if location := a.Location(); location != nil {
+		latitude, longitude := location.Latitude(), location.Longitude()
+		address.Latitude = &latitude
+		address.Longitude = &longitude
+	}
+	return address
 }
 
 // FromDomainMenu maps a domain.Menu struct into a Menu.
 func (DefaultMapper) fromDomainMenu(menu *domain.Menu) *Menu {
 	items := []MenuItem{}
 	for _, item := range menu.GetItems() {
-		items = append(items, MenuItem{Id: int32(item.GetId()), Name: item.GetName(), Price: item.GetPrice().String()})
+		available := item.IsAvailable()
+		items = append(items, MenuItem{
+			Id:             int32(item.GetId()),
+			Name:           item.GetName(),
+			Description:    item.GetDescription(),
+			Translations:   fromDomainTranslations(item.GetTranslations()),
+			Price:          item.GetPrice().String(),
+			Available:      &available,
+			AvailableUntil: item.GetAvailableUntil(),
+			Allergens:      fromDomainAllergens(item.GetAllergens()),
+			DietaryTags:    fromDomainDietaryTags(item.GetDietaryTags()),
+		})
 	}
 	return &Menu{Items: items}
 }
+
+// FromDomainScheduledMenus maps a slice of domain.ScheduledMenu into a slice of ScheduledMenu.
+func (dm DefaultMapper) fromDomainScheduledMenus(scheduledMenus []*domain.ScheduledMenu) []*ScheduledMenu {
+	items := []*ScheduledMenu{}
+	for _, scheduledMenu := range scheduledMenus {
+		items = append(items, &ScheduledMenu{
+			Id:            scheduledMenu.Id,
+			EffectiveFrom: scheduledMenu.EffectiveFrom,
+			Menu:          dm.fromDomainMenu(scheduledMenu.Menu),
+		})
+	}
+	return items
+}
+
+// FromDomainAuditEntries maps a slice of domain.AuditEntry into a slice of AuditEntry.
+func (DefaultMapper) fromDomainAuditEntries(entries []*domain.AuditEntry) []*AuditEntry {
+	items := []*AuditEntry{}
+	for _, entry := range entries {
+		changes := make(map[string]*FieldChange, len(entry.Changes))
+		for field, change := range entry.Changes {
+			changes[field] = &FieldChange{Before: change.GetBefore(), After: change.GetAfter()}
+		}
+		items = append(items, &AuditEntry{
+			Id:        entry.Id,
+			Operation: string(entry.Operation),
+			Actor:     entry.Actor,
+			TenantId:  entry.TenantId,
+			Changes:   changes,
+			RequestId: entry.RequestId,
+			Source:    string(entry.Source),
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	return items
+}
+
+// ToDomainDayparts maps a slice of Daypart into a slice of domain.Daypart. It fails
+// if any of the times of day is not in HH:MM format.
+func (dm DefaultMapper) toDomainDayparts(dayparts []*Daypart) ([]*domain.Daypart, error) {
+	domainDayparts := []*domain.Daypart{}
+	for _, daypart := range dayparts {
+		windows := []*domain.TimeWindow{}
+		for _, window := range daypart.Windows {
+			start, err := domain.ParseTimeOfDay(window.Start)
+			if err != nil {
+				return nil, err
+			}
+			end, err := domain.ParseTimeOfDay(window.End)
+			if err != nil {
+				return nil, err
+			}
+			windows = append(windows, domain.NewTimeWindow(start, end))
+		}
+		domainDayparts = append(domainDayparts, domain.NewDaypart(daypart.Name, windows, dm.toDomainMenu(daypart.Menu)))
+	}
+	return domainDayparts, nil
+}
+
+// FromDomainDayparts maps a slice of domain.Daypart into a slice of Daypart.
+func (dm DefaultMapper) fromDomainDayparts(dayparts []*domain.Daypart) []*Daypart {
+	if len(dayparts) == 0 {
+		return nil
+	}
+	items := []*Daypart{}
+	for _, daypart := range dayparts {
+		windows := []TimeWindow{}
+		for _, window := range daypart.GetWindows() {
+			windows = append(windows, TimeWindow{Start: domain.FormatTimeOfDay(window.GetStart()), End: domain.FormatTimeOfDay(window.GetEnd())})
+		}
+		items = append(items, &Daypart{Name: daypart.GetName(), Windows: windows, Menu: dm.fromDomainMenu(daypart.GetMenu())})
+	}
+	return items
+}
+
+// ToDomainTicketLineItems maps a slice of TicketLineItem into a slice of domain.TicketLineItem.
+// The names of the line items are ignored because they are taken from the menu.
+func (DefaultMapper) toDomainTicketLineItems(lineItems []TicketLineItem) []*domain.TicketLineItem {
+	domainLineItems := []*domain.TicketLineItem{}
+	for _, lineItem := range lineItems {
+		domainLineItems = append(domainLineItems, domain.NewTicketLineItem(int16(lineItem.MenuItemId), "", lineItem.Quantity))
+	}
+	return domainLineItems
+}
+
+// FromDomainTicket maps a domain.Ticket struct into a Ticket.
+func (DefaultMapper) fromDomainTicket(t *domain.Ticket) *Ticket {
+	lineItems := []TicketLineItem{}
+	for _, lineItem := range t.LineItems {
+		lineItems = append(lineItems, TicketLineItem{
+			MenuItemId: int32(lineItem.GetMenuItemId()),
+			Name:       lineItem.GetName(),
+			Quantity:   lineItem.GetQuantity(),
+		})
+	}
+	return &Ticket{
+		Id:               t.Id,
+		RestaurantId:     t.RestaurantId,
+		OrderId:          t.OrderId,
+		State:            string(t.State),
+		LineItems:        lineItems,
+		ReadyBy:          t.ReadyBy,
+		CreatedAt:        t.CreatedAt,
+		AcceptedAt:       t.AcceptedAt,
+		PreparingAt:      t.PreparingAt,
+		ReadyForPickupAt: t.ReadyForPickupAt,
+		PickedUpAt:       t.PickedUpAt,
+		CancelledAt:      t.CancelledAt,
+	}
+}
+
+// FromDomainTickets maps a slice of domain.Ticket into a slice of Ticket.
+func (dm DefaultMapper) fromDomainTickets(tickets []*domain.Ticket) []*Ticket {
+	items := []*Ticket{}
+	for _, item := range tickets {
+		items = append(items, dm.fromDomainTicket(item))
+	}
+
+	return items
+}
+
+// toDomainAllergens maps a slice of strings into a slice of domain.Allergen.
+func toDomainAllergens(values []string) []domain.Allergen {
+	if len(values) == 0 {
+		return nil
+	}
+	allergens := []domain.Allergen{}
+	for _, v := range values {
+		allergens = append(allergens, domain.Allergen(v))
+	}
+	return allergens
+}
+
+// toDomainDietaryTags maps a slice of strings into a slice of domain.DietaryTag.
+func toDomainDietaryTags(values []string) []domain.DietaryTag {
+	if len(values) == 0 {
+		return nil
+	}
+	tags := []domain.DietaryTag{}
+	for _, v := range values {
+		tags = append(tags, domain.DietaryTag(v))
+	}
+	return tags
+}
+
+// fromDomainAllergens maps a slice of domain.Allergen into a slice of strings.
+func fromDomainAllergens(allergens []domain.Allergen) []string {
+	if len(allergens) == 0 {
+		return nil
+	}
+	values := []string{}
+	for _, a := range allergens {
+		values = append(values, string(a))
+	}
+	return values
+}
+
+// fromDomainDietaryTags maps a slice of domain.DietaryTag into a slice of strings.
+func fromDomainDietaryTags(tags []domain.DietaryTag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	values := []string{}
+	for _, t := range tags {
+		values = append(values, string(t))
+	}
+	return values
+}
+
+// toDomainTranslations maps a map of Translation keyed by locale into a map of domain.Translation.
+func toDomainTranslations(translations map[string]Translation) map[string]*domain.Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	domainTranslations := map[string]*domain.Translation{}
+	for locale, t := range translations {
+		domainTranslations[locale] = domain.NewTranslation(t.Name, t.Description)
+	}
+	return domainTranslations
+}
+
+// fromDomainTranslations maps a map of domain.Translation keyed by locale into a map of Translation.
+func fromDomainTranslations(translations map[string]*domain.Translation) map[string]Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	values := map[string]Translation{}
+	for locale, t := range translations {
+		values[locale] = Translation{Name: t.GetName(), Description: t.GetDescription()}
+	}
+	return values
+}
+
+// parseAllergens parses a comma separated list of allergens.
+func parseAllergens(value string) ([]domain.Allergen, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	allergens := []domain.Allergen{}
+	for _, v := range strings.Split(value, ",") {
+		a, err := domain.ParseAllergen(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		allergens = append(allergens, a)
+	}
+	return allergens, nil
+}
+
+// parseRoles parses a comma separated list of roles.
+func parseRoles(value string) []domain.Role {
+	roles := []domain.Role{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			roles = append(roles, domain.Role(v))
+		}
+	}
+	return roles
+}
+
+// parsePrice parses an optional price (nil if empty).
+func parsePrice(value string) (*big.Float, error) {
+	if value == "" {
+		return nil, nil
+	}
+	price, ok := new(big.Float).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid price: %s", value)
+	}
+	return price, nil
+}
+
+// Maximum radius (in meters) allowed in nearby searches.
+const maxRadius = 50000
+
+// parseNear parses a point given as 'latitude,longitude' and a radius in meters.
+func parseNear(near string, radius string) (*domain.GeoPoint, float64, error) {
+	coordinates := strings.Split(near, ",")
+	if len(coordinates) != 2 {
+		return nil, 0, fmt.Errorf("invalid point, expected 'latitude,longitude': %s", near)
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(coordinates[0]), 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid latitude: %s", coordinates[0])
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(coordinates[1]), 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid longitude: %s", coordinates[1])
+	}
+	point, err := domain.NewGeoPoint(latitude, longitude)
+	if err != nil {
+		return nil, 0, err
+	}
+	meters, err := strconv.ParseFloat(radius, 64)
+	if err != nil || !(meters > 0 && meters <= maxRadius) {
+		return nil, 0, fmt.Errorf("invalid radius, expected meters in (0, %v]: %s", maxRadius, radius)
+	}
+	return point, meters, nil
+}
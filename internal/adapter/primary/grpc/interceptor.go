@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"f4allgo-restaurant/internal/adapter/primary/auth"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Metadata keys carrying the identity of the caller. They are set by the API
+// gateway once the caller has been authenticated, so they must not be accepted
+// from untrusted clients.
+const (
+	userIdKey   = "x-user-id"
+	tenantIdKey = "x-tenant-id"
+	rolesKey    = "x-roles"
+)
+
+// Metadata keys of the idempotent calls. The key is given by the client, while the
+// responses replayed from a previous execution of the call are flagged.
+const (
+	idempotencyKeyKey     = "idempotency-key"
+	idempotentReplayedKey = "idempotent-replayed"
+)
+
+// readOnlyMethodPrefixes are the prefixes of the methods that don't modify resources,
+// whose calls are not made idempotent.
+var readOnlyMethodPrefixes = []string{"Get", "Search", "Validate"}
+
+// expectedVersionKey is the metadata key carrying the version of the restaurant the
+// caller expects to modify.
+const expectedVersionKey = "expected-version"
+
+// authorizationKey is the metadata key carrying the bearer token of the caller.
+const authorizationKey = "authorization"
+
+// requestIdKey is the metadata key carrying the identifier of the call, so that its
+// traces (e.g. the audit log) can be correlated with the ones of the caller.
+const requestIdKey = "x-request-id"
+
+// Maximum length of the request identifiers given by the callers.
+const maxRequestIdLength = 255
+
+// IdentityInterceptor propagates the identity of the caller into the context of
+// the call, so that the core can authorize the operations. Calls without a user id
+// are anonymous. It must only be used behind an API gateway trusted to set the
+// identity metadata.
+func IdentityInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userId := firstValue(md, userIdKey); userId != "" {
+			roles := []domain.Role{}
+			for _, value := range md.Get(rolesKey) {
+				for _, role := range strings.Split(value, ",") {
+					if role = strings.TrimSpace(role); role != "" {
+						roles = append(roles, domain.Role(role))
+					}
+				}
+			}
+			ctx = domain.ContextWithIdentity(ctx, domain.NewIdentity(userId, firstValue(md, tenantIdKey), roles))
+		}
+	}
+	return handler(ctx, req)
+}
+
+// AuthInterceptor authenticates the callers with the bearer token of the call,
+// propagating its claims and the identity of the caller into the context of the
+// call. Calls without a valid token are rejected. It replaces the identity
+// interceptor, since the identity metadata is not trusted once the callers are
+// authenticated by the service itself.
+func AuthInterceptor(verifier *auth.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		token, err := auth.BearerToken(firstValue(md, authorizationKey))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		authCtx, err := verifier.Authenticate(ctx, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(authCtx, req)
+	}
+}
+
+// RequestMetadataInterceptor propagates the metadata of the call into the context of
+// the call, so that the core can record it. The identifier of the call is taken from
+// the x-request-id metadata, or generated if the caller didn't send a valid one, and
+// it's sent back in the header of the response.
+func RequestMetadataInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestId := firstValue(md, requestIdKey)
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		requestId = uuid.NewString()
+	}
+	// The header can't be set outside of a server call, as in the tests.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIdKey, requestId))
+	ctx = domain.ContextWithRequestMetadata(ctx, domain.NewRequestMetadata(requestId, domain.SourceGrpc))
+	return handler(ctx, req)
+}
+
+// ErrorInterceptor translates core errors into the proper gRPC status codes.
+func ErrorInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+
+	switch err.(type) {
+	case *coreerrors.ForbiddenError:
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case *coreerrors.RestaurantNotFoundError, *coreerrors.MenuItemNotFoundError, *coreerrors.MenuVersionNotFoundError,
+		*coreerrors.ScheduledMenuNotFoundError, *coreerrors.TicketNotFoundError:
+		return nil, status.Error(codes.NotFound, err.Error())
+	case *coreerrors.MenuItemAlreadyExistsError:
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case *coreerrors.InvalidTicketStateError:
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case *coreerrors.IdempotencyKeyReusedError:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case *coreerrors.IdempotencyKeyConflictError, *coreerrors.ConcurrencyConflictError:
+		return nil, status.Error(codes.Aborted, err.Error())
+	case *coreerrors.CoreError:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case *coreerrors.RepositoryError, *coreerrors.EventPublisherError:
+		return nil, status.Error(codes.Internal, err.Error())
+	default:
+		return nil, err
+	}
+}
+
+// ExpectedVersionInterceptor propagates the version of the restaurant expected by the
+// caller into the context of the call, so that the core rejects the changes to a
+// restaurant modified since the caller read it. Calls without an expected version
+// modify the restaurant regardless of its version.
+func ExpectedVersionInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if value := firstValue(md, expectedVersionKey); value != "" {
+		version, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid expected version: "+err.Error())
+		}
+		ctx = domain.ContextWithExpectedVersion(ctx, version)
+	}
+	return handler(ctx, req)
+}
+
+// IdempotencyInterceptor makes idempotent the mutating calls carrying an
+// idempotency-key metadata. The handler runs within the transaction of the
+// IdempotencyService and its response is stored with the key, so retries get the
+// same response, flagged with an idempotent-replayed header. A key reused with
+// another method or request is rejected. It must run after the error interceptor,
+// so that the errors of the service are translated too.
+func IdempotencyInterceptor(idempotencyService port.IdempotencyService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		value := firstValue(md, idempotencyKeyKey)
+		message, ok := req.(proto.Message)
+		if value == "" || !ok || !isMutating(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		hash, err := requestHash(info.FullMethod, message)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		key, err := domain.NewIdempotencyKey(value, hash)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		var resp any
+		response, replayed, err := idempotencyService.Execute(ctx, key, func(txCtx context.Context) ([]byte, error) {
+			if resp, err = handler(txCtx, req); err != nil {
+				return nil, err
+			}
+			stored, err := anypb.New(resp.(proto.Message))
+			if err != nil {
+				return nil, err
+			}
+			return proto.Marshal(stored)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !replayed {
+			return resp, nil
+		}
+
+		var stored anypb.Any
+		if err := proto.Unmarshal(response, &stored); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if resp, err = stored.UnmarshalNew(); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		// The header can't be set outside of a server call, as in the tests.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(idempotentReplayedKey, "true"))
+		return resp, nil
+	}
+}
+
+// isMutating returns true if the calls of a method modify resources, that is, all but
+// the queries and validations.
+func isMutating(fullMethod string) bool {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for _, prefix := range readOnlyMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// requestHash returns the hash of the method and the deterministic encoding of the
+// request of a call.
+func requestHash(fullMethod string, req proto.Message) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(fullMethod + "\n"))
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// firstValue returns the first value of a metadata key, or an empty string if the
+// key is not present.
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
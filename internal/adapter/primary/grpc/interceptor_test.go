@@ -0,0 +1,285 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"f4allgo-restaurant/internal/adapter/primary/auth"
+	"f4allgo-restaurant/internal/core/domain"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+	"f4allgo-restaurant/test"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestIdentityInterceptor(t *testing.T) {
+	testcases := []struct {
+		name         string
+		md           metadata.MD
+		wantIdentity *domain.Identity
+	}{
+		{
+			name:         "propagate the identity of a tenant user",
+			md:           metadata.Pairs("x-user-id", "user1", "x-tenant-id", "tenant1"),
+			wantIdentity: domain.NewIdentity("user1", "tenant1", []domain.Role{}),
+		},
+		{
+			name:         "propagate the identity of an admin",
+			md:           metadata.Pairs("x-user-id", "admin1", "x-roles", "admin, operator"),
+			wantIdentity: domain.NewIdentity("admin1", "", []domain.Role{domain.RoleAdmin, "operator"}),
+		},
+		{
+			name:         "leave anonymous a call without user id",
+			md:           metadata.Pairs("x-tenant-id", "tenant1"),
+			wantIdentity: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var identity *domain.Identity
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			_, err := IdentityInterceptor(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+				identity = domain.IdentityFromContext(ctx)
+				return nil, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantIdentity, identity)
+		})
+	}
+}
+
+func TestRequestMetadataInterceptor(t *testing.T) {
+	testcases := []struct {
+		name          string
+		md            metadata.MD
+		wantRequestId string
+	}{
+		{
+			name:          "propagate the request id of the caller",
+			md:            metadata.Pairs("x-request-id", "request1"),
+			wantRequestId: "request1",
+		},
+		{
+			name: "generate a request id when the caller doesn't send one",
+			md:   metadata.MD{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requestMetadata *domain.RequestMetadata
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			_, err := RequestMetadataInterceptor(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+				requestMetadata = domain.RequestMetadataFromContext(ctx)
+				return nil, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, domain.SourceGrpc, requestMetadata.GetSource())
+			if tc.wantRequestId != "" {
+				assert.Equal(t, tc.wantRequestId, requestMetadata.GetRequestId())
+			} else {
+				assert.NotEmpty(t, requestMetadata.GetRequestId())
+			}
+		})
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	keySet, err := auth.NewFileKeySet(test.JwksFile())
+	assert.NoError(t, err)
+	verifier := auth.NewVerifier(keySet, auth.Options{TenantClaim: "tenant_id", RolesClaim: "roles"})
+	token, err := test.SignToken(test.EcKeyId, jwt.MapClaims{"sub": "admin1", "roles": []string{"admin"}, "exp": time.Now().Add(time.Hour).Unix()})
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		name         string
+		md           metadata.MD
+		wantCode     codes.Code
+		wantIdentity *domain.Identity
+	}{
+		{
+			name:         "authenticate a call with a valid token",
+			md:           metadata.Pairs("authorization", "Bearer "+token),
+			wantCode:     codes.OK,
+			wantIdentity: domain.NewIdentity("admin1", "", []domain.Role{domain.RoleAdmin}),
+		},
+		{
+			name:     "reject a call without token",
+			md:       metadata.Pairs("x-user-id", "admin1", "x-roles", "admin"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "reject a call with an invalid token",
+			md:       metadata.Pairs("authorization", "Bearer invalid"),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var identity *domain.Identity
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			_, err := AuthInterceptor(verifier)(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+				identity = domain.IdentityFromContext(ctx)
+				return nil, nil
+			})
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			assert.Equal(t, tc.wantIdentity, identity)
+		})
+	}
+}
+
+func TestExpectedVersionInterceptor(t *testing.T) {
+	version := func(v int64) *int64 { return &v }
+	testcases := []struct {
+		name        string
+		md          metadata.MD
+		wantVersion *int64
+		wantCode    codes.Code
+	}{
+		{name: "propagate the expected version", md: metadata.Pairs("expected-version", "3"), wantVersion: version(3), wantCode: codes.OK},
+		{name: "expect no version without metadata", md: metadata.Pairs(), wantVersion: nil, wantCode: codes.OK},
+		{name: "reject an invalid expected version", md: metadata.Pairs("expected-version", "abc"), wantVersion: nil, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var expected *int64
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			_, err := ExpectedVersionInterceptor(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+				expected = domain.ExpectedVersionFromContext(ctx)
+				return nil, nil
+			})
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			assert.Equal(t, tc.wantVersion, expected)
+		})
+	}
+}
+
+func TestErrorInterceptor(t *testing.T) {
+	testcases := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "translate a forbidden error", err: coreerrors.NewForbiddenError(), wantCode: codes.PermissionDenied},
+		{name: "translate a not found error", err: coreerrors.NewRestaurantNotFoundError(), wantCode: codes.NotFound},
+		{name: "translate a concurrency conflict", err: coreerrors.NewConcurrencyConflictError(), wantCode: codes.Aborted},
+		{name: "translate a conflicting idempotent call", err: coreerrors.NewIdempotencyKeyConflictError(), wantCode: codes.Aborted},
+		{name: "translate a core error", err: coreerrors.NewCoreError(errors.New("error")), wantCode: codes.InvalidArgument},
+		{name: "translate a repository error", err: coreerrors.NewRepositoryError(errors.New("error")), wantCode: codes.Internal},
+		{name: "keep any other error", err: errors.New("error"), wantCode: codes.Unknown},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ErrorInterceptor(context.Background(), nil, nil, func(context.Context, any) (any, error) {
+				return nil, tc.err
+			})
+			assert.Equal(t, tc.wantCode, status.Code(err))
+		})
+	}
+}
+
+// memoryIdempotencyService is an in-memory IdempotencyService.
+type memoryIdempotencyService struct {
+	records map[string]*domain.IdempotencyRecord
+}
+
+func (ms *memoryIdempotencyService) Execute(ctx context.Context, key *domain.IdempotencyKey, operation func(ctx context.Context) ([]byte, error)) ([]byte, bool, error) {
+	if record, ok := ms.records[key.GetKey()]; ok {
+		if record.GetKey().GetRequestHash() != key.GetRequestHash() {
+			return nil, false, coreerrors.NewIdempotencyKeyReusedError()
+		}
+		return record.GetResponse(), true, nil
+	}
+	response, err := operation(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	ms.records[key.GetKey()] = domain.NewIdempotencyRecord("", key, response, time.Now())
+	return response, false, nil
+}
+
+func (ms *memoryIdempotencyService) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotencyInterceptor(t *testing.T) {
+	type call struct {
+		method string
+		name   string
+		key    string
+	}
+	testcases := []struct {
+		name           string
+		calls          []call
+		wantIds        []int64
+		wantErrs       []codes.Code
+		wantExecutions int
+	}{
+		{
+			name:           "execute every call without key",
+			calls:          []call{{method: "CreateRestaurant", name: "restaurant"}, {method: "CreateRestaurant", name: "restaurant"}},
+			wantIds:        []int64{1, 2},
+			wantErrs:       []codes.Code{codes.OK, codes.OK},
+			wantExecutions: 2,
+		},
+		{
+			name:           "replay a call retried with the same key",
+			calls:          []call{{method: "CreateRestaurant", name: "restaurant", key: "key1"}, {method: "CreateRestaurant", name: "restaurant", key: "key1"}},
+			wantIds:        []int64{1, 1},
+			wantErrs:       []codes.Code{codes.OK, codes.OK},
+			wantExecutions: 1,
+		},
+		{
+			name:           "reject a key reused with another request",
+			calls:          []call{{method: "CreateRestaurant", name: "restaurant", key: "key1"}, {method: "CreateRestaurant", name: "other", key: "key1"}},
+			wantIds:        []int64{1, 0},
+			wantErrs:       []codes.Code{codes.OK, codes.InvalidArgument},
+			wantExecutions: 1,
+		},
+		{
+			name:           "ignore the key of a query",
+			calls:          []call{{method: "GetRestaurants", name: "restaurant", key: "key1"}, {method: "GetRestaurants", name: "restaurant", key: "key1"}},
+			wantIds:        []int64{1, 2},
+			wantErrs:       []codes.Code{codes.OK, codes.OK},
+			wantExecutions: 2,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			executions := 0
+			interceptor := IdempotencyInterceptor(&memoryIdempotencyService{records: map[string]*domain.IdempotencyRecord{}})
+			for i, c := range tc.calls {
+				ctx := context.Background()
+				if c.key != "" {
+					ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("idempotency-key", c.key))
+				}
+				info := &grpc.UnaryServerInfo{FullMethod: "/RestaurantService/" + c.method}
+				req := &CreateRestaurantRequest{Restaurant: &Restaurant{Name: c.name}}
+				resp, err := ErrorInterceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+					return interceptor(ctx, req, info, func(context.Context, any) (any, error) {
+						executions++
+						return &CreateRestaurantResponse{RestaurantId: int64(executions)}, nil
+					})
+				})
+
+				assert.Equal(t, tc.wantErrs[i], status.Code(err))
+				if err == nil {
+					assert.Equal(t, tc.wantIds[i], resp.(*CreateRestaurantResponse).GetRestaurantId())
+				}
+			}
+			assert.Equal(t, tc.wantExecutions, executions)
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package grpc
 
 import (
+	"fmt"
 	"math/big"
+	"time"
 
 	"f4allgo-restaurant/internal/core/domain"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Mapper maps structs and slices from the rest handler layer to the
@@ -19,6 +23,18 @@ type Mapper interface {
 	// toDomainMenu maps a Menu struct into a domain.Menu.
 	toDomainMenu(*Menu) *domain.Menu
 
+	// toDomainRestaurantChanges maps an UpdateRestaurantRequest struct into a domain.RestaurantChanges.
+	toDomainRestaurantChanges(*UpdateRestaurantRequest) (*domain.RestaurantChanges, error)
+
+	// toDomainMenuItem maps a MenuItem struct into a domain.MenuItem.
+	toDomainMenuItem(*MenuItem) *domain.MenuItem
+
+	// toDomainMenuItemChanges maps an UpdateMenuItemRequest struct into a domain.MenuItemChanges.
+	toDomainMenuItemChanges(*UpdateMenuItemRequest) (*domain.MenuItemChanges, error)
+
+	// toDomainRestaurantQuery maps a RestaurantQuery struct into a domain.RestaurantQuery.
+	toDomainRestaurantQuery(*RestaurantQuery) (*domain.RestaurantQuery, error)
+
 	// toDomainRestaurants maps a slice of Restaurant into a slice of domain.Restaurant.
 	toDomainRestaurants([]*Restaurant) []*domain.Restaurant
 
@@ -31,8 +47,35 @@ type Mapper interface {
 	// fromDomainMenu maps a domain.Menu struct into a Menu.
 	fromDomainMenu(*domain.Menu) *Menu
 
+	// toDomainDayparts maps a slice of Daypart into a slice of domain.Daypart.
+	toDomainDayparts([]*Daypart) []*domain.Daypart
+
+	// fromDomainDayparts maps a slice of domain.Daypart into a slice of Daypart.
+	fromDomainDayparts([]*domain.Daypart) []*Daypart
+
 	// fromDomainRestaurants maps a slice of domain.Restaurant into a slice of Restaurant.
 	fromDomainRestaurants([]*domain.Restaurant) []*Restaurant
+
+	// fromDomainRestaurantPage maps a domain.RestaurantPage struct into a GetRestaurantsResponse.
+	fromDomainRestaurantPage(*domain.RestaurantPage) *GetRestaurantsResponse
+
+	// fromDomainSearchResults maps a slice of domain.RestaurantSearchResult into a slice of SearchResult.
+	fromDomainSearchResults([]*domain.RestaurantSearchResult) []*SearchResult
+
+	// toDomainTicketLineItems maps a slice of TicketLineItem into a slice of domain.TicketLineItem.
+	toDomainTicketLineItems([]*TicketLineItem) []*domain.TicketLineItem
+
+	// fromDomainTicket maps a domain.Ticket struct into a Ticket.
+	fromDomainTicket(*domain.Ticket) *Ticket
+
+	// fromDomainTickets maps a slice of domain.Ticket into a slice of Ticket.
+	fromDomainTickets([]*domain.Ticket) []*Ticket
+
+	// toDomainOrderLines maps a slice of OrderLine into a slice of domain.OrderLine.
+	toDomainOrderLines([]*OrderLine) []*domain.OrderLine
+
+	// fromDomainQuote maps a domain.Quote struct into a ValidateOrderResponse.
+	fromDomainQuote(*domain.Quote) *ValidateOrderResponse
 }
 
 // DefaultMapper is the default implementation of Mapper.
@@ -45,7 +88,12 @@ var _ Mapper = (*DefaultMapper)(nil)
 // toDomainRestaurant maps a Restaurant struct into a domain.Restaurant.
 func (dm DefaultMapper) toDomainRestaurant(r *Restaurant) *domain.Restaurant {
 	domainRestaurant := domain.Restaurant{}
+	domainRestaurant.TenantId = r.TenantId
 	domainRestaurant.Name = r.Name
+	domainRestaurant.DefaultLocale = r.DefaultLocale
+	domainRestaurant.TimeZone = r.TimeZone
+	domainRestaurant.Description = r.Description
+	domainRestaurant.DescriptionTranslations = r.DescriptionTranslations
 	domainRestaurant.Address = dm.toDomainAddress(r.Address)
 	domainRestaurant.Menu = dm.toDomainMenu(r.Menu)
 	return &domainRestaurant
@@ -53,20 +101,141 @@ func (dm DefaultMapper) toDomainRestaurant(r *Restaurant) *domain.Restaurant {
 
 // ToDomainAddress maps a Address struct into a domain.Address.
 func (DefaultMapper) toDomainAddress(a *Address) *domain.Address {
-	return domain.NewAddress(a.Street, a.City, a.State, a.Zip)
+	address := domain.NewAddress(a.Street, a.City, a.State, a.Zip)
+	if a.Location != nil {
+		if location, err := domain.NewGeoPoint(a.Location.Latitude, a.Location.Longitude); err == nil {
+			address = address.WithLocation(location)
+		}
+	}
+	return address
 }
 
 // ToDomainMenu maps a Menu struct into a domain.Menu.
-func (DefaultMapper) toDomainMenu(m *Menu) *domain.Menu {
+func (dm DefaultMapper) toDomainMenu(m *Menu) *domain.Menu {
 	domainItems := []*domain.MenuItem{}
 	for _, item := range m.Items {
-		f := new(big.Float)
-		f.SetString(item.Price)
-		domainItems = append(domainItems, domain.NewMenuItem(int16(item.Id), item.Name, f))
+		domainItems = append(domainItems, dm.toDomainMenuItem(item))
 	}
 	return domain.NewMenu(domainItems)
 }
 
+// ToDomainRestaurantChanges maps an UpdateRestaurantRequest struct into a domain.RestaurantChanges,
+// taking into account only the fields present in the update mask.
+func (dm DefaultMapper) toDomainRestaurantChanges(r *UpdateRestaurantRequest) (*domain.RestaurantChanges, error) {
+	changes := domain.RestaurantChanges{}
+	restaurant := r.GetRestaurant()
+	for _, path := range r.GetUpdateMask().GetPaths() {
+		switch path {
+		case "name":
+			name := restaurant.GetName()
+			changes.Name = &name
+		case "address":
+			if restaurant.GetAddress() == nil {
+				return nil, fmt.Errorf("missing address")
+			}
+			changes.Address = dm.toDomainAddress(restaurant.GetAddress())
+		case "default_locale":
+			defaultLocale := restaurant.GetDefaultLocale()
+			changes.DefaultLocale = &defaultLocale
+		case "time_zone":
+			timeZone := restaurant.GetTimeZone()
+			changes.TimeZone = &timeZone
+		case "description":
+			description := restaurant.GetDescription()
+			changes.Description = &description
+		case "description_translations":
+			translations := restaurant.GetDescriptionTranslations()
+			if translations == nil {
+				translations = map[string]string{}
+			}
+			changes.DescriptionTranslations = &translations
+		default:
+			return nil, fmt.Errorf("invalid update mask path: %s", path)
+		}
+	}
+	return &changes, nil
+}
+
+// ToDomainMenuItem maps a MenuItem struct into a domain.MenuItem.
+func (DefaultMapper) toDomainMenuItem(item *MenuItem) *domain.MenuItem {
+	f := new(big.Float)
+	f.SetString(item.Price)
+	available := item.Available == nil || *item.Available
+	return domain.NewMenuItem(int16(item.Id), item.Name, f).
+		WithAvailability(available, toTime(item.AvailableUntil)).
+		WithLabels(toDomainAllergens(item.Allergens), toDomainDietaryTags(item.DietaryTags)).
+		WithTexts(item.Description, toDomainTranslations(item.Translations))
+}
+
+// ToDomainMenuItemChanges maps an UpdateMenuItemRequest struct into a domain.MenuItemChanges,
+// taking into account only the fields present in the update mask.
+func (DefaultMapper) toDomainMenuItemChanges(r *UpdateMenuItemRequest) (*domain.MenuItemChanges, error) {
+	changes := domain.MenuItemChanges{}
+	for _, path := range r.GetUpdateMask().GetPaths() {
+		switch path {
+		case "name":
+			changes.Name = &r.Name
+		case "price":
+			changes.Price = new(big.Float)
+			if _, ok := changes.Price.SetString(r.Price); !ok {
+				return nil, fmt.Errorf("invalid price: %s", r.Price)
+			}
+		case "allergens":
+			allergens := toDomainAllergens(r.Allergens)
+			changes.Allergens = &allergens
+		case "dietary_tags":
+			dietaryTags := toDomainDietaryTags(r.DietaryTags)
+			changes.DietaryTags = &dietaryTags
+		case "description":
+			changes.Description = &r.Description
+		case "translations":
+			translations := toDomainTranslations(r.Translations)
+			changes.Translations = &translations
+		default:
+			return nil, fmt.Errorf("invalid update mask path: %s", path)
+		}
+	}
+	return &changes, nil
+}
+
+// ToDomainRestaurantQuery maps a RestaurantQuery struct into a domain.RestaurantQuery
+// (nil if absent). Unspecified sort fields and directions are left to the defaults.
+func (DefaultMapper) toDomainRestaurantQuery(q *RestaurantQuery) (*domain.RestaurantQuery, error) {
+	if q == nil {
+		return nil, nil
+	}
+	query := &domain.RestaurantQuery{
+		NamePrefix:     q.NamePrefix,
+		City:           q.City,
+		State:          q.State,
+		Zip:            q.Zip,
+		MenuItemName:   q.MenuItemName,
+		SortBy:         restaurantSortFields[q.SortBy],
+		SortDirection:  sortDirections[q.SortDirection],
+		IncludeDeleted: q.IncludeDeleted,
+	}
+	var err error
+	if query.MinPrice, err = toPrice(q.MinPrice); err != nil {
+		return nil, err
+	}
+	if query.MaxPrice, err = toPrice(q.MaxPrice); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// toPrice parses an optional price (nil if empty).
+func toPrice(value string) (*big.Float, error) {
+	if value == "" {
+		return nil, nil
+	}
+	price, ok := new(big.Float).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid price: %s", value)
+	}
+	return price, nil
+}
+
 // ToDomainRestaurants maps a slice of Restaurant into a slice of domain.Restaurant.
 func (dm DefaultMapper) toDomainRestaurants(restaurants []*Restaurant) []*domain.Restaurant {
 	items := []*domain.Restaurant{}
@@ -81,26 +250,75 @@ func (dm DefaultMapper) toDomainRestaurants(restaurants []*Restaurant) []*domain
 func (dm DefaultMapper) fromDomainRestaurant(r *domain.Restaurant) *Restaurant {
 	restRestaurant := Restaurant{}
 	restRestaurant.Id = r.Id
+	restRestaurant.TenantId = r.TenantId
+	restRestaurant.Version = r.Version
 	restRestaurant.Name = r.Name
+	restRestaurant.DefaultLocale = r.DefaultLocale
+	restRestaurant.TimeZone = r.TimeZone
+	restRestaurant.Description = r.Description
+	restRestaurant.DescriptionTranslations = r.DescriptionTranslations
 	restRestaurant.Address = dm.fromDomainAddress(r.Address)
 	restRestaurant.Menu = dm.fromDomainMenu(r.Menu)
+	restRestaurant.Dayparts = dm.fromDomainDayparts(r.Dayparts)
+	restRestaurant.DeletedAt = fromTime(r.DeletedAt)
 	return &restRestaurant
 }
 
 // FromDomainAddress maps a domain.Address struct into a Address.
 func (DefaultMapper) fromDomainAddress(a *domain.Address) *Address {
-	return &Address{Street: a.Street(), City: a.City(), State: a.State(), Zip: a.Zip()}
+	address := &Address{Street: a.Street(), City: a.City(), State: a.State(), Zip: a.Zip()}
+	if location := a.Location(); location != nil {
+		address.Location = &GeoPoint{Latitude: location.Latitude(), Longitude: location.Longitude()}
+	}
+	return address
 }
 
 // FromDomainMenu maps a domain.Menu struct into a Menu.
 func (DefaultMapper) fromDomainMenu(menu *domain.Menu) *Menu {
 	items := []*MenuItem{}
 	for _, item := range menu.GetItems() {
-		items = append(items, &MenuItem{Id: int32(item.GetId()), Name: item.GetName(), Price: item.GetPrice().String()})
+		available := item.IsAvailable()
+		items = append(items, &MenuItem{
+			Id:             int32(item.GetId()),
+			Name:           item.GetName(),
+			Description:    item.GetDescription(),
+			Translations:   fromDomainTranslations(item.GetTranslations()),
+			Price:          item.GetPrice().String(),
+			Available:      &available,
+			AvailableUntil: fromTime(item.GetAvailableUntil()),
+			Allergens:      fromDomainAllergens(item.GetAllergens()),
+			DietaryTags:    fromDomainDietaryTags(item.GetDietaryTags()),
+		})
 	}
 	return &Menu{Items: items}
 }
 
+// ToDomainDayparts maps a slice of Daypart into a slice of domain.Daypart.
+func (dm DefaultMapper) toDomainDayparts(dayparts []*Daypart) []*domain.Daypart {
+	domainDayparts := []*domain.Daypart{}
+	for _, daypart := range dayparts {
+		windows := []*domain.TimeWindow{}
+		for _, window := range daypart.Windows {
+			windows = append(windows, domain.NewTimeWindow(int(window.Start), int(window.End)))
+		}
+		domainDayparts = append(domainDayparts, domain.NewDaypart(daypart.Name, windows, dm.toDomainMenu(daypart.Menu)))
+	}
+	return domainDayparts
+}
+
+// FromDomainDayparts maps a slice of domain.Daypart into a slice of Daypart.
+func (dm DefaultMapper) fromDomainDayparts(dayparts []*domain.Daypart) []*Daypart {
+	items := []*Daypart{}
+	for _, daypart := range dayparts {
+		windows := []*TimeWindow{}
+		for _, window := range daypart.GetWindows() {
+			windows = append(windows, &TimeWindow{Start: int32(window.GetStart()), End: int32(window.GetEnd())})
+		}
+		items = append(items, &Daypart{Name: daypart.GetName(), Windows: windows, Menu: dm.fromDomainMenu(daypart.GetMenu())})
+	}
+	return items
+}
+
 // FromDomainRestaurants maps a slice of domain.Restaurant into a slice of Restaurant.
 func (dm DefaultMapper) fromDomainRestaurants(restaurants []*domain.Restaurant) []*Restaurant {
 	items := []*Restaurant{}
@@ -110,3 +328,275 @@ func (dm DefaultMapper) fromDomainRestaurants(restaurants []*domain.Restaurant)
 
 	return items
 }
+
+// FromDomainRestaurantPage maps a domain.RestaurantPage struct into a GetRestaurantsResponse.
+func (dm DefaultMapper) fromDomainRestaurantPage(page *domain.RestaurantPage) *GetRestaurantsResponse {
+	response := &GetRestaurantsResponse{Restaurants: dm.fromDomainRestaurants(page.Restaurants), TotalEstimated: page.Estimated}
+	if page.Total != nil {
+		total := int32(*page.Total)
+		response.Total = &total
+	}
+	if page.NextCursor != nil {
+		response.NextCursor = page.NextCursor.Encode()
+	}
+	if page.PrevCursor != nil {
+		response.PrevCursor = page.PrevCursor.Encode()
+	}
+
+	return response
+}
+
+// FromDomainSearchResults maps a slice of domain.RestaurantSearchResult into a slice of SearchResult.
+func (dm DefaultMapper) fromDomainSearchResults(results []*domain.RestaurantSearchResult) []*SearchResult {
+	items := []*SearchResult{}
+	for _, item := range results {
+		items = append(items, &SearchResult{Restaurant: dm.fromDomainRestaurant(item.GetRestaurant()), Rank: item.GetRank(), Highlight: item.GetHighlight()})
+	}
+
+	return items
+}
+
+// toTime maps an optional protobuf timestamp into an optional time.Time.
+func toTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// fromTime maps an optional time.Time into an optional protobuf timestamp.
+func fromTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// allergens maps the protobuf allergens into domain allergens.
+var allergens = map[Allergen]domain.Allergen{
+	Allergen_ALLERGEN_CELERY:      domain.AllergenCelery,
+	Allergen_ALLERGEN_GLUTEN:      domain.AllergenGluten,
+	Allergen_ALLERGEN_CRUSTACEANS: domain.AllergenCrustaceans,
+	Allergen_ALLERGEN_EGGS:        domain.AllergenEggs,
+	Allergen_ALLERGEN_FISH:        domain.AllergenFish,
+	Allergen_ALLERGEN_LUPIN:       domain.AllergenLupin,
+	Allergen_ALLERGEN_MILK:        domain.AllergenMilk,
+	Allergen_ALLERGEN_MOLLUSCS:    domain.AllergenMolluscs,
+	Allergen_ALLERGEN_MUSTARD:     domain.AllergenMustard,
+	Allergen_ALLERGEN_NUTS:        domain.AllergenNuts,
+	Allergen_ALLERGEN_PEANUTS:     domain.AllergenPeanuts,
+	Allergen_ALLERGEN_SESAME:      domain.AllergenSesame,
+	Allergen_ALLERGEN_SOYBEANS:    domain.AllergenSoybeans,
+	Allergen_ALLERGEN_SULPHITES:   domain.AllergenSulphites,
+}
+
+// dietaryTags maps the protobuf dietary tags into domain dietary tags.
+var dietaryTags = map[DietaryTag]domain.DietaryTag{
+	DietaryTag_DIETARY_TAG_VEGAN:       domain.DietaryTagVegan,
+	DietaryTag_DIETARY_TAG_VEGETARIAN:  domain.DietaryTagVegetarian,
+	DietaryTag_DIETARY_TAG_GLUTEN_FREE: domain.DietaryTagGlutenFree,
+}
+
+// toDomainTranslations maps a map of protobuf translations keyed by locale into a map of domain.Translation.
+func toDomainTranslations(translations map[string]*Translation) map[string]*domain.Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	domainTranslations := map[string]*domain.Translation{}
+	for locale, t := range translations {
+		domainTranslations[locale] = domain.NewTranslation(t.GetName(), t.GetDescription())
+	}
+	return domainTranslations
+}
+
+// fromDomainTranslations maps a map of domain.Translation keyed by locale into a map of protobuf translations.
+func fromDomainTranslations(translations map[string]*domain.Translation) map[string]*Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	values := map[string]*Translation{}
+	for locale, t := range translations {
+		values[locale] = &Translation{Name: t.GetName(), Description: t.GetDescription()}
+	}
+	return values
+}
+
+// toDomainAllergens maps a slice of protobuf allergens into a slice of domain.Allergen.
+// Unknown values are mapped to an invalid domain allergen so the core can reject them.
+func toDomainAllergens(values []Allergen) []domain.Allergen {
+	if len(values) == 0 {
+		return nil
+	}
+	result := []domain.Allergen{}
+	for _, v := range values {
+		result = append(result, allergens[v])
+	}
+	return result
+}
+
+// toDomainDietaryTags maps a slice of protobuf dietary tags into a slice of domain.DietaryTag.
+// Unknown values are mapped to an invalid domain dietary tag so the core can reject them.
+func toDomainDietaryTags(values []DietaryTag) []domain.DietaryTag {
+	if len(values) == 0 {
+		return nil
+	}
+	result := []domain.DietaryTag{}
+	for _, v := range values {
+		result = append(result, dietaryTags[v])
+	}
+	return result
+}
+
+// fromDomainAllergens maps a slice of domain.Allergen into a slice of protobuf allergens.
+func fromDomainAllergens(values []domain.Allergen) []Allergen {
+	if len(values) == 0 {
+		return nil
+	}
+	result := []Allergen{}
+	for _, v := range values {
+		for k, a := range allergens {
+			if a == v {
+				result = append(result, k)
+			}
+		}
+	}
+	return result
+}
+
+// fromDomainDietaryTags maps a slice of domain.DietaryTag into a slice of protobuf dietary tags.
+func fromDomainDietaryTags(values []domain.DietaryTag) []DietaryTag {
+	if len(values) == 0 {
+		return nil
+	}
+	result := []DietaryTag{}
+	for _, v := range values {
+		for k, t := range dietaryTags {
+			if t == v {
+				result = append(result, k)
+			}
+		}
+	}
+	return result
+}
+
+// restaurantSortFields maps the protobuf sort fields into domain sort fields.
+var restaurantSortFields = map[RestaurantSortField]domain.RestaurantSortField{
+	RestaurantSortField_RESTAURANT_SORT_FIELD_ID:    domain.RestaurantSortById,
+	RestaurantSortField_RESTAURANT_SORT_FIELD_NAME:  domain.RestaurantSortByName,
+	RestaurantSortField_RESTAURANT_SORT_FIELD_CITY:  domain.RestaurantSortByCity,
+	RestaurantSortField_RESTAURANT_SORT_FIELD_STATE: domain.RestaurantSortByState,
+	RestaurantSortField_RESTAURANT_SORT_FIELD_ZIP:   domain.RestaurantSortByZip,
+}
+
+// sortDirections maps the protobuf sort directions into domain sort directions.
+var sortDirections = map[SortDirection]domain.SortDirection{
+	SortDirection_SORT_DIRECTION_ASC:  domain.SortAscending,
+	SortDirection_SORT_DIRECTION_DESC: domain.SortDescending,
+}
+
+// countModes maps the protobuf count modes into domain count modes.
+var countModes = map[CountMode]domain.CountMode{
+	CountMode_COUNT_MODE_EXACT:     domain.CountExact,
+	CountMode_COUNT_MODE_ESTIMATED: domain.CountEstimated,
+	CountMode_COUNT_MODE_NONE:      domain.CountNone,
+}
+
+// ticketStates maps the protobuf ticket states into domain ticket states.
+var ticketStates = map[TicketState]domain.TicketState{
+	TicketState_TICKET_STATE_CREATE_PENDING:   domain.TicketStateCreatePending,
+	TicketState_TICKET_STATE_CREATED:          domain.TicketStateCreated,
+	TicketState_TICKET_STATE_ACCEPTED:         domain.TicketStateAccepted,
+	TicketState_TICKET_STATE_PREPARING:        domain.TicketStatePreparing,
+	TicketState_TICKET_STATE_READY_FOR_PICKUP: domain.TicketStateReadyForPickup,
+	TicketState_TICKET_STATE_PICKED_UP:        domain.TicketStatePickedUp,
+	TicketState_TICKET_STATE_CANCELLED:        domain.TicketStateCancelled,
+}
+
+// toDomainTicketLineItems maps a slice of TicketLineItem into a slice of domain.TicketLineItem.
+// The names of the line items are ignored because they are taken from the menu.
+func (DefaultMapper) toDomainTicketLineItems(lineItems []*TicketLineItem) []*domain.TicketLineItem {
+	result := []*domain.TicketLineItem{}
+	for _, lineItem := range lineItems {
+		result = append(result, domain.NewTicketLineItem(int16(lineItem.MenuItemId), "", lineItem.Quantity))
+	}
+	return result
+}
+
+// fromDomainTicket maps a domain.Ticket struct into a Ticket.
+func (DefaultMapper) fromDomainTicket(t *domain.Ticket) *Ticket {
+	lineItems := []*TicketLineItem{}
+	for _, lineItem := range t.LineItems {
+		lineItems = append(lineItems, &TicketLineItem{
+			MenuItemId: int32(lineItem.GetMenuItemId()),
+			Name:       lineItem.GetName(),
+			Quantity:   lineItem.GetQuantity(),
+		})
+	}
+	ticket := &Ticket{
+		Id:               t.Id,
+		RestaurantId:     t.RestaurantId,
+		OrderId:          t.OrderId,
+		LineItems:        lineItems,
+		ReadyBy:          fromTime(t.ReadyBy),
+		CreatedAt:        fromTime(t.CreatedAt),
+		AcceptedAt:       fromTime(t.AcceptedAt),
+		PreparingAt:      fromTime(t.PreparingAt),
+		ReadyForPickupAt: fromTime(t.ReadyForPickupAt),
+		PickedUpAt:       fromTime(t.PickedUpAt),
+		CancelledAt:      fromTime(t.CancelledAt),
+	}
+	for k, s := range ticketStates {
+		if s == t.State {
+			ticket.State = k
+		}
+	}
+	return ticket
+}
+
+// fromDomainTickets maps a slice of domain.Ticket into a slice of Ticket.
+func (dm DefaultMapper) fromDomainTickets(tickets []*domain.Ticket) []*Ticket {
+	result := []*Ticket{}
+	for _, t := range tickets {
+		result = append(result, dm.fromDomainTicket(t))
+	}
+	return result
+}
+
+// orderLineRejections maps the protobuf order line rejections into domain order line rejections.
+var orderLineRejections = map[OrderLineRejection]domain.OrderLineRejection{
+	OrderLineRejection_ORDER_LINE_REJECTION_MENU_ITEM_NOT_FOUND:     domain.OrderLineRejectionMenuItemNotFound,
+	OrderLineRejection_ORDER_LINE_REJECTION_MENU_ITEM_NOT_AVAILABLE: domain.OrderLineRejectionMenuItemNotAvailable,
+	OrderLineRejection_ORDER_LINE_REJECTION_INVALID_QUANTITY:        domain.OrderLineRejectionInvalidQuantity,
+	OrderLineRejection_ORDER_LINE_REJECTION_DUPLICATED_MENU_ITEM:    domain.OrderLineRejectionDuplicatedMenuItem,
+}
+
+// toDomainOrderLines maps a slice of OrderLine into a slice of domain.OrderLine.
+func (DefaultMapper) toDomainOrderLines(lines []*OrderLine) []*domain.OrderLine {
+	result := []*domain.OrderLine{}
+	for _, line := range lines {
+		result = append(result, domain.NewOrderLine(int16(line.MenuItemId), line.Quantity))
+	}
+	return result
+}
+
+// fromDomainQuote maps a domain.Quote struct into a ValidateOrderResponse. Prices
+// are rendered with two decimals.
+func (DefaultMapper) fromDomainQuote(q *domain.Quote) *ValidateOrderResponse {
+	lines := []*QuoteLine{}
+	for _, line := range q.GetLines() {
+		quoteLine := &QuoteLine{MenuItemId: int32(line.GetMenuItemId()), Name: line.GetName(), Quantity: line.GetQuantity()}
+		if line.GetRejection() != nil {
+			for k, r := range orderLineRejections {
+				if r == *line.GetRejection() {
+					quoteLine.RejectionReason = k
+				}
+			}
+		} else {
+			quoteLine.UnitPrice = line.GetUnitPrice().Text('f', 2)
+			quoteLine.LineTotal = line.GetLineTotal().Text('f', 2)
+		}
+		lines = append(lines, quoteLine)
+	}
+	return &ValidateOrderResponse{Valid: q.IsValid(), Lines: lines, Total: q.GetTotal().Text('f', 2)}
+}
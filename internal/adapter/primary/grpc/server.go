@@ -2,7 +2,13 @@ package grpc
 
 import (
 	context "context"
+	"f4allgo-restaurant/internal/core/domain"
 	"f4allgo-restaurant/internal/core/port"
+	"fmt"
+	"math"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Implementation of the generated interface RestaurantServiceServer.
@@ -21,12 +27,46 @@ func NewRestaurantServiceServer(s port.RestaurantService) *restaurantServiceServ
 
 func (rs *restaurantServiceServer) GetRestaurants(ctx context.Context, req *GetRestaurantsRequest) (*GetRestaurantsResponse, error) {
 	offset, limit := getOffsetAndLimit(req)
-	domainRestaurants, total, err := rs.restaurantService.FindAll(ctx, int(offset), int(limit))
+	var page *domain.RestaurantPage
+	var err error
+	if req.Near != nil {
+		point, radius, nearErr := getNearAndRadius(req)
+		if nearErr != nil {
+			return nil, nearErr
+		}
+		domainRestaurants, total, nearbyErr := rs.restaurantService.FindNearby(ctx, point, radius, offset, limit, toDomainAllergens(req.ExcludedAllergens))
+		page, err = &domain.RestaurantPage{Restaurants: domainRestaurants, Total: &total}, nearbyErr
+	} else {
+		query, queryErr := rs.mapper.toDomainRestaurantQuery(req.Query)
+		if queryErr != nil {
+			return nil, queryErr
+		}
+		pagination, paginationErr := getPagination(req, offset, limit)
+		if paginationErr != nil {
+			return nil, paginationErr
+		}
+		page, err = rs.restaurantService.FindAll(ctx, query, pagination, toDomainAllergens(req.ExcludedAllergens))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, domainRestaurant := range page.Restaurants {
+		page.Restaurants[i], _ = localize(domainRestaurant, req.Locales)
+	}
+	return rs.mapper.fromDomainRestaurantPage(page), nil
+}
+
+func (rs *restaurantServiceServer) SearchRestaurants(ctx context.Context, req *SearchRestaurantsRequest) (*SearchRestaurantsResponse, error) {
+	offset, limit := getOffsetAndLimit(req)
+	results, total, err := rs.restaurantService.Search(ctx, req.Text, offset, limit, toDomainAllergens(req.ExcludedAllergens))
 	if err != nil {
 		return nil, err
 	}
-	dtoRestaurants := rs.mapper.fromDomainRestaurants(domainRestaurants)
-	return &GetRestaurantsResponse{Restaurants: dtoRestaurants, Total: int32(total)}, nil
+	for i, result := range results {
+		restaurant, _ := localize(result.GetRestaurant(), req.Locales)
+		results[i] = domain.NewRestaurantSearchResult(restaurant, result.GetRank(), result.GetHighlight())
+	}
+	return &SearchRestaurantsResponse{Results: rs.mapper.fromDomainSearchResults(results), Total: int32(total)}, nil
 }
 
 func (rs *restaurantServiceServer) CreateRestaurant(ctx context.Context, req *CreateRestaurantRequest) (*CreateRestaurantResponse, error) {
@@ -35,19 +75,34 @@ func (rs *restaurantServiceServer) CreateRestaurant(ctx context.Context, req *Cr
 	return &CreateRestaurantResponse{RestaurantId: restaurantId}, err
 }
 
+func (rs *restaurantServiceServer) UpdateRestaurant(ctx context.Context, req *UpdateRestaurantRequest) (*UpdateRestaurantResponse, error) {
+	changes, err := rs.mapper.toDomainRestaurantChanges(req)
+	if err != nil {
+		return nil, err
+	}
+	err = rs.restaurantService.UpdateRestaurant(ctx, req.RestaurantId, changes)
+	return &UpdateRestaurantResponse{}, err
+}
+
 func (rs *restaurantServiceServer) UpdateMenu(ctx context.Context, req *UpdateMenuRequest) (*UpdateMenuResponse, error) {
 	err := rs.restaurantService.UpdateMenu(ctx, req.RestaurantId, rs.mapper.toDomainMenu(req.Menu))
 	return &UpdateMenuResponse{}, err
 }
 
+func (rs *restaurantServiceServer) UpdateDayparts(ctx context.Context, req *UpdateDaypartsRequest) (*UpdateDaypartsResponse, error) {
+	err := rs.restaurantService.UpdateDayparts(ctx, req.RestaurantId, rs.mapper.toDomainDayparts(req.Dayparts))
+	return &UpdateDaypartsResponse{}, err
+}
+
 func (rs *restaurantServiceServer) GetRestaurantById(ctx context.Context, req *GetRestaurantByIdRequest) (*GetRestaurantResponse, error) {
-	domainRestaurant, err := rs.restaurantService.FindById(ctx, req.RestaurantId)
+	domainRestaurant, err := rs.restaurantService.FindById(ctx, req.RestaurantId, toTime(req.At), toDomainAllergens(req.ExcludedAllergens))
 	if err != nil {
 		return nil, err
 	}
 
+	domainRestaurant, locale := localize(domainRestaurant, req.Locales)
 	dtoRestaurant := rs.mapper.fromDomainRestaurant(domainRestaurant)
-	return &GetRestaurantResponse{Restaurant: dtoRestaurant}, nil
+	return &GetRestaurantResponse{Restaurant: dtoRestaurant, Locale: locale}, nil
 }
 
 func (rs *restaurantServiceServer) DeleteRestaurant(ctx context.Context, req *DeleteRestaurantRequest) (*DeleteRestaurantResponse, error) {
@@ -55,9 +110,67 @@ func (rs *restaurantServiceServer) DeleteRestaurant(ctx context.Context, req *De
 	return &DeleteRestaurantResponse{}, err
 }
 
-func getOffsetAndLimit(req *GetRestaurantsRequest) (int, int) {
-	offset := req.Offset
-	limit := req.Limit
+func (rs *restaurantServiceServer) RestoreRestaurant(ctx context.Context, req *RestoreRestaurantRequest) (*RestoreRestaurantResponse, error) {
+	err := rs.restaurantService.Restore(ctx, req.RestaurantId)
+	return &RestoreRestaurantResponse{}, err
+}
+
+func (rs *restaurantServiceServer) SetMenuItemAvailability(ctx context.Context, req *SetMenuItemAvailabilityRequest) (*SetMenuItemAvailabilityResponse, error) {
+	menuItemId, err := toMenuItemId(req.MenuItemId)
+	if err != nil {
+		return nil, err
+	}
+	err = rs.restaurantService.SetItemAvailability(ctx, req.RestaurantId, menuItemId, req.Available, toTime(req.AvailableUntil))
+	return &SetMenuItemAvailabilityResponse{}, err
+}
+
+func (rs *restaurantServiceServer) AddMenuItem(ctx context.Context, req *AddMenuItemRequest) (*AddMenuItemResponse, error) {
+	if _, err := toMenuItemId(req.MenuItem.GetId()); err != nil {
+		return nil, err
+	}
+	err := rs.restaurantService.AddMenuItem(ctx, req.RestaurantId, rs.mapper.toDomainMenuItem(req.MenuItem))
+	return &AddMenuItemResponse{}, err
+}
+
+func (rs *restaurantServiceServer) UpdateMenuItem(ctx context.Context, req *UpdateMenuItemRequest) (*UpdateMenuItemResponse, error) {
+	menuItemId, err := toMenuItemId(req.MenuItemId)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := rs.mapper.toDomainMenuItemChanges(req)
+	if err != nil {
+		return nil, err
+	}
+	err = rs.restaurantService.UpdateMenuItem(ctx, req.RestaurantId, menuItemId, changes)
+	return &UpdateMenuItemResponse{}, err
+}
+
+func (rs *restaurantServiceServer) RemoveMenuItem(ctx context.Context, req *RemoveMenuItemRequest) (*RemoveMenuItemResponse, error) {
+	menuItemId, err := toMenuItemId(req.MenuItemId)
+	if err != nil {
+		return nil, err
+	}
+	err = rs.restaurantService.RemoveMenuItem(ctx, req.RestaurantId, menuItemId)
+	return &RemoveMenuItemResponse{}, err
+}
+
+func (rs *restaurantServiceServer) ValidateOrder(ctx context.Context, req *ValidateOrderRequest) (*ValidateOrderResponse, error) {
+	quote, err := rs.restaurantService.ValidateOrder(ctx, req.RestaurantId, rs.mapper.toDomainOrderLines(req.Lines))
+	if err != nil {
+		return nil, err
+	}
+	return rs.mapper.fromDomainQuote(quote), nil
+}
+
+// paginatedRequest is implemented by the requests of the paginated listings.
+type paginatedRequest interface {
+	GetOffset() int32
+	GetLimit() int32
+}
+
+func getOffsetAndLimit(req paginatedRequest) (int, int) {
+	offset := req.GetOffset()
+	limit := req.GetLimit()
 
 	if limit == 0 {
 		limit = 10
@@ -67,3 +180,52 @@ func getOffsetAndLimit(req *GetRestaurantsRequest) (int, int) {
 
 	return int(offset), int(limit)
 }
+
+// getPagination builds the pagination of a listing from the cursor and the count mode
+// of the request, besides the offset and the limit.
+func getPagination(req *GetRestaurantsRequest, offset int, limit int) (*domain.Pagination, error) {
+	pagination := &domain.Pagination{Offset: offset, Limit: limit, Count: countModes[req.Count]}
+	if req.Cursor != "" {
+		cursor, err := domain.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		pagination.Cursor = cursor
+	}
+	return pagination, nil
+}
+
+// Maximum radius (in meters) allowed in nearby searches.
+const maxRadius = 50000
+
+func getNearAndRadius(req *GetRestaurantsRequest) (*domain.GeoPoint, float64, error) {
+	point, err := domain.NewGeoPoint(req.Near.Latitude, req.Near.Longitude)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	radius := req.Radius
+	if radius == 0 {
+		radius = 5000
+	} else if !(radius > 0 && radius <= maxRadius) {
+		return nil, 0, fmt.Errorf("invalid radius, expected meters in (0, %v]: %v", maxRadius, radius)
+	}
+
+	return point, radius, nil
+}
+
+// toMenuItemId narrows the id of a menu item, which the requests carry as an int32,
+// rejecting the ids out of the range of the menu item ids.
+func toMenuItemId(id int32) (int16, error) {
+	if id < 1 || id > math.MaxInt16 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid menu item id, expected an integer in [1, %d]: %d", math.MaxInt16, id)
+	}
+	return int16(id), nil
+}
+
+// localize returns the restaurant with the texts of the available locale that
+// best matches the preferred ones, together with that locale.
+func localize(restaurant *domain.Restaurant, preferred []string) (*domain.Restaurant, string) {
+	locale := domain.NegotiateLocale(restaurant.Locales(), preferred)
+	return restaurant.Localize(locale), locale
+}
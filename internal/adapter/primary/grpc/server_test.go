@@ -0,0 +1,17 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetRestaurantsWithMalformedCursor(t *testing.T) {
+	// The cursor is rejected before the service is called.
+	rs := NewRestaurantServiceServer(nil)
+	_, err := rs.GetRestaurants(context.Background(), &GetRestaurantsRequest{Cursor: "malformed"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	context "context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+)
+
+// Implementation of the generated interface TicketServiceServer.
+type ticketServiceServer struct {
+	UnimplementedTicketServiceServer
+	mapper        Mapper
+	ticketService port.TicketService
+}
+
+// Interface compliance verification.
+var _ TicketServiceServer = (*ticketServiceServer)(nil)
+
+func NewTicketServiceServer(s port.TicketService) *ticketServiceServer {
+	return &ticketServiceServer{mapper: DefaultMapper{}, ticketService: s}
+}
+
+func (ts *ticketServiceServer) GetTickets(ctx context.Context, req *GetTicketsRequest) (*GetTicketsResponse, error) {
+	var state *domain.TicketState
+	if req.State != TicketState_TICKET_STATE_UNSPECIFIED {
+		domainState, ok := ticketStates[req.State]
+		if !ok {
+			return nil, errors.New("unknown ticket state")
+		}
+		state = &domainState
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = 10
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	domainTickets, total, err := ts.ticketService.FindByRestaurant(ctx, req.RestaurantId, state, int(req.Offset), int(limit))
+	if err != nil {
+		return nil, err
+	}
+	return &GetTicketsResponse{Tickets: ts.mapper.fromDomainTickets(domainTickets), Total: int32(total)}, nil
+}
+
+func (ts *ticketServiceServer) GetTicketById(ctx context.Context, req *GetTicketByIdRequest) (*GetTicketResponse, error) {
+	domainTicket, err := ts.ticketService.FindById(ctx, req.TicketId)
+	if err != nil {
+		return nil, err
+	}
+	return &GetTicketResponse{Ticket: ts.mapper.fromDomainTicket(domainTicket)}, nil
+}
+
+func (ts *ticketServiceServer) CreateTicket(ctx context.Context, req *CreateTicketRequest) (*CreateTicketResponse, error) {
+	ticketId, err := ts.ticketService.Create(ctx, req.RestaurantId, req.OrderId, ts.mapper.toDomainTicketLineItems(req.LineItems))
+	return &CreateTicketResponse{TicketId: ticketId}, err
+}
+
+func (ts *ticketServiceServer) AcceptTicket(ctx context.Context, req *AcceptTicketRequest) (*ChangeTicketStateResponse, error) {
+	if req.ReadyBy == nil {
+		return nil, errors.New("ready_by is required")
+	}
+	err := ts.ticketService.Accept(ctx, req.TicketId, req.ReadyBy.AsTime())
+	return &ChangeTicketStateResponse{}, err
+}
+
+func (ts *ticketServiceServer) RejectTicket(ctx context.Context, req *ChangeTicketStateRequest) (*ChangeTicketStateResponse, error) {
+	err := ts.ticketService.Reject(ctx, req.TicketId)
+	return &ChangeTicketStateResponse{}, err
+}
+
+func (ts *ticketServiceServer) StartPreparingTicket(ctx context.Context, req *ChangeTicketStateRequest) (*ChangeTicketStateResponse, error) {
+	err := ts.ticketService.StartPreparing(ctx, req.TicketId)
+	return &ChangeTicketStateResponse{}, err
+}
+
+func (ts *ticketServiceServer) MarkTicketReadyForPickup(ctx context.Context, req *ChangeTicketStateRequest) (*ChangeTicketStateResponse, error) {
+	err := ts.ticketService.MarkReadyForPickup(ctx, req.TicketId)
+	return &ChangeTicketStateResponse{}, err
+}
+
+func (ts *ticketServiceServer) PickUpTicket(ctx context.Context, req *ChangeTicketStateRequest) (*ChangeTicketStateResponse, error) {
+	err := ts.ticketService.PickUp(ctx, req.TicketId)
+	return &ChangeTicketStateResponse{}, err
+}
+
+func (ts *ticketServiceServer) CancelTicket(ctx context.Context, req *ChangeTicketStateRequest) (*ChangeTicketStateResponse, error) {
+	err := ts.ticketService.Cancel(ctx, req.TicketId)
+	return &ChangeTicketStateResponse{}, err
+}
@@ -1,9 +1,12 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
+	"f4allgo-restaurant/internal/core/domain"
 	"f4allgo-restaurant/internal/core/port"
 
 	coreerrors "f4allgo-restaurant/internal/core/service/errors"
@@ -12,13 +15,14 @@ import (
 )
 
 type RestaurantHandler struct {
-	mapper            Mapper
-	restaurantService port.RestaurantService
+	mapper              Mapper
+	restaurantService   port.RestaurantService
+	menuScheduleService port.MenuScheduleService
 }
 
 // NewRestaurantHandler builds a new RestaurantHandler struct.
-func NewRestaurantHandler(s port.RestaurantService) *RestaurantHandler {
-	return &RestaurantHandler{mapper: DefaultMapper{}, restaurantService: s}
+func NewRestaurantHandler(s port.RestaurantService, ms port.MenuScheduleService) *RestaurantHandler {
+	return &RestaurantHandler{mapper: DefaultMapper{}, restaurantService: s, menuScheduleService: ms}
 }
 
 // CreateRestaurant creates a restaurant.
@@ -39,6 +43,31 @@ func (rh *RestaurantHandler) CreateRestaurant(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, CreateRestaurantResponse{RestaurantId: restaurantId})
 }
 
+// BatchCreateRestaurants creates a batch of restaurants, reporting the result of each
+// one. It's routed as a custom method of the collection (POST /restaurants:batchCreate),
+// so the method is captured by the "method" parameter, including its leading colon.
+func (rh *RestaurantHandler) BatchCreateRestaurants(ctx *gin.Context) {
+	if ctx.Param("method") != ":batchCreate" {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	var request BatchCreateRestaurantsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	restaurants := rh.mapper.toDomainRestaurants(request.Restaurants)
+	results, err := rh.restaurantService.CreateMany(ctx, restaurants, domain.BatchMode(request.Mode))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BatchCreateRestaurantsResponse{Results: rh.mapper.fromDomainCreationResults(results)})
+}
+
 // DeleteRestaurant deletes a restaurant.
 func (rh *RestaurantHandler) DeleteRestaurant(ctx *gin.Context) {
 	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
@@ -56,19 +85,99 @@ func (rh *RestaurantHandler) DeleteRestaurant(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{})
 }
 
-// GetRestaurants gets the list of all restaurants.
+// RestoreRestaurant restores a deleted restaurant that hasn't been purged yet.
+func (rh *RestaurantHandler) RestoreRestaurant(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+
+	err = rh.restaurantService.Restore(ctx, restaurantId)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// GetRestaurants gets a page of the restaurants matching the search query params, or
+// only the ones near a point (sorted by distance) if the 'near' query param is
+// present, in which case the search query params are ignored. The pages are read
+// after the 'cursor' query param if present (as returned in the 'nextCursor' and
+// 'prevCursor' fields), or after the 'offset' otherwise. Only the listings without
+// 'near' support cursors and the 'count' query param. The texts of every restaurant
+// are in the locale that best matches the Accept-Language header.
 func (rh *RestaurantHandler) GetRestaurants(ctx *gin.Context) {
 	offset, limit := getOffsetAndLimit(ctx)
-	domainRestaurants, total, err := rh.restaurantService.FindAll(ctx, offset, limit)
+	excludedAllergens, err := parseAllergens(ctx.Query("excludeAllergens"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, err := getRestaurantQuery(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var page *domain.RestaurantPage
+	if near := ctx.Query("near"); near != "" {
+		point, radius, parseErr := parseNear(near, ctx.DefaultQuery("radius", "5000"))
+		if parseErr != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": parseErr.Error()})
+			return
+		}
+		domainRestaurants, total, nearbyErr := rh.restaurantService.FindNearby(ctx, point, radius, offset, limit, excludedAllergens)
+		page, err = &domain.RestaurantPage{Restaurants: domainRestaurants, Total: &total}, nearbyErr
+	} else {
+		pagination, parseErr := getPagination(ctx, offset, limit)
+		if parseErr != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": parseErr.Error()})
+			return
+		}
+		page, err = rh.restaurantService.FindAll(ctx, query, pagination, excludedAllergens)
+	}
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	preferred := parseAcceptLanguage(ctx.GetHeader("Accept-Language"))
+	for i, domainRestaurant := range page.Restaurants {
+		page.Restaurants[i], _ = localize(domainRestaurant, preferred)
+	}
+	ctx.JSON(http.StatusOK, rh.mapper.fromDomainRestaurantPage(page))
+}
+
+// SearchRestaurants gets the restaurants whose name, menu items or address match the
+// full-text search given in the 'q' query param, sorted by relevance. The texts of
+// every restaurant are in the locale that best matches the Accept-Language header.
+func (rh *RestaurantHandler) SearchRestaurants(ctx *gin.Context) {
+	offset, limit := getOffsetAndLimit(ctx)
+	excludedAllergens, err := parseAllergens(ctx.Query("excludeAllergens"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, total, err := rh.restaurantService.Search(ctx, ctx.Query("q"), offset, limit, excludedAllergens)
+	if err != nil {
+		handleError(ctx, err)
 		return
 	}
-	dtoRestaurants := rh.mapper.fromDomainRestaurants(domainRestaurants)
-	ctx.JSON(http.StatusOK, GetRestaurantsResponse{Restaurants: dtoRestaurants, Total: total})
+	preferred := parseAcceptLanguage(ctx.GetHeader("Accept-Language"))
+	for i, result := range results {
+		restaurant, _ := localize(result.GetRestaurant(), preferred)
+		results[i] = domain.NewRestaurantSearchResult(restaurant, result.GetRank(), result.GetHighlight())
+	}
+	ctx.JSON(http.StatusOK, SearchRestaurantsResponse{Results: rh.mapper.fromDomainSearchResults(results), Total: total})
 }
 
-// GetRestaurant gets a restaurant by its ID.
+// GetRestaurant gets a restaurant by its ID. If the 'at' query param (RFC 3339) is
+// present, the menu of the restaurant is the one offered at that instant. The texts
+// are in the locale that best matches the Accept-Language header, which is returned
+// in the Content-Language header.
 func (rh *RestaurantHandler) GetRestaurant(ctx *gin.Context) {
 	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
 	if err != nil {
@@ -76,17 +185,123 @@ func (rh *RestaurantHandler) GetRestaurant(ctx *gin.Context) {
 		return
 	}
 
-	domainRestaurant, err := rh.restaurantService.FindById(ctx, restaurantId)
+	excludedAllergens, err := parseAllergens(ctx.Query("excludeAllergens"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var at *time.Time
+	if atStr := ctx.Query("at"); atStr != "" {
+		t, err := time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		at = &t
+	}
+
+	domainRestaurant, err := rh.restaurantService.FindById(ctx, restaurantId, at, excludedAllergens)
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
 
+	domainRestaurant, locale := localize(domainRestaurant, parseAcceptLanguage(ctx.GetHeader("Accept-Language")))
+	ctx.Header("Content-Language", locale)
+	// The representation depends on the locale, the instant of the menu and the
+	// allergens excluded, so they are part of the entity tag.
+	ctx.Header("Vary", "Accept-Language")
+	ctx.Header(etagHeader, representationEtag(domainRestaurant.Version, locale, ctx.Query("at"), ctx.Query("excludeAllergens")))
 	dtoRestaurant := rh.mapper.fromDomainRestaurant(domainRestaurant)
 	ctx.JSON(http.StatusOK, GetRestaurantResponse{Restaurant: dtoRestaurant})
 }
 
-// UpdateMenu updates the menu of a restaurant.
+// GetMenu gets the menu of a restaurant in effect at the instant given by the 'at'
+// query param (RFC 3339), or the current one if it's not present.
+func (rh *RestaurantHandler) GetMenu(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+
+	at := time.Now()
+	if atStr := ctx.Query("at"); atStr != "" {
+		at, err = time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	domainMenu, err := rh.restaurantService.FindMenuAt(ctx, restaurantId, at)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, GetMenuResponse{Menu: rh.mapper.fromDomainMenu(domainMenu)})
+}
+
+// GetMenuVersions gets the versions of the menu of a restaurant, newest first.
+func (rh *RestaurantHandler) GetMenuVersions(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	offset, limit := getOffsetAndLimit(ctx)
+
+	domainMenus, total, err := rh.restaurantService.FindMenuVersions(ctx, restaurantId, offset, limit)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, GetMenuVersionsResponse{Versions: rh.mapper.fromDomainMenus(domainMenus), Total: total})
+}
+
+// GetAuditEntries gets the audit entries of the mutations of a restaurant, newest first.
+func (rh *RestaurantHandler) GetAuditEntries(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	offset, limit := getOffsetAndLimit(ctx)
+
+	domainEntries, total, err := rh.restaurantService.FindAuditEntries(ctx, restaurantId, offset, limit)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, GetAuditEntriesResponse{Entries: rh.mapper.fromDomainAuditEntries(domainEntries), Total: total})
+}
+
+// UpdateRestaurant renames and/or relocates a restaurant.
+func (rh *RestaurantHandler) UpdateRestaurant(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	var request UpdateRestaurantRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recorder := withVersionRecorder(ctx)
+	err = rh.restaurantService.UpdateRestaurant(ctx, restaurantId, rh.mapper.toDomainRestaurantChanges(&request))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	setEtag(ctx, recorder)
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// UpdateMenu updates the menu of a restaurant, or schedules it to replace the
+// current one if an effective instant is provided.
 func (rh *RestaurantHandler) UpdateMenu(ctx *gin.Context) {
 	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
 	if err != nil {
@@ -99,11 +314,212 @@ func (rh *RestaurantHandler) UpdateMenu(ctx *gin.Context) {
 		return
 	}
 
+	if request.EffectiveFrom != nil {
+		scheduledMenuId, err := rh.menuScheduleService.Schedule(ctx, restaurantId, rh.mapper.toDomainMenu(request.Menu), *request.EffectiveFrom)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusAccepted, ScheduleMenuResponse{ScheduledMenuId: scheduledMenuId})
+		return
+	}
+
+	recorder := withVersionRecorder(ctx)
 	err = rh.restaurantService.UpdateMenu(ctx, restaurantId, rh.mapper.toDomainMenu(request.Menu))
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
+	setEtag(ctx, recorder)
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// UpdateDayparts replaces the dayparts of a restaurant.
+func (rh *RestaurantHandler) UpdateDayparts(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	var request UpdateDaypartsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	domainDayparts, err := rh.mapper.toDomainDayparts(request.Dayparts)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recorder := withVersionRecorder(ctx)
+	err = rh.restaurantService.UpdateDayparts(ctx, restaurantId, domainDayparts)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	setEtag(ctx, recorder)
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// GetScheduledMenus gets the pending scheduled menus of a restaurant.
+func (rh *RestaurantHandler) GetScheduledMenus(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+
+	domainScheduledMenus, err := rh.menuScheduleService.FindByRestaurant(ctx, restaurantId)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, GetScheduledMenusResponse{ScheduledMenus: rh.mapper.fromDomainScheduledMenus(domainScheduledMenus)})
+}
+
+// CancelScheduledMenu cancels a pending scheduled menu of a restaurant.
+func (rh *RestaurantHandler) CancelScheduledMenu(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	scheduledMenuId, err := strconv.ParseInt(ctx.Param("scheduledMenuId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+
+	err = rh.menuScheduleService.Cancel(ctx, restaurantId, scheduledMenuId)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// SetItemAvailability marks a single menu item of a restaurant as available or not.
+func (rh *RestaurantHandler) SetItemAvailability(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	menuItemId, err := strconv.ParseInt(ctx.Param("menuItemId"), 10, 16)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	var request SetItemAvailabilityRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recorder := withVersionRecorder(ctx)
+	err = rh.restaurantService.SetItemAvailability(ctx, restaurantId, int16(menuItemId), *request.Available, request.AvailableUntil)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	setEtag(ctx, recorder)
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// AddMenuItem adds a single item to the menu of a restaurant.
+func (rh *RestaurantHandler) AddMenuItem(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	var request AddMenuItemRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recorder := withVersionRecorder(ctx)
+	err = rh.restaurantService.AddMenuItem(ctx, restaurantId, rh.mapper.toDomainMenuItem(request.MenuItem))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	setEtag(ctx, recorder)
+	ctx.JSON(http.StatusCreated, gin.H{})
+}
+
+// ValidateOrder prices an order against the menu of a restaurant, reporting the
+// lines that cannot be ordered.
+func (rh *RestaurantHandler) ValidateOrder(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	var request QuoteRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := rh.restaurantService.ValidateOrder(ctx, restaurantId, rh.mapper.toDomainOrderLines(request.Lines))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rh.mapper.fromDomainQuote(quote))
+}
+
+// UpdateMenuItem applies partial changes to a single menu item of a restaurant.
+func (rh *RestaurantHandler) UpdateMenuItem(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	menuItemId, err := strconv.ParseInt(ctx.Param("menuItemId"), 10, 16)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	var request UpdateMenuItemRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recorder := withVersionRecorder(ctx)
+	err = rh.restaurantService.UpdateMenuItem(ctx, restaurantId, int16(menuItemId), rh.mapper.toDomainMenuItemChanges(&request))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	setEtag(ctx, recorder)
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// RemoveMenuItem removes a single item from the menu of a restaurant.
+func (rh *RestaurantHandler) RemoveMenuItem(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	menuItemId, err := strconv.ParseInt(ctx.Param("menuItemId"), 10, 16)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+
+	recorder := withVersionRecorder(ctx)
+	err = rh.restaurantService.RemoveMenuItem(ctx, restaurantId, int16(menuItemId))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	setEtag(ctx, recorder)
 	ctx.JSON(http.StatusOK, gin.H{})
 }
 
@@ -111,9 +527,48 @@ func (rh *RestaurantHandler) UpdateMenu(ctx *gin.Context) {
 func handleError(ctx *gin.Context, err error) {
 	switch e := err.(type) {
 
+	case *coreerrors.ForbiddenError:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": e.Error()})
+
 	case *coreerrors.RestaurantNotFoundError:
 		ctx.AbortWithStatus(http.StatusNotFound)
 
+	case *coreerrors.MenuItemNotFoundError:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": e.Error()})
+
+	case *coreerrors.MenuItemAlreadyExistsError:
+		ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": e.Error()})
+
+	case *coreerrors.MenuVersionNotFoundError:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": e.Error()})
+
+	case *coreerrors.ScheduledMenuNotFoundError:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": e.Error()})
+
+	case *coreerrors.TicketNotFoundError:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": e.Error()})
+
+	case *coreerrors.InvalidTicketStateError:
+		ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": e.Error()})
+
+	case *coreerrors.IdempotencyKeyReusedError:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": e.Error()})
+
+	case *coreerrors.IdempotencyKeyConflictError:
+		ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": e.Error()})
+
+	case *coreerrors.ConcurrencyConflictError:
+		// The precondition of a conditional request failed, or otherwise the restaurant
+		// changed while the request was running.
+		status := http.StatusConflict
+		if ctx.GetHeader(ifMatchHeader) != "" {
+			status = http.StatusPreconditionFailed
+		}
+		ctx.AbortWithStatusJSON(status, gin.H{"error": e.Error()})
+
+	case *coreerrors.CoreError:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": e.Error()})
+
 	case *coreerrors.RepositoryError:
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": e.Error()})
 
@@ -146,3 +601,65 @@ func getOffsetAndLimit(ctx *gin.Context) (int, int) {
 
 	return int(offset), int(limit)
 }
+
+// getRestaurantQuery builds the criteria to search restaurants from the query params:
+// 'name' (prefix), 'city', 'state', 'zip', 'menuItem' (text contained in the name of
+// a menu item), 'minPrice' and 'maxPrice' (of a menu item), 'sort' (field),
+// 'direction' ('asc' or 'desc') and 'includeDeleted' (only allowed to admins).
+func getRestaurantQuery(ctx *gin.Context) (*domain.RestaurantQuery, error) {
+	query := &domain.RestaurantQuery{
+		NamePrefix:   ctx.Query("name"),
+		City:         ctx.Query("city"),
+		State:        ctx.Query("state"),
+		Zip:          ctx.Query("zip"),
+		MenuItemName: ctx.Query("menuItem"),
+	}
+	var err error
+	if query.MinPrice, err = parsePrice(ctx.Query("minPrice")); err != nil {
+		return nil, err
+	}
+	if query.MaxPrice, err = parsePrice(ctx.Query("maxPrice")); err != nil {
+		return nil, err
+	}
+	if sort := ctx.Query("sort"); sort != "" {
+		if query.SortBy, err = domain.ParseRestaurantSortField(sort); err != nil {
+			return nil, err
+		}
+	}
+	if direction := ctx.Query("direction"); direction != "" {
+		if query.SortDirection, err = domain.ParseSortDirection(direction); err != nil {
+			return nil, err
+		}
+	}
+	if includeDeleted := ctx.Query("includeDeleted"); includeDeleted != "" {
+		if query.IncludeDeleted, err = strconv.ParseBool(includeDeleted); err != nil {
+			return nil, fmt.Errorf("invalid includeDeleted: %s", includeDeleted)
+		}
+	}
+	return query, nil
+}
+
+// getPagination parses the pagination query params of the listings: the cursor and
+// the count mode, besides the offset and the limit.
+func getPagination(ctx *gin.Context, offset int, limit int) (*domain.Pagination, error) {
+	pagination := &domain.Pagination{Offset: offset, Limit: limit}
+	var err error
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		if pagination.Cursor, err = domain.DecodeCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+	if count := ctx.Query("count"); count != "" {
+		if pagination.Count, err = domain.ParseCountMode(count); err != nil {
+			return nil, err
+		}
+	}
+	return pagination, nil
+}
+
+// localize returns the restaurant with the texts of the available locale that
+// best matches the preferred ones, together with that locale.
+func localize(restaurant *domain.Restaurant, preferred []string) (*domain.Restaurant, string) {
+	locale := domain.NegotiateLocale(restaurant.Locales(), preferred)
+	return restaurant.Localize(locale), locale
+}
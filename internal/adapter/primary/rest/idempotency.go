@@ -0,0 +1,192 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers of the idempotent requests. The key is given by the client, while the
+// responses replayed from a previous execution of the request are flagged.
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotentReplayedMessage = "true"
+)
+
+// storedHeaders are the headers of the responses stored with the idempotency keys,
+// besides the content type, so that the replayed responses carry them too.
+var storedHeaders = []string{etagHeader, "Content-Language", "Location"}
+
+// storedResponse is the response of an idempotent request, as stored with its key.
+type storedResponse struct {
+	Status      int               `json:"status"`
+	ContentType string            `json:"contentType,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
+
+// failedRequestError rolls back the execution of an idempotent request whose handler
+// responded with an error, so that its key isn't stored and it can be retried.
+type failedRequestError struct{}
+
+func (failedRequestError) Error() string {
+	return "the request failed"
+}
+
+// IdempotencyMiddleware makes idempotent the mutating requests (POST, PUT, PATCH and
+// DELETE) carrying an Idempotency-Key header. The handler runs within the transaction
+// of the IdempotencyService and its successful response (with its relevant headers) is
+// stored with the key, so retries get the same response. A key reused with another
+// method, path or body is rejected, as well as the keys of the anonymous callers. It
+// requires gin's ContextWithFallback to be enabled, since the handlers join the
+// transaction through the gin context.
+func IdempotencyMiddleware(idempotencyService port.IdempotencyService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value := ctx.GetHeader(idempotencyKeyHeader)
+		if value == "" || !isMutating(ctx.Request.Method) {
+			ctx.Next()
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		key, err := domain.NewIdempotencyKey(value, requestHash(ctx.Request, body))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// The request context is given to the service instead of the gin context, since
+		// the latter falls back to the former once it carries the transaction.
+		writer := &bufferedResponseWriter{ResponseWriter: ctx.Writer, status: http.StatusOK, size: -1}
+		ctx.Writer = writer
+		response, replayed, err := idempotencyService.Execute(ctx.Request.Context(), key, func(txCtx context.Context) ([]byte, error) {
+			ctx.Request = ctx.Request.WithContext(txCtx)
+			ctx.Next()
+			if writer.status >= http.StatusBadRequest {
+				return nil, failedRequestError{}
+			}
+			return json.Marshal(writer.stored())
+		})
+		ctx.Writer = writer.ResponseWriter
+
+		switch {
+		case err == nil && replayed:
+			var stored storedResponse
+			if err := json.Unmarshal(response, &stored); err != nil {
+				handleError(ctx, err)
+				return
+			}
+			ctx.Header(idempotentReplayedHeader, idempotentReplayedMessage)
+			writeStoredResponse(ctx, &stored)
+			ctx.Abort()
+		case err == nil:
+			writeStoredResponse(ctx, writer.stored())
+		case errors.Is(err, failedRequestError{}):
+			writeStoredResponse(ctx, writer.stored())
+		default:
+			handleError(ctx, err)
+		}
+	}
+}
+
+// isMutating returns true if the requests of an HTTP method modify resources.
+func isMutating(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+}
+
+// requestHash returns the hash of the method, path (with the query) and body of a
+// request.
+func requestHash(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeStoredResponse writes a stored response to the client.
+func writeStoredResponse(ctx *gin.Context, stored *storedResponse) {
+	if stored.ContentType != "" {
+		ctx.Header("Content-Type", stored.ContentType)
+	}
+	for name, value := range stored.Headers {
+		ctx.Header(name, value)
+	}
+	ctx.Status(stored.Status)
+	ctx.Writer.WriteHeaderNow()
+	_, _ = ctx.Writer.Write(stored.Body)
+}
+
+// bufferedResponseWriter holds the response written by a handler, so that it's only
+// sent to the client once the idempotent request is committed. The headers are set
+// on the underlying writer.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	status int
+	size   int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if code > 0 && w.size < 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	if w.size < 0 {
+		w.size = 0
+	}
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.body.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	return w.size
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.size >= 0
+}
+
+// stored returns the response written by the handler.
+func (w *bufferedResponseWriter) stored() *storedResponse {
+	var headers map[string]string
+	for _, name := range storedHeaders {
+		if value := w.Header().Get(name); value != "" {
+			if headers == nil {
+				headers = make(map[string]string)
+			}
+			headers[name] = value
+		}
+	}
+	return &storedResponse{Status: w.status, ContentType: w.Header().Get("Content-Type"), Headers: headers, Body: w.body.Bytes()}
+}
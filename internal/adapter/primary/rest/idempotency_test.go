@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"f4allgo-restaurant/internal/core/domain"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotencyService is an in-memory IdempotencyService that also checks that
+// the operations join its transaction.
+type memoryIdempotencyService struct {
+	records map[string]*domain.IdempotencyRecord
+}
+
+type transactionKey struct{}
+
+func (ms *memoryIdempotencyService) Execute(ctx context.Context, key *domain.IdempotencyKey, operation func(ctx context.Context) ([]byte, error)) ([]byte, bool, error) {
+	if record, ok := ms.records[key.GetKey()]; ok {
+		if record.GetKey().GetRequestHash() != key.GetRequestHash() {
+			return nil, false, coreerrors.NewIdempotencyKeyReusedError()
+		}
+		return record.GetResponse(), true, nil
+	}
+	response, err := operation(context.WithValue(ctx, transactionKey{}, true))
+	if err != nil {
+		return nil, false, err
+	}
+	ms.records[key.GetKey()] = domain.NewIdempotencyRecord("", key, response, time.Now())
+	return response, false, nil
+}
+
+func (ms *memoryIdempotencyService) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	type request struct {
+		method string
+		body   string
+		key    string
+	}
+	testcases := []struct {
+		name           string
+		requests       []request
+		failing        bool
+		wantStatuses   []int
+		wantBodies     []string
+		wantETags      []string
+		wantReplayed   []bool
+		wantExecutions int
+	}{
+		{
+			name:           "execute every request without key",
+			requests:       []request{{method: http.MethodPost, body: "{}"}, {method: http.MethodPost, body: "{}"}},
+			wantStatuses:   []int{http.StatusCreated, http.StatusCreated},
+			wantBodies:     []string{`{"execution":1}`, `{"execution":2}`},
+			wantETags:      []string{`"1"`, `"2"`},
+			wantReplayed:   []bool{false, false},
+			wantExecutions: 2,
+		},
+		{
+			name:           "replay a request retried with the same key",
+			requests:       []request{{method: http.MethodPost, body: "{}", key: "key1"}, {method: http.MethodPost, body: "{}", key: "key1"}},
+			wantStatuses:   []int{http.StatusCreated, http.StatusCreated},
+			wantBodies:     []string{`{"execution":1}`, `{"execution":1}`},
+			wantETags:      []string{`"1"`, `"1"`},
+			wantReplayed:   []bool{false, true},
+			wantExecutions: 1,
+		},
+		{
+			name:           "reject a key reused with another body",
+			requests:       []request{{method: http.MethodPost, body: "{}", key: "key1"}, {method: http.MethodPost, body: `{"name":"other"}`, key: "key1"}},
+			wantStatuses:   []int{http.StatusCreated, http.StatusUnprocessableEntity},
+			wantBodies:     []string{`{"execution":1}`, `{"error":"the idempotency key was already used with a different request"}`},
+			wantETags:      []string{`"1"`, ""},
+			wantReplayed:   []bool{false, false},
+			wantExecutions: 1,
+		},
+		{
+			name:           "execute again a failed request",
+			requests:       []request{{method: http.MethodPost, body: "{}", key: "key1"}, {method: http.MethodPost, body: "{}", key: "key1"}},
+			failing:        true,
+			wantStatuses:   []int{http.StatusUnprocessableEntity, http.StatusUnprocessableEntity},
+			wantBodies:     []string{`{"error":"execution 1 failed"}`, `{"error":"execution 2 failed"}`},
+			wantETags:      []string{"", ""},
+			wantReplayed:   []bool{false, false},
+			wantExecutions: 2,
+		},
+		{
+			name:           "ignore the key of a request not modifying resources",
+			requests:       []request{{method: http.MethodGet, key: "key1"}, {method: http.MethodGet, key: "key1"}},
+			wantStatuses:   []int{http.StatusCreated, http.StatusCreated},
+			wantBodies:     []string{`{"execution":1}`, `{"execution":2}`},
+			wantETags:      []string{`"1"`, `"2"`},
+			wantReplayed:   []bool{false, false},
+			wantExecutions: 2,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			executions := 0
+			handler := func(ctx *gin.Context) {
+				executions++
+				if ctx.GetHeader(idempotencyKeyHeader) != "" && isMutating(ctx.Request.Method) {
+					assert.Equal(t, true, ctx.Value(transactionKey{}))
+				}
+				if tc.failing {
+					ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("execution %d failed", executions)})
+					return
+				}
+				ctx.Header(etagHeader, fmt.Sprintf(`"%d"`, executions))
+				ctx.JSON(http.StatusCreated, gin.H{"execution": executions})
+			}
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(IdempotencyMiddleware(&memoryIdempotencyService{records: map[string]*domain.IdempotencyRecord{}}))
+			router.POST("/restaurants", handler)
+			router.GET("/restaurants", handler)
+
+			for i, r := range tc.requests {
+				req := httptest.NewRequest(r.method, "/restaurants", strings.NewReader(r.body))
+				if r.key != "" {
+					req.Header.Set(idempotencyKeyHeader, r.key)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.wantStatuses[i], w.Code)
+				assert.JSONEq(t, tc.wantBodies[i], w.Body.String())
+				assert.Equal(t, tc.wantETags[i], w.Header().Get(etagHeader))
+				assert.Equal(t, tc.wantReplayed[i], w.Header().Get(idempotentReplayedHeader) == idempotentReplayedMessage)
+			}
+			assert.Equal(t, tc.wantExecutions, executions)
+		})
+	}
+}
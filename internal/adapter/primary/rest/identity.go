@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"f4allgo-restaurant/internal/adapter/primary/auth"
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers carrying the identity of the caller. They are set by the API gateway
+// once the caller has been authenticated, so they must not be accepted from
+// untrusted clients.
+const (
+	userIdHeader   = "X-User-Id"
+	tenantIdHeader = "X-Tenant-Id"
+	rolesHeader    = "X-Roles"
+)
+
+// IdentityMiddleware propagates the identity of the caller into the context of
+// the request, so that the core can authorize the operations. Requests without a
+// user id are anonymous. It must only be used behind an API gateway trusted to set
+// the headers. It requires gin's ContextWithFallback to be enabled, since the
+// handlers pass the gin context to the core.
+func IdentityMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if userId := ctx.GetHeader(userIdHeader); userId != "" {
+			identity := domain.NewIdentity(userId, ctx.GetHeader(tenantIdHeader), parseRoles(ctx.GetHeader(rolesHeader)))
+			ctx.Request = ctx.Request.WithContext(domain.ContextWithIdentity(ctx.Request.Context(), identity))
+		}
+		ctx.Next()
+	}
+}
+
+// AuthMiddleware authenticates the callers with the bearer token of the request,
+// propagating its claims and the identity of the caller into the context of the
+// request. Requests to the exempted paths are served without authentication, and
+// any other request without a valid token is rejected. It replaces the identity
+// middleware, since the identity headers are not trusted once the callers are
+// authenticated by the service itself.
+func AuthMiddleware(verifier *auth.Verifier, exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+	return func(ctx *gin.Context) {
+		if exempt[ctx.Request.URL.Path] {
+			ctx.Next()
+			return
+		}
+		token, err := auth.BearerToken(ctx.GetHeader("Authorization"))
+		if err == nil {
+			var authCtx context.Context
+			if authCtx, err = verifier.Authenticate(ctx.Request.Context(), token); err == nil {
+				ctx.Request = ctx.Request.WithContext(authCtx)
+				ctx.Next()
+				return
+			}
+		}
+		ctx.Header("WWW-Authenticate", "Bearer")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"f4allgo-restaurant/internal/adapter/primary/auth"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/test"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityMiddleware(t *testing.T) {
+	testcases := []struct {
+		name         string
+		headers      map[string]string
+		wantIdentity *domain.Identity
+	}{
+		{
+			name:         "propagate the identity of a tenant user",
+			headers:      map[string]string{"X-User-Id": "user1", "X-Tenant-Id": "tenant1"},
+			wantIdentity: domain.NewIdentity("user1", "tenant1", []domain.Role{}),
+		},
+		{
+			name:         "propagate the identity of an admin",
+			headers:      map[string]string{"X-User-Id": "admin1", "X-Roles": "admin, operator"},
+			wantIdentity: domain.NewIdentity("admin1", "", []domain.Role{domain.RoleAdmin, "operator"}),
+		},
+		{
+			name:         "leave anonymous a request without user id",
+			headers:      map[string]string{"X-Tenant-Id": "tenant1"},
+			wantIdentity: nil,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var identity *domain.Identity
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(IdentityMiddleware())
+			router.GET("/", func(ctx *gin.Context) {
+				identity = domain.IdentityFromContext(ctx)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tc.wantIdentity, identity)
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	keySet, err := auth.NewFileKeySet(test.JwksFile())
+	assert.NoError(t, err)
+	verifier := auth.NewVerifier(keySet, auth.Options{TenantClaim: "tenant_id", RolesClaim: "roles"})
+	token, err := test.SignToken(test.RsaKeyId, jwt.MapClaims{"sub": "user1", "tenant_id": "tenant1", "exp": time.Now().Add(time.Hour).Unix()})
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		name         string
+		path         string
+		headers      map[string]string
+		wantStatus   int
+		wantIdentity *domain.Identity
+	}{
+		{
+			name:         "authenticate a request with a valid token",
+			path:         "/api",
+			headers:      map[string]string{"Authorization": "Bearer " + token},
+			wantStatus:   http.StatusOK,
+			wantIdentity: domain.NewIdentity("user1", "tenant1", []domain.Role{}),
+		},
+		{
+			name:       "reject a request without token",
+			path:       "/api",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "reject a request with an invalid token",
+			path:       "/api",
+			headers:    map[string]string{"Authorization": "Bearer invalid"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "ignore the identity headers",
+			path:       "/api",
+			headers:    map[string]string{"X-User-Id": "user1", "X-Tenant-Id": "tenant1"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "serve an exempted path without token",
+			path:       "/health",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var identity *domain.Identity
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(AuthMiddleware(verifier, "/health"))
+			router.GET("/health", func(*gin.Context) {})
+			router.GET("/api", func(ctx *gin.Context) {
+				identity = domain.IdentityFromContext(ctx)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.Equal(t, tc.wantIdentity, identity)
+		})
+	}
+}
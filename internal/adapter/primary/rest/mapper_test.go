@@ -1,9 +1,17 @@
 package rest
 
 import (
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,12 +33,231 @@ func TestToAndFromDomainRestaurants(t *testing.T) {
 	assert.True(t, reflect.DeepEqual(restRestaurants, backToRest))
 }
 
+func TestFromDomainSearchResults(t *testing.T) {
+	restRestaurant := newRestaurant()
+	domainResults := []*domain.RestaurantSearchResult{domain.NewRestaurantSearchResult(mapper.toDomainRestaurant(restRestaurant), 0.5, "<mark>ramen</mark>")}
+	restResults := mapper.fromDomainSearchResults(domainResults)
+
+	assert.True(t, reflect.DeepEqual([]*SearchResult{{Restaurant: restRestaurant, Rank: 0.5, Highlight: "<mark>ramen</mark>"}}, restResults))
+}
+
+func TestFromDomainCreationResults(t *testing.T) {
+	domainResults := []*domain.CreationResult{domain.NewCreationResult(1000, nil), domain.NewCreationResult(2000, errors.New("error"))}
+	restResults := mapper.fromDomainCreationResults(domainResults)
+
+	assert.Equal(t, []*CreationResult{{Index: 0, RestaurantId: 1000}, {Index: 1, Error: "error"}}, restResults)
+}
+
+func TestToDomainMenuItemChanges(t *testing.T) {
+	name := "aName"
+	changes := mapper.toDomainMenuItemChanges(&UpdateMenuItemRequest{Name: &name, Allergens: []string{}})
+
+	assert.Equal(t, &name, changes.Name)
+	assert.Nil(t, changes.Price)
+	assert.NotNil(t, changes.Allergens)
+	assert.Empty(t, *changes.Allergens)
+	assert.Nil(t, changes.DietaryTags)
+}
+
+func TestToAndFromDomainDayparts(t *testing.T) {
+	restDayparts := []*Daypart{
+		{Name: "breakfast", Windows: []TimeWindow{{Start: "07:00", End: "11:30"}}, Menu: newMenu()},
+		{Name: "dinner", Windows: []TimeWindow{{Start: "20:00", End: "24:00"}, {Start: "00:00", End: "01:00"}}, Menu: newMenu()},
+	}
+	domainDayparts, err := mapper.toDomainDayparts(restDayparts)
+	assert.NoError(t, err)
+	assert.Equal(t, 420, domainDayparts[0].GetWindows()[0].GetStart())
+	assert.Equal(t, 690, domainDayparts[0].GetWindows()[0].GetEnd())
+	assert.Equal(t, 1440, domainDayparts[1].GetWindows()[0].GetEnd())
+	backToRest := mapper.fromDomainDayparts(domainDayparts)
+
+	assert.True(t, reflect.DeepEqual(restDayparts, backToRest))
+
+	_, err = mapper.toDomainDayparts([]*Daypart{{Name: "late", Windows: []TimeWindow{{Start: "7pm", End: "24:00"}}, Menu: newMenu()}})
+	assert.Error(t, err)
+}
+
+func TestLocalize(t *testing.T) {
+	domainRestaurant := mapper.toDomainRestaurant(newRestaurant())
+
+	localized, locale := localize(domainRestaurant, parseAcceptLanguage("en-US,en;q=0.9,es;q=0.5"))
+	assert.Equal(t, "en-GB", locale)
+	assert.Equal(t, "aDescription", localized.Description)
+	assert.Equal(t, "TranslatedName1", localized.Menu.GetItems()[0].GetName())
+	assert.Equal(t, "Descripción1", localized.Menu.GetItems()[0].GetDescription())
+	assert.Equal(t, "Name2", localized.Menu.GetItems()[1].GetName())
+
+	localized, locale = localize(domainRestaurant, parseAcceptLanguage(""))
+	assert.Equal(t, "es-ES", locale)
+	assert.Equal(t, "aDescripción", localized.Description)
+	assert.Equal(t, "Name1", localized.Menu.GetItems()[0].GetName())
+}
+
+func TestParseNear(t *testing.T) {
+	tests := []struct {
+		name    string
+		near    string
+		radius  string
+		wantErr bool
+	}{
+		{"valid", "40.4168,-3.7038", "5000", false},
+		{"valid with spaces", "40.4168, -3.7038", "50000", false},
+		{"missing longitude", "40.4168", "5000", true},
+		{"invalid latitude", "abc,-3.7038", "5000", true},
+		{"latitude out of range", "91,-3.7038", "5000", true},
+		{"longitude out of range", "40.4168,-181", "5000", true},
+		{"zero radius", "40.4168,-3.7038", "0", true},
+		{"radius too big", "40.4168,-3.7038", "50001", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			point, radius, err := parseNear(tt.near, tt.radius)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 40.4168, point.Latitude())
+			assert.Equal(t, -3.7038, point.Longitude())
+			assert.Greater(t, radius, 0.0)
+		})
+	}
+}
+
+func TestGetRestaurantQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    string
+		wantQuery *domain.RestaurantQuery
+		wantErr   bool
+	}{
+		{"no params", "", &domain.RestaurantQuery{}, false},
+		{
+			"all params",
+			"name=Bur&city=Madrid&state=Madrid&zip=28013&menuItem=burger&minPrice=5&maxPrice=10.5&sort=name&direction=desc&includeDeleted=true",
+			&domain.RestaurantQuery{
+				NamePrefix: "Bur", City: "Madrid", State: "Madrid", Zip: "28013", MenuItemName: "burger",
+				MinPrice: big.NewFloat(5), MaxPrice: big.NewFloat(10.5), SortBy: domain.RestaurantSortByName, SortDirection: domain.SortDescending,
+				IncludeDeleted: true,
+			},
+			false,
+		},
+		{"invalid price", "minPrice=cheap", nil, true},
+		{"invalid sort field", "sort=rating", nil, true},
+		{"invalid sort direction", "direction=up", nil, true},
+		{"invalid include deleted", "includeDeleted=maybe", nil, true},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/restaurants?"+tt.params, nil)
+			query, err := getRestaurantQuery(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantQuery.NamePrefix, query.NamePrefix)
+			assert.Equal(t, tt.wantQuery.City, query.City)
+			assert.Equal(t, tt.wantQuery.State, query.State)
+			assert.Equal(t, tt.wantQuery.Zip, query.Zip)
+			assert.Equal(t, tt.wantQuery.MenuItemName, query.MenuItemName)
+			assert.Equal(t, tt.wantQuery.SortBy, query.SortBy)
+			assert.Equal(t, tt.wantQuery.SortDirection, query.SortDirection)
+			assert.Equal(t, tt.wantQuery.IncludeDeleted, query.IncludeDeleted)
+			if tt.wantQuery.MinPrice != nil {
+				assert.Zero(t, tt.wantQuery.MinPrice.Cmp(query.MinPrice))
+				assert.Zero(t, tt.wantQuery.MaxPrice.Cmp(query.MaxPrice))
+			} else {
+				assert.Nil(t, query.MinPrice)
+				assert.Nil(t, query.MaxPrice)
+			}
+		})
+	}
+}
+
+func TestGetPagination(t *testing.T) {
+	query := &domain.RestaurantQuery{}
+	_ = query.Validate()
+	restaurant := mapper.toDomainRestaurant(newRestaurant())
+	cursor := domain.NewCursorAt(query, restaurant, true)
+	tests := []struct {
+		name           string
+		params         string
+		wantPagination *domain.Pagination
+		wantErr        bool
+	}{
+		{"no params", "", &domain.Pagination{Offset: 5, Limit: 10}, false},
+		{"all params", "cursor=" + cursor.Encode() + "&count=none", &domain.Pagination{Offset: 5, Limit: 10, Cursor: cursor, Count: domain.CountNone}, false},
+		{"malformed cursor", "cursor=not-a-cursor", nil, true},
+		{"invalid count mode", "count=approximate", nil, true},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/restaurants?"+tt.params, nil)
+			pagination, err := getPagination(ctx, 5, 10)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPagination, pagination)
+		})
+	}
+}
+
+func TestFromDomainRestaurantPage(t *testing.T) {
+	query := &domain.RestaurantQuery{SortBy: domain.RestaurantSortByName}
+	_ = query.Validate()
+	restaurant := mapper.toDomainRestaurant(newRestaurant())
+	total := int64(3)
+	page := &domain.RestaurantPage{Restaurants: []*domain.Restaurant{restaurant}, Total: &total, NextCursor: domain.NewCursorAt(query, restaurant, false)}
+
+	response := mapper.fromDomainRestaurantPage(page)
+	assert.Len(t, response.Restaurants, 1)
+	assert.Equal(t, &total, response.Total)
+	assert.Empty(t, response.PrevCursor)
+	cursor, err := domain.DecodeCursor(response.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, page.NextCursor, cursor)
+	assert.NoError(t, (&domain.Pagination{Limit: 1, Cursor: cursor}).Validate(query))
+}
+
+func TestFromDomainQuote(t *testing.T) {
+	domainRestaurant := mapper.toDomainRestaurant(newRestaurant())
+	lines := []OrderLine{{MenuItemId: 1, Quantity: 3}, {MenuItemId: 9, Quantity: 1}}
+	quote, err := domainRestaurant.Quote(mapper.toDomainOrderLines(lines), time.Now())
+	assert.NoError(t, err)
+
+	response := mapper.fromDomainQuote(quote)
+	assert.False(t, response.Valid)
+	assert.Equal(t, "38.97", response.Total)
+	assert.Equal(t, "Name1", response.Lines[0].Name)
+	assert.Equal(t, "12.99", *response.Lines[0].UnitPrice)
+	assert.Equal(t, "38.97", *response.Lines[0].LineTotal)
+	assert.Nil(t, response.Lines[0].RejectionReason)
+	assert.Nil(t, response.Lines[1].UnitPrice)
+	assert.Equal(t, "menu-item-not-found", *response.Lines[1].RejectionReason)
+}
+
 // --------------------------------------------------------------------------------
 // Utility functions to create restaurants and menus.
 // --------------------------------------------------------------------------------
 
 func newRestaurant() *Restaurant {
-	return &Restaurant{Name: "aName", Address: newAddress(), Menu: newMenu()}
+	return &Restaurant{
+		TenantId:                "aTenant",
+		Name:                    "aName",
+		DefaultLocale:           "es-ES",
+		TimeZone:                "Europe/Madrid",
+		Description:             "aDescripción",
+		DescriptionTranslations: map[string]string{"en-GB": "aDescription"},
+		Address:                 newAddress(),
+		Menu:                    newMenu(),
+	}
 }
 
 func newAddress() *Address {
@@ -38,7 +265,9 @@ func newAddress() *Address {
 }
 
 func newMenu() *Menu {
-	item1 := MenuItem{Id: 1, Name: "Name1", Price: "12.99"}
-	item2 := MenuItem{Id: 2, Name: "Name2", Price: "4.55"}
+	available := true
+	item1 := MenuItem{Id: 1, Name: "Name1", Description: "Descripción1", Translations: map[string]Translation{"en-GB": {Name: "TranslatedName1"}},
+		Price: "12.99", Available: &available, Allergens: []string{"gluten", "milk"}, DietaryTags: []string{"vegetarian"}}
+	item2 := MenuItem{Id: 2, Name: "Name2", Price: "4.55", Available: &available}
 	return &Menu{Items: []MenuItem{item1, item2}}
 }
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers of the conditional requests. The version of a restaurant is returned as its
+// entity tag, which the clients send back to modify it only if it hasn't changed. The
+// entity tags of the representations of a restaurant (e.g. in another locale) differ,
+// but they all carry its version.
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// PreconditionMiddleware propagates the version of the restaurant expected by the
+// caller, given by the If-Match header, into the context of the request, so that the
+// core rejects the changes to a restaurant modified since the caller read it. The "*"
+// wildcard matches any version, while the entity tags not issued by the service never
+// match, failing the precondition. The header is ignored on the safe methods, which
+// don't change the restaurants. It requires gin's ContextWithFallback to be enabled,
+// since the handlers pass the gin context to the core.
+func PreconditionMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value := strings.TrimSpace(ctx.GetHeader(ifMatchHeader))
+		if value == "" || value == "*" || isSafeMethod(ctx.Request.Method) {
+			ctx.Next()
+			return
+		}
+		version, ok := parseEtag(value)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "the entity tag doesn't match the current version"})
+			return
+		}
+		ctx.Request = ctx.Request.WithContext(domain.ContextWithExpectedVersion(ctx.Request.Context(), version))
+		ctx.Next()
+	}
+}
+
+// isSafeMethod tells if an HTTP method is safe (read only).
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// etag returns the (strong) entity tag of a version of a restaurant.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// representationEtag returns the (strong) entity tag of a representation of a version
+// of a restaurant, which is the version followed by the hash of the values the
+// representation depends on.
+func representationEtag(version int64, values ...string) string {
+	hash := fnv.New32a()
+	for _, value := range values {
+		hash.Write([]byte(value + "\n"))
+	}
+	return `"` + strconv.FormatInt(version, 10) + "-" + strconv.FormatUint(uint64(hash.Sum32()), 16) + `"`
+}
+
+// parseEtag returns the version of a restaurant from its entity tag (of any of its
+// representations), or false if it's not an entity tag issued by the service.
+func parseEtag(value string) (int64, bool) {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, false
+	}
+	tag, _, _ := strings.Cut(value[1:len(value)-1], "-")
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// withVersionRecorder makes the core record the version a restaurant is left at by
+// the change requested, so that it can be returned as the entity tag of the response.
+func withVersionRecorder(ctx *gin.Context) *domain.VersionRecorder {
+	requestCtx, recorder := domain.ContextWithVersionRecorder(ctx.Request.Context())
+	ctx.Request = ctx.Request.WithContext(requestCtx)
+	return recorder
+}
+
+// setEtag sets the entity tag of the version recorded by the core, if any.
+func setEtag(ctx *gin.Context, recorder *domain.VersionRecorder) {
+	if version := recorder.GetVersion(); version != nil {
+		ctx.Header(etagHeader, etag(*version))
+	}
+}
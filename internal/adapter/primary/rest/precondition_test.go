@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreconditionMiddleware(t *testing.T) {
+	version := func(v int64) *int64 { return &v }
+	testcases := []struct {
+		name        string
+		method      string
+		ifMatch     string
+		wantStatus  int
+		wantVersion *int64
+	}{
+		{name: "expect no version without precondition", method: http.MethodPatch, ifMatch: "", wantStatus: http.StatusOK, wantVersion: nil},
+		{name: "expect no version with the wildcard", method: http.MethodPatch, ifMatch: "*", wantStatus: http.StatusOK, wantVersion: nil},
+		{name: "propagate the version of an entity tag", method: http.MethodPatch, ifMatch: `"3"`, wantStatus: http.StatusOK, wantVersion: version(3)},
+		{name: "propagate the version of the entity tag of a representation", method: http.MethodPatch, ifMatch: representationEtag(3, "es"), wantStatus: http.StatusOK, wantVersion: version(3)},
+		{name: "propagate the version of an entity tag of a deletion", method: http.MethodDelete, ifMatch: `"3"`, wantStatus: http.StatusOK, wantVersion: version(3)},
+		{name: "fail the precondition of a weak entity tag", method: http.MethodPatch, ifMatch: `W/"3"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "fail the precondition of an unknown entity tag", method: http.MethodPost, ifMatch: `"abc"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "ignore the precondition of a read", method: http.MethodGet, ifMatch: `"3"`, wantStatus: http.StatusOK, wantVersion: nil},
+		{name: "ignore an unknown entity tag of a read", method: http.MethodGet, ifMatch: `W/"3"`, wantStatus: http.StatusOK, wantVersion: nil},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var expected *int64
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(PreconditionMiddleware())
+			router.Handle(tc.method, "/", func(ctx *gin.Context) {
+				expected = domain.ExpectedVersionFromContext(ctx)
+			})
+
+			req := httptest.NewRequest(tc.method, "/", nil)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.Equal(t, tc.wantVersion, expected)
+		})
+	}
+}
+
+func TestRepresentationEtag(t *testing.T) {
+	assert.Equal(t, representationEtag(3, "en", "", ""), representationEtag(3, "en", "", ""))
+	assert.NotEqual(t, representationEtag(3, "en", "", ""), representationEtag(3, "es", "", ""))
+	assert.NotEqual(t, representationEtag(3, "en", "", ""), representationEtag(3, "en", "2024-01-01T08:30:00Z", ""))
+	assert.NotEqual(t, representationEtag(3, "en", "", ""), representationEtag(3, "en", "", "NUTS"))
+	assert.NotEqual(t, representationEtag(3, "en", "", ""), representationEtag(4, "en", "", ""))
+}
+
+func TestSetEtag(t *testing.T) {
+	testcases := []struct {
+		name     string
+		version  *int64
+		wantEtag string
+	}{
+		{name: "set the entity tag of the recorded version", version: func(v int64) *int64 { return &v }(4), wantEtag: `"4"`},
+		{name: "set no entity tag without a recorded version", version: nil, wantEtag: ""},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.PATCH("/", func(ctx *gin.Context) {
+				recorder := withVersionRecorder(ctx)
+				if tc.version != nil {
+					domain.RecordVersion(ctx, *tc.version)
+				}
+				setEtag(ctx, recorder)
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/", nil))
+
+			assert.Equal(t, tc.wantEtag, w.Header().Get(etagHeader))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package rest
+
+import (
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Header carrying the identifier of the request, so that its traces (e.g. the
+// audit log) can be correlated with the ones of the caller.
+const requestIdHeader = "X-Request-Id"
+
+// Maximum length of the request identifiers given by the callers.
+const maxRequestIdLength = 255
+
+// RequestMetadataMiddleware propagates the metadata of the request into the context
+// of the request, so that the core can record it. The identifier of the request is
+// taken from the X-Request-Id header, or generated if the caller didn't send a valid
+// one, and it's echoed in the response. It requires gin's ContextWithFallback to be
+// enabled, since the handlers pass the gin context to the core.
+func RequestMetadataMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestId := ctx.GetHeader(requestIdHeader)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = uuid.NewString()
+		}
+		ctx.Header(requestIdHeader, requestId)
+		metadata := domain.NewRequestMetadata(requestId, domain.SourceRest)
+		ctx.Request = ctx.Request.WithContext(domain.ContextWithRequestMetadata(ctx.Request.Context(), metadata))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMetadataMiddleware(t *testing.T) {
+	testcases := []struct {
+		name          string
+		requestId     string
+		wantRequestId string
+	}{
+		{name: "propagate the request id of the caller", requestId: "request1", wantRequestId: "request1"},
+		{name: "generate a request id when the caller doesn't send one", requestId: ""},
+		{name: "generate a request id when the one of the caller is too long", requestId: strings.Repeat("a", 256)},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var metadata *domain.RequestMetadata
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(RequestMetadataMiddleware())
+			router.GET("/", func(ctx *gin.Context) {
+				metadata = domain.RequestMetadataFromContext(ctx)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.requestId != "" {
+				req.Header.Set("X-Request-Id", tc.requestId)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, domain.SourceRest, metadata.GetSource())
+			if tc.wantRequestId != "" {
+				assert.Equal(t, tc.wantRequestId, metadata.GetRequestId())
+			} else {
+				assert.NotEmpty(t, metadata.GetRequestId())
+				assert.NotEqual(t, tc.requestId, metadata.GetRequestId())
+			}
+			assert.Equal(t, metadata.GetRequestId(), w.Header().Get("X-Request-Id"))
+		})
+	}
+}
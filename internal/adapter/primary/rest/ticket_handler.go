@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketHandler struct {
+	mapper        Mapper
+	ticketService port.TicketService
+}
+
+// NewTicketHandler builds a new TicketHandler struct.
+func NewTicketHandler(s port.TicketService) *TicketHandler {
+	return &TicketHandler{mapper: DefaultMapper{}, ticketService: s}
+}
+
+// CreateTicket creates a kitchen ticket for an order in a restaurant.
+func (th *TicketHandler) CreateTicket(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+	var request CreateTicketRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticketId, err := th.ticketService.Create(ctx, restaurantId, request.OrderId, th.mapper.toDomainTicketLineItems(request.LineItems))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, CreateTicketResponse{TicketId: ticketId})
+}
+
+// GetTickets gets the tickets of a restaurant, optionally filtered by state.
+func (th *TicketHandler) GetTickets(ctx *gin.Context) {
+	restaurantId, err := strconv.ParseInt(ctx.Param("restaurantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+
+	var state *domain.TicketState
+	if value := ctx.Query("state"); value != "" {
+		parsed, err := domain.ParseTicketState(value)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		state = &parsed
+	}
+
+	offset, limit := getOffsetAndLimit(ctx)
+	domainTickets, total, err := th.ticketService.FindByRestaurant(ctx, restaurantId, state, offset, limit)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, GetTicketsResponse{Tickets: th.mapper.fromDomainTickets(domainTickets), Total: total})
+}
+
+// GetTicket gets a ticket by its ID.
+func (th *TicketHandler) GetTicket(ctx *gin.Context) {
+	ticketId, err := strconv.ParseInt(ctx.Param("ticketId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+
+	domainTicket, err := th.ticketService.FindById(ctx, ticketId)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, GetTicketResponse{Ticket: th.mapper.fromDomainTicket(domainTicket)})
+}
+
+// AcceptTicket accepts a ticket, committing to have it ready by a given instant.
+func (th *TicketHandler) AcceptTicket(ctx *gin.Context) {
+	var request AcceptTicketRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	th.changeState(ctx, func(c context.Context, ticketId int64) error {
+		return th.ticketService.Accept(c, ticketId, *request.ReadyBy)
+	})
+}
+
+// RejectTicket rejects a ticket.
+func (th *TicketHandler) RejectTicket(ctx *gin.Context) {
+	th.changeState(ctx, th.ticketService.Reject)
+}
+
+// StartPreparingTicket marks a ticket as being prepared.
+func (th *TicketHandler) StartPreparingTicket(ctx *gin.Context) {
+	th.changeState(ctx, th.ticketService.StartPreparing)
+}
+
+// MarkTicketReadyForPickup marks a ticket as ready for pickup.
+func (th *TicketHandler) MarkTicketReadyForPickup(ctx *gin.Context) {
+	th.changeState(ctx, th.ticketService.MarkReadyForPickup)
+}
+
+// PickUpTicket marks a ticket as picked up.
+func (th *TicketHandler) PickUpTicket(ctx *gin.Context) {
+	th.changeState(ctx, th.ticketService.PickUp)
+}
+
+// CancelTicket cancels a ticket.
+func (th *TicketHandler) CancelTicket(ctx *gin.Context) {
+	th.changeState(ctx, th.ticketService.Cancel)
+}
+
+// changeState parses the ticket identifier and applies a transition of the ticket
+// lifecycle, translating the errors into the proper http error codes.
+func (th *TicketHandler) changeState(ctx *gin.Context, transition func(context.Context, int64) error) {
+	ticketId, err := strconv.ParseInt(ctx.Param("ticketId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{})
+		return
+	}
+
+	if err := transition(ctx, ticketId); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{})
+}
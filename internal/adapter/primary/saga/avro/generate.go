@@ -0,0 +1,3 @@
+package avro
+
+//go:generate go run github.com/actgardner/gogen-avro/v10/cmd/gogen-avro . *.avsc
@@ -0,0 +1,146 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"f4allgo-restaurant/internal/adapter/primary/saga/avro"
+	"f4allgo-restaurant/internal/boot"
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/confluentinc/confluent-kafka-go/schemaregistry"
+	"github.com/confluentinc/confluent-kafka-go/schemaregistry/serde"
+	avroserde "github.com/confluentinc/confluent-kafka-go/schemaregistry/serde/avro"
+	"github.com/rs/zerolog"
+)
+
+// Header of the saga command messages with the type of the command.
+const commandTypeHeader string = "command_type"
+
+// Identity of the caller of the saga commands, which are sent by the orchestrator
+// of the sagas on behalf of no particular tenant.
+var sagaIdentity = domain.NewIdentity("saga-orchestrator", "", nil)
+
+// Time to wait before retrying a command that could not be processed.
+const retryBackoff time.Duration = 5 * time.Second
+
+// CommandConsumer consumes the saga commands from a Kafka channel and hands them to
+// a CommandHandler. The offsets are committed once each command is processed, so
+// the commands are delivered at least once (the ticket use cases invoked by the
+// handler are idempotent).
+type CommandConsumer struct {
+	consumer     *kafka.Consumer
+	deserializer *avroserde.SpecificDeserializer
+	handler      *CommandHandler
+	channel      string
+	logger       zerolog.Logger
+}
+
+func NewCommandConsumer(handler *CommandHandler, logger zerolog.Logger, config *boot.Config) (*CommandConsumer, error) {
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  config.KafkaBootstrapServers,
+		"group.id":           config.KafkaConsumerGroup,
+		"auto.offset.reset":  "earliest",
+		"enable.auto.commit": false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating the kafka consumer: %w", err)
+	}
+
+	client, err := schemaregistry.NewClient(schemaregistry.NewConfig(config.KafkaSchemaRegistry))
+	if err != nil {
+		return nil, fmt.Errorf("creating the schema registry client: %w", err)
+	}
+
+	deserializer, err := avroserde.NewSpecificDeserializer(client, serde.ValueSerde, avroserde.NewDeserializerConfig())
+	if err != nil {
+		return nil, fmt.Errorf("creating the avro deserializer: %w", err)
+	}
+
+	return &CommandConsumer{consumer: consumer, deserializer: deserializer, handler: handler, channel: config.KafkaSagaCommandChannel, logger: logger}, nil
+}
+
+// InitCommandConsumer initializes a background process (inside a go routine) that
+// consumes the saga commands from the configured channel.
+func (c *CommandConsumer) InitCommandConsumer() error {
+	if err := c.consumer.SubscribeTopics([]string{c.channel}, nil); err != nil {
+		return fmt.Errorf("subscribing to the saga command channel: %w", err)
+	}
+	c.logger.Debug().Msgf("initializing the saga command consumer on channel %s", c.channel)
+	go c.execute()
+	return nil
+}
+
+func (c *CommandConsumer) execute() {
+	for {
+		msg, err := c.consumer.ReadMessage(time.Second)
+		if err != nil {
+			if kafkaErr, ok := err.(kafka.Error); !ok || kafkaErr.Code() != kafka.ErrTimedOut {
+				c.logger.Err(err).Msg("reading a saga command")
+			}
+			continue
+		}
+
+		if err := c.process(msg); err != nil {
+			c.logger.Err(err).Msg("processing a saga command, it will be retried")
+			// Rewinds the partition so the command is read again after a while.
+			if err := c.consumer.Seek(msg.TopicPartition, 0); err != nil {
+				c.logger.Err(err).Msg("rewinding the saga command channel")
+			}
+			time.Sleep(retryBackoff)
+			continue
+		}
+
+		if _, err := c.consumer.CommitMessage(msg); err != nil {
+			// The command will be redelivered, which is harmless.
+			c.logger.Err(err).Msg("committing the offset of a saga command")
+		}
+	}
+}
+
+// process decodes a saga command and hands it to the handler. Unknown or malformed
+// commands are skipped, since they would never be processed.
+func (c *CommandConsumer) process(msg *kafka.Message) error {
+	commandType := getHeader(msg, commandTypeHeader)
+	command := newCommand(commandType)
+	if command == nil {
+		c.logger.Warn().Msgf("skipping the unknown saga command '%s'", commandType)
+		return nil
+	}
+
+	if err := c.deserializer.DeserializeInto(*msg.TopicPartition.Topic, msg.Value, command); err != nil {
+		c.logger.Err(err).Msgf("skipping the malformed saga command '%s'", commandType)
+		return nil
+	}
+
+	return c.handler.Handle(domain.ContextWithIdentity(context.Background(), sagaIdentity), command)
+}
+
+// newCommand returns an empty avro record for a type of saga command (nil if the
+// type is unknown).
+func newCommand(commandType string) any {
+	switch commandType {
+	case createTicketCommand:
+		command := avro.NewCreateTicketCommandAvro()
+		return &command
+	case confirmCreateTicketCommand:
+		command := avro.NewConfirmCreateTicketCommandAvro()
+		return &command
+	case cancelCreateTicketCommand:
+		command := avro.NewCancelCreateTicketCommandAvro()
+		return &command
+	default:
+		return nil
+	}
+}
+
+func getHeader(msg *kafka.Message, key string) string {
+	for _, header := range msg.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
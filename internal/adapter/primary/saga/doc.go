@@ -0,0 +1,6 @@
+// Package saga includes types and functions to implement a primary adapter that
+// makes the restaurant service a participant of the sagas orchestrated by other
+// services (e.g. the Create Order saga). It consumes the saga commands from a
+// message broker, invokes the ticket use cases exposed by the core of our
+// application and writes the replies to the outbox within the same transaction.
+package saga
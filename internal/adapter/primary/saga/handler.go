@@ -0,0 +1,157 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+
+	"f4allgo-restaurant/internal/adapter/primary/saga/avro"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/rs/zerolog"
+)
+
+// Types of the saga commands handled by the restaurant service.
+const (
+	createTicketCommand        string = "CreateTicket"
+	confirmCreateTicketCommand string = "ConfirmCreateTicket"
+	cancelCreateTicketCommand  string = "CancelCreateTicket"
+)
+
+// Type of the replies sent to the saga orchestrators.
+const commandReplyType string = "CommandReply"
+
+// ReplyWriter writes the replies to the saga commands. Implementations must write
+// the replies within the transaction present in the context (if any), so that they
+// are atomic with the state change caused by the command (e.g. the outbox).
+type ReplyWriter interface {
+
+	// SaveReply writes a reply to be sent to the replyTo channel using the sagaId
+	// as key.
+	SaveReply(ctx context.Context, replyTo string, sagaId string, replyType string, avroRecord any) error
+}
+
+// CommandHandler processes the saga commands invoking the ticket use cases and
+// replying to the saga orchestrators.
+type CommandHandler struct {
+	ticketService port.TicketService
+	replyWriter   ReplyWriter
+	trManager     trm.Manager
+	logger        zerolog.Logger
+}
+
+// NewCommandHandler builds a new CommandHandler struct.
+func NewCommandHandler(s port.TicketService, replyWriter ReplyWriter, trManager trm.Manager, logger zerolog.Logger) *CommandHandler {
+	return &CommandHandler{ticketService: s, replyWriter: replyWriter, trManager: trManager, logger: logger}
+}
+
+// commandHeader holds the fields present in every saga command.
+type commandHeader struct {
+	commandType string
+	sagaId      string
+	commandId   string
+	replyTo     string
+}
+
+// Handle processes a saga command. The use case and the success reply are written
+// in the same transaction. If the command is rejected by the business rules, the
+// changes are rolled back and a failure reply is written instead. An error is only
+// returned when the command could not be processed (e.g. the database is down), so
+// it must be retried.
+func (h *CommandHandler) Handle(ctx context.Context, command any) error {
+	switch c := command.(type) {
+	case *avro.CreateTicketCommandAvro:
+		return h.handle(ctx, commandHeader{createTicketCommand, c.SagaId, c.CommandId, c.ReplyTo}, func(ctx context.Context) (*int64, error) {
+			ticketId, err := h.ticketService.CreatePending(ctx, c.RestaurantId, c.OrderId, toDomainTicketLineItems(c.LineItems))
+			return &ticketId, err
+		})
+	case *avro.ConfirmCreateTicketCommandAvro:
+		return h.handle(ctx, commandHeader{confirmCreateTicketCommand, c.SagaId, c.CommandId, c.ReplyTo}, func(ctx context.Context) (*int64, error) {
+			return &c.TicketId, h.ticketService.ConfirmCreate(ctx, c.TicketId)
+		})
+	case *avro.CancelCreateTicketCommandAvro:
+		return h.handle(ctx, commandHeader{cancelCreateTicketCommand, c.SagaId, c.CommandId, c.ReplyTo}, func(ctx context.Context) (*int64, error) {
+			return &c.TicketId, h.ticketService.CancelCreate(ctx, c.TicketId)
+		})
+	default:
+		return fmt.Errorf("unsupported saga command %T", command)
+	}
+}
+
+// handle runs a use case and writes the reply to the command.
+func (h *CommandHandler) handle(ctx context.Context, header commandHeader, useCase func(context.Context) (*int64, error)) error {
+	err := h.trManager.Do(ctx, func(ctx context.Context) error {
+		ticketId, err := useCase(ctx)
+		if err != nil {
+			return err
+		}
+		return h.reply(ctx, header, newCommandReply(header, ticketId, nil))
+	})
+	if err == nil || !isRejection(err) {
+		return err
+	}
+
+	reason := rejectionReason(err)
+	h.logger.Info().Msgf("the saga command %s (%s) was rejected: %s", header.commandType, header.commandId, reason)
+	return h.trManager.Do(ctx, func(ctx context.Context) error {
+		return h.reply(ctx, header, newCommandReply(header, nil, &reason))
+	})
+}
+
+func (h *CommandHandler) reply(ctx context.Context, header commandHeader, reply *avro.CommandReplyAvro) error {
+	if err := h.replyWriter.SaveReply(ctx, header.replyTo, header.sagaId, commandReplyType, reply); err != nil {
+		return fmt.Errorf("writing the reply to the saga command: %w", err)
+	}
+	return nil
+}
+
+// isRejection returns true if the error is caused by the command itself (it breaks
+// some business rule), so retrying it is pointless.
+func isRejection(err error) bool {
+	switch err.(type) {
+	case *coreerrors.RestaurantNotFoundError, *coreerrors.TicketNotFoundError,
+		*coreerrors.InvalidTicketStateError, *coreerrors.CoreError:
+		return true
+	default:
+		return false
+	}
+}
+
+// rejectionReason returns the reason sent to the saga orchestrator when a command
+// is rejected.
+func rejectionReason(err error) string {
+	if _, ok := err.(*coreerrors.RestaurantNotFoundError); ok {
+		return "restaurant not found"
+	}
+	return err.Error()
+}
+
+// newCommandReply builds a success reply (if there is no failure reason) or a
+// failure reply to a command.
+func newCommandReply(header commandHeader, ticketId *int64, reason *string) *avro.CommandReplyAvro {
+	reply := &avro.CommandReplyAvro{
+		SagaId:      header.sagaId,
+		CommandId:   header.commandId,
+		CommandType: header.commandType,
+		Outcome:     avro.CommandOutcomeAvroSUCCESS,
+	}
+	if reason != nil {
+		reply.Outcome = avro.CommandOutcomeAvroFAILURE
+		reply.Reason = &avro.UnionNullString{String: *reason, UnionType: avro.UnionNullStringTypeEnumString}
+	} else if ticketId != nil {
+		reply.TicketId = &avro.UnionNullLong{Long: *ticketId, UnionType: avro.UnionNullLongTypeEnumLong}
+	}
+	return reply
+}
+
+// toDomainTicketLineItems maps the line items of a CreateTicket command into a slice
+// of domain.TicketLineItem. The names are taken from the menu.
+func toDomainTicketLineItems(lineItems []avro.CreateTicketLineItemAvro) []*domain.TicketLineItem {
+	result := []*domain.TicketLineItem{}
+	for _, lineItem := range lineItems {
+		result = append(result, domain.NewTicketLineItem(int16(lineItem.MenuItemId), "", lineItem.Quantity))
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/adapter/primary/saga/avro"
+	"f4allgo-restaurant/internal/core/domain"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+	"f4allgo-restaurant/internal/core/service/mocks"
+	"f4allgo-restaurant/test"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// replyWriterSpy records the replies written by the CommandHandler.
+type replyWriterSpy struct {
+	replyTo []string
+	replies []*avro.CommandReplyAvro
+	err     error
+}
+
+func (w *replyWriterSpy) SaveReply(_ context.Context, replyTo string, _ string, _ string, avroRecord any) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.replyTo = append(w.replyTo, replyTo)
+	w.replies = append(w.replies, avroRecord.(*avro.CommandReplyAvro))
+	return nil
+}
+
+func TestHandle(t *testing.T) {
+	createTicket := &avro.CreateTicketCommandAvro{SagaId: "saga-1", CommandId: "command-1", ReplyTo: "order-saga-replies",
+		RestaurantId: 1, OrderId: 1000, LineItems: []avro.CreateTicketLineItemAvro{{MenuItemId: 1, Quantity: 2}}}
+	confirmCreateTicket := &avro.ConfirmCreateTicketCommandAvro{SagaId: "saga-1", CommandId: "command-2", ReplyTo: "order-saga-replies", TicketId: 10}
+	cancelCreateTicket := &avro.CancelCreateTicketCommandAvro{SagaId: "saga-1", CommandId: "command-3", ReplyTo: "order-saga-replies", TicketId: 10}
+	lineItems := []*domain.TicketLineItem{domain.NewTicketLineItem(1, "", 2)}
+	testcases := []struct {
+		name             string
+		command          any
+		replyErr         error
+		mockExpectations func(*mocks.MockTicketService)
+		wantReply        *avro.CommandReplyAvro
+		wantErr          bool
+	}{
+		{
+			name:    "mock a successful CreateTicket command",
+			command: createTicket,
+			mockExpectations: func(ms *mocks.MockTicketService) {
+				ms.EXPECT().CreatePending(context.Background(), int64(1), int64(1000), lineItems).Return(10, nil).Once()
+			},
+			wantReply: &avro.CommandReplyAvro{SagaId: "saga-1", CommandId: "command-1", CommandType: createTicketCommand,
+				Outcome: avro.CommandOutcomeAvroSUCCESS, TicketId: &avro.UnionNullLong{Long: 10, UnionType: avro.UnionNullLongTypeEnumLong}},
+		},
+		{
+			name:    "mock a rejected CreateTicket command",
+			command: createTicket,
+			mockExpectations: func(ms *mocks.MockTicketService) {
+				ms.EXPECT().CreatePending(context.Background(), int64(1), int64(1000), lineItems).Return(0, coreerrors.NewRestaurantNotFoundError()).Once()
+			},
+			wantReply: &avro.CommandReplyAvro{SagaId: "saga-1", CommandId: "command-1", CommandType: createTicketCommand,
+				Outcome: avro.CommandOutcomeAvroFAILURE, Reason: &avro.UnionNullString{String: "restaurant not found", UnionType: avro.UnionNullStringTypeEnumString}},
+		},
+		{
+			name:    "mock a CreateTicket command failing with a RepositoryError",
+			command: createTicket,
+			mockExpectations: func(ms *mocks.MockTicketService) {
+				ms.EXPECT().CreatePending(context.Background(), int64(1), int64(1000), lineItems).Return(0, coreerrors.NewRepositoryError(errors.New("error"))).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name:    "mock a successful ConfirmCreateTicket command",
+			command: confirmCreateTicket,
+			mockExpectations: func(ms *mocks.MockTicketService) {
+				ms.EXPECT().ConfirmCreate(context.Background(), int64(10)).Return(nil).Once()
+			},
+			wantReply: &avro.CommandReplyAvro{SagaId: "saga-1", CommandId: "command-2", CommandType: confirmCreateTicketCommand,
+				Outcome: avro.CommandOutcomeAvroSUCCESS, TicketId: &avro.UnionNullLong{Long: 10, UnionType: avro.UnionNullLongTypeEnumLong}},
+		},
+		{
+			name:    "mock a rejected CancelCreateTicket command",
+			command: cancelCreateTicket,
+			mockExpectations: func(ms *mocks.MockTicketService) {
+				ms.EXPECT().CancelCreate(context.Background(), int64(10)).Return(coreerrors.NewInvalidTicketStateError(errors.New("invalid state"))).Once()
+			},
+			wantReply: &avro.CommandReplyAvro{SagaId: "saga-1", CommandId: "command-3", CommandType: cancelCreateTicketCommand,
+				Outcome: avro.CommandOutcomeAvroFAILURE, Reason: &avro.UnionNullString{String: "invalid state", UnionType: avro.UnionNullStringTypeEnumString}},
+		},
+		{
+			name:     "mock a failure writing the reply",
+			command:  confirmCreateTicket,
+			replyErr: errors.New("error"),
+			mockExpectations: func(ms *mocks.MockTicketService) {
+				ms.EXPECT().ConfirmCreate(context.Background(), int64(10)).Return(nil).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name:             "mock an unsupported command",
+			command:          &avro.CommandReplyAvro{},
+			mockExpectations: func(ms *mocks.MockTicketService) {},
+			wantErr:          true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := mocks.NewMockTicketService(t)
+			tc.mockExpectations(ms)
+			replyWriter := &replyWriterSpy{err: tc.replyErr}
+			h := NewCommandHandler(ms, replyWriter, test.NewNopTrManager(), zerolog.Nop())
+			err := h.Handle(context.Background(), tc.command)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"order-saga-replies"}, replyWriter.replyTo)
+				assert.Equal(t, []*avro.CommandReplyAvro{tc.wantReply}, replyWriter.replies)
+			} else {
+				assert.Error(t, err)
+				assert.Empty(t, replyWriter.replies)
+			}
+		})
+	}
+}
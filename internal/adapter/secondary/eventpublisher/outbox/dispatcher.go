@@ -122,7 +122,7 @@ func (d *OutboxDispatcher) processOutbox() {
 	err := d.repository.findInBatches(batchSize, func(batch *[]*Outbox, tx *gorm.DB) error {
 		d.logger.Debug().Msgf("Sending %d messages to kafka", len(*batch))
 		for _, o := range *batch {
-			topic := buildOutboxTopicNamefromEventType(o.EventType)
+			topic := resolveTopic(o)
 			err := d.producer.Produce(&kafka.Message{
 				TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
 				Key:            []byte(o.AggregateId),
@@ -164,6 +164,15 @@ func (d *OutboxDispatcher) processOutbox() {
 	}
 }
 
+// resolveTopic returns the topic where an outbox row must be sent: the one of the
+// row if present or the one derived from its event type otherwise.
+func resolveTopic(o *Outbox) string {
+	if o.Topic != nil && *o.Topic != "" {
+		return *o.Topic
+	}
+	return buildOutboxTopicNamefromEventType(o.EventType)
+}
+
 func buildOutboxTopicNamefromEventType(eventType string) string {
 	return fmt.Sprintf("outbox-%s", strcase.ToKebab(eventType))
 }
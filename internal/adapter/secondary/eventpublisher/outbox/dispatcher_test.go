@@ -21,11 +21,101 @@ func TestBuildOutboxTopicNamefromEventType(t *testing.T) {
 			args: args{eventType: "RestaurantDeleted"},
 			want: "outbox-restaurant-deleted",
 		},
+		{
+			name: "When RestaurantRestored then outbox-restaurant-restored",
+			args: args{eventType: "RestaurantRestored"},
+			want: "outbox-restaurant-restored",
+		},
 		{
 			name: "When RestaurantMenuUpdated then outbox-restaurant-menu-updated",
 			args: args{eventType: "RestaurantMenuUpdated"},
 			want: "outbox-restaurant-menu-updated",
 		},
+		{
+			name: "When RestaurantDaypartsUpdated then outbox-restaurant-dayparts-updated",
+			args: args{eventType: "RestaurantDaypartsUpdated"},
+			want: "outbox-restaurant-dayparts-updated",
+		},
+		{
+			name: "When MenuItemAvailabilityChanged then outbox-menu-item-availability-changed",
+			args: args{eventType: "MenuItemAvailabilityChanged"},
+			want: "outbox-menu-item-availability-changed",
+		},
+		{
+			name: "When MenuItemAdded then outbox-menu-item-added",
+			args: args{eventType: "MenuItemAdded"},
+			want: "outbox-menu-item-added",
+		},
+		{
+			name: "When MenuItemUpdated then outbox-menu-item-updated",
+			args: args{eventType: "MenuItemUpdated"},
+			want: "outbox-menu-item-updated",
+		},
+		{
+			name: "When MenuItemPriceChanged then outbox-menu-item-price-changed",
+			args: args{eventType: "MenuItemPriceChanged"},
+			want: "outbox-menu-item-price-changed",
+		},
+		{
+			name: "When MenuItemRemoved then outbox-menu-item-removed",
+			args: args{eventType: "MenuItemRemoved"},
+			want: "outbox-menu-item-removed",
+		},
+		{
+			name: "When RestaurantRenamed then outbox-restaurant-renamed",
+			args: args{eventType: "RestaurantRenamed"},
+			want: "outbox-restaurant-renamed",
+		},
+		{
+			name: "When RestaurantAddressChanged then outbox-restaurant-address-changed",
+			args: args{eventType: "RestaurantAddressChanged"},
+			want: "outbox-restaurant-address-changed",
+		},
+		{
+			name: "When RestaurantDescriptionChanged then outbox-restaurant-description-changed",
+			args: args{eventType: "RestaurantDescriptionChanged"},
+			want: "outbox-restaurant-description-changed",
+		},
+		{
+			name: "When RestaurantTimeZoneChanged then outbox-restaurant-time-zone-changed",
+			args: args{eventType: "RestaurantTimeZoneChanged"},
+			want: "outbox-restaurant-time-zone-changed",
+		},
+		{
+			name: "When TicketCreated then outbox-ticket-created",
+			args: args{eventType: "TicketCreated"},
+			want: "outbox-ticket-created",
+		},
+		{
+			name: "When TicketAccepted then outbox-ticket-accepted",
+			args: args{eventType: "TicketAccepted"},
+			want: "outbox-ticket-accepted",
+		},
+		{
+			name: "When TicketRejected then outbox-ticket-rejected",
+			args: args{eventType: "TicketRejected"},
+			want: "outbox-ticket-rejected",
+		},
+		{
+			name: "When TicketPreparationStarted then outbox-ticket-preparation-started",
+			args: args{eventType: "TicketPreparationStarted"},
+			want: "outbox-ticket-preparation-started",
+		},
+		{
+			name: "When TicketReadyForPickup then outbox-ticket-ready-for-pickup",
+			args: args{eventType: "TicketReadyForPickup"},
+			want: "outbox-ticket-ready-for-pickup",
+		},
+		{
+			name: "When TicketPickedUp then outbox-ticket-picked-up",
+			args: args{eventType: "TicketPickedUp"},
+			want: "outbox-ticket-picked-up",
+		},
+		{
+			name: "When TicketCancelled then outbox-ticket-cancelled",
+			args: args{eventType: "TicketCancelled"},
+			want: "outbox-ticket-cancelled",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -35,3 +125,36 @@ func TestBuildOutboxTopicNamefromEventType(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveTopic(t *testing.T) {
+	replyTo := "order-service-saga-replies"
+	empty := ""
+	tests := []struct {
+		name   string
+		outbox *Outbox
+		want   string
+	}{
+		{
+			name:   "When the row has no topic then the one of the event type",
+			outbox: &Outbox{EventType: "TicketCreated"},
+			want:   "outbox-ticket-created",
+		},
+		{
+			name:   "When the row has an empty topic then the one of the event type",
+			outbox: &Outbox{EventType: "TicketCreated", Topic: &empty},
+			want:   "outbox-ticket-created",
+		},
+		{
+			name:   "When the row has a topic then the one of the row",
+			outbox: &Outbox{EventType: "CreateTicketReply", Topic: &replyTo},
+			want:   replyTo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveTopic(tt.outbox); got != tt.want {
+				t.Errorf("resolveTopic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ type Outbox struct {
 	AggregateId   string
 	EventType     string
 	Payload       []byte
+	Topic         *string
 	CreatedAt     time.Time
 }
 
@@ -13,8 +13,62 @@ type Mapper interface {
 	// fromRestaurantDeleted maps a domain.RestaurantDeleted event into an outbox row.
 	fromRestaurantDeleted(address *domain.RestaurantDeleted) *avro.RestaurantDeletedAvro
 
+	// fromRestaurantRestored maps a domain.RestaurantRestored event into an outbox row.
+	fromRestaurantRestored(event *domain.RestaurantRestored) *avro.RestaurantRestoredAvro
+
 	// fromRestaurantMenuUpdated maps a domain.RestaurantMenuUpdated into an outbox row.
 	fromRestaurantMenuUpdated(menu *domain.RestaurantMenuUpdated) *avro.RestaurantMenuUpdatedAvro
+
+	// fromRestaurantDaypartsUpdated maps a domain.RestaurantDaypartsUpdated into an outbox row.
+	fromRestaurantDaypartsUpdated(event *domain.RestaurantDaypartsUpdated) *avro.RestaurantDaypartsUpdatedAvro
+
+	// fromMenuItemAvailabilityChanged maps a domain.MenuItemAvailabilityChanged into an outbox row.
+	fromMenuItemAvailabilityChanged(event *domain.MenuItemAvailabilityChanged) *avro.MenuItemAvailabilityChangedAvro
+
+	// fromMenuItemAdded maps a domain.MenuItemAdded into an outbox row.
+	fromMenuItemAdded(event *domain.MenuItemAdded) *avro.MenuItemAddedAvro
+
+	// fromMenuItemUpdated maps a domain.MenuItemUpdated into an outbox row.
+	fromMenuItemUpdated(event *domain.MenuItemUpdated) *avro.MenuItemUpdatedAvro
+
+	// fromMenuItemPriceChanged maps a domain.MenuItemPriceChanged into an outbox row.
+	fromMenuItemPriceChanged(event *domain.MenuItemPriceChanged) *avro.MenuItemPriceChangedAvro
+
+	// fromMenuItemRemoved maps a domain.MenuItemRemoved into an outbox row.
+	fromMenuItemRemoved(event *domain.MenuItemRemoved) *avro.MenuItemRemovedAvro
+
+	// fromRestaurantRenamed maps a domain.RestaurantRenamed into an outbox row.
+	fromRestaurantRenamed(event *domain.RestaurantRenamed) *avro.RestaurantRenamedAvro
+
+	// fromRestaurantAddressChanged maps a domain.RestaurantAddressChanged into an outbox row.
+	fromRestaurantAddressChanged(event *domain.RestaurantAddressChanged) *avro.RestaurantAddressChangedAvro
+
+	// fromRestaurantDescriptionChanged maps a domain.RestaurantDescriptionChanged into an outbox row.
+	fromRestaurantDescriptionChanged(event *domain.RestaurantDescriptionChanged) *avro.RestaurantDescriptionChangedAvro
+
+	// fromRestaurantTimeZoneChanged maps a domain.RestaurantTimeZoneChanged into an outbox row.
+	fromRestaurantTimeZoneChanged(event *domain.RestaurantTimeZoneChanged) *avro.RestaurantTimeZoneChangedAvro
+
+	// fromTicketCreated maps a domain.TicketCreated into an outbox row.
+	fromTicketCreated(event *domain.TicketCreated) *avro.TicketCreatedAvro
+
+	// fromTicketAccepted maps a domain.TicketAccepted into an outbox row.
+	fromTicketAccepted(event *domain.TicketAccepted) *avro.TicketAcceptedAvro
+
+	// fromTicketRejected maps a domain.TicketRejected into an outbox row.
+	fromTicketRejected(event *domain.TicketRejected) *avro.TicketRejectedAvro
+
+	// fromTicketPreparationStarted maps a domain.TicketPreparationStarted into an outbox row.
+	fromTicketPreparationStarted(event *domain.TicketPreparationStarted) *avro.TicketPreparationStartedAvro
+
+	// fromTicketReadyForPickup maps a domain.TicketReadyForPickup into an outbox row.
+	fromTicketReadyForPickup(event *domain.TicketReadyForPickup) *avro.TicketReadyForPickupAvro
+
+	// fromTicketPickedUp maps a domain.TicketPickedUp into an outbox row.
+	fromTicketPickedUp(event *domain.TicketPickedUp) *avro.TicketPickedUpAvro
+
+	// fromTicketCancelled maps a domain.TicketCancelled into an outbox row.
+	fromTicketCancelled(event *domain.TicketCancelled) *avro.TicketCancelledAvro
 }
 
 // DefaultMapper is the default implementation of Mapper.
@@ -25,11 +79,24 @@ type DefaultMapper struct{}
 var _ Mapper = (*DefaultMapper)(nil)
 
 func (dm DefaultMapper) fromRestaurantCreated(event *domain.RestaurantCreated) *avro.RestaurantCreatedAvro {
+	defaultLocale := event.Restaurant.DefaultLocale
+	if defaultLocale == "" {
+		defaultLocale = domain.DefaultLocale
+	}
+	timeZone := event.Restaurant.TimeZone
+	if timeZone == "" {
+		timeZone = domain.DefaultTimeZone
+	}
 	return &avro.RestaurantCreatedAvro{
-		Id:      event.Restaurant.Id,
-		Name:    event.Restaurant.Name,
-		Address: dm.fromDomainAddress(event.Restaurant.Address),
-		Menu:    dm.fromDomainMenu(event.Restaurant.Menu),
+		Id:                      event.Restaurant.Id,
+		TenantId:                event.Restaurant.TenantId,
+		Name:                    event.Restaurant.Name,
+		DefaultLocale:           defaultLocale,
+		TimeZone:                timeZone,
+		Description:             event.Restaurant.Description,
+		DescriptionTranslations: fromDomainDescriptionTranslations(event.Restaurant.DescriptionTranslations),
+		Address:                 dm.fromDomainAddress(event.Restaurant.Address),
+		Menu:                    dm.fromDomainMenu(event.Restaurant.Menu),
 	}
 }
 
@@ -39,6 +106,12 @@ func (dm DefaultMapper) fromRestaurantDeleted(event *domain.RestaurantDeleted) *
 	}
 }
 
+func (dm DefaultMapper) fromRestaurantRestored(event *domain.RestaurantRestored) *avro.RestaurantRestoredAvro {
+	return &avro.RestaurantRestoredAvro{
+		RestaurantId: event.RestaurantId,
+	}
+}
+
 func (dm DefaultMapper) fromRestaurantMenuUpdated(event *domain.RestaurantMenuUpdated) *avro.RestaurantMenuUpdatedAvro {
 	return &avro.RestaurantMenuUpdatedAvro{
 		RestaurantId: event.RestaurantId,
@@ -46,20 +119,219 @@ func (dm DefaultMapper) fromRestaurantMenuUpdated(event *domain.RestaurantMenuUp
 	}
 }
 
+func (dm DefaultMapper) fromRestaurantDaypartsUpdated(event *domain.RestaurantDaypartsUpdated) *avro.RestaurantDaypartsUpdatedAvro {
+	avroDayparts := []avro.DaypartAvro{}
+	for _, daypart := range event.Dayparts {
+		avroWindows := []avro.TimeWindowAvro{}
+		for _, window := range daypart.GetWindows() {
+			avroWindows = append(avroWindows, avro.TimeWindowAvro{Start: int32(window.GetStart()), End: int32(window.GetEnd())})
+		}
+		avroDayparts = append(avroDayparts, avro.DaypartAvro{
+			Name:    daypart.GetName(),
+			Windows: avroWindows,
+			Menu:    dm.fromDomainMenu(daypart.GetMenu()),
+		})
+	}
+	return &avro.RestaurantDaypartsUpdatedAvro{
+		RestaurantId: event.RestaurantId,
+		Dayparts:     avroDayparts,
+	}
+}
+
+func (dm DefaultMapper) fromMenuItemAvailabilityChanged(event *domain.MenuItemAvailabilityChanged) *avro.MenuItemAvailabilityChangedAvro {
+	var availableUntil *avro.UnionNullLong
+	if event.AvailableUntil != nil {
+		availableUntil = &avro.UnionNullLong{Long: event.AvailableUntil.UnixMilli(), UnionType: avro.UnionNullLongTypeEnumLong}
+	}
+	return &avro.MenuItemAvailabilityChangedAvro{
+		RestaurantId:   event.RestaurantId,
+		MenuItemId:     int32(event.MenuItemId),
+		Available:      event.Available,
+		AvailableUntil: availableUntil,
+	}
+}
+
+func (dm DefaultMapper) fromMenuItemAdded(event *domain.MenuItemAdded) *avro.MenuItemAddedAvro {
+	return &avro.MenuItemAddedAvro{
+		RestaurantId: event.RestaurantId,
+		MenuItem:     dm.fromDomainMenuItem(event.MenuItem),
+	}
+}
+
+func (dm DefaultMapper) fromMenuItemUpdated(event *domain.MenuItemUpdated) *avro.MenuItemUpdatedAvro {
+	return &avro.MenuItemUpdatedAvro{
+		RestaurantId: event.RestaurantId,
+		MenuItem:     dm.fromDomainMenuItem(event.MenuItem),
+	}
+}
+
+func (dm DefaultMapper) fromMenuItemPriceChanged(event *domain.MenuItemPriceChanged) *avro.MenuItemPriceChangedAvro {
+	return &avro.MenuItemPriceChangedAvro{
+		RestaurantId: event.RestaurantId,
+		MenuItemId:   int32(event.MenuItemId),
+		OldPrice:     []byte(event.OldPrice.Text('f', 2)),
+		NewPrice:     []byte(event.NewPrice.Text('f', 2)),
+	}
+}
+
+func (dm DefaultMapper) fromMenuItemRemoved(event *domain.MenuItemRemoved) *avro.MenuItemRemovedAvro {
+	return &avro.MenuItemRemovedAvro{
+		RestaurantId: event.RestaurantId,
+		MenuItemId:   int32(event.MenuItemId),
+	}
+}
+
+func (dm DefaultMapper) fromRestaurantRenamed(event *domain.RestaurantRenamed) *avro.RestaurantRenamedAvro {
+	return &avro.RestaurantRenamedAvro{
+		RestaurantId: event.RestaurantId,
+		Name:         event.Name,
+	}
+}
+
+func (dm DefaultMapper) fromRestaurantAddressChanged(event *domain.RestaurantAddressChanged) *avro.RestaurantAddressChangedAvro {
+	return &avro.RestaurantAddressChangedAvro{
+		RestaurantId: event.RestaurantId,
+		Address:      dm.fromDomainAddress(event.Address),
+	}
+}
+
+func (dm DefaultMapper) fromRestaurantDescriptionChanged(event *domain.RestaurantDescriptionChanged) *avro.RestaurantDescriptionChangedAvro {
+	return &avro.RestaurantDescriptionChangedAvro{
+		RestaurantId:  event.RestaurantId,
+		DefaultLocale: event.DefaultLocale,
+		Description:   event.Description,
+		Translations:  fromDomainDescriptionTranslations(event.Translations),
+	}
+}
+
+func (dm DefaultMapper) fromRestaurantTimeZoneChanged(event *domain.RestaurantTimeZoneChanged) *avro.RestaurantTimeZoneChangedAvro {
+	return &avro.RestaurantTimeZoneChangedAvro{
+		RestaurantId: event.RestaurantId,
+		TimeZone:     event.TimeZone,
+	}
+}
+
+func (dm DefaultMapper) fromTicketCreated(event *domain.TicketCreated) *avro.TicketCreatedAvro {
+	lineItems := []avro.TicketLineItemAvro{}
+	for _, lineItem := range event.Ticket.LineItems {
+		lineItems = append(lineItems, avro.TicketLineItemAvro{
+			MenuItemId: int32(lineItem.GetMenuItemId()),
+			Name:       lineItem.GetName(),
+			Quantity:   lineItem.GetQuantity(),
+		})
+	}
+	return &avro.TicketCreatedAvro{
+		TicketId:     event.Ticket.Id,
+		RestaurantId: event.Ticket.RestaurantId,
+		OrderId:      event.Ticket.OrderId,
+		LineItems:    lineItems,
+	}
+}
+
+func (dm DefaultMapper) fromTicketAccepted(event *domain.TicketAccepted) *avro.TicketAcceptedAvro {
+	return &avro.TicketAcceptedAvro{
+		TicketId:     event.TicketId,
+		RestaurantId: event.RestaurantId,
+		OrderId:      event.OrderId,
+		ReadyBy:      event.ReadyBy.UnixMilli(),
+	}
+}
+
+func (dm DefaultMapper) fromTicketRejected(event *domain.TicketRejected) *avro.TicketRejectedAvro {
+	return &avro.TicketRejectedAvro{
+		TicketId:     event.TicketId,
+		RestaurantId: event.RestaurantId,
+		OrderId:      event.OrderId,
+	}
+}
+
+func (dm DefaultMapper) fromTicketPreparationStarted(event *domain.TicketPreparationStarted) *avro.TicketPreparationStartedAvro {
+	return &avro.TicketPreparationStartedAvro{
+		TicketId:     event.TicketId,
+		RestaurantId: event.RestaurantId,
+		OrderId:      event.OrderId,
+	}
+}
+
+func (dm DefaultMapper) fromTicketReadyForPickup(event *domain.TicketReadyForPickup) *avro.TicketReadyForPickupAvro {
+	return &avro.TicketReadyForPickupAvro{
+		TicketId:     event.TicketId,
+		RestaurantId: event.RestaurantId,
+		OrderId:      event.OrderId,
+	}
+}
+
+func (dm DefaultMapper) fromTicketPickedUp(event *domain.TicketPickedUp) *avro.TicketPickedUpAvro {
+	return &avro.TicketPickedUpAvro{
+		TicketId:     event.TicketId,
+		RestaurantId: event.RestaurantId,
+		OrderId:      event.OrderId,
+	}
+}
+
+func (dm DefaultMapper) fromTicketCancelled(event *domain.TicketCancelled) *avro.TicketCancelledAvro {
+	return &avro.TicketCancelledAvro{
+		TicketId:     event.TicketId,
+		RestaurantId: event.RestaurantId,
+		OrderId:      event.OrderId,
+	}
+}
+
 func (dm DefaultMapper) fromDomainAddress(address *domain.Address) avro.AdressAvro {
+	var latitude, longitude *avro.UnionNullDouble
+	if location := address.Location(); location != nil {
+		latitude = &avro.UnionNullDouble{Double: location.Latitude(), UnionType: avro.UnionNullDoubleTypeEnumDouble}
+		longitude = &avro.UnionNullDouble{Double: location.Longitude(), UnionType: avro.UnionNullDoubleTypeEnumDouble}
+	}
 	return avro.AdressAvro{
-		Street: address.Street(),
-		City:   address.City(),
-		State:  address.State(),
-		Zip:    address.Zip(),
+		Street:    address.Street(),
+		City:      address.City(),
+		State:     address.State(),
+		Zip:       address.Zip(),
+		Latitude:  latitude,
+		Longitude: longitude,
 	}
 }
 func (dm DefaultMapper) fromDomainMenu(menu *domain.Menu) avro.MenuAvro {
 	avroItems := []avro.MenuItemAvro{}
 	for _, item := range menu.GetItems() {
-		avroItems = append(avroItems, avro.MenuItemAvro{Id: int32(item.GetId()), Name: item.GetName(), Price: []byte(item.GetPrice().Text('f', 2))})
+		avroItems = append(avroItems, dm.fromDomainMenuItem(item))
 	}
 	return avro.MenuAvro{
 		Items: avroItems,
 	}
 }
+
+func (dm DefaultMapper) fromDomainMenuItem(item *domain.MenuItem) avro.MenuItemAvro {
+	allergens := []string{}
+	for _, a := range item.GetAllergens() {
+		allergens = append(allergens, string(a))
+	}
+	dietaryTags := []string{}
+	for _, t := range item.GetDietaryTags() {
+		dietaryTags = append(dietaryTags, string(t))
+	}
+	translations := map[string]avro.TranslationAvro{}
+	for locale, t := range item.GetTranslations() {
+		translations[locale] = avro.TranslationAvro{Name: t.GetName(), Description: t.GetDescription()}
+	}
+	return avro.MenuItemAvro{
+		Id:           int32(item.GetId()),
+		Name:         item.GetName(),
+		Description:  item.GetDescription(),
+		Translations: translations,
+		Price:        []byte(item.GetPrice().Text('f', 2)),
+		Allergens:    allergens,
+		DietaryTags:  dietaryTags,
+	}
+}
+
+// fromDomainDescriptionTranslations returns a non-nil copy of the translations
+// of a description, since Avro maps cannot be null.
+func fromDomainDescriptionTranslations(translations map[string]string) map[string]string {
+	values := map[string]string{}
+	for locale, t := range translations {
+		values[locale] = t
+	}
+	return values
+}
@@ -20,6 +20,8 @@ import (
 
 const lockMaxDuration time.Duration = 30
 const restaurantAggregateType string = "Restaurant"
+const ticketAggregateType string = "Ticket"
+const sagaAggregateType string = "Saga"
 
 // OutboxRepository manages outbox persistent operations on domain events before they
 // are published to a message broker. It is part of the outbox pattern implementation.
@@ -29,6 +31,12 @@ type OutboxRepository interface {
 	// by the event publisher inside a business transaction where the event is raised.
 	Save(ctx context.Context, e domain.DomainEvent) error
 
+	// SaveReply persists in the outbox the reply to a command received from another
+	// service (e.g. a saga orchestrator). The reply is sent to the replyTo topic using
+	// the sagaId as key. This operation is used inside the transaction where the
+	// command is processed, so the reply is atomic with the resulting state change.
+	SaveReply(ctx context.Context, replyTo string, sagaId string, replyType string, avroRecord any) error
+
 	// acquireLock gets a lock on the outbox table using optimistic locking.
 	acquireLock() (bool, error)
 
@@ -79,6 +87,14 @@ func (r *OutboxPostgresRepository) Save(ctx context.Context, event domain.Domain
 			EventType:     e.GetType(),
 		}
 		avroRecord = r.mapper.fromRestaurantDeleted(e)
+	case *domain.RestaurantRestored:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromRestaurantRestored(e)
 	case *domain.RestaurantMenuUpdated:
 		outboxRow = &Outbox{
 			Id:            uuid.New(),
@@ -87,8 +103,161 @@ func (r *OutboxPostgresRepository) Save(ctx context.Context, event domain.Domain
 			EventType:     e.GetType(),
 		}
 		avroRecord = r.mapper.fromRestaurantMenuUpdated(e)
+	case *domain.RestaurantDaypartsUpdated:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromRestaurantDaypartsUpdated(e)
+	case *domain.MenuItemAvailabilityChanged:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromMenuItemAvailabilityChanged(e)
+	case *domain.MenuItemAdded:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromMenuItemAdded(e)
+	case *domain.MenuItemUpdated:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromMenuItemUpdated(e)
+	case *domain.MenuItemPriceChanged:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromMenuItemPriceChanged(e)
+	case *domain.MenuItemRemoved:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromMenuItemRemoved(e)
+	case *domain.RestaurantRenamed:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromRestaurantRenamed(e)
+	case *domain.RestaurantDescriptionChanged:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromRestaurantDescriptionChanged(e)
+	case *domain.RestaurantTimeZoneChanged:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromRestaurantTimeZoneChanged(e)
+	case *domain.RestaurantAddressChanged:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: restaurantAggregateType,
+			AggregateId:   strconv.FormatInt(e.RestaurantId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromRestaurantAddressChanged(e)
+	case *domain.TicketCreated:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: ticketAggregateType,
+			AggregateId:   strconv.FormatInt(e.Ticket.Id, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromTicketCreated(e)
+	case *domain.TicketAccepted:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: ticketAggregateType,
+			AggregateId:   strconv.FormatInt(e.TicketId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromTicketAccepted(e)
+	case *domain.TicketRejected:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: ticketAggregateType,
+			AggregateId:   strconv.FormatInt(e.TicketId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromTicketRejected(e)
+	case *domain.TicketPreparationStarted:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: ticketAggregateType,
+			AggregateId:   strconv.FormatInt(e.TicketId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromTicketPreparationStarted(e)
+	case *domain.TicketReadyForPickup:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: ticketAggregateType,
+			AggregateId:   strconv.FormatInt(e.TicketId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromTicketReadyForPickup(e)
+	case *domain.TicketPickedUp:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: ticketAggregateType,
+			AggregateId:   strconv.FormatInt(e.TicketId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromTicketPickedUp(e)
+	case *domain.TicketCancelled:
+		outboxRow = &Outbox{
+			Id:            uuid.New(),
+			AggregateType: ticketAggregateType,
+			AggregateId:   strconv.FormatInt(e.TicketId, 10),
+			EventType:     e.GetType(),
+		}
+		avroRecord = r.mapper.fromTicketCancelled(e)
+	}
+
+	return r.save(ctx, outboxRow, avroRecord)
+}
+
+func (r *OutboxPostgresRepository) SaveReply(ctx context.Context, replyTo string, sagaId string, replyType string, avroRecord any) error {
+	outboxRow := &Outbox{
+		Id:            uuid.New(),
+		AggregateType: sagaAggregateType,
+		AggregateId:   sagaId,
+		EventType:     replyType,
+		Topic:         &replyTo,
 	}
+	return r.save(ctx, outboxRow, avroRecord)
+}
 
+// save serializes the avro record into the payload of the outbox row (using the
+// schema registry) and persists the row in the current transaction (if any).
+func (r *OutboxPostgresRepository) save(ctx context.Context, outboxRow *Outbox, avroRecord any) error {
 	client, err := schemaregistry.NewClient(schemaregistry.NewConfig(r.config.KafkaSchemaRegistry))
 	if err != nil {
 		return fmt.Errorf("creating the schema registry client: %w", err)
@@ -99,7 +268,7 @@ func (r *OutboxPostgresRepository) Save(ctx context.Context, event domain.Domain
 		return fmt.Errorf("creating the avro serializer: %w", err)
 	}
 
-	avroBytes, err := ser.Serialize(buildOutboxTopicNamefromEventType(outboxRow.EventType), avroRecord)
+	avroBytes, err := ser.Serialize(resolveTopic(outboxRow), avroRecord)
 	if err != nil {
 		return fmt.Errorf("serializing to avro: %w", err)
 	}
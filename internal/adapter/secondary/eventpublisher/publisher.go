@@ -34,11 +34,47 @@ func NewDomainEventOutboxPublisher(db *gorm.DB, ctxGetter *trmgorm.CtxGetter, lo
 	if scope != nil {
 		restaurantCreated := scope.Tagged(map[string]string{"event_type": "RestaurantCreated"}).Counter("outgoing_events")
 		restaurantDeleted := scope.Tagged(map[string]string{"event_type": "RestaurantDeleted"}).Counter("outgoing_events")
+		restaurantRestored := scope.Tagged(map[string]string{"event_type": "RestaurantRestored"}).Counter("outgoing_events")
 		restaurantMenuUpdated := scope.Tagged(map[string]string{"event_type": "RestaurantMenuUpdated"}).Counter("outgoing_events")
+		restaurantDaypartsUpdated := scope.Tagged(map[string]string{"event_type": "RestaurantDaypartsUpdated"}).Counter("outgoing_events")
+		menuItemAvailabilityChanged := scope.Tagged(map[string]string{"event_type": "MenuItemAvailabilityChanged"}).Counter("outgoing_events")
+		menuItemAdded := scope.Tagged(map[string]string{"event_type": "MenuItemAdded"}).Counter("outgoing_events")
+		menuItemUpdated := scope.Tagged(map[string]string{"event_type": "MenuItemUpdated"}).Counter("outgoing_events")
+		menuItemPriceChanged := scope.Tagged(map[string]string{"event_type": "MenuItemPriceChanged"}).Counter("outgoing_events")
+		menuItemRemoved := scope.Tagged(map[string]string{"event_type": "MenuItemRemoved"}).Counter("outgoing_events")
+		restaurantRenamed := scope.Tagged(map[string]string{"event_type": "RestaurantRenamed"}).Counter("outgoing_events")
+		restaurantAddressChanged := scope.Tagged(map[string]string{"event_type": "RestaurantAddressChanged"}).Counter("outgoing_events")
+		restaurantDescriptionChanged := scope.Tagged(map[string]string{"event_type": "RestaurantDescriptionChanged"}).Counter("outgoing_events")
+		restaurantTimeZoneChanged := scope.Tagged(map[string]string{"event_type": "RestaurantTimeZoneChanged"}).Counter("outgoing_events")
+		ticketCreated := scope.Tagged(map[string]string{"event_type": "TicketCreated"}).Counter("outgoing_events")
+		ticketAccepted := scope.Tagged(map[string]string{"event_type": "TicketAccepted"}).Counter("outgoing_events")
+		ticketRejected := scope.Tagged(map[string]string{"event_type": "TicketRejected"}).Counter("outgoing_events")
+		ticketPreparationStarted := scope.Tagged(map[string]string{"event_type": "TicketPreparationStarted"}).Counter("outgoing_events")
+		ticketReadyForPickup := scope.Tagged(map[string]string{"event_type": "TicketReadyForPickup"}).Counter("outgoing_events")
+		ticketPickedUp := scope.Tagged(map[string]string{"event_type": "TicketPickedUp"}).Counter("outgoing_events")
+		ticketCancelled := scope.Tagged(map[string]string{"event_type": "TicketCancelled"}).Counter("outgoing_events")
 		eventCounters = map[string]tally.Counter{
-			"RestaurantCreated":     restaurantCreated,
-			"RestaurantDeleted":     restaurantDeleted,
-			"RestaurantMenuUpdated": restaurantMenuUpdated,
+			"RestaurantCreated":            restaurantCreated,
+			"RestaurantDeleted":            restaurantDeleted,
+			"RestaurantRestored":           restaurantRestored,
+			"RestaurantMenuUpdated":        restaurantMenuUpdated,
+			"RestaurantDaypartsUpdated":    restaurantDaypartsUpdated,
+			"MenuItemAvailabilityChanged":  menuItemAvailabilityChanged,
+			"MenuItemAdded":                menuItemAdded,
+			"MenuItemUpdated":              menuItemUpdated,
+			"MenuItemPriceChanged":         menuItemPriceChanged,
+			"MenuItemRemoved":              menuItemRemoved,
+			"RestaurantRenamed":            restaurantRenamed,
+			"RestaurantAddressChanged":     restaurantAddressChanged,
+			"RestaurantDescriptionChanged": restaurantDescriptionChanged,
+			"RestaurantTimeZoneChanged":    restaurantTimeZoneChanged,
+			"TicketCreated":                ticketCreated,
+			"TicketAccepted":               ticketAccepted,
+			"TicketRejected":               ticketRejected,
+			"TicketPreparationStarted":     ticketPreparationStarted,
+			"TicketReadyForPickup":         ticketReadyForPickup,
+			"TicketPickedUp":               ticketPickedUp,
+			"TicketCancelled":              ticketCancelled,
 		}
 	}
 
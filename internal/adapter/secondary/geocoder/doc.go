@@ -0,0 +1,6 @@
+// Package geocoder includes types and functions to resolve the geographic location
+// of addresses. It implements the Geocoder interface defined as a secondary port in
+// the core module. The provided implementation is a local one, backed by a static
+// table of known addresses that can be loaded from a CSV file, so no external
+// geocoding service is needed.
+package geocoder
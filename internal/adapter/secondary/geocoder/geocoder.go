@@ -0,0 +1,93 @@
+package geocoder
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+)
+
+// StaticGeocoder resolves the location of addresses using a static table of known
+// addresses. The lookup ignores case and surrounding whitespace.
+type StaticGeocoder struct {
+	locations map[string]*domain.GeoPoint
+}
+
+// Interface compliance verification.
+var _ port.Geocoder = (*StaticGeocoder)(nil)
+
+// NewStaticGeocoder builds an empty StaticGeocoder.
+func NewStaticGeocoder() *StaticGeocoder {
+	return &StaticGeocoder{locations: map[string]*domain.GeoPoint{}}
+}
+
+// NewCsvGeocoder builds a StaticGeocoder loading the known addresses from a CSV file
+// with the columns: street, city, state, zip, latitude and longitude. The first row
+// is considered a header and skipped.
+func NewCsvGeocoder(path string) (*StaticGeocoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return loadCsv(f)
+}
+
+// Add registers the location of an address.
+func (g *StaticGeocoder) Add(address *domain.Address, location *domain.GeoPoint) {
+	g.locations[key(address)] = location
+}
+
+// Geocode returns the location of an address, or nil if it's unknown.
+func (g *StaticGeocoder) Geocode(_ context.Context, address *domain.Address) (*domain.GeoPoint, error) {
+	return g.locations[key(address)], nil
+}
+
+// loadCsv builds a StaticGeocoder from CSV formatted data.
+func loadCsv(r io.Reader) (*StaticGeocoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 6
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	g := NewStaticGeocoder()
+	for i, record := range records {
+		if i == 0 {
+			continue
+		}
+		latitude, err := strconv.ParseFloat(record[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latitude in line %d: %w", i+1, err)
+		}
+		longitude, err := strconv.ParseFloat(record[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid longitude in line %d: %w", i+1, err)
+		}
+		location, err := domain.NewGeoPoint(latitude, longitude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid location in line %d: %w", i+1, err)
+		}
+		g.Add(domain.NewAddress(record[0], record[1], record[2], record[3]), location)
+	}
+
+	return g, nil
+}
+
+// key builds the lookup key of an address.
+func key(address *domain.Address) string {
+	fields := []string{address.Street(), address.City(), address.State(), address.Zip()}
+	for i, field := range fields {
+		fields[i] = strings.ToLower(strings.TrimSpace(field))
+	}
+	return strings.Join(fields, "|")
+}
@@ -0,0 +1,89 @@
+package geocoder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeocode(t *testing.T) {
+	data := `street,city,state,zip,latitude,longitude
+Puerta del Sol 1,Madrid,Madrid,28013,40.4168,-3.7038
+Placa de Catalunya 1,Barcelona,Barcelona,08002,41.3874,2.1686
+`
+	g, err := loadCsv(strings.NewReader(data))
+	assert.NoError(t, err)
+
+	madrid, _ := domain.NewGeoPoint(40.4168, -3.7038)
+	testcases := []struct {
+		name         string
+		address      *domain.Address
+		wantLocation *domain.GeoPoint
+	}{
+		{
+			name:         "geocode a known address",
+			address:      domain.NewAddress("Puerta del Sol 1", "Madrid", "Madrid", "28013"),
+			wantLocation: madrid,
+		},
+		{
+			name:         "geocode a known address ignoring case and whitespace",
+			address:      domain.NewAddress(" puerta del sol 1", "MADRID", "madrid", "28013 "),
+			wantLocation: madrid,
+		},
+		{
+			name:         "geocode an unknown address",
+			address:      domain.NewAddress("Gran Via 1", "Madrid", "Madrid", "28013"),
+			wantLocation: nil,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			location, err := g.Geocode(context.Background(), tc.address)
+			assert.NoError(t, err)
+			assert.True(t, tc.wantLocation.Equals(location))
+		})
+	}
+}
+
+func TestLoadCsv(t *testing.T) {
+	testcases := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name:    "load a valid file",
+			data:    "street,city,state,zip,latitude,longitude\nstreet1,city1,state1,zip1,40.4168,-3.7038\n",
+			wantErr: false,
+		},
+		{
+			name:    "load a file with a wrong number of columns",
+			data:    "street,city,state,zip,latitude,longitude\nstreet1,city1,state1,zip1,40.4168\n",
+			wantErr: true,
+		},
+		{
+			name:    "load a file with an invalid latitude",
+			data:    "street,city,state,zip,latitude,longitude\nstreet1,city1,state1,zip1,north,-3.7038\n",
+			wantErr: true,
+		},
+		{
+			name:    "load a file with an out of range longitude",
+			data:    "street,city,state,zip,latitude,longitude\nstreet1,city1,state1,zip1,40.4168,-200\n",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadCsv(strings.NewReader(tc.data))
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
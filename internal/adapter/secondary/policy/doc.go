@@ -0,0 +1,6 @@
+// Package policy includes types and functions to decide which operations the
+// callers are allowed to perform. It implements the AuthorizationPolicy interface
+// defined as a secondary port in the core module. The provided implementation is
+// role based: the permissions granted to each role are loaded from a YAML file, and
+// every denied request is audited through the logs and the metrics.
+package policy
@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+
+	"github.com/rs/zerolog"
+	"github.com/uber-go/tally/v4"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// anonymousRole is the role the callers without identity are evaluated with.
+	anonymousRole domain.Role = "anonymous"
+	// anyPermission grants all the permissions to a role.
+	anyPermission domain.Permission = "*"
+)
+
+// knownPermissions are the permissions that can be granted in a policy file, so
+// that typos are detected when it's loaded.
+var knownPermissions = map[domain.Permission]bool{
+	anyPermission:                     true,
+	domain.PermissionReadRestaurant:   true,
+	domain.PermissionCreateRestaurant: true,
+	domain.PermissionUpdateRestaurant: true,
+	domain.PermissionDeleteRestaurant: true,
+	domain.PermissionUpdateMenu:       true,
+	domain.PermissionReadAuditLog:     true,
+	domain.PermissionReadTicket:       true,
+	domain.PermissionUpdateTicket:     true,
+}
+
+// policyFile is the YAML representation of a policy: the permissions granted to
+// each role.
+type policyFile struct {
+	Roles map[domain.Role][]domain.Permission `yaml:"roles"`
+}
+
+// RolePolicy grants permissions to the callers according to their roles. Callers
+// without identity are evaluated with the 'anonymous' role, so the policy decides
+// whether they can perform any operation at all.
+type RolePolicy struct {
+	permissions map[domain.Role]map[domain.Permission]bool
+	logger      zerolog.Logger
+	scope       tally.Scope
+}
+
+// Interface compliance verification.
+var _ port.AuthorizationPolicy = (*RolePolicy)(nil)
+
+// NewYamlRolePolicy builds a RolePolicy loading the permissions of the roles from
+// a YAML file. The denied requests are logged and, if a scope is provided, counted
+// along with the allowed ones.
+func NewYamlRolePolicy(path string, logger zerolog.Logger, scope tally.Scope) (*RolePolicy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return loadYaml(f, logger, scope)
+}
+
+// IsAllowed returns true if any of the roles of the caller has been granted the
+// permission.
+func (p *RolePolicy) IsAllowed(_ context.Context, identity *domain.Identity, permission domain.Permission) (bool, error) {
+	roles := []domain.Role{anonymousRole}
+	if identity != nil {
+		roles = identity.GetRoles()
+	}
+
+	allowed := false
+	for _, role := range roles {
+		if granted := p.permissions[role]; granted[permission] || granted[anyPermission] {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		p.audit(identity, permission)
+	}
+	if p.scope != nil {
+		decision := "allowed"
+		if !allowed {
+			decision = "denied"
+		}
+		p.scope.Tagged(map[string]string{"permission": string(permission), "decision": decision}).Counter("authorization_decisions").Inc(1)
+	}
+
+	return allowed, nil
+}
+
+// audit logs a denied request with the identity of the caller.
+func (p *RolePolicy) audit(identity *domain.Identity, permission domain.Permission) {
+	event := p.logger.Warn().Str("permission", string(permission))
+	if identity != nil {
+		roles := make([]string, 0, len(identity.GetRoles()))
+		for _, role := range identity.GetRoles() {
+			roles = append(roles, string(role))
+		}
+		event = event.Str("subject", identity.GetSubject()).Str("tenant", identity.GetTenantId()).Strs("roles", roles)
+	} else {
+		event = event.Bool("anonymous", true)
+	}
+	event.Msg("authorization denied")
+}
+
+// loadYaml builds a RolePolicy from YAML formatted data.
+func loadYaml(r io.Reader, logger zerolog.Logger, scope tally.Scope) (*RolePolicy, error) {
+	var file policyFile
+	if err := yaml.NewDecoder(r).Decode(&file); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	permissions := make(map[domain.Role]map[domain.Permission]bool, len(file.Roles))
+	for role, granted := range file.Roles {
+		permissions[role] = make(map[domain.Permission]bool, len(granted))
+		for _, permission := range granted {
+			if !knownPermissions[permission] {
+				return nil, fmt.Errorf("unknown permission '%s' granted to role '%s'", permission, role)
+			}
+			permissions[role][permission] = true
+		}
+	}
+
+	return &RolePolicy{permissions: permissions, logger: logger, scope: scope}, nil
+}
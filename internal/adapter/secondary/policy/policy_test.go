@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"f4allgo-restaurant/internal/core/domain"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally/v4"
+)
+
+func TestIsAllowed(t *testing.T) {
+	data := `roles:
+  anonymous:
+    - restaurant:read
+  manager:
+    - restaurant:read
+    - menu:update
+  admin:
+    - "*"
+`
+	p, err := loadYaml(strings.NewReader(data), zerolog.Nop(), nil)
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		name        string
+		identity    *domain.Identity
+		permission  domain.Permission
+		wantAllowed bool
+	}{
+		{
+			name:        "allow a granted permission",
+			identity:    domain.NewIdentity("user1", "tenant1", []domain.Role{domain.RoleManager}),
+			permission:  domain.PermissionUpdateMenu,
+			wantAllowed: true,
+		},
+		{
+			name:        "deny a permission not granted",
+			identity:    domain.NewIdentity("user1", "tenant1", []domain.Role{domain.RoleManager}),
+			permission:  domain.PermissionDeleteRestaurant,
+			wantAllowed: false,
+		},
+		{
+			name:        "allow a permission granted to any of the roles",
+			identity:    domain.NewIdentity("user1", "tenant1", []domain.Role{domain.RoleViewer, domain.RoleManager}),
+			permission:  domain.PermissionUpdateMenu,
+			wantAllowed: true,
+		},
+		{
+			name:        "allow any permission granted with a wildcard",
+			identity:    domain.NewIdentity("admin1", "", []domain.Role{domain.RoleAdmin}),
+			permission:  domain.PermissionDeleteRestaurant,
+			wantAllowed: true,
+		},
+		{
+			name:        "deny any permission to a caller without roles",
+			identity:    domain.NewIdentity("user1", "tenant1", []domain.Role{}),
+			permission:  domain.PermissionReadRestaurant,
+			wantAllowed: false,
+		},
+		{
+			name:        "allow a permission granted to anonymous callers",
+			identity:    nil,
+			permission:  domain.PermissionReadRestaurant,
+			wantAllowed: true,
+		},
+		{
+			name:        "deny a permission not granted to anonymous callers",
+			identity:    nil,
+			permission:  domain.PermissionCreateRestaurant,
+			wantAllowed: false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, err := p.IsAllowed(context.Background(), tc.identity, tc.permission)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantAllowed, allowed)
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	var logs bytes.Buffer
+	scope := tally.NewTestScope("", nil)
+	p, err := loadYaml(strings.NewReader("roles:\n  viewer:\n    - restaurant:read\n"), zerolog.New(&logs), scope)
+	assert.NoError(t, err)
+
+	viewer := domain.NewIdentity("user1", "tenant1", []domain.Role{domain.RoleViewer})
+	_, _ = p.IsAllowed(context.Background(), viewer, domain.PermissionReadRestaurant)
+	_, _ = p.IsAllowed(context.Background(), viewer, domain.PermissionUpdateMenu)
+
+	assert.Contains(t, logs.String(), `"permission":"menu:update","subject":"user1","tenant":"tenant1","roles":["viewer"]`)
+	assert.NotContains(t, logs.String(), "restaurant:read")
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["authorization_decisions+decision=allowed,permission=restaurant:read"].Value())
+	assert.Equal(t, int64(1), counters["authorization_decisions+decision=denied,permission=menu:update"].Value())
+}
+
+func TestLoadYaml(t *testing.T) {
+	testcases := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "load a valid file",
+			data: "roles:\n  viewer:\n    - restaurant:read\n",
+		},
+		{
+			name: "load an empty file",
+			data: "",
+		},
+		{
+			name:    "fail to load an unknown permission",
+			data:    "roles:\n  viewer:\n    - restaurant:reed\n",
+			wantErr: true,
+		},
+		{
+			name:    "fail to load a malformed file",
+			data:    "roles: [",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadYaml(strings.NewReader(tc.data), zerolog.Nop(), nil)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewYamlRolePolicy(t *testing.T) {
+	// The policy file shipped with the service.
+	p, err := NewYamlRolePolicy("../../../../configs/policy.yaml", zerolog.Nop(), nil)
+	assert.NoError(t, err)
+
+	manager := domain.NewIdentity("user1", "tenant1", []domain.Role{domain.RoleManager})
+	allowed, _ := p.IsAllowed(context.Background(), manager, domain.PermissionUpdateMenu)
+	assert.True(t, allowed)
+	allowed, _ = p.IsAllowed(context.Background(), manager, domain.PermissionCreateRestaurant)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	tally "github.com/uber-go/tally/v4"
+	"gorm.io/gorm"
+)
+
+// Postgres implementation of the secondary port AuditLogRepository. It uses GORM to
+// persist and retrieve audit entries from Postgres.
+type AuditLogPostgresRepository struct {
+	mapper    Mapper
+	db        *gorm.DB
+	ctxGetter *trmgorm.CtxGetter
+	timers    map[timerEnum]tally.Timer
+}
+
+// Interface compliance verification.
+var _ port.AuditLogRepository = (*AuditLogPostgresRepository)(nil)
+
+// Enumeration of timers for audit log repository operations.
+const (
+	saveAuditEntry timerEnum = iota
+	findAuditEntriesByRestaurant
+)
+
+func NewAuditLogPostgresRepository(db *gorm.DB, ctxGetter *trmgorm.CtxGetter, scope tally.Scope) *AuditLogPostgresRepository {
+	var timers map[timerEnum]tally.Timer
+	if scope != nil {
+		Save := scope.Tagged(map[string]string{"repository": "audit_log", "operation": "Save"}).Timer("repository_latencies")
+		FindByRestaurant := scope.Tagged(map[string]string{"repository": "audit_log", "operation": "FindByRestaurant"}).Timer("repository_latencies")
+
+		timers = make(map[timerEnum]tally.Timer)
+		timers[saveAuditEntry] = Save
+		timers[findAuditEntriesByRestaurant] = FindByRestaurant
+	}
+	return &AuditLogPostgresRepository{mapper: DefaultMapper{}, db: db, ctxGetter: ctxGetter, timers: timers}
+}
+
+// Save persists an audit entry in the database.
+func (r *AuditLogPostgresRepository) Save(ctx context.Context, entry *domain.AuditEntry) error {
+	entryDto := r.mapper.fromDomainAuditEntry(entry)
+	if err := r.executeWithTimer(saveAuditEntry, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Create(entryDto).Error
+	}); err != nil {
+		return err
+	}
+	entry.Id = entryDto.ID
+	return nil
+}
+
+// FindByRestaurant retrieves the audit entries of a restaurant, newest first.
+func (r *AuditLogPostgresRepository) FindByRestaurant(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.AuditEntry, int64, error) {
+	var entries []*AuditEntry
+	var total int64
+	if err := r.executeWithTimer(findAuditEntriesByRestaurant, func() error {
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ?", restaurantId).Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+			return err
+		}
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&AuditEntry{}).Where("restaurant_id = ?", restaurantId).Count(&total).Error
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return r.mapper.toDomainAuditEntries(entries), total, nil
+}
+
+// executeWithTimer executes a function using a tally timer if present.
+func (r *AuditLogPostgresRepository) executeWithTimer(t timerEnum, fn func() error) error {
+	if r.timers[t] != nil {
+		tsw := r.timers[t].Start()
+		defer tsw.Stop()
+	}
+	return fn()
+}
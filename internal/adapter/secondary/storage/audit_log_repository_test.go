@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndFindAuditEntries(t *testing.T) {
+	name := "Burger King"
+	identity := domain.NewIdentity("owner", "tenant1", nil)
+	metadata := domain.NewRequestMetadata("request1", domain.SourceRest)
+	created := domain.NewAuditEntry(1000, domain.AuditCreateRestaurant, identity, metadata, map[string]*domain.FieldChange{"name": domain.NewFieldChange(nil, &name)}, time.Now().Add(-time.Minute))
+	deleted := domain.NewAuditEntry(1000, domain.AuditDeleteRestaurant, identity, metadata, map[string]*domain.FieldChange{}, time.Now())
+
+	err := trManager.Do(context.Background(), func(ctx context.Context) error {
+		for _, entry := range []*domain.AuditEntry{created, deleted} {
+			assert.NoError(t, auditLogRepository.Save(ctx, entry))
+			assert.NotZero(t, entry.Id)
+		}
+
+		entries, total, err := auditLogRepository.FindByRestaurant(ctx, 1000, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, entries, 2)
+		// The newest entries come first.
+		assert.Equal(t, deleted.Id, entries[0].Id)
+		assert.Equal(t, created.Id, entries[1].Id)
+		assert.Equal(t, domain.AuditCreateRestaurant, entries[1].Operation)
+		assert.Equal(t, "owner", entries[1].Actor)
+		assert.Equal(t, "tenant1", entries[1].TenantId)
+		assert.Equal(t, "request1", entries[1].RequestId)
+		assert.Equal(t, domain.SourceRest, entries[1].Source)
+		assert.Nil(t, entries[1].Changes["name"].GetBefore())
+		assert.Equal(t, &name, entries[1].Changes["name"].GetAfter())
+		assert.WithinDuration(t, created.CreatedAt, entries[1].CreatedAt, time.Millisecond)
+
+		entries, total, err = auditLogRepository.FindByRestaurant(ctx, 1000, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, entries, 1)
+
+		entries, total, err = auditLogRepository.FindByRestaurant(ctx, 2000, 0, 10)
+		assert.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, entries)
+		return errors.New(ROLLBACK_PLEASE)
+	})
+	assert.Error(t, err)
+}
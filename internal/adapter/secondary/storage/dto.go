@@ -1,33 +1,287 @@
 package storage
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 // Restaurant is a Gorm DTO that carries the information of domain restaurants.
+// The menu version is maintained by the repository on every change of the menu,
+// so it's never written from the DTO. The dayparts are only read through the DTO
+// (they are replaced as a whole by the repository). The deletion instant makes Gorm
+// delete the restaurants softly and exclude the deleted ones from the queries, unless
+// they are unscoped.
 type Restaurant struct {
-	ID      int64
-	Name    string
-	Address *Address    `gorm:"embedded"`
-	Menu    []*MenuItem `gorm:"foreignKey:RestaurantID"`
+	ID                      int64
+	TenantID                string
+	Name                    string
+	DefaultLocale           string
+	TimeZone                string
+	Description             string
+	DescriptionTranslations map[string]string `gorm:"serializer:json"`
+	Address                 *Address          `gorm:"embedded"`
+	Menu                    []*MenuItem       `gorm:"foreignKey:RestaurantID"`
+	MenuVersion             int32             `gorm:"->"`
+	Dayparts                []*Daypart        `gorm:"foreignKey:RestaurantID"`
+	Version                 int64             `gorm:"->"`
+	DeletedAt               gorm.DeletedAt
 }
 
 func (Restaurant) TableName() string {
 	return "restaurant"
 }
 
+// SearchHit is a Gorm DTO that carries a restaurant matching a full-text search, as
+// found in the search index (see the restaurant_search table).
+type SearchHit struct {
+	RestaurantID int64
+	Rank         float32
+	Highlight    string
+}
+
 // Address is a Gorm DTO that carries the information of domain addresses.
 type Address struct {
-	Street string
-	City   string
-	State  string
-	Zip    string
+	Street    string
+	City      string
+	State     string
+	Zip       string
+	Latitude  *float64
+	Longitude *float64
 }
 
-// MenuItem is a Gorm DTO that carries the information of domain menu items
+// MenuItem is a Gorm DTO that carries the information of domain menu items. The
+// json tags define how the items are stored in the menu versions.
 type MenuItem struct {
-	RestaurantID int64 `gorm:"primaryKey"`
-	Id           int32 `gorm:"primaryKey"`
-	Name         string
-	Price        string
+	RestaurantID   int64                   `gorm:"primaryKey" json:"-"`
+	Id             int32                   `gorm:"primaryKey" json:"id"`
+	Name           string                  `json:"name"`
+	Description    string                  `json:"description,omitempty"`
+	Translations   map[string]*Translation `gorm:"serializer:json" json:"translations,omitempty"`
+	Price          string                  `json:"price"`
+	Available      bool                    `json:"available"`
+	AvailableUntil *time.Time              `json:"availableUntil,omitempty"`
+	Allergens      []string                `gorm:"serializer:json" json:"allergens,omitempty"`
+	DietaryTags    []string                `gorm:"serializer:json" json:"dietaryTags,omitempty"`
 }
 
 func (MenuItem) TableName() string {
 	return "menu_item"
 }
+
+// Translation is a DTO that carries the information of domain translations. It's
+// stored as JSON within the menu items.
+type Translation struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// MenuVersion is a Gorm DTO that carries an immutable snapshot of the menu of a
+// restaurant.
+type MenuVersion struct {
+	RestaurantID int64       `gorm:"primaryKey"`
+	Version      int32       `gorm:"primaryKey"`
+	ValidFrom    time.Time   `gorm:"default:CURRENT_TIMESTAMP"`
+	Items        []*MenuItem `gorm:"serializer:json"`
+}
+
+func (MenuVersion) TableName() string {
+	return "menu_version"
+}
+
+// Daypart is a Gorm DTO that carries the information of domain dayparts. The
+// position keeps the order in which the dayparts were provided.
+type Daypart struct {
+	RestaurantID int64  `gorm:"primaryKey"`
+	Name         string `gorm:"primaryKey"`
+	Position     int16
+	Windows      []*TimeWindow `gorm:"serializer:json"`
+	Items        []*MenuItem   `gorm:"serializer:json"`
+}
+
+func (Daypart) TableName() string {
+	return "daypart"
+}
+
+// TimeWindow is a DTO that carries the information of domain time windows (in
+// minutes since midnight). It's stored as JSON within the dayparts.
+type TimeWindow struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ScheduledMenu is a Gorm DTO that carries the information of domain scheduled menus.
+type ScheduledMenu struct {
+	ID            int64
+	RestaurantID  int64
+	EffectiveFrom time.Time
+	Items         []*MenuItem `gorm:"serializer:json"`
+}
+
+func (ScheduledMenu) TableName() string {
+	return "scheduled_menu"
+}
+
+// IdempotencyKey is a Gorm DTO that carries the information of domain idempotency
+// records.
+type IdempotencyKey struct {
+	Scope       string `gorm:"primaryKey"`
+	Key         string `gorm:"primaryKey"`
+	RequestHash string
+	Response    []byte
+	CreatedAt   time.Time
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_key"
+}
+
+// AuditEntry is a Gorm DTO that carries the information of domain audit entries.
+type AuditEntry struct {
+	ID           int64
+	RestaurantID int64
+	Operation    string
+	Actor        string
+	TenantID     string
+	Changes      map[string]*FieldChange `gorm:"serializer:json"`
+	RequestID    string
+	Source       string
+	CreatedAt    time.Time
+}
+
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// FieldChange is a DTO that carries the information of domain field changes. It's
+// stored as JSON within the audit entries.
+type FieldChange struct {
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// StoredEvent is a Gorm DTO that carries a domain event of the event store, which
+// records a change of an aggregate. The events of an aggregate are numbered by the
+// version the aggregate reaches with them, and by their position within the change
+// that raised them.
+type StoredEvent struct {
+	ID            int64
+	AggregateType string
+	AggregateID   int64
+	Version       int64
+	Position      int16
+	EventType     string
+	Payload       *EventPayload `gorm:"serializer:json"`
+	CreatedAt     time.Time
+}
+
+func (StoredEvent) TableName() string {
+	return "event_store"
+}
+
+// EventPayload is a DTO that carries the information of the domain events of a
+// restaurant. It's stored as JSON within the events, and every type of event only sets
+// the fields of the domain event it carries.
+type EventPayload struct {
+	Restaurant              *Restaurant       `json:"restaurant,omitempty"`
+	Name                    string            `json:"name,omitempty"`
+	Address                 *Address          `json:"address,omitempty"`
+	DefaultLocale           string            `json:"defaultLocale,omitempty"`
+	Description             string            `json:"description,omitempty"`
+	DescriptionTranslations map[string]string `json:"descriptionTranslations,omitempty"`
+	TimeZone                string            `json:"timeZone,omitempty"`
+	Menu                    []*MenuItem       `json:"menu,omitempty"`
+	Dayparts                []*Daypart        `json:"dayparts,omitempty"`
+	MenuItem                *MenuItem         `json:"menuItem,omitempty"`
+	MenuItemId              int32             `json:"menuItemId,omitempty"`
+	OldPrice                string            `json:"oldPrice,omitempty"`
+	NewPrice                string            `json:"newPrice,omitempty"`
+	Available               bool              `json:"available,omitempty"`
+	AvailableUntil          *time.Time        `json:"availableUntil,omitempty"`
+}
+
+// Snapshot is a Gorm DTO that carries the state of an aggregate at a version, so that
+// it can be rebuilt replaying only the events after it. Only the latest snapshot of
+// every aggregate is kept.
+type Snapshot struct {
+	AggregateType string `gorm:"primaryKey"`
+	AggregateID   int64  `gorm:"primaryKey"`
+	Version       int64
+	State         *RestaurantState `gorm:"serializer:json"`
+	CreatedAt     time.Time
+}
+
+func (Snapshot) TableName() string {
+	return "event_store_snapshot"
+}
+
+// RestaurantState is a DTO that carries the state of a restaurant as it's stored as
+// JSON within the snapshots. It's decoupled from the Restaurant DTO, so that the
+// snapshots don't change along with the tables, and the schema tells how it was
+// written, so that the snapshots taken with a previous one can still be read.
+type RestaurantState struct {
+	Schema                  int               `json:"schema"`
+	ID                      int64             `json:"id"`
+	TenantID                string            `json:"tenantId,omitempty"`
+	Name                    string            `json:"name"`
+	DefaultLocale           string            `json:"defaultLocale,omitempty"`
+	TimeZone                string            `json:"timeZone,omitempty"`
+	Description             string            `json:"description,omitempty"`
+	DescriptionTranslations map[string]string `json:"descriptionTranslations,omitempty"`
+	Address                 *AddressState     `json:"address,omitempty"`
+	Menu                    []*MenuItem       `json:"menu"`
+	MenuVersion             int32             `json:"menuVersion"`
+	Dayparts                []*DaypartState   `json:"dayparts"`
+	Version                 int64             `json:"version"`
+	DeletedAt               *time.Time        `json:"deletedAt,omitempty"`
+}
+
+// AddressState is a DTO that carries the address of a restaurant within its state.
+type AddressState struct {
+	Street    string   `json:"street"`
+	City      string   `json:"city"`
+	State     string   `json:"state"`
+	Zip       string   `json:"zip"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// DaypartState is a DTO that carries a daypart of a restaurant within its state, in
+// the order in which the dayparts were provided.
+type DaypartState struct {
+	Name    string        `json:"name"`
+	Windows []*TimeWindow `json:"windows"`
+	Items   []*MenuItem   `json:"items"`
+}
+
+// Ticket is a Gorm DTO that carries the information of domain tickets.
+type Ticket struct {
+	ID               int64
+	RestaurantID     int64
+	OrderID          int64
+	State            string
+	ReadyBy          *time.Time
+	CreatedAt        *time.Time
+	AcceptedAt       *time.Time
+	PreparingAt      *time.Time
+	ReadyForPickupAt *time.Time
+	PickedUpAt       *time.Time
+	CancelledAt      *time.Time
+	LineItems        []*TicketLineItem `gorm:"foreignKey:TicketID"`
+}
+
+func (Ticket) TableName() string {
+	return "ticket"
+}
+
+// TicketLineItem is a Gorm DTO that carries the information of domain ticket line items.
+type TicketLineItem struct {
+	TicketID   int64 `gorm:"primaryKey"`
+	MenuItemID int32 `gorm:"primaryKey"`
+	Name       string
+	Quantity   int32
+}
+
+func (TicketLineItem) TableName() string {
+	return "ticket_line_item"
+}
@@ -0,0 +1,810 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+	"fmt"
+	"reflect"
+	"time"
+
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	tally "github.com/uber-go/tally/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Event-sourced implementation of the secondary port RestaurantRepository. Every change
+// of a restaurant is decided against its current state and appended to its stream in
+// the event store (see the event_store table) as the domain events it raises, and the
+// restaurants are rebuilt replaying their events from their latest snapshot, which is
+// taken every few versions. The relational tables of the Postgres implementation are
+// the read model of the queries spanning several restaurants (the listings, the nearby
+// and full-text searches and the versions of the menus), which are delegated to it, and
+// they are written projecting the events as they are appended.
+//
+// The restaurants stored before the event store was adopted have no events, so they are
+// read from the read model, which becomes the first snapshot of their stream when they
+// are first changed. The events must be projected in the same transaction that appends
+// them, so like the rest of the operations changing a restaurant, the changes must run
+// within a transaction.
+type RestaurantEventSourcedRepository struct {
+	projection       *RestaurantPostgresRepository
+	mapper           Mapper
+	db               *gorm.DB
+	ctxGetter        *trmgorm.CtxGetter
+	snapshotInterval int64
+	timers           map[timerEnum]tally.Timer
+}
+
+// Interface compliance verification.
+var _ port.RestaurantRepository = (*RestaurantEventSourcedRepository)(nil)
+
+// Type of the aggregates whose events are recorded by the repository.
+const restaurantAggregate = "restaurant"
+
+// Schema of the states of the restaurants written to the snapshots.
+const restaurantStateSchema = 1
+
+// Types of the domain events recorded by the repository.
+const (
+	restaurantCreated            = "RestaurantCreated"
+	restaurantRenamed            = "RestaurantRenamed"
+	restaurantAddressChanged     = "RestaurantAddressChanged"
+	restaurantDescriptionChanged = "RestaurantDescriptionChanged"
+	restaurantTimeZoneChanged    = "RestaurantTimeZoneChanged"
+	restaurantMenuUpdated        = "RestaurantMenuUpdated"
+	restaurantDaypartsUpdated    = "RestaurantDaypartsUpdated"
+	menuItemAdded                = "MenuItemAdded"
+	menuItemUpdated              = "MenuItemUpdated"
+	menuItemPriceChanged         = "MenuItemPriceChanged"
+	menuItemAvailabilityChanged  = "MenuItemAvailabilityChanged"
+	menuItemRemoved              = "MenuItemRemoved"
+	restaurantDeleted            = "RestaurantDeleted"
+	restaurantRestored           = "RestaurantRestored"
+)
+
+// Enumeration of timers for event store operations.
+const (
+	loadStream timerEnum = iota
+	appendToStream
+	projectStream
+	snapshotStream
+)
+
+// NewRestaurantEventSourcedRepository creates the repository, taking a snapshot of the
+// restaurants every snapshotInterval versions (never if it's zero).
+func NewRestaurantEventSourcedRepository(db *gorm.DB, ctxGetter *trmgorm.CtxGetter, snapshotInterval int, scope tally.Scope) *RestaurantEventSourcedRepository {
+	var timers map[timerEnum]tally.Timer
+	if scope != nil {
+		Load := scope.Tagged(map[string]string{"repository": "event_store", "operation": "Load"}).Timer("repository_latencies")
+		Append := scope.Tagged(map[string]string{"repository": "event_store", "operation": "Append"}).Timer("repository_latencies")
+		Project := scope.Tagged(map[string]string{"repository": "event_store", "operation": "Project"}).Timer("repository_latencies")
+		Snapshot := scope.Tagged(map[string]string{"repository": "event_store", "operation": "Snapshot"}).Timer("repository_latencies")
+
+		timers = make(map[timerEnum]tally.Timer)
+		timers[loadStream] = Load
+		timers[appendToStream] = Append
+		timers[projectStream] = Project
+		timers[snapshotStream] = Snapshot
+	}
+	return &RestaurantEventSourcedRepository{
+		projection:       NewRestaurantPostgresRepository(db, ctxGetter, scope),
+		mapper:           DefaultMapper{},
+		db:               db,
+		ctxGetter:        ctxGetter,
+		snapshotInterval: int64(snapshotInterval),
+		timers:           timers,
+	}
+}
+
+// FindAll retrieves a page of the restaurants matching the query from the read model.
+func (r *RestaurantEventSourcedRepository) FindAll(ctx context.Context, query *domain.RestaurantQuery, pagination *domain.Pagination) (*domain.RestaurantPage, error) {
+	return r.projection.FindAll(ctx, query, pagination)
+}
+
+// FindById rebuilds a restaurant from its events. The menus are left out of the rebuilt
+// restaurant unless they are requested.
+func (r *RestaurantEventSourcedRepository) FindById(ctx context.Context, restaurantId int64, fetchMenu bool) (*domain.Restaurant, error) {
+	state, _, err := r.load(ctx, restaurantId)
+	if err != nil {
+		return nil, err
+	}
+	if state.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return r.toDomainRestaurant(state, fetchMenu), nil
+}
+
+// FindNearby retrieves the restaurants located within a radius of a point from the read
+// model.
+func (r *RestaurantEventSourcedRepository) FindNearby(ctx context.Context, point *domain.GeoPoint, radius float64, offset int, limit int) ([]*domain.Restaurant, int64, error) {
+	return r.projection.FindNearby(ctx, point, radius, offset, limit)
+}
+
+// Search retrieves the restaurants matching a full-text search from the read model.
+func (r *RestaurantEventSourcedRepository) Search(ctx context.Context, text string, offset int, limit int) ([]*domain.RestaurantSearchResult, int64, error) {
+	return r.projection.Search(ctx, text, offset, limit)
+}
+
+// FindMenuAt retrieves the version of a restaurant's menu that was in effect at a given
+// instant from the read model.
+func (r *RestaurantEventSourcedRepository) FindMenuAt(ctx context.Context, restaurantId int64, at time.Time) (*domain.Menu, error) {
+	return r.projection.FindMenuAt(ctx, restaurantId, at)
+}
+
+// FindMenuVersions retrieves the versions of a restaurant's menu from the read model.
+func (r *RestaurantEventSourcedRepository) FindMenuVersions(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.Menu, int64, error) {
+	return r.projection.FindMenuVersions(ctx, restaurantId, offset, limit)
+}
+
+// Save persists a restaurant in the read model, which generates its identifier, and
+// starts its stream with its creation. Like the read model, nothing is recorded if the
+// restaurant already existed.
+func (r *RestaurantEventSourcedRepository) Save(ctx context.Context, restaurant *domain.Restaurant) error {
+	inserted, err := r.projection.insert(ctx, restaurant)
+	if err != nil || !inserted {
+		return err
+	}
+	_, err = r.record(ctx, nil, restaurant.Id, restaurant.Version, []domain.DomainEvent{domain.NewRestaurantCreated(restaurant)})
+	return err
+}
+
+// Update records the replacement of the menu of a restaurant, along with the changes
+// of its name, address, description and time zone (the dayparts are kept).
+func (r *RestaurantEventSourcedRepository) Update(ctx context.Context, restaurant *domain.Restaurant) (int64, error) {
+	state, err := r.change(ctx, restaurant.Id, restaurant.Version, func(state *Restaurant) ([]domain.DomainEvent, error) {
+		events := r.profileChanges(state, restaurant)
+		return append(events, domain.NewRestaurantMenuUpdated(restaurant.Id, restaurant.Menu)), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	restaurant.Version = state.Version
+	if restaurant.Menu != nil {
+		restaurant.Menu = restaurant.Menu.WithVersion(state.MenuVersion, nil)
+	}
+	return 1, nil
+}
+
+// UpdateProfile records the changes of the name, address, description and time zone
+// of a restaurant. Nothing is recorded (and no rows are affected) if none of them
+// changed.
+func (r *RestaurantEventSourcedRepository) UpdateProfile(ctx context.Context, restaurant *domain.Restaurant) (int64, error) {
+	state, err := r.change(ctx, restaurant.Id, restaurant.Version, func(state *Restaurant) ([]domain.DomainEvent, error) {
+		return r.profileChanges(state, restaurant), nil
+	})
+	if err != nil || state == nil {
+		return 0, err
+	}
+
+	restaurant.Version = state.Version
+	return 1, nil
+}
+
+// UpdateDayparts records the replacement of all the dayparts of a restaurant.
+func (r *RestaurantEventSourcedRepository) UpdateDayparts(ctx context.Context, restaurantId int64, version int64, dayparts []*domain.Daypart) error {
+	_, err := r.change(ctx, restaurantId, version, func(*Restaurant) ([]domain.DomainEvent, error) {
+		return []domain.DomainEvent{domain.NewRestaurantDaypartsUpdated(restaurantId, dayparts)}, nil
+	})
+	return err
+}
+
+// SaveMenuItem records the addition of a single menu item to a restaurant.
+func (r *RestaurantEventSourcedRepository) SaveMenuItem(ctx context.Context, restaurantId int64, version int64, menuItem *domain.MenuItem) error {
+	_, err := r.change(ctx, restaurantId, version, func(state *Restaurant) ([]domain.DomainEvent, error) {
+		if findMenuItem(state.Menu, int32(menuItem.GetId())) != nil {
+			return nil, fmt.Errorf("menu item %d already exists in restaurant %d", menuItem.GetId(), restaurantId)
+		}
+		return []domain.DomainEvent{domain.NewMenuItemAdded(restaurantId, menuItem)}, nil
+	})
+	return err
+}
+
+// UpdateMenuItem records the changes of a single menu item of a restaurant: the change
+// of its price and its availability on their own, and the update of the item for the
+// rest of its details (or if nothing changed, so that a new version of the menu is
+// still written). Nothing is recorded if the item doesn't exist.
+func (r *RestaurantEventSourcedRepository) UpdateMenuItem(ctx context.Context, restaurantId int64, version int64, menuItem *domain.MenuItem) (int64, error) {
+	state, err := r.change(ctx, restaurantId, version, func(state *Restaurant) ([]domain.DomainEvent, error) {
+		current := findMenuItem(state.Menu, int32(menuItem.GetId()))
+		if current == nil {
+			return nil, nil
+		}
+		previous := r.mapper.toDomainMenu([]*MenuItem{current}).GetItem(menuItem.GetId())
+		updated := r.mapper.fromDomainMenuItem(menuItem)
+
+		var events []domain.DomainEvent
+		if previous.GetPrice().Cmp(menuItem.GetPrice()) != 0 {
+			events = append(events, domain.NewMenuItemPriceChanged(restaurantId, menuItem.GetId(), previous.GetPrice(), menuItem.GetPrice()))
+		}
+		if current.Available != updated.Available || !sameInstant(current.AvailableUntil, updated.AvailableUntil) {
+			events = append(events, domain.NewMenuItemAvailabilityChanged(restaurantId, menuItem))
+		}
+		if len(events) == 0 || !sameDetails(current, updated) {
+			events = append(events, domain.NewMenuItemUpdated(restaurantId, menuItem))
+		}
+		return events, nil
+	})
+	if err != nil || state == nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// DeleteMenuItem records the removal of a single menu item of a restaurant. Nothing is
+// recorded if the item doesn't exist.
+func (r *RestaurantEventSourcedRepository) DeleteMenuItem(ctx context.Context, restaurantId int64, version int64, menuItemId int16) (int64, error) {
+	state, err := r.change(ctx, restaurantId, version, func(state *Restaurant) ([]domain.DomainEvent, error) {
+		if findMenuItem(state.Menu, int32(menuItemId)) == nil {
+			return nil, nil
+		}
+		return []domain.DomainEvent{domain.NewMenuItemRemoved(restaurantId, menuItemId)}, nil
+	})
+	if err != nil || state == nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// Delete records the deletion of a restaurant, which happens at the instant its event
+// is recorded.
+func (r *RestaurantEventSourcedRepository) Delete(ctx context.Context, restaurantId int64, version int64) (int64, error) {
+	if _, err := r.change(ctx, restaurantId, version, func(*Restaurant) ([]domain.DomainEvent, error) {
+		return []domain.DomainEvent{domain.NewRestaurantDeleted(restaurantId)}, nil
+	}); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// FindDeletedById rebuilds a deleted restaurant from its events, without its menus.
+func (r *RestaurantEventSourcedRepository) FindDeletedById(ctx context.Context, restaurantId int64) (*domain.Restaurant, error) {
+	state, _, err := r.load(ctx, restaurantId)
+	if err != nil {
+		return nil, err
+	}
+	if !state.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return r.toDomainRestaurant(state, false), nil
+}
+
+// Restore records the restoration of a deleted restaurant if it's still at the given
+// version. Like the read model, it returns zero rows if the restaurant isn't deleted
+// and domain.ErrConcurrentModification if it's at another version.
+func (r *RestaurantEventSourcedRepository) Restore(ctx context.Context, restaurantId int64, version int64) (int64, error) {
+	state, stored, err := r.load(ctx, restaurantId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !state.DeletedAt.Valid {
+		return 0, nil
+	}
+	if state.Version != version {
+		return 0, domain.ErrConcurrentModification
+	}
+
+	if !stored {
+		if err := r.snapshot(ctx, state); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := r.record(ctx, state, restaurantId, version+1, []domain.DomainEvent{domain.NewRestaurantRestored(restaurantId)}); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// Purge removes the streams (the events and the snapshot) of the restaurants deleted
+// before an instant, and then the restaurants from the read model.
+func (r *RestaurantEventSourcedRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).
+		Exec("DELETE FROM event_store WHERE aggregate_type = ? AND aggregate_id IN (SELECT id FROM restaurant WHERE deleted_at < ?)", restaurantAggregate, before).Error; err != nil {
+		return 0, err
+	}
+	if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).
+		Exec("DELETE FROM event_store_snapshot WHERE aggregate_type = ? AND aggregate_id IN (SELECT id FROM restaurant WHERE deleted_at < ?)", restaurantAggregate, before).Error; err != nil {
+		return 0, err
+	}
+	return r.projection.Purge(ctx, before)
+}
+
+// change records the domain events of a change of a restaurant that is still at the
+// given version, which are decided from its current state, and returns the resulting
+// state (nil if the change raised no events, so nothing was recorded). It returns
+// domain.ErrRestaurantNotFound if the restaurant doesn't exist (or it's deleted), and
+// domain.ErrConcurrentModification if it's at another version.
+func (r *RestaurantEventSourcedRepository) change(ctx context.Context, restaurantId int64, version int64, decide func(state *Restaurant) ([]domain.DomainEvent, error)) (*Restaurant, error) {
+	state, stored, err := r.load(ctx, restaurantId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrRestaurantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if state.DeletedAt.Valid {
+		return nil, domain.ErrRestaurantNotFound
+	}
+	if state.Version != version {
+		return nil, domain.ErrConcurrentModification
+	}
+
+	events, err := decide(state)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+
+	// The restaurants that aren't in the event store yet start their stream with a
+	// snapshot of the state they had in the read model.
+	if !stored {
+		if err := r.snapshot(ctx, state); err != nil {
+			return nil, err
+		}
+	}
+	return r.record(ctx, state, restaurantId, version+1, events)
+}
+
+// record appends the domain events of a change of a restaurant (in the given state, or
+// nil if they create it) that takes it to the given version, projects them to the read
+// model and takes a snapshot of the resulting state if it's due. It returns the
+// resulting state, and domain.ErrConcurrentModification if another change appended
+// the same version concurrently.
+func (r *RestaurantEventSourcedRepository) record(ctx context.Context, state *Restaurant, restaurantId int64, version int64, events []domain.DomainEvent) (*Restaurant, error) {
+	// The instants are kept at the precision of the database, so that the restaurants
+	// rebuilt from the events match the read model.
+	now := time.Now().Truncate(time.Microsecond)
+	storedEvents := make([]*StoredEvent, 0, len(events))
+	for i, event := range events {
+		payload, err := r.fromDomainEvent(restaurantId, event)
+		if err != nil {
+			return nil, err
+		}
+		storedEvents = append(storedEvents, &StoredEvent{AggregateType: restaurantAggregate, AggregateID: restaurantId, Version: version,
+			Position: int16(i), EventType: event.GetType(), Payload: payload, CreatedAt: now})
+	}
+	if err := r.append(ctx, storedEvents); err != nil {
+		return nil, err
+	}
+
+	state, err := replay(state, storedEvents)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.project(ctx, state, storedEvents); err != nil {
+		return nil, err
+	}
+	if r.snapshotInterval > 0 && version%r.snapshotInterval == 0 {
+		if err := r.snapshot(ctx, state); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// load rebuilds the current state of a restaurant (deleted or not) replaying the events
+// appended after its latest snapshot, and tells if it's recorded in the event store.
+// The restaurants without a stream are read from the read model instead, returning
+// gorm.ErrRecordNotFound if they don't exist.
+func (r *RestaurantEventSourcedRepository) load(ctx context.Context, restaurantId int64) (*Restaurant, bool, error) {
+	var snapshots []*Snapshot
+	var events []*StoredEvent
+	var state *Restaurant
+	if err := r.executeWithTimer(loadStream, func() error {
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("aggregate_type = ? AND aggregate_id = ?", restaurantAggregate, restaurantId).
+			Find(&snapshots).Error; err != nil {
+			return err
+		}
+		var version int64
+		if len(snapshots) > 0 {
+			var err error
+			if state, err = fromRestaurantState(snapshots[0].State); err != nil {
+				return err
+			}
+			version = snapshots[0].Version
+		}
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("aggregate_type = ? AND aggregate_id = ? AND version > ?", restaurantAggregate, restaurantId, version).
+			Order("version ASC, position ASC").Find(&events).Error; err != nil {
+			return err
+		}
+		if state == nil && len(events) == 0 {
+			return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Unscoped().Preload("Menu").Preload("Dayparts", byPosition).First(&state, restaurantId).Error
+		}
+		return nil
+	}); err != nil {
+		return nil, false, err
+	}
+	if len(snapshots) == 0 && len(events) == 0 {
+		return state, false, nil
+	}
+
+	state, err := replay(state, events)
+	if err != nil {
+		return nil, false, err
+	}
+	return state, true, nil
+}
+
+// append appends the events of a change to the event store unless an event with the
+// same version was already appended to the stream (concurrently), returning
+// domain.ErrConcurrentModification in that case.
+func (r *RestaurantEventSourcedRepository) append(ctx context.Context, events []*StoredEvent) error {
+	return r.executeWithTimer(appendToStream, func() error {
+		result := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(events)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < int64(len(events)) {
+			return domain.ErrConcurrentModification
+		}
+		return nil
+	})
+}
+
+// project writes the events of a change of a restaurant to the read model, which
+// reaches the version of the restaurant after them (given its resulting state), along
+// with a new version of its menu if any of them changes it. The creations aren't
+// projected, since the read model generates the identifiers of the restaurants before
+// their streams start. The read model must still be at the version preceding the
+// events, returning domain.ErrConcurrentModification otherwise: the restaurants without
+// a stream are changed as they are read from it, so nothing else prevents a concurrent
+// change from being overwritten.
+func (r *RestaurantEventSourcedRepository) project(ctx context.Context, state *Restaurant, events []*StoredEvent) error {
+	return r.executeWithTimer(projectStream, func() error {
+		db := r.ctxGetter.DefaultTrOrDB(ctx, r.db)
+		var menuChanged bool
+		for _, event := range events {
+			payload := event.Payload
+			var err error
+			switch event.EventType {
+			case restaurantCreated:
+				continue
+			case restaurantRenamed:
+				err = db.Model(&Restaurant{ID: state.ID}).Update("name", payload.Name).Error
+			case restaurantAddressChanged:
+				err = db.Model(&Restaurant{ID: state.ID}).Updates(map[string]interface{}{
+					"street":    payload.Address.Street,
+					"city":      payload.Address.City,
+					"state":     payload.Address.State,
+					"zip":       payload.Address.Zip,
+					"latitude":  payload.Address.Latitude,
+					"longitude": payload.Address.Longitude,
+				}).Error
+			case restaurantDescriptionChanged:
+				err = db.Model(&Restaurant{ID: state.ID}).Updates(map[string]interface{}{
+					"default_locale":           payload.DefaultLocale,
+					"description":              payload.Description,
+					"description_translations": toJSON(payload.DescriptionTranslations),
+				}).Error
+			case restaurantTimeZoneChanged:
+				err = db.Model(&Restaurant{ID: state.ID}).Update("time_zone", payload.TimeZone).Error
+			case restaurantMenuUpdated:
+				if err = db.Where("restaurant_id = ?", state.ID).Delete(&MenuItem{}).Error; err == nil && len(payload.Menu) > 0 {
+					err = db.Create(withRestaurantId(state.ID, payload.Menu...)).Error
+				}
+			case restaurantDaypartsUpdated:
+				if err = db.Where("restaurant_id = ?", state.ID).Delete(&Daypart{}).Error; err == nil && len(payload.Dayparts) > 0 {
+					err = db.Create(payload.Dayparts).Error
+				}
+			case menuItemAdded:
+				err = db.Create(withRestaurantId(state.ID, payload.MenuItem)).Error
+			case menuItemUpdated:
+				menuItem := withRestaurantId(state.ID, payload.MenuItem)[0]
+				err = db.Model(menuItem).Select("Name", "Description", "Translations", "Price", "Available", "AvailableUntil", "Allergens", "DietaryTags").Updates(menuItem).Error
+			case menuItemPriceChanged:
+				err = db.Model(&MenuItem{RestaurantID: state.ID, Id: payload.MenuItemId}).Update("price", payload.NewPrice).Error
+			case menuItemAvailabilityChanged:
+				err = db.Model(&MenuItem{RestaurantID: state.ID, Id: payload.MenuItemId}).Updates(map[string]interface{}{
+					"available":       payload.Available,
+					"available_until": payload.AvailableUntil,
+				}).Error
+			case menuItemRemoved:
+				err = db.Where("restaurant_id = ? AND id = ?", state.ID, payload.MenuItemId).Delete(&MenuItem{}).Error
+			case restaurantDeleted:
+				err = db.Model(&Restaurant{ID: state.ID}).Update("deleted_at", event.CreatedAt).Error
+			case restaurantRestored:
+				err = db.Unscoped().Model(&Restaurant{ID: state.ID}).Update("deleted_at", nil).Error
+			default:
+				err = fmt.Errorf("unknown event %s at version %d of restaurant %d", event.EventType, event.Version, event.AggregateID)
+			}
+			if err != nil {
+				return err
+			}
+			menuChanged = menuChanged || changesMenu(event)
+		}
+		if events[0].EventType == restaurantCreated {
+			return nil
+		}
+
+		result := db.Exec("UPDATE restaurant SET version = ? WHERE id = ? AND version = ?", state.Version, state.ID, events[0].Version-1)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrConcurrentModification
+		}
+		if menuChanged {
+			_, err := r.projection.saveMenuVersion(ctx, state.ID)
+			return err
+		}
+		return nil
+	})
+}
+
+// snapshot replaces the snapshot of a restaurant with its current state.
+func (r *RestaurantEventSourcedRepository) snapshot(ctx context.Context, state *Restaurant) error {
+	return r.executeWithTimer(snapshotStream, func() error {
+		snapshot := &Snapshot{AggregateType: restaurantAggregate, AggregateID: state.ID, Version: state.Version, State: toRestaurantState(state)}
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(snapshot).Error
+	})
+}
+
+// toDomainRestaurant maps the state of a restaurant into a domain restaurant as the read
+// model returns it: without menus unless they are requested, and with empty menus
+// (instead of none) otherwise.
+func (r *RestaurantEventSourcedRepository) toDomainRestaurant(state *Restaurant, fetchMenu bool) *domain.Restaurant {
+	restaurant := *state
+	if !fetchMenu {
+		restaurant.Menu, restaurant.Dayparts = nil, nil
+	} else {
+		if restaurant.Menu == nil {
+			restaurant.Menu = []*MenuItem{}
+		}
+		if restaurant.Dayparts == nil {
+			restaurant.Dayparts = []*Daypart{}
+		}
+	}
+	return r.mapper.toDomainRestaurant(&restaurant)
+}
+
+// fromDomainEvent maps a domain event of a restaurant into the payload it's stored with.
+func (r *RestaurantEventSourcedRepository) fromDomainEvent(restaurantId int64, event domain.DomainEvent) (*EventPayload, error) {
+	payload := &EventPayload{}
+	switch e := event.(type) {
+	case *domain.RestaurantCreated:
+		payload.Restaurant = r.mapper.fromDomainRestaurant(e.Restaurant)
+	case *domain.RestaurantRenamed:
+		payload.Name = e.Name
+	case *domain.RestaurantAddressChanged:
+		payload.Address = r.mapper.fromDomainAddress(e.Address)
+	case *domain.RestaurantDescriptionChanged:
+		payload.DefaultLocale, payload.Description, payload.DescriptionTranslations = e.DefaultLocale, e.Description, e.Translations
+	case *domain.RestaurantTimeZoneChanged:
+		payload.TimeZone = e.TimeZone
+	case *domain.RestaurantMenuUpdated:
+		payload.Menu = r.mapper.fromDomainMenu(e.Menu)
+	case *domain.RestaurantDaypartsUpdated:
+		payload.Dayparts = r.mapper.fromDomainDayparts(restaurantId, e.Dayparts)
+	case *domain.MenuItemAdded:
+		payload.MenuItem = r.mapper.fromDomainMenuItem(e.MenuItem)
+	case *domain.MenuItemUpdated:
+		payload.MenuItem = r.mapper.fromDomainMenuItem(e.MenuItem)
+	case *domain.MenuItemPriceChanged:
+		payload.MenuItemId, payload.OldPrice, payload.NewPrice = int32(e.MenuItemId), e.OldPrice.Text('f', 2), e.NewPrice.Text('f', 2)
+	case *domain.MenuItemAvailabilityChanged:
+		payload.MenuItemId, payload.Available, payload.AvailableUntil = int32(e.MenuItemId), e.Available, e.AvailableUntil
+	case *domain.MenuItemRemoved:
+		payload.MenuItemId = int32(e.MenuItemId)
+	case *domain.RestaurantDeleted, *domain.RestaurantRestored:
+	default:
+		return nil, fmt.Errorf("unsupported event %s of restaurant %d", event.GetType(), restaurantId)
+	}
+	return payload, nil
+}
+
+// profileChanges returns the domain events raised by the changes of the name, address,
+// description and time zone of a restaurant in the given state.
+func (r *RestaurantEventSourcedRepository) profileChanges(state *Restaurant, restaurant *domain.Restaurant) []domain.DomainEvent {
+	profile := r.mapper.fromDomainRestaurant(restaurant)
+	var events []domain.DomainEvent
+	if profile.Name != state.Name {
+		events = append(events, domain.NewRestaurantRenamed(restaurant.Id, restaurant.Name))
+	}
+	if !reflect.DeepEqual(profile.Address, state.Address) {
+		events = append(events, domain.NewRestaurantAddressChanged(restaurant.Id, restaurant.Address))
+	}
+	if profile.DefaultLocale != state.DefaultLocale || profile.Description != state.Description ||
+		!reflect.DeepEqual(profile.DescriptionTranslations, state.DescriptionTranslations) {
+		events = append(events, domain.NewRestaurantDescriptionChanged(restaurant.Id, profile.DefaultLocale, profile.Description, profile.DescriptionTranslations))
+	}
+	if profile.TimeZone != state.TimeZone {
+		events = append(events, domain.NewRestaurantTimeZoneChanged(restaurant.Id, profile.TimeZone))
+	}
+	return events
+}
+
+// executeWithTimer executes a function using a tally timer if present.
+func (r *RestaurantEventSourcedRepository) executeWithTimer(t timerEnum, fn func() error) error {
+	if r.timers[t] != nil {
+		tsw := r.timers[t].Start()
+		defer tsw.Stop()
+	}
+	return fn()
+}
+
+// replay applies the events of a restaurant to its state (nil before its creation) and
+// returns the resulting state. The changes of the menu increase its version once per
+// change, as the read model does when it writes a new version of the menu.
+func replay(state *Restaurant, events []*StoredEvent) (*Restaurant, error) {
+	var menuChangedAt int64
+	for _, event := range events {
+		var err error
+		if state, err = apply(state, event); err != nil {
+			return nil, err
+		}
+		if changesMenu(event) && menuChangedAt != event.Version {
+			state.MenuVersion++
+			menuChangedAt = event.Version
+		}
+	}
+	return state, nil
+}
+
+// apply applies an event to the state of a restaurant (nil before its creation) and
+// returns the resulting state.
+func apply(state *Restaurant, event *StoredEvent) (*Restaurant, error) {
+	if (state == nil) != (event.EventType == restaurantCreated) {
+		return nil, fmt.Errorf("unexpected event %s at version %d of restaurant %d", event.EventType, event.Version, event.AggregateID)
+	}
+	payload := event.Payload
+	switch event.EventType {
+	case restaurantCreated:
+		state = payload.Restaurant
+	case restaurantRenamed:
+		state.Name = payload.Name
+	case restaurantAddressChanged:
+		state.Address = payload.Address
+	case restaurantDescriptionChanged:
+		state.DefaultLocale, state.Description, state.DescriptionTranslations = payload.DefaultLocale, payload.Description, payload.DescriptionTranslations
+	case restaurantTimeZoneChanged:
+		state.TimeZone = payload.TimeZone
+	case restaurantMenuUpdated:
+		state.Menu = payload.Menu
+	case restaurantDaypartsUpdated:
+		state.Dayparts = payload.Dayparts
+	case menuItemAdded:
+		state.Menu = append(state.Menu, payload.MenuItem)
+	case menuItemUpdated:
+		for i, item := range state.Menu {
+			if item.Id == payload.MenuItem.Id {
+				state.Menu[i] = payload.MenuItem
+			}
+		}
+	case menuItemPriceChanged:
+		if item := findMenuItem(state.Menu, payload.MenuItemId); item != nil {
+			item.Price = payload.NewPrice
+		}
+	case menuItemAvailabilityChanged:
+		if item := findMenuItem(state.Menu, payload.MenuItemId); item != nil {
+			item.Available, item.AvailableUntil = payload.Available, payload.AvailableUntil
+		}
+	case menuItemRemoved:
+		menu := make([]*MenuItem, 0, len(state.Menu))
+		for _, item := range state.Menu {
+			if item.Id != payload.MenuItemId {
+				menu = append(menu, item)
+			}
+		}
+		state.Menu = menu
+	case restaurantDeleted:
+		state.DeletedAt = gorm.DeletedAt{Time: event.CreatedAt, Valid: true}
+	case restaurantRestored:
+		state.DeletedAt = gorm.DeletedAt{}
+	default:
+		return nil, fmt.Errorf("unknown event %s at version %d of restaurant %d", event.EventType, event.Version, event.AggregateID)
+	}
+	state.Version = event.Version
+	return state, nil
+}
+
+// toRestaurantState maps the state of a restaurant into the state its snapshots store.
+func toRestaurantState(restaurant *Restaurant) *RestaurantState {
+	state := &RestaurantState{
+		Schema:                  restaurantStateSchema,
+		ID:                      restaurant.ID,
+		TenantID:                restaurant.TenantID,
+		Name:                    restaurant.Name,
+		DefaultLocale:           restaurant.DefaultLocale,
+		TimeZone:                restaurant.TimeZone,
+		Description:             restaurant.Description,
+		DescriptionTranslations: restaurant.DescriptionTranslations,
+		Menu:                    restaurant.Menu,
+		MenuVersion:             restaurant.MenuVersion,
+		Version:                 restaurant.Version,
+	}
+	if address := restaurant.Address; address != nil {
+		state.Address = &AddressState{Street: address.Street, City: address.City, State: address.State, Zip: address.Zip,
+			Latitude: address.Latitude, Longitude: address.Longitude}
+	}
+	for _, daypart := range restaurant.Dayparts {
+		state.Dayparts = append(state.Dayparts, &DaypartState{Name: daypart.Name, Windows: daypart.Windows, Items: daypart.Items})
+	}
+	if restaurant.DeletedAt.Valid {
+		deletedAt := restaurant.DeletedAt.Time
+		state.DeletedAt = &deletedAt
+	}
+	return state
+}
+
+// fromRestaurantState maps the state stored in the snapshot of a restaurant into its
+// state, returning an error if it was written with an unknown schema.
+func fromRestaurantState(state *RestaurantState) (*Restaurant, error) {
+	if state == nil || state.Schema != restaurantStateSchema {
+		return nil, errors.New("unsupported schema of the snapshot of a restaurant")
+	}
+	restaurant := &Restaurant{
+		ID:                      state.ID,
+		TenantID:                state.TenantID,
+		Name:                    state.Name,
+		DefaultLocale:           state.DefaultLocale,
+		TimeZone:                state.TimeZone,
+		Description:             state.Description,
+		DescriptionTranslations: state.DescriptionTranslations,
+		Menu:                    withRestaurantId(state.ID, state.Menu...),
+		MenuVersion:             state.MenuVersion,
+		Version:                 state.Version,
+	}
+	if address := state.Address; address != nil {
+		restaurant.Address = &Address{Street: address.Street, City: address.City, State: address.State, Zip: address.Zip,
+			Latitude: address.Latitude, Longitude: address.Longitude}
+	}
+	for i, daypart := range state.Dayparts {
+		restaurant.Dayparts = append(restaurant.Dayparts, &Daypart{RestaurantID: state.ID, Name: daypart.Name, Position: int16(i),
+			Windows: daypart.Windows, Items: daypart.Items})
+	}
+	if state.DeletedAt != nil {
+		restaurant.DeletedAt = gorm.DeletedAt{Time: *state.DeletedAt, Valid: true}
+	}
+	return restaurant, nil
+}
+
+// changesMenu tells if an event changes the menu of a restaurant once it's created.
+func changesMenu(event *StoredEvent) bool {
+	switch event.EventType {
+	case restaurantMenuUpdated, menuItemAdded, menuItemUpdated, menuItemPriceChanged, menuItemAvailabilityChanged, menuItemRemoved:
+		return true
+	}
+	return false
+}
+
+// findMenuItem returns the item of a menu with the given identifier, or nil if there's
+// none.
+func findMenuItem(menu []*MenuItem, menuItemId int32) *MenuItem {
+	for _, item := range menu {
+		if item.Id == menuItemId {
+			return item
+		}
+	}
+	return nil
+}
+
+// withRestaurantId sets the restaurant of menu items read from the events, which don't
+// carry it.
+func withRestaurantId(restaurantId int64, menuItems ...*MenuItem) []*MenuItem {
+	for _, item := range menuItems {
+		item.RestaurantID = restaurantId
+	}
+	return menuItems
+}
+
+// sameDetails tells if two menu items have the same details, other than their price
+// and availability.
+func sameDetails(a *MenuItem, b *MenuItem) bool {
+	return a.Name == b.Name && a.Description == b.Description && reflect.DeepEqual(a.Translations, b.Translations) &&
+		reflect.DeepEqual(a.Allergens, b.Allergens) && reflect.DeepEqual(a.DietaryTags, b.DietaryTags)
+}
+
+// sameInstant tells if two optional instants are both absent or the same.
+func sameInstant(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
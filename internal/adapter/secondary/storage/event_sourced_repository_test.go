@@ -0,0 +1,538 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"f4allgo-restaurant/internal/boot"
+	"f4allgo-restaurant/internal/core/domain"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewRestaurantEventSourcedRepository(t *testing.T) {
+	rr := NewRestaurantEventSourcedRepository(db, trmgorm.DefaultCtxGetter, 10, nil)
+	assert.Equal(t, DefaultMapper{}, rr.mapper)
+	assert.Equal(t, int64(10), rr.snapshotInterval)
+	assert.NotNil(t, rr.projection)
+	assert.Nil(t, rr.timers)
+
+	rr = NewRestaurantEventSourcedRepository(db, trmgorm.DefaultCtxGetter, 10, boot.GetTallyScope())
+	assert.NotNil(t, rr.timers)
+	assert.NotNil(t, rr.projection.timers)
+}
+
+// TestEventSourcedRestaurantRepository runs the tests of the restaurant repository
+// against the event-sourced implementation, taking a snapshot every two versions (the
+// cases simulating errors keep using the mocked Postgres repository).
+func TestEventSourcedRestaurantRepository(t *testing.T) {
+	postgresRepository := restaurantRepository
+	restaurantRepository = NewRestaurantEventSourcedRepository(db, trmgorm.DefaultCtxGetter, 2, boot.GetTallyScope())
+	defer func() { restaurantRepository = postgresRepository }()
+
+	t.Run("FindAll", TestFindAll)
+	t.Run("FindAllWithCursors", TestFindAllWithCursors)
+	t.Run("FindById", TestFindById)
+	t.Run("FindByIdWithTranslations", TestFindByIdWithTranslations)
+	t.Run("FindNearby", TestFindNearby)
+	t.Run("Search", TestSearch)
+	t.Run("FindMenuAt", TestFindMenuAt)
+	t.Run("FindMenuVersions", TestFindMenuVersions)
+	t.Run("Save", TestSave)
+	t.Run("Update", TestUpdate)
+	t.Run("UpdateProfile", TestUpdateProfile)
+	t.Run("UpdateDayparts", TestUpdateDayparts)
+	t.Run("SaveMenuItem", TestSaveMenuItem)
+	t.Run("UpdateMenuItem", TestUpdateMenuItem)
+	t.Run("DeleteMenuItem", TestDeleteMenuItem)
+	t.Run("Delete", TestDelete)
+	t.Run("Restore", TestRestore)
+	t.Run("Purge", TestPurge)
+}
+
+func TestReplayFromSnapshots(t *testing.T) {
+	repository := NewRestaurantEventSourcedRepository(db, trmgorm.DefaultCtxGetter, 3, nil)
+
+	err := trManager.Do(context.Background(), func(ctx context.Context) error {
+		// The restaurant isn't in the event store yet, so its first change starts the
+		// stream with a snapshot of the read model.
+		restaurant, err := repository.FindById(ctx, 1000, true)
+		assert.NoError(t, err)
+		assert.NoError(t, repository.SaveMenuItem(ctx, 1000, 1, domain.NewMenuItem(4, "item1.4", big.NewFloat(16.17))))
+		assertStream(t, ctx, 1000, 1, []int64{2})
+
+		restaurant.Name = "A different name"
+		restaurant.Version = 2
+		rowsAffected, err := repository.UpdateProfile(ctx, restaurant)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rowsAffected)
+		rowsAffected, err = repository.DeleteMenuItem(ctx, 1000, 3, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rowsAffected)
+		assertStream(t, ctx, 1000, 3, []int64{2, 3, 4})
+
+		// The rebuilt restaurant matches the read model.
+		replayed, err := repository.FindById(ctx, 1000, true)
+		assert.NoError(t, err)
+		projected, err := repository.projection.FindById(ctx, 1000, true)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), replayed.Version)
+		assert.Equal(t, projected.Version, replayed.Version)
+		assert.Equal(t, projected.Menu.GetVersion(), replayed.Menu.GetVersion())
+		assert.Equal(t, projected.Snapshot(), replayed.Snapshot())
+		assert.Equal(t, "A different name", replayed.Name)
+		assert.Nil(t, replayed.Menu.GetItem(1))
+		assert.NotNil(t, replayed.Menu.GetItem(4))
+
+		return errors.New(ROLLBACK_PLEASE)
+	})
+	assert.Error(t, err)
+}
+
+func TestRecordDomainEvents(t *testing.T) {
+	repository := NewRestaurantEventSourcedRepository(db, trmgorm.DefaultCtxGetter, 0, nil)
+
+	err := trManager.Do(context.Background(), func(ctx context.Context) error {
+		// Saving a restaurant that already exists doesn't start its stream again.
+		existing := mapper.toDomainRestaurant(newTestRestaurant())
+		existing.Version = 5
+		assert.NoError(t, repository.Save(ctx, existing))
+		assert.Equal(t, int64(5), existing.Version)
+		assertEventTypes(t, ctx, 1000, []string{})
+
+		// A change is recorded as the domain events it raises, all of them taking the
+		// restaurant to the same version.
+		until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		menuItem := domain.NewMenuItem(2, "item1.2", big.NewFloat(20)).WithAvailability(false, &until)
+		rowsAffected, err := repository.UpdateMenuItem(ctx, 1000, 1, menuItem)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rowsAffected)
+		assertEventTypes(t, ctx, 1000, []string{"MenuItemPriceChanged", "MenuItemAvailabilityChanged"})
+
+		restaurant, err := repository.FindById(ctx, 1000, true)
+		assert.NoError(t, err)
+		restaurant.Name = "A different name"
+		restaurant.TimeZone = "Europe/Madrid"
+		rowsAffected, err = repository.UpdateProfile(ctx, restaurant)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rowsAffected)
+		assert.Equal(t, int64(3), restaurant.Version)
+		assertEventTypes(t, ctx, 1000, []string{"MenuItemPriceChanged", "MenuItemAvailabilityChanged", "RestaurantRenamed", "RestaurantTimeZoneChanged"})
+
+		// Nothing is recorded if nothing changed.
+		rowsAffected, err = repository.UpdateProfile(ctx, restaurant)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), rowsAffected)
+		assert.Equal(t, int64(3), restaurant.Version)
+
+		// The read model is projected from the events.
+		replayed, err := repository.FindById(ctx, 1000, true)
+		assert.NoError(t, err)
+		projected, err := repository.projection.FindById(ctx, 1000, true)
+		assert.NoError(t, err)
+		assert.Equal(t, projected.Version, replayed.Version)
+		assert.Equal(t, int32(3), replayed.Menu.GetVersion())
+		assert.Equal(t, projected.Menu.GetVersion(), replayed.Menu.GetVersion())
+		assert.Equal(t, projected.Snapshot(), replayed.Snapshot())
+		assert.Equal(t, "20.00", projected.Menu.GetItem(2).GetPrice().Text('f', 2))
+		assert.False(t, projected.Menu.GetItem(2).IsAvailable())
+
+		return errors.New(ROLLBACK_PLEASE)
+	})
+	assert.Error(t, err)
+}
+
+func TestAppendConcurrently(t *testing.T) {
+	repository := NewRestaurantEventSourcedRepository(db, trmgorm.DefaultCtxGetter, 0, nil)
+	newEvents := func() []*StoredEvent {
+		return []*StoredEvent{
+			{AggregateType: restaurantAggregate, AggregateID: 1000, Version: 2, Position: 0, EventType: restaurantRenamed, Payload: &EventPayload{Name: "name"}},
+			{AggregateType: restaurantAggregate, AggregateID: 1000, Version: 2, Position: 1, EventType: restaurantTimeZoneChanged, Payload: &EventPayload{TimeZone: "UTC"}},
+		}
+	}
+
+	err := trManager.Do(context.Background(), func(ctx context.Context) error {
+		assert.NoError(t, repository.append(ctx, newEvents()))
+		err := repository.append(ctx, newEvents())
+		assert.ErrorIs(t, err, domain.ErrConcurrentModification)
+
+		return errors.New(ROLLBACK_PLEASE)
+	})
+	assert.Error(t, err)
+}
+
+func TestReplay(t *testing.T) {
+	now := time.Now()
+	created := &Restaurant{ID: 1000, Name: "restaurant1", Menu: []*MenuItem{{Id: 1, Name: "item1.1", Price: "12.13", Available: true}}, MenuVersion: 1, Version: 1}
+	events := []*StoredEvent{
+		{AggregateID: 1000, Version: 1, EventType: restaurantCreated, Payload: &EventPayload{Restaurant: created}},
+		{AggregateID: 1000, Version: 2, Position: 0, EventType: menuItemPriceChanged, Payload: &EventPayload{MenuItemId: 1, OldPrice: "12.13", NewPrice: "14.15"}},
+		{AggregateID: 1000, Version: 2, Position: 1, EventType: menuItemAvailabilityChanged, Payload: &EventPayload{MenuItemId: 1, AvailableUntil: &now}},
+		{AggregateID: 1000, Version: 3, EventType: restaurantRenamed, Payload: &EventPayload{Name: "A different name"}},
+		{AggregateID: 1000, Version: 4, EventType: restaurantDeleted, Payload: &EventPayload{}, CreatedAt: now},
+	}
+
+	state, err := replay(nil, events)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), state.Version)
+	// The changes of the menu write a single version of it per change.
+	assert.Equal(t, int32(2), state.MenuVersion)
+	assert.Equal(t, "14.15", state.Menu[0].Price)
+	assert.False(t, state.Menu[0].Available)
+	assert.Equal(t, "A different name", state.Name)
+	assert.True(t, state.DeletedAt.Valid)
+
+	_, err = replay(nil, events[1:])
+	assert.Error(t, err)
+	_, err = replay(state, []*StoredEvent{{AggregateID: 1000, Version: 5, EventType: "Unknown", Payload: &EventPayload{}}})
+	assert.Error(t, err)
+}
+
+func TestChangeWithMockedDB(t *testing.T) {
+	restaurantRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "tenant_id", "name", "default_locale", "time_zone", "street", "city", "state", "zip", "menu_version", "version"}).
+			AddRow(1000, "tenant1", "restaurant1", "en", "UTC", "street1", "city1", "state1", "zip1", 2, 1)
+	}
+	snapshotRows := func() *sqlmock.Rows {
+		state, _ := json.Marshal(toRestaurantState(newTestRestaurantWithoutMenu()))
+		return sqlmock.NewRows([]string{"aggregate_type", "aggregate_id", "version", "state"}).AddRow(restaurantAggregate, 1000, 1, state)
+	}
+	testcases := []struct {
+		name             string
+		mockExpectations func(sqlmock.Sqlmock)
+		wantVersion      int64
+		wantErr          error
+		wantErrMsg       string
+	}{
+		{
+			name: "start the stream of a restaurant of the read model with a snapshot",
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				// The read model is snapshotted before the change, and the resulting
+				// state is snapshotted again at the second version.
+				mock.ExpectQuery(`SELECT .+ FROM "event_store_snapshot" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectQuery(`SELECT .+ FROM "event_store" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectQuery(`SELECT .+ FROM "restaurant" .+`).WillReturnRows(restaurantRows())
+				mock.ExpectQuery(`SELECT .+ FROM "daypart" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectQuery(`SELECT .+ FROM "menu_item" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "event_store_snapshot" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "event_store" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "restaurant" SET "name"=.+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("UPDATE restaurant SET version .+").WithArgs(2, 1000, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "event_store_snapshot" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantVersion: 2,
+		},
+		{
+			name: "change a restaurant rebuilt from its snapshot",
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .+ FROM "event_store_snapshot" .+`).WillReturnRows(snapshotRows())
+				mock.ExpectQuery(`SELECT .+ FROM "event_store" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "event_store" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "restaurant" SET "name"=.+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("UPDATE restaurant SET version .+").WithArgs(2, 1000, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "event_store_snapshot" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantVersion: 2,
+		},
+		{
+			name: "simulate a version appended concurrently",
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .+ FROM "event_store_snapshot" .+`).WillReturnRows(snapshotRows())
+				mock.ExpectQuery(`SELECT .+ FROM "event_store" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "event_store" .+`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantErr: domain.ErrConcurrentModification,
+		},
+		{
+			name: "simulate a read model changed concurrently",
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .+ FROM "event_store_snapshot" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectQuery(`SELECT .+ FROM "event_store" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectQuery(`SELECT .+ FROM "restaurant" .+`).WillReturnRows(restaurantRows())
+				mock.ExpectQuery(`SELECT .+ FROM "daypart" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectQuery(`SELECT .+ FROM "menu_item" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "event_store_snapshot" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "event_store" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "restaurant" SET "name"=.+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("UPDATE restaurant SET version .+").WithArgs(2, 1000, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: domain.ErrConcurrentModification,
+		},
+		{
+			name: "simulate error when loading a restaurant",
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .+ FROM "event_store_snapshot" .+`).WillReturnError(errors.New("error#1"))
+			},
+			wantErrMsg: "error#1",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository, trm, mock := createMockEventSourcedRepository(2)
+			tc.mockExpectations(mock)
+			restaurant := mapper.toDomainRestaurant(newTestRestaurantWithoutMenu())
+			restaurant.Name = "A different name"
+
+			err := trm.Do(context.Background(), func(ctx context.Context) error {
+				rowsAffected, err := repository.UpdateProfile(ctx, restaurant)
+				switch {
+				case tc.wantErr != nil:
+					assert.ErrorIs(t, err, tc.wantErr)
+				case len(tc.wantErrMsg) > 0:
+					assert.EqualError(t, err, tc.wantErrMsg)
+				default:
+					assert.NoError(t, err)
+					assert.Equal(t, int64(1), rowsAffected)
+					assert.Equal(t, tc.wantVersion, restaurant.Version)
+				}
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProjectWithMockedDB(t *testing.T) {
+	state := newTestRestaurant()
+	state.Version = 3
+	testcases := []struct {
+		name             string
+		events           []*StoredEvent
+		mockExpectations func(sqlmock.Sqlmock)
+		wantErr          error
+		wantErrMsg       string
+	}{
+		{
+			name:             "project the creation of a restaurant",
+			events:           []*StoredEvent{{AggregateID: 1000, Version: 1, EventType: restaurantCreated, Payload: &EventPayload{Restaurant: state}}},
+			mockExpectations: func(mock sqlmock.Sqlmock) {},
+		},
+		{
+			name: "project a change of the profile of a restaurant",
+			events: []*StoredEvent{
+				{AggregateID: 1000, Version: 3, Position: 0, EventType: restaurantRenamed, Payload: &EventPayload{Name: "A different name"}},
+				{AggregateID: 1000, Version: 3, Position: 1, EventType: restaurantTimeZoneChanged, Payload: &EventPayload{TimeZone: "Europe/Madrid"}},
+			},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "restaurant" SET "name"=.+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "restaurant" SET "time_zone"=.+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("UPDATE restaurant SET version .+").WithArgs(3, 1000, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:   "project a change of the menu of a restaurant",
+			events: []*StoredEvent{{AggregateID: 1000, Version: 3, EventType: menuItemRemoved, Payload: &EventPayload{MenuItemId: 1}}},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM "menu_item" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("UPDATE restaurant SET version .+").WithArgs(3, 1000, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("UPDATE restaurant SET menu_version .+").WillReturnRows(sqlmock.NewRows([]string{"menu_version"}).AddRow(3))
+				mock.ExpectQuery(`SELECT .+ FROM "menu_item" .+`).WillReturnRows(sqlmock.NewRows([]string{"restaurant_id", "id", "name", "price"}).
+					AddRow(1000, 2, "item1.2", "14.15"))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "menu_version" .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "simulate a read model changed concurrently",
+			events: []*StoredEvent{{AggregateID: 1000, Version: 3, EventType: restaurantDeleted, Payload: &EventPayload{}}},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "restaurant" SET "deleted_at"=.+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("UPDATE restaurant SET version .+").WithArgs(3, 1000, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: domain.ErrConcurrentModification,
+		},
+		{
+			name:   "simulate error when projecting an event",
+			events: []*StoredEvent{{AggregateID: 1000, Version: 3, EventType: restaurantRenamed, Payload: &EventPayload{Name: "A different name"}}},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "restaurant" SET "name"=.+`).WillReturnError(errors.New("error#2"))
+				mock.ExpectRollback()
+			},
+			wantErrMsg: "error#2",
+		},
+		{
+			name:   "simulate error when updating the version of the read model",
+			events: []*StoredEvent{{AggregateID: 1000, Version: 3, EventType: restaurantRestored, Payload: &EventPayload{}}},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "restaurant" SET "deleted_at"=.+`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("UPDATE restaurant SET version .+").WillReturnError(errors.New("error#3"))
+			},
+			wantErrMsg: "error#3",
+		},
+		{
+			name:             "simulate an unknown event",
+			events:           []*StoredEvent{{AggregateID: 1000, Version: 3, EventType: "Unknown", Payload: &EventPayload{}}},
+			mockExpectations: func(mock sqlmock.Sqlmock) {},
+			wantErrMsg:       "unknown event Unknown at version 3 of restaurant 1000",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository, _, mock := createMockEventSourcedRepository(0)
+			tc.mockExpectations(mock)
+			err := repository.project(context.Background(), state, tc.events)
+			switch {
+			case tc.wantErr != nil:
+				assert.ErrorIs(t, err, tc.wantErr)
+			case len(tc.wantErrMsg) > 0:
+				assert.EqualError(t, err, tc.wantErrMsg)
+			default:
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurgeWithMockedDB(t *testing.T) {
+	testcases := []struct {
+		name             string
+		mockExpectations func(sqlmock.Sqlmock)
+		wantPurged       int64
+		wantErrMsg       string
+	}{
+		{
+			name: "purge the streams and the read model of the deleted restaurants",
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM event_store WHERE .+").WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec("DELETE FROM event_store_snapshot WHERE .+").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM menu_item .+").WillReturnResult(sqlmock.NewResult(0, 6))
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM "restaurant" .+`).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			wantPurged: 2,
+		},
+		{
+			name: "simulate error when purging the events",
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM event_store WHERE .+").WillReturnError(errors.New("error#4"))
+			},
+			wantErrMsg: "error#4",
+		},
+		{
+			name: "simulate error when purging the snapshots",
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM event_store WHERE .+").WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec("DELETE FROM event_store_snapshot WHERE .+").WillReturnError(errors.New("error#5"))
+			},
+			wantErrMsg: "error#5",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository, _, mock := createMockEventSourcedRepository(0)
+			tc.mockExpectations(mock)
+			purged, err := repository.Purge(context.Background(), time.Now())
+			if len(tc.wantErrMsg) > 0 {
+				assert.EqualError(t, err, tc.wantErrMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantPurged, purged)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRestaurantState(t *testing.T) {
+	latitude, longitude := 40.4, -3.7
+	deletedAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	restaurant := &Restaurant{ID: 1000, TenantID: "tenant1", Name: "restaurant1", DefaultLocale: "en", TimeZone: "UTC",
+		Description: "description", DescriptionTranslations: map[string]string{"es": "descripción"},
+		Address:     &Address{Street: "street1", City: "city1", State: "state1", Zip: "zip1", Latitude: &latitude, Longitude: &longitude},
+		Menu:        []*MenuItem{{RestaurantID: 1000, Id: 1, Name: "item1.1", Price: "12.13", Available: true}},
+		MenuVersion: 2,
+		Dayparts:    []*Daypart{{RestaurantID: 1000, Name: "lunch", Position: 0, Windows: []*TimeWindow{{Start: 720, End: 960}}}},
+		Version:     3,
+		DeletedAt:   gorm.DeletedAt{Time: deletedAt, Valid: true},
+	}
+
+	// The state is stored as JSON, and read back as it was.
+	data, err := json.Marshal(toRestaurantState(restaurant))
+	assert.NoError(t, err)
+	var state *RestaurantState
+	assert.NoError(t, json.Unmarshal(data, &state))
+	assert.Equal(t, restaurantStateSchema, state.Schema)
+	read, err := fromRestaurantState(state)
+	assert.NoError(t, err)
+	assert.Equal(t, restaurant, read)
+
+	state.Schema = restaurantStateSchema + 1
+	_, err = fromRestaurantState(state)
+	assert.Error(t, err)
+}
+
+// assertStream checks the version of the snapshot and the versions of the events
+// recorded for a restaurant.
+func assertStream(t *testing.T, ctx context.Context, restaurantId int64, wantSnapshotVersion int64, wantEventVersions []int64) {
+	var snapshot *Snapshot
+	assert.NoError(t, trmgorm.DefaultCtxGetter.DefaultTrOrDB(ctx, db).Where("aggregate_id = ?", restaurantId).First(&snapshot).Error)
+	assert.Equal(t, wantSnapshotVersion, snapshot.Version)
+
+	var versions []int64
+	assert.NoError(t, trmgorm.DefaultCtxGetter.DefaultTrOrDB(ctx, db).Model(&StoredEvent{}).Where("aggregate_id = ?", restaurantId).
+		Order("version ASC").Pluck("version", &versions).Error)
+	assert.Equal(t, wantEventVersions, versions)
+}
+
+// assertEventTypes checks the types of the events recorded for a restaurant, in order.
+func assertEventTypes(t *testing.T, ctx context.Context, restaurantId int64, wantEventTypes []string) {
+	eventTypes := []string{}
+	assert.NoError(t, trmgorm.DefaultCtxGetter.DefaultTrOrDB(ctx, db).Model(&StoredEvent{}).Where("aggregate_id = ?", restaurantId).
+		Order("version ASC, position ASC").Pluck("event_type", &eventTypes).Error)
+	assert.Equal(t, wantEventTypes, eventTypes)
+}
+
+// createMockEventSourcedRepository creates an event-sourced repository that uses a
+// sqlmock connection as the underlying database connection, as createMockRepository
+// does for the Postgres repository.
+func createMockEventSourcedRepository(snapshotInterval int) (*RestaurantEventSourcedRepository, trm.Manager, sqlmock.Sqlmock) {
+	projection, trManager, mock := createMockRepository()
+	return NewRestaurantEventSourcedRepository(projection.db, trmgorm.DefaultCtxGetter, snapshotInterval, nil), trManager, mock
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+	"time"
+
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	tally "github.com/uber-go/tally/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Postgres implementation of the secondary port IdempotencyKeyRepository. It uses
+// GORM to persist and retrieve idempotency records from Postgres.
+type IdempotencyKeyPostgresRepository struct {
+	mapper    Mapper
+	db        *gorm.DB
+	ctxGetter *trmgorm.CtxGetter
+	timers    map[timerEnum]tally.Timer
+}
+
+// Interface compliance verification.
+var _ port.IdempotencyKeyRepository = (*IdempotencyKeyPostgresRepository)(nil)
+
+// Enumeration of timers for idempotency key repository operations.
+const (
+	findIdempotencyKey timerEnum = iota
+	saveIdempotencyKey
+	purgeIdempotencyKey
+)
+
+func NewIdempotencyKeyPostgresRepository(db *gorm.DB, ctxGetter *trmgorm.CtxGetter, scope tally.Scope) *IdempotencyKeyPostgresRepository {
+	var timers map[timerEnum]tally.Timer
+	if scope != nil {
+		Find := scope.Tagged(map[string]string{"repository": "idempotency_key", "operation": "Find"}).Timer("repository_latencies")
+		Save := scope.Tagged(map[string]string{"repository": "idempotency_key", "operation": "Save"}).Timer("repository_latencies")
+		Purge := scope.Tagged(map[string]string{"repository": "idempotency_key", "operation": "Purge"}).Timer("repository_latencies")
+
+		timers = make(map[timerEnum]tally.Timer)
+		timers[findIdempotencyKey] = Find
+		timers[saveIdempotencyKey] = Save
+		timers[purgeIdempotencyKey] = Purge
+	}
+	return &IdempotencyKeyPostgresRepository{mapper: DefaultMapper{}, db: db, ctxGetter: ctxGetter, timers: timers}
+}
+
+// Find retrieves the record of an idempotency key in a scope, or nil if there's none.
+func (r *IdempotencyKeyPostgresRepository) Find(ctx context.Context, scope string, key string) (*domain.IdempotencyRecord, error) {
+	var idempotencyKeys []*IdempotencyKey
+	if err := r.executeWithTimer(findIdempotencyKey, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("scope = ? AND key = ?", scope, key).Limit(1).Find(&idempotencyKeys).Error
+	}); err != nil {
+		return nil, err
+	}
+	if len(idempotencyKeys) == 0 {
+		return nil, nil
+	}
+
+	return r.mapper.toDomainIdempotencyRecord(idempotencyKeys[0]), nil
+}
+
+// Save inserts the record of an idempotency key, ignoring it if the key already
+// exists in its scope. An insert concurrent with another transaction inserting the
+// same key waits for it to finish, so the key is only saved once.
+func (r *IdempotencyKeyPostgresRepository) Save(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	var result *gorm.DB
+	if err := r.executeWithTimer(saveIdempotencyKey, func() error {
+		result = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(r.mapper.fromDomainIdempotencyRecord(record))
+		return result.Error
+	}); err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Purge removes the records of the idempotency keys created before an instant.
+func (r *IdempotencyKeyPostgresRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var rowsAffected int64
+	if err := r.executeWithTimer(purgeIdempotencyKey, func() error {
+		result := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("created_at < ?", before).Delete(&IdempotencyKey{})
+		rowsAffected = result.RowsAffected
+		return result.Error
+	}); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// executeWithTimer executes a function using a tally timer if present.
+func (r *IdempotencyKeyPostgresRepository) executeWithTimer(t timerEnum, fn func() error) error {
+	if r.timers[t] != nil {
+		tsw := r.timers[t].Start()
+		defer tsw.Stop()
+	}
+	return fn()
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndFindIdempotencyKey(t *testing.T) {
+	key, _ := domain.NewIdempotencyKey("key1", "hash1")
+	record := domain.NewIdempotencyRecord("user1", key, []byte(`{"restaurantId":1000}`), time.Now())
+
+	err := trManager.Do(context.Background(), func(ctx context.Context) error {
+		found, err := idempotencyKeyRepository.Find(ctx, "user1", "key1")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+
+		saved, err := idempotencyKeyRepository.Save(ctx, record)
+		assert.NoError(t, err)
+		assert.True(t, saved)
+
+		found, err = idempotencyKeyRepository.Find(ctx, "user1", "key1")
+		assert.NoError(t, err)
+		assert.Equal(t, "hash1", found.GetKey().GetRequestHash())
+		assert.Equal(t, record.GetResponse(), found.GetResponse())
+		assert.WithinDuration(t, record.GetCreatedAt(), found.GetCreatedAt(), time.Millisecond)
+
+		// Keys are scoped by caller.
+		found, err = idempotencyKeyRepository.Find(ctx, "user2", "key1")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+
+		saved, err = idempotencyKeyRepository.Save(ctx, record)
+		assert.NoError(t, err)
+		assert.False(t, saved)
+		return errors.New(ROLLBACK_PLEASE)
+	})
+	assert.Error(t, err)
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+	key, _ := domain.NewIdempotencyKey("key1", "hash1")
+	anotherKey, _ := domain.NewIdempotencyKey("key2", "hash2")
+
+	err := trManager.Do(context.Background(), func(ctx context.Context) error {
+		_, err := idempotencyKeyRepository.Save(ctx, domain.NewIdempotencyRecord("user1", key, []byte("{}"), time.Now().Add(-48*time.Hour)))
+		assert.NoError(t, err)
+		_, err = idempotencyKeyRepository.Save(ctx, domain.NewIdempotencyRecord("user1", anotherKey, []byte("{}"), time.Now()))
+		assert.NoError(t, err)
+
+		purged, err := idempotencyKeyRepository.Purge(ctx, time.Now().Add(-24*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		found, err := idempotencyKeyRepository.Find(ctx, "user1", "key1")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+		found, err = idempotencyKeyRepository.Find(ctx, "user1", "key2")
+		assert.NoError(t, err)
+		assert.NotNil(t, found)
+		return errors.New(ROLLBACK_PLEASE)
+	})
+	assert.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"math/big"
 
 	"f4allgo-restaurant/internal/core/domain"
+
+	"gorm.io/gorm"
 )
 
 type Mapper interface {
@@ -20,6 +22,9 @@ type Mapper interface {
 	// fromDomainMenu maps a domain.Menu struct into a Menu.
 	fromDomainMenu(menu *domain.Menu) []*MenuItem
 
+	// fromDomainMenuItem maps a domain.MenuItem struct into a MenuItem.
+	fromDomainMenuItem(menuItem *domain.MenuItem) *MenuItem
+
 	// toDomainRestaurant maps a Restaurant struct into a domain.Restaurant.
 	toDomainRestaurant(restaurantDto *Restaurant) *domain.Restaurant
 
@@ -31,6 +36,45 @@ type Mapper interface {
 
 	// toDomainMenu maps a Menu struct into a domain.Menu.
 	toDomainMenu(menuItems []*MenuItem) *domain.Menu
+
+	// toDomainMenuVersion maps a MenuVersion struct into a versioned domain.Menu.
+	toDomainMenuVersion(menuVersion *MenuVersion) *domain.Menu
+
+	// toDomainMenuVersions maps a slice of MenuVersion into a slice of versioned domain.Menu.
+	toDomainMenuVersions(menuVersions []*MenuVersion) []*domain.Menu
+
+	// fromDomainDayparts maps a slice of domain.Daypart of a restaurant into a slice of Daypart.
+	fromDomainDayparts(restaurantId int64, dayparts []*domain.Daypart) []*Daypart
+
+	// toDomainDayparts maps a slice of Daypart into a slice of domain.Daypart.
+	toDomainDayparts(dayparts []*Daypart) []*domain.Daypart
+
+	// fromDomainScheduledMenu maps a domain.ScheduledMenu struct into a ScheduledMenu.
+	fromDomainScheduledMenu(scheduledMenu *domain.ScheduledMenu) *ScheduledMenu
+
+	// toDomainScheduledMenus maps a slice of ScheduledMenu into a slice of domain.ScheduledMenu.
+	toDomainScheduledMenus(scheduledMenus []*ScheduledMenu) []*domain.ScheduledMenu
+
+	// fromDomainIdempotencyRecord maps a domain.IdempotencyRecord struct into an IdempotencyKey.
+	fromDomainIdempotencyRecord(record *domain.IdempotencyRecord) *IdempotencyKey
+
+	// toDomainIdempotencyRecord maps an IdempotencyKey struct into a domain.IdempotencyRecord.
+	toDomainIdempotencyRecord(idempotencyKey *IdempotencyKey) *domain.IdempotencyRecord
+
+	// fromDomainAuditEntry maps a domain.AuditEntry struct into an AuditEntry.
+	fromDomainAuditEntry(entry *domain.AuditEntry) *AuditEntry
+
+	// toDomainAuditEntries maps a slice of AuditEntry into a slice of domain.AuditEntry.
+	toDomainAuditEntries(entries []*AuditEntry) []*domain.AuditEntry
+
+	// fromDomainTicket maps a domain.Ticket struct into a Ticket.
+	fromDomainTicket(ticket *domain.Ticket) *Ticket
+
+	// toDomainTicket maps a Ticket struct into a domain.Ticket.
+	toDomainTicket(ticketDto *Ticket) *domain.Ticket
+
+	// toDomainTickets maps a slice of Ticket into a slice of domain.Ticket.
+	toDomainTickets(tickets []*Ticket) []*domain.Ticket
 }
 
 // DefaultMapper is the default implementation of Mapper.
@@ -44,7 +88,25 @@ func (dm DefaultMapper) fromDomainRestaurant(restaurant *domain.Restaurant) *Res
 	if restaurant == nil {
 		return nil
 	}
-	return &Restaurant{ID: restaurant.Id, Name: restaurant.Name, Address: dm.fromDomainAddress(restaurant.Address), Menu: dm.fromDomainMenu(restaurant.Menu)}
+	restaurantDto := &Restaurant{ID: restaurant.Id, TenantID: restaurant.TenantId, Name: restaurant.Name, Address: dm.fromDomainAddress(restaurant.Address), Menu: dm.fromDomainMenu(restaurant.Menu)}
+	restaurantDto.DefaultLocale = restaurant.DefaultLocale
+	if restaurantDto.DefaultLocale == "" {
+		restaurantDto.DefaultLocale = domain.DefaultLocale
+	}
+	restaurantDto.TimeZone = restaurant.TimeZone
+	if restaurantDto.TimeZone == "" {
+		restaurantDto.TimeZone = domain.DefaultTimeZone
+	}
+	restaurantDto.Description = restaurant.Description
+	restaurantDto.DescriptionTranslations = restaurant.DescriptionTranslations
+	if restaurant.Menu != nil {
+		restaurantDto.MenuVersion = restaurant.Menu.GetVersion()
+	}
+	restaurantDto.Version = restaurant.Version
+	if restaurant.DeletedAt != nil {
+		restaurantDto.DeletedAt = gorm.DeletedAt{Time: *restaurant.DeletedAt, Valid: true}
+	}
+	return restaurantDto
 }
 
 func (dm DefaultMapper) fromDomainRestaurants(restaurants []*domain.Restaurant) []*Restaurant {
@@ -63,30 +125,67 @@ func (DefaultMapper) fromDomainAddress(address *domain.Address) *Address {
 	if address == nil {
 		return nil
 	}
-	return &Address{Street: address.Street(), City: address.City(), State: address.State(), Zip: address.Zip()}
+	dtoAddress := &Address{Street: address.Street(), City: address.City(), State: address.State(), Zip: address.Zip()}
+	if location := address.Location(); location != nil {
+		latitude, longitude := location.Latitude(), location.Longitude()
+		dtoAddress.Latitude = &latitude
+		dtoAddress.Longitude = &longitude
+	}
+	return dtoAddress
 }
 
-func (DefaultMapper) fromDomainMenu(menu *domain.Menu) []*MenuItem {
+func (dm DefaultMapper) fromDomainMenu(menu *domain.Menu) []*MenuItem {
 	if menu == nil {
 		return nil
 	}
 	dtoItems := []*MenuItem{}
 	for _, item := range menu.GetItems() {
-		dtoItems = append(dtoItems, &MenuItem{Id: int32(item.GetId()), Name: item.GetName(), Price: item.GetPrice().Text('f', 2)})
+		dtoItems = append(dtoItems, dm.fromDomainMenuItem(item))
 	}
 
 	return dtoItems
 }
 
+func (DefaultMapper) fromDomainMenuItem(item *domain.MenuItem) *MenuItem {
+	if item == nil {
+		return nil
+	}
+	return &MenuItem{
+		Id:             int32(item.GetId()),
+		Name:           item.GetName(),
+		Description:    item.GetDescription(),
+		Translations:   fromDomainTranslations(item.GetTranslations()),
+		Price:          item.GetPrice().Text('f', 2),
+		Available:      item.IsAvailable(),
+		AvailableUntil: item.GetAvailableUntil(),
+		Allergens:      fromDomainAllergens(item.GetAllergens()),
+		DietaryTags:    fromDomainDietaryTags(item.GetDietaryTags()),
+	}
+}
+
 func (dm DefaultMapper) toDomainRestaurant(restaurantDto *Restaurant) *domain.Restaurant {
 	if restaurantDto == nil {
 		return nil
 	}
 	domainRestaurant := domain.Restaurant{}
 	domainRestaurant.Id = restaurantDto.ID
+	domainRestaurant.TenantId = restaurantDto.TenantID
 	domainRestaurant.Name = restaurantDto.Name
+	domainRestaurant.DefaultLocale = restaurantDto.DefaultLocale
+	domainRestaurant.TimeZone = restaurantDto.TimeZone
+	domainRestaurant.Description = restaurantDto.Description
+	domainRestaurant.DescriptionTranslations = restaurantDto.DescriptionTranslations
 	domainRestaurant.Address = dm.toDomainAddress(restaurantDto.Address)
 	domainRestaurant.Menu = dm.toDomainMenu(restaurantDto.Menu)
+	if domainRestaurant.Menu != nil {
+		domainRestaurant.Menu = domainRestaurant.Menu.WithVersion(restaurantDto.MenuVersion, nil)
+	}
+	domainRestaurant.Dayparts = dm.toDomainDayparts(restaurantDto.Dayparts)
+	domainRestaurant.Version = restaurantDto.Version
+	if restaurantDto.DeletedAt.Valid {
+		deletedAt := restaurantDto.DeletedAt.Time
+		domainRestaurant.DeletedAt = &deletedAt
+	}
 
 	return &domainRestaurant
 }
@@ -107,11 +206,17 @@ func (DefaultMapper) toDomainAddress(address *Address) *domain.Address {
 	if address == nil {
 		return nil
 	}
-	return domain.NewAddress(
+	domainAddress := domain.NewAddress(
 		address.Street,
 		address.City,
 		address.State,
 		address.Zip)
+	if address.Latitude != nil && address.Longitude != nil {
+		if location, err := domain.NewGeoPoint(*address.Latitude, *address.Longitude); err == nil {
+			domainAddress = domainAddress.WithLocation(location)
+		}
+	}
+	return domainAddress
 }
 
 func (DefaultMapper) toDomainMenu(menuItems []*MenuItem) *domain.Menu {
@@ -122,8 +227,304 @@ func (DefaultMapper) toDomainMenu(menuItems []*MenuItem) *domain.Menu {
 	for _, item := range menuItems {
 		f := new(big.Float)
 		f.SetString(item.Price)
-		domainItems = append(domainItems, domain.NewMenuItem(int16(item.Id), item.Name, f))
+		domainItem := domain.NewMenuItem(int16(item.Id), item.Name, f).
+			WithAvailability(item.Available, item.AvailableUntil).
+			WithLabels(toDomainAllergens(item.Allergens), toDomainDietaryTags(item.DietaryTags)).
+			WithTexts(item.Description, toDomainTranslations(item.Translations))
+		domainItems = append(domainItems, domainItem)
 	}
 
 	return domain.NewMenu(domainItems)
 }
+
+func (dm DefaultMapper) fromDomainDayparts(restaurantId int64, dayparts []*domain.Daypart) []*Daypart {
+	if dayparts == nil {
+		return nil
+	}
+	dtoDayparts := []*Daypart{}
+	for i, daypart := range dayparts {
+		windows := []*TimeWindow{}
+		for _, window := range daypart.GetWindows() {
+			windows = append(windows, &TimeWindow{Start: window.GetStart(), End: window.GetEnd()})
+		}
+		dtoDayparts = append(dtoDayparts, &Daypart{
+			RestaurantID: restaurantId,
+			Name:         daypart.GetName(),
+			Position:     int16(i),
+			Windows:      windows,
+			Items:        dm.fromDomainMenu(daypart.GetMenu()),
+		})
+	}
+
+	return dtoDayparts
+}
+
+func (dm DefaultMapper) toDomainDayparts(dayparts []*Daypart) []*domain.Daypart {
+	if dayparts == nil {
+		return nil
+	}
+	domainDayparts := []*domain.Daypart{}
+	for _, daypart := range dayparts {
+		windows := []*domain.TimeWindow{}
+		for _, window := range daypart.Windows {
+			windows = append(windows, domain.NewTimeWindow(window.Start, window.End))
+		}
+		domainDayparts = append(domainDayparts, domain.NewDaypart(daypart.Name, windows, dm.toDomainMenu(daypart.Items)))
+	}
+
+	return domainDayparts
+}
+
+func (dm DefaultMapper) toDomainMenuVersion(menuVersion *MenuVersion) *domain.Menu {
+	if menuVersion == nil {
+		return nil
+	}
+	validFrom := menuVersion.ValidFrom
+	return dm.toDomainMenu(menuVersion.Items).WithVersion(menuVersion.Version, &validFrom)
+}
+
+func (dm DefaultMapper) toDomainMenuVersions(menuVersions []*MenuVersion) []*domain.Menu {
+	if menuVersions == nil {
+		return nil
+	}
+	domainMenus := []*domain.Menu{}
+	for _, menuVersion := range menuVersions {
+		domainMenus = append(domainMenus, dm.toDomainMenuVersion(menuVersion))
+	}
+
+	return domainMenus
+}
+
+func (dm DefaultMapper) fromDomainScheduledMenu(scheduledMenu *domain.ScheduledMenu) *ScheduledMenu {
+	if scheduledMenu == nil {
+		return nil
+	}
+	return &ScheduledMenu{
+		ID:            scheduledMenu.Id,
+		RestaurantID:  scheduledMenu.RestaurantId,
+		EffectiveFrom: scheduledMenu.EffectiveFrom,
+		Items:         dm.fromDomainMenu(scheduledMenu.Menu),
+	}
+}
+
+func (dm DefaultMapper) toDomainScheduledMenus(scheduledMenus []*ScheduledMenu) []*domain.ScheduledMenu {
+	if scheduledMenus == nil {
+		return nil
+	}
+	domainScheduledMenus := []*domain.ScheduledMenu{}
+	for _, scheduledMenu := range scheduledMenus {
+		domainScheduledMenus = append(domainScheduledMenus, &domain.ScheduledMenu{
+			Id:            scheduledMenu.ID,
+			RestaurantId:  scheduledMenu.RestaurantID,
+			Menu:          dm.toDomainMenu(scheduledMenu.Items),
+			EffectiveFrom: scheduledMenu.EffectiveFrom,
+		})
+	}
+
+	return domainScheduledMenus
+}
+
+func (DefaultMapper) fromDomainIdempotencyRecord(record *domain.IdempotencyRecord) *IdempotencyKey {
+	if record == nil {
+		return nil
+	}
+	return &IdempotencyKey{
+		Scope:       record.GetScope(),
+		Key:         record.GetKey().GetKey(),
+		RequestHash: record.GetKey().GetRequestHash(),
+		Response:    record.GetResponse(),
+		CreatedAt:   record.GetCreatedAt(),
+	}
+}
+
+func (DefaultMapper) toDomainIdempotencyRecord(idempotencyKey *IdempotencyKey) *domain.IdempotencyRecord {
+	if idempotencyKey == nil {
+		return nil
+	}
+	key, _ := domain.NewIdempotencyKey(idempotencyKey.Key, idempotencyKey.RequestHash)
+	return domain.NewIdempotencyRecord(idempotencyKey.Scope, key, idempotencyKey.Response, idempotencyKey.CreatedAt)
+}
+
+func (DefaultMapper) fromDomainAuditEntry(entry *domain.AuditEntry) *AuditEntry {
+	if entry == nil {
+		return nil
+	}
+	changes := make(map[string]*FieldChange, len(entry.Changes))
+	for field, change := range entry.Changes {
+		changes[field] = &FieldChange{Before: change.GetBefore(), After: change.GetAfter()}
+	}
+	return &AuditEntry{
+		ID:           entry.Id,
+		RestaurantID: entry.RestaurantId,
+		Operation:    string(entry.Operation),
+		Actor:        entry.Actor,
+		TenantID:     entry.TenantId,
+		Changes:      changes,
+		RequestID:    entry.RequestId,
+		Source:       string(entry.Source),
+		CreatedAt:    entry.CreatedAt,
+	}
+}
+
+func (DefaultMapper) toDomainAuditEntries(entries []*AuditEntry) []*domain.AuditEntry {
+	if entries == nil {
+		return nil
+	}
+	domainEntries := []*domain.AuditEntry{}
+	for _, entry := range entries {
+		changes := make(map[string]*domain.FieldChange, len(entry.Changes))
+		for field, change := range entry.Changes {
+			changes[field] = domain.NewFieldChange(change.Before, change.After)
+		}
+		domainEntries = append(domainEntries, &domain.AuditEntry{
+			Id:           entry.ID,
+			RestaurantId: entry.RestaurantID,
+			Operation:    domain.AuditOperation(entry.Operation),
+			Actor:        entry.Actor,
+			TenantId:     entry.TenantID,
+			Changes:      changes,
+			RequestId:    entry.RequestID,
+			Source:       domain.RequestSource(entry.Source),
+			CreatedAt:    entry.CreatedAt,
+		})
+	}
+
+	return domainEntries
+}
+
+func (DefaultMapper) fromDomainTicket(ticket *domain.Ticket) *Ticket {
+	if ticket == nil {
+		return nil
+	}
+	dtoLineItems := []*TicketLineItem{}
+	for _, lineItem := range ticket.LineItems {
+		dtoLineItems = append(dtoLineItems, &TicketLineItem{
+			TicketID:   ticket.Id,
+			MenuItemID: int32(lineItem.GetMenuItemId()),
+			Name:       lineItem.GetName(),
+			Quantity:   lineItem.GetQuantity(),
+		})
+	}
+	return &Ticket{
+		ID:               ticket.Id,
+		RestaurantID:     ticket.RestaurantId,
+		OrderID:          ticket.OrderId,
+		State:            string(ticket.State),
+		ReadyBy:          ticket.ReadyBy,
+		CreatedAt:        ticket.CreatedAt,
+		AcceptedAt:       ticket.AcceptedAt,
+		PreparingAt:      ticket.PreparingAt,
+		ReadyForPickupAt: ticket.ReadyForPickupAt,
+		PickedUpAt:       ticket.PickedUpAt,
+		CancelledAt:      ticket.CancelledAt,
+		LineItems:        dtoLineItems,
+	}
+}
+
+func (DefaultMapper) toDomainTicket(ticketDto *Ticket) *domain.Ticket {
+	if ticketDto == nil {
+		return nil
+	}
+	domainLineItems := []*domain.TicketLineItem{}
+	for _, lineItem := range ticketDto.LineItems {
+		domainLineItems = append(domainLineItems, domain.NewTicketLineItem(int16(lineItem.MenuItemID), lineItem.Name, lineItem.Quantity))
+	}
+	return &domain.Ticket{
+		Id:               ticketDto.ID,
+		RestaurantId:     ticketDto.RestaurantID,
+		OrderId:          ticketDto.OrderID,
+		State:            domain.TicketState(ticketDto.State),
+		LineItems:        domainLineItems,
+		ReadyBy:          ticketDto.ReadyBy,
+		CreatedAt:        ticketDto.CreatedAt,
+		AcceptedAt:       ticketDto.AcceptedAt,
+		PreparingAt:      ticketDto.PreparingAt,
+		ReadyForPickupAt: ticketDto.ReadyForPickupAt,
+		PickedUpAt:       ticketDto.PickedUpAt,
+		CancelledAt:      ticketDto.CancelledAt,
+	}
+}
+
+func (dm DefaultMapper) toDomainTickets(tickets []*Ticket) []*domain.Ticket {
+	if tickets == nil {
+		return nil
+	}
+	domainTickets := []*domain.Ticket{}
+	for _, ticket := range tickets {
+		domainTickets = append(domainTickets, dm.toDomainTicket(ticket))
+	}
+
+	return domainTickets
+}
+
+// fromDomainAllergens maps a slice of domain.Allergen into a slice of strings.
+func fromDomainAllergens(allergens []domain.Allergen) []string {
+	if len(allergens) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(allergens))
+	for _, a := range allergens {
+		values = append(values, string(a))
+	}
+	return values
+}
+
+// fromDomainDietaryTags maps a slice of domain.DietaryTag into a slice of strings.
+func fromDomainDietaryTags(tags []domain.DietaryTag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(tags))
+	for _, t := range tags {
+		values = append(values, string(t))
+	}
+	return values
+}
+
+// toDomainAllergens maps a slice of strings into a slice of domain.Allergen.
+func toDomainAllergens(values []string) []domain.Allergen {
+	if len(values) == 0 {
+		return nil
+	}
+	allergens := make([]domain.Allergen, 0, len(values))
+	for _, v := range values {
+		allergens = append(allergens, domain.Allergen(v))
+	}
+	return allergens
+}
+
+// toDomainDietaryTags maps a slice of strings into a slice of domain.DietaryTag.
+func toDomainDietaryTags(values []string) []domain.DietaryTag {
+	if len(values) == 0 {
+		return nil
+	}
+	tags := make([]domain.DietaryTag, 0, len(values))
+	for _, v := range values {
+		tags = append(tags, domain.DietaryTag(v))
+	}
+	return tags
+}
+
+// fromDomainTranslations maps a map of domain.Translation into a map of Translation.
+func fromDomainTranslations(translations map[string]*domain.Translation) map[string]*Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	values := make(map[string]*Translation, len(translations))
+	for locale, t := range translations {
+		values[locale] = &Translation{Name: t.GetName(), Description: t.GetDescription()}
+	}
+	return values
+}
+
+// toDomainTranslations maps a map of Translation into a map of domain.Translation.
+func toDomainTranslations(values map[string]*Translation) map[string]*domain.Translation {
+	if len(values) == 0 {
+		return nil
+	}
+	translations := make(map[string]*domain.Translation, len(values))
+	for locale, v := range values {
+		translations[locale] = domain.NewTranslation(v.Name, v.Description)
+	}
+	return translations
+}
@@ -3,8 +3,10 @@ package storage
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestToAndFromDomainRestaurant(t *testing.T) {
@@ -22,6 +24,18 @@ func TestToAndFromDomainRestaurant(t *testing.T) {
 				restaurants: []*Restaurant{newStorageRestaurant(), newStorageRestaurant()},
 			},
 		},
+		{
+			name: "map a slice of storage restaurants with location",
+			args: args{
+				restaurants: []*Restaurant{newStorageRestaurantWithLocation()},
+			},
+		},
+		{
+			name: "map a slice of deleted storage restaurants",
+			args: args{
+				restaurants: []*Restaurant{newStorageDeletedRestaurant()},
+			},
+		},
 		{
 			name: "map a nil slice",
 			args: args{
@@ -57,20 +71,62 @@ func TestToAndFromDomainRestaurant(t *testing.T) {
 	}
 }
 
+func TestToAndFromDomainDayparts(t *testing.T) {
+	mapper := DefaultMapper{}
+	testcases := []struct {
+		name     string
+		dayparts []*Daypart
+	}{
+		{
+			name:     "map a slice of storage dayparts",
+			dayparts: newStorageDayparts(),
+		},
+		{
+			name:     "map an empty slice",
+			dayparts: []*Daypart{},
+		},
+		{
+			name:     "map a nil slice",
+			dayparts: nil,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			domainDayparts := mapper.toDomainDayparts(tc.dayparts)
+			backToStorage := mapper.fromDomainDayparts(1000, domainDayparts)
+			assert.True(t, reflect.DeepEqual(tc.dayparts, backToStorage))
+		})
+	}
+}
+
 // --------------------------------------------------------------------------------
 // Utility functions to create restaurants and menus.
 // --------------------------------------------------------------------------------
 
 func newStorageRestaurant() *Restaurant {
-	return &Restaurant{Name: "aName", Address: newStorageAddress(), Menu: newStorageMenu()}
+	return &Restaurant{TenantID: "aTenant", Name: "aName", DefaultLocale: "es-ES", TimeZone: "Europe/Madrid", Description: "aDescription", DescriptionTranslations: map[string]string{"en": "anotherDescription"}, Address: newStorageAddress(), Menu: newStorageMenu()}
+}
+
+func newStorageRestaurantWithLocation() *Restaurant {
+	address := newStorageAddress()
+	latitude, longitude := 40.4168, -3.7038
+	address.Latitude = &latitude
+	address.Longitude = &longitude
+	return &Restaurant{Name: "aName", DefaultLocale: "en", TimeZone: "UTC", Address: address, Menu: newStorageMenu()}
+}
+
+func newStorageDeletedRestaurant() *Restaurant {
+	restaurant := newStorageRestaurant()
+	restaurant.DeletedAt = gorm.DeletedAt{Time: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), Valid: true}
+	return restaurant
 }
 
 func newStorageRestaurantWithoutAddress() *Restaurant {
-	return &Restaurant{Name: "aName", Menu: newStorageMenu()}
+	return &Restaurant{Name: "aName", DefaultLocale: "en", TimeZone: "UTC", Menu: newStorageMenu()}
 }
 
 func newStorageRestaurantWithoutMenu() *Restaurant {
-	return &Restaurant{Name: "aName", Address: newStorageAddress()}
+	return &Restaurant{Name: "aName", DefaultLocale: "en", TimeZone: "UTC", Address: newStorageAddress()}
 }
 
 func newStorageAddress() *Address {
@@ -78,7 +134,13 @@ func newStorageAddress() *Address {
 }
 
 func newStorageMenu() []*MenuItem {
-	item1 := MenuItem{Id: 1, Name: "Name1", Price: "12.99"}
+	item1 := MenuItem{Id: 1, Name: "Name1", Description: "Description1", Translations: map[string]*Translation{"en": {Name: "Name1.en"}}, Price: "12.99", Allergens: []string{"milk", "nuts"}, DietaryTags: []string{"vegetarian"}}
 	item2 := MenuItem{Id: 2, Name: "Name2", Price: "4.55"}
 	return []*MenuItem{&item1, &item2}
 }
+
+func newStorageDayparts() []*Daypart {
+	breakfast := Daypart{RestaurantID: 1000, Name: "breakfast", Position: 0, Windows: []*TimeWindow{{Start: 420, End: 660}}, Items: newStorageMenu()}
+	dinner := Daypart{RestaurantID: 1000, Name: "dinner", Position: 1, Windows: []*TimeWindow{{Start: 1200, End: 1440}, {Start: 0, End: 60}}, Items: newStorageMenu()}
+	return []*Daypart{&breakfast, &dinner}
+}
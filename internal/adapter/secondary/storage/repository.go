@@ -2,8 +2,12 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"f4allgo-restaurant/internal/core/domain"
 	"f4allgo-restaurant/internal/core/port"
+	"fmt"
+	"strings"
+	"time"
 
 	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
 	tally "github.com/uber-go/tally/v4"
@@ -30,9 +34,21 @@ type timerEnum int
 const (
 	findAll timerEnum = iota
 	findById
+	findNearby
+	search
+	findMenuAt
+	findMenuVersions
 	save
 	update
+	updateProfile
+	updateDayparts
+	saveMenuItem
+	updateMenuItem
+	deleteMenuItem
 	delete
+	findDeletedById
+	restore
+	purge
 )
 
 func NewRestaurantPostgresRepository(db *gorm.DB, ctxGetter *trmgorm.CtxGetter, scope tally.Scope) *RestaurantPostgresRepository {
@@ -40,47 +56,226 @@ func NewRestaurantPostgresRepository(db *gorm.DB, ctxGetter *trmgorm.CtxGetter,
 	if scope != nil {
 		FindAll := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "FindAll"}).Timer("repository_latencies")
 		FindById := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "FindById"}).Timer("repository_latencies")
+		FindNearby := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "FindNearby"}).Timer("repository_latencies")
+		Search := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "Search"}).Timer("repository_latencies")
+		FindMenuAt := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "FindMenuAt"}).Timer("repository_latencies")
+		FindMenuVersions := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "FindMenuVersions"}).Timer("repository_latencies")
 		Save := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "Save"}).Timer("repository_latencies")
 		Update := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "Update"}).Timer("repository_latencies")
+		UpdateProfile := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "UpdateProfile"}).Timer("repository_latencies")
+		UpdateDayparts := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "UpdateDayparts"}).Timer("repository_latencies")
+		SaveMenuItem := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "SaveMenuItem"}).Timer("repository_latencies")
+		UpdateMenuItem := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "UpdateMenuItem"}).Timer("repository_latencies")
+		DeleteMenuItem := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "DeleteMenuItem"}).Timer("repository_latencies")
 		Delete := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "Delete"}).Timer("repository_latencies")
+		FindDeletedById := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "FindDeletedById"}).Timer("repository_latencies")
+		Restore := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "Restore"}).Timer("repository_latencies")
+		Purge := scope.Tagged(map[string]string{"repository": "restaurant", "operation": "Purge"}).Timer("repository_latencies")
 
 		timers = make(map[timerEnum]tally.Timer)
 		timers[findAll] = FindAll
 		timers[findById] = FindById
+		timers[findNearby] = FindNearby
+		timers[search] = Search
+		timers[findMenuAt] = FindMenuAt
+		timers[findMenuVersions] = FindMenuVersions
 		timers[save] = Save
 		timers[update] = Update
+		timers[updateProfile] = UpdateProfile
+		timers[updateDayparts] = UpdateDayparts
+		timers[saveMenuItem] = SaveMenuItem
+		timers[updateMenuItem] = UpdateMenuItem
+		timers[deleteMenuItem] = DeleteMenuItem
 		timers[delete] = Delete
+		timers[findDeletedById] = FindDeletedById
+		timers[restore] = Restore
+		timers[purge] = Purge
 	}
 	return &RestaurantPostgresRepository{mapper: DefaultMapper{}, db: db, ctxGetter: ctxGetter, timers: timers}
 }
 
-// FindAll restrieves all the registered restaurants.
-func (r *RestaurantPostgresRepository) FindAll(ctx context.Context, offset int, limit int) ([]*domain.Restaurant, int64, error) {
+// byPosition sorts the dayparts of the restaurants in the order they were provided.
+func byPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// matching filters the restaurants by the criteria of a query. Texts are compared
+// ignoring case (except the zip), using the expression indexes on the restaurants.
+// The deleted restaurants are excluded by Gorm unless the query includes them.
+func matching(query *domain.RestaurantQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.IncludeDeleted {
+			db = db.Unscoped()
+		}
+		if query.NamePrefix != "" {
+			db = db.Where("lower(restaurant.name) LIKE ?", escapeLike(strings.ToLower(query.NamePrefix))+"%")
+		}
+		if query.City != "" {
+			db = db.Where("lower(restaurant.city) = lower(?)", query.City)
+		}
+		if query.State != "" {
+			db = db.Where("lower(restaurant.state) = lower(?)", query.State)
+		}
+		if query.Zip != "" {
+			db = db.Where("restaurant.zip = ?", query.Zip)
+		}
+		if query.HasMenuItemCriteria() {
+			conditions := []string{"menu_item.restaurant_id = restaurant.id"}
+			var vars []interface{}
+			if query.MenuItemName != "" {
+				conditions = append(conditions, "lower(menu_item.name) LIKE ?")
+				vars = append(vars, "%"+escapeLike(strings.ToLower(query.MenuItemName))+"%")
+			}
+			if query.MinPrice != nil {
+				conditions = append(conditions, "CAST(menu_item.price AS NUMERIC) >= ?")
+				vars = append(vars, query.MinPrice.Text('f', -1))
+			}
+			if query.MaxPrice != nil {
+				conditions = append(conditions, "CAST(menu_item.price AS NUMERIC) <= ?")
+				vars = append(vars, query.MaxPrice.Text('f', -1))
+			}
+			db = db.Where("EXISTS (SELECT 1 FROM menu_item WHERE "+strings.Join(conditions, " AND ")+")", vars...)
+		}
+		return db
+	}
+}
+
+// sortedBy sorts the restaurants as requested by a query (or in the opposite order if
+// reversed), breaking ties by id so that the pages are stable. The id is sorted in the
+// same direction as the sort field, so that the sort key can be compared as a row in
+// the keyset conditions.
+func sortedBy(query *domain.RestaurantQuery, reversed bool) clause.OrderBy {
+	desc := (query.SortDirection == domain.SortDescending) != reversed
+	columns := []clause.OrderByColumn{{
+		Column: clause.Column{Table: "restaurant", Name: string(query.SortBy)},
+		Desc:   desc,
+	}}
+	if query.SortBy != domain.RestaurantSortById {
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Table: "restaurant", Name: "id"}, Desc: desc})
+	}
+	return clause.OrderBy{Columns: columns}
+}
+
+// beyond filters the restaurants placed after the position of a cursor in the order of
+// the query (or before it if the cursor is backward), comparing their sort keys.
+func beyond(query *domain.RestaurantQuery, cursor *domain.Cursor) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		operator := ">"
+		if (query.SortDirection == domain.SortDescending) != cursor.IsBackward() {
+			operator = "<"
+		}
+		if query.SortBy == domain.RestaurantSortById {
+			return db.Where("restaurant.id "+operator+" ?", cursor.GetId())
+		}
+		// The sort field has already been validated, so it's safe to build the condition.
+		return db.Where(fmt.Sprintf("(restaurant.%s, restaurant.id) %s (?, ?)", query.SortBy, operator), cursor.GetValue(), cursor.GetId())
+	}
+}
+
+// escapeLike escapes the wildcards of a text to be matched literally in a LIKE
+// pattern.
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
+
+// toJSON encodes a map to be written into a JSONB column, since Gorm doesn't apply
+// the serializer of the fields when updating from a map. Empty maps are written as
+// NULL.
+func toJSON(value map[string]string) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+// FindAll restrieves a page of the registered restaurants matching the query, sorted as
+// requested by it. The menu item criteria are resolved with a correlated subquery, so
+// restaurants offering several matching items are not duplicated. With a cursor, the page
+// is read with a keyset condition on the sort key of the query (the sort field and the id)
+// instead of an offset, so that the index on the sort key can be used and the pages don't
+// shift under concurrent changes. A restaurant more than the limit is read to know if
+// there's a next page.
+func (r *RestaurantPostgresRepository) FindAll(ctx context.Context, query *domain.RestaurantQuery, pagination *domain.Pagination) (*domain.RestaurantPage, error) {
 	var restaurants []*Restaurant
-	var total int64
+	var total *int64
+	estimated := false
+
+	cursor := pagination.Cursor
+	backward := cursor != nil && cursor.IsBackward()
 
 	if err := r.executeWithTimer(findAll, func() error {
-		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Preload("Menu").Order("id ASC").Offset(offset).Limit(limit).Find(&restaurants).Error; err != nil {
-			return err
+		db := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Scopes(matching(query))
+		if cursor != nil {
+			db = db.Scopes(beyond(query, cursor))
+		} else {
+			db = db.Offset(pagination.Offset)
 		}
-		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&Restaurant{}).Count(&total).Error; err != nil {
+		if err := db.Preload("Menu").Preload("Dayparts", byPosition).Order(sortedBy(query, backward)).Limit(pagination.Limit + 1).Find(&restaurants).Error; err != nil {
 			return err
 		}
-		return nil
+		var err error
+		total, estimated, err = r.count(ctx, query, pagination.Count)
+		return err
 	}); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	return r.mapper.toDomainRestaurants(restaurants), total, nil
+	hasMore := len(restaurants) > pagination.Limit
+	if hasMore {
+		restaurants = restaurants[:pagination.Limit]
+	}
+	if backward {
+		for i, j := 0, len(restaurants)-1; i < j; i, j = i+1, j-1 {
+			restaurants[i], restaurants[j] = restaurants[j], restaurants[i]
+		}
+	}
+
+	page := &domain.RestaurantPage{Restaurants: r.mapper.toDomainRestaurants(restaurants), Total: total, Estimated: estimated}
+	if len(page.Restaurants) > 0 {
+		first, last := page.Restaurants[0], page.Restaurants[len(page.Restaurants)-1]
+		// Going backward there are always restaurants after the page (the ones it came
+		// from), while going forward there are restaurants before it unless it's the
+		// first page.
+		if backward || hasMore {
+			page.NextCursor = domain.NewCursorAt(query, last, false)
+		}
+		if (backward && hasMore) || (!backward && (cursor != nil || pagination.Offset > 0)) {
+			page.PrevCursor = domain.NewCursorAt(query, first, true)
+		}
+	}
+	return page, nil
+}
+
+// count computes the total number of restaurants matching the query as requested by
+// the count mode. The estimate is taken from the statistics of the table, so it's only
+// used when the query has no criteria and the table has already been analyzed.
+func (r *RestaurantPostgresRepository) count(ctx context.Context, query *domain.RestaurantQuery, mode domain.CountMode) (*int64, bool, error) {
+	var total int64
+	switch {
+	case mode == domain.CountNone:
+		return nil, false, nil
+	case mode == domain.CountEstimated && !query.HasCriteria():
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Raw("SELECT reltuples::BIGINT FROM pg_class WHERE oid = 'restaurant'::regclass").Scan(&total).Error; err != nil {
+			return nil, false, err
+		}
+		if total >= 0 {
+			return &total, true, nil
+		}
+	}
+	if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&Restaurant{}).Scopes(matching(query)).Count(&total).Error; err != nil {
+		return nil, false, err
+	}
+	return &total, false, nil
 }
 
 // FindById retrieves a particular restaurant by its identifier. The method allows the client to decide if
-// the restaurant's menu should be fetched or not.
+// the restaurant's menus (the default one and the dayparts) should be fetched or not.
 func (r *RestaurantPostgresRepository) FindById(ctx context.Context, restaurantId int64, fetchMenu bool) (*domain.Restaurant, error) {
 	var restaurant *Restaurant
 	if err := r.executeWithTimer(findById, func() error {
 		if fetchMenu {
-			return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Preload("Menu").First(&restaurant, restaurantId).Error
+			return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Preload("Menu").Preload("Dayparts", byPosition).First(&restaurant, restaurantId).Error
 		} else {
 			return r.ctxGetter.DefaultTrOrDB(ctx, r.db).First(&restaurant, restaurantId).Error
 		}
@@ -91,53 +286,437 @@ func (r *RestaurantPostgresRepository) FindById(ctx context.Context, restaurantI
 	return r.mapper.toDomainRestaurant(restaurant), nil
 }
 
-// Save persists a restaurant in the database.
+// FindNearby retrieves the restaurants located within a radius (in meters) of a point, sorted
+// by distance. It relies on the 'earthdistance' extension: the earth_box condition is the one
+// that can be resolved using the gist index, while the earth_distance one discards the
+// restaurants placed in the corners of the box.
+func (r *RestaurantPostgresRepository) FindNearby(ctx context.Context, point *domain.GeoPoint, radius float64, offset int, limit int) ([]*domain.Restaurant, int64, error) {
+	var restaurants []*Restaurant
+	var total int64
+
+	latitude, longitude := point.Latitude(), point.Longitude()
+	nearby := func(db *gorm.DB) *gorm.DB {
+		return db.Where("latitude IS NOT NULL AND longitude IS NOT NULL").
+			Where("earth_box(ll_to_earth(?, ?), ?) @> ll_to_earth(latitude, longitude)", latitude, longitude, radius).
+			Where("earth_distance(ll_to_earth(?, ?), ll_to_earth(latitude, longitude)) <= ?", latitude, longitude, radius)
+	}
+	byDistance := clause.OrderBy{Expression: clause.Expr{
+		SQL:                "earth_distance(ll_to_earth(?, ?), ll_to_earth(latitude, longitude)) ASC, id ASC",
+		Vars:               []interface{}{latitude, longitude},
+		WithoutParentheses: true,
+	}}
+
+	if err := r.executeWithTimer(findNearby, func() error {
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Scopes(nearby).Preload("Menu").Preload("Dayparts", byPosition).Order(byDistance).Offset(offset).Limit(limit).Find(&restaurants).Error; err != nil {
+			return err
+		}
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&Restaurant{}).Scopes(nearby).Count(&total).Error; err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return r.mapper.toDomainRestaurants(restaurants), total, nil
+}
+
+// searchHeadlineOptions configures the highlights of the full-text searches: up to
+// three short fragments of the indexed texts, with the matched words delimited.
+var searchHeadlineOptions = fmt.Sprintf(`StartSel=%s, StopSel=%s, MaxWords=15, MinWords=5, MaxFragments=3, FragmentDelimiter=" … "`,
+	domain.HighlightStart, domain.HighlightStop)
+
+// Search retrieves the restaurants matching a full-text search, sorted by relevance. The
+// search documents are maintained by triggers in the restaurant_search table (see the sql
+// scripts), and the text is parsed with the web search syntax so that any user input is
+// a valid query. The matching hits are fetched first (skipping the documents of deleted
+// restaurants, which are kept until they are purged), and then the restaurants with their
+// menus and dayparts.
+func (r *RestaurantPostgresRepository) Search(ctx context.Context, text string, offset int, limit int) ([]*domain.RestaurantSearchResult, int64, error) {
+	var hits []*SearchHit
+	var restaurants []*Restaurant
+	var total int64
+
+	matchingText := func(db *gorm.DB) *gorm.DB {
+		return db.Table("restaurant_search").Where("document @@ websearch_to_tsquery('simple', ?)", text).
+			Where("NOT EXISTS (SELECT 1 FROM restaurant WHERE restaurant.id = restaurant_search.restaurant_id AND restaurant.deleted_at IS NOT NULL)")
+	}
+
+	if err := r.executeWithTimer(search, func() error {
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Scopes(matchingText).
+			Select("restaurant_id, ts_rank_cd(document, websearch_to_tsquery('simple', ?), 32) AS rank, ts_headline('simple', content, websearch_to_tsquery('simple', ?), ?) AS highlight", text, text, searchHeadlineOptions).
+			Order("rank DESC, restaurant_id ASC").Offset(offset).Limit(limit).Scan(&hits).Error; err != nil {
+			return err
+		}
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Scopes(matchingText).Count(&total).Error; err != nil {
+			return err
+		}
+		if len(hits) == 0 {
+			return nil
+		}
+		ids := make([]int64, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.RestaurantID)
+		}
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Preload("Menu").Preload("Dayparts", byPosition).Find(&restaurants, ids).Error
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	byId := make(map[int64]*Restaurant, len(restaurants))
+	for _, restaurant := range restaurants {
+		byId[restaurant.ID] = restaurant
+	}
+	results := make([]*domain.RestaurantSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if restaurant, ok := byId[hit.RestaurantID]; ok {
+			results = append(results, domain.NewRestaurantSearchResult(r.mapper.toDomainRestaurant(restaurant), hit.Rank, hit.Highlight))
+		}
+	}
+	return results, total, nil
+}
+
+// FindMenuAt retrieves the version of a restaurant's menu that was in effect at a
+// given instant.
+func (r *RestaurantPostgresRepository) FindMenuAt(ctx context.Context, restaurantId int64, at time.Time) (*domain.Menu, error) {
+	var menuVersion *MenuVersion
+	if err := r.executeWithTimer(findMenuAt, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ? AND valid_from <= ?", restaurantId, at).
+			Order("version DESC").First(&menuVersion).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return r.mapper.toDomainMenuVersion(menuVersion), nil
+}
+
+// FindMenuVersions retrieves the versions of a restaurant's menu, newest first.
+func (r *RestaurantPostgresRepository) FindMenuVersions(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.Menu, int64, error) {
+	var menuVersions []*MenuVersion
+	var total int64
+
+	if err := r.executeWithTimer(findMenuVersions, func() error {
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ?", restaurantId).Order("version DESC").Offset(offset).Limit(limit).Find(&menuVersions).Error; err != nil {
+			return err
+		}
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&MenuVersion{}).Where("restaurant_id = ?", restaurantId).Count(&total).Error; err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return r.mapper.toDomainMenuVersions(menuVersions), total, nil
+}
+
+// Save persists a restaurant in the database along with the first version of its menu.
 func (r *RestaurantPostgresRepository) Save(ctx context.Context, restaurant *domain.Restaurant) error {
+	_, err := r.insert(ctx, restaurant)
+	return err
+}
+
+// insert persists a restaurant along with the first version of its menu and tells if
+// it was inserted. Nothing is written (and the restaurant is left untouched) if a
+// restaurant with the same identifier already exists.
+func (r *RestaurantPostgresRepository) insert(ctx context.Context, restaurant *domain.Restaurant) (bool, error) {
 	restaurantDto := r.mapper.fromDomainRestaurant(restaurant)
+	var inserted bool
+	var version int32
 	if err := r.executeWithTimer(save, func() error {
-		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(restaurantDto).Error
-	}); err != nil {
+		result := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(restaurantDto)
+		inserted = result.RowsAffected > 0
+		if result.Error != nil || !inserted || restaurant.Menu == nil {
+			return result.Error
+		}
+		var err error
+		version, err = r.saveMenuVersion(ctx, restaurantDto.ID)
 		return err
+	}); err != nil || !inserted {
+		return false, err
 	}
 	restaurant.Id = restaurantDto.ID
-	return nil
+	// The database starts the restaurants at the first version.
+	restaurant.Version = 1
+	if restaurant.Menu != nil {
+		restaurant.Menu = restaurant.Menu.WithVersion(version, nil)
+	}
+	return true, nil
 }
 
-// Update updates a restaurant and its relations.
+// Update updates a restaurant and its relations, writing a new version of its menu.
 func (r *RestaurantPostgresRepository) Update(ctx context.Context, restaurant *domain.Restaurant) (int64, error) {
-	var result *gorm.DB
+	var rowsAffected int64
+	var version int32
 	if err := r.executeWithTimer(update, func() error {
+		// Nothing is written if the restaurant doesn't exist in the database in the first place
+		// or it was modified concurrently.
+		var err error
+		if rowsAffected, err = r.bumpVersion(ctx, restaurant.Id, restaurant.Version); err != nil || rowsAffected == 0 {
+			return err
+		}
+
 		// Delete previous menu items.
-		err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ?", restaurant.Id).Delete(&MenuItem{}).Error
+		err = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ?", restaurant.Id).Delete(&MenuItem{}).Error
 		if err != nil {
 			return err
 		}
 
-		// Persist the aggregate again. If the aggregate doesn't exist in the database in the first place
-		// en error will happen (inserting the menu items or the restaurant, it doesn't matter) and the
-		// affected rows will be zero (that's exactly what we want).
-		result = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Save(r.mapper.fromDomainRestaurant(restaurant))
-		return result.Error
+		// Persist the aggregate again.
+		if err = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Save(r.mapper.fromDomainRestaurant(restaurant)).Error; err != nil {
+			return err
+		}
+
+		// The previous versions of the menu are kept untouched.
+		version, err = r.saveMenuVersion(ctx, restaurant.Id)
+		return err
 	}); err != nil {
 		return 0, err
 	}
+	if rowsAffected == 0 {
+		return 0, nil
+	}
+
+	restaurant.Version++
+	if restaurant.Menu != nil {
+		restaurant.Menu = restaurant.Menu.WithVersion(version, nil)
+	}
+	return rowsAffected, nil
+}
+
+// UpdateProfile updates the name, address and description of a restaurant without
+// touching its menu.
+func (r *RestaurantPostgresRepository) UpdateProfile(ctx context.Context, restaurant *domain.Restaurant) (int64, error) {
+	var rowsAffected int64
+	if err := r.executeWithTimer(updateProfile, func() error {
+		var err error
+		if rowsAffected, err = r.bumpVersion(ctx, restaurant.Id, restaurant.Version); err != nil || rowsAffected == 0 {
+			return err
+		}
+
+		restaurantDto := r.mapper.fromDomainRestaurant(restaurant)
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&Restaurant{ID: restaurant.Id}).Updates(map[string]interface{}{
+			"name":                     restaurantDto.Name,
+			"street":                   restaurantDto.Address.Street,
+			"city":                     restaurantDto.Address.City,
+			"state":                    restaurantDto.Address.State,
+			"zip":                      restaurantDto.Address.Zip,
+			"latitude":                 restaurantDto.Address.Latitude,
+			"longitude":                restaurantDto.Address.Longitude,
+			"default_locale":           restaurantDto.DefaultLocale,
+			"time_zone":                restaurantDto.TimeZone,
+			"description":              restaurantDto.Description,
+			"description_translations": toJSON(restaurantDto.DescriptionTranslations),
+		}).Error
+	}); err != nil {
+		return 0, err
+	}
+
+	if rowsAffected > 0 {
+		restaurant.Version++
+	}
+	return rowsAffected, nil
+}
+
+// UpdateDayparts replaces all the daypart rows of a restaurant.
+func (r *RestaurantPostgresRepository) UpdateDayparts(ctx context.Context, restaurantId int64, version int64, dayparts []*domain.Daypart) error {
+	return r.executeWithTimer(updateDayparts, func() error {
+		if rowsAffected, err := r.bumpVersion(ctx, restaurantId, version); err != nil || rowsAffected == 0 {
+			return err
+		}
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ?", restaurantId).Delete(&Daypart{}).Error; err != nil {
+			return err
+		}
+		if len(dayparts) == 0 {
+			return nil
+		}
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Create(r.mapper.fromDomainDayparts(restaurantId, dayparts)).Error
+	})
+}
+
+// SaveMenuItem persists a single new menu item row of a restaurant.
+func (r *RestaurantPostgresRepository) SaveMenuItem(ctx context.Context, restaurantId int64, version int64, menuItem *domain.MenuItem) error {
+	return r.executeWithTimer(saveMenuItem, func() error {
+		if rowsAffected, err := r.bumpVersion(ctx, restaurantId, version); err != nil || rowsAffected == 0 {
+			return err
+		}
+		menuItemDto := r.mapper.fromDomainMenuItem(menuItem)
+		menuItemDto.RestaurantID = restaurantId
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Create(menuItemDto).Error; err != nil {
+			return err
+		}
+		_, err := r.saveMenuVersion(ctx, restaurantId)
+		return err
+	})
+}
+
+// UpdateMenuItem updates a single menu item row of a restaurant.
+func (r *RestaurantPostgresRepository) UpdateMenuItem(ctx context.Context, restaurantId int64, version int64, menuItem *domain.MenuItem) (int64, error) {
+	var result *gorm.DB
+	if err := r.executeWithTimer(updateMenuItem, func() error {
+		if rowsAffected, err := r.bumpVersion(ctx, restaurantId, version); err != nil || rowsAffected == 0 {
+			return err
+		}
+		menuItemDto := r.mapper.fromDomainMenuItem(menuItem)
+		menuItemDto.RestaurantID = restaurantId
+		// Selecting the columns explicitly forces Gorm to also update zero values
+		// (e.g. when the item is marked as not available).
+		result = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(menuItemDto).Select("Name", "Description", "Translations", "Price", "Available", "AvailableUntil", "Allergens", "DietaryTags").Updates(menuItemDto)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		_, err := r.saveMenuVersion(ctx, restaurantId)
+		return err
+	}); err != nil || result == nil {
+		return 0, err
+	}
 
 	return result.RowsAffected, nil
 }
 
-// Delete deletes a restaurant and its relations from the database.
-func (r *RestaurantPostgresRepository) Delete(ctx context.Context, restaurantId int64) (int64, error) {
+// DeleteMenuItem deletes a single menu item row of a restaurant.
+func (r *RestaurantPostgresRepository) DeleteMenuItem(ctx context.Context, restaurantId int64, version int64, menuItemId int16) (int64, error) {
+	var result *gorm.DB
+	if err := r.executeWithTimer(deleteMenuItem, func() error {
+		if rowsAffected, err := r.bumpVersion(ctx, restaurantId, version); err != nil || rowsAffected == 0 {
+			return err
+		}
+		result = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ? AND id = ?", restaurantId, menuItemId).Delete(&MenuItem{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		_, err := r.saveMenuVersion(ctx, restaurantId)
+		return err
+	}); err != nil || result == nil {
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+// Delete marks a restaurant as deleted (Gorm sets its deletion instant instead of
+// removing the row), keeping its relations until it's purged.
+func (r *RestaurantPostgresRepository) Delete(ctx context.Context, restaurantId int64, version int64) (int64, error) {
 	var result *gorm.DB
 	if err := r.executeWithTimer(delete, func() error {
-		result = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Select(clause.Associations).Delete(&Restaurant{ID: restaurantId})
+		if rowsAffected, err := r.bumpVersion(ctx, restaurantId, version); err != nil || rowsAffected == 0 {
+			return err
+		}
+		result = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Delete(&Restaurant{ID: restaurantId})
 		return result.Error
-	}); err != nil {
+	}); err != nil || result == nil {
 		return 0, err
 	}
 
 	return result.RowsAffected, nil
 }
 
+// FindDeletedById retrieves a deleted restaurant by its identifier, without its menus.
+func (r *RestaurantPostgresRepository) FindDeletedById(ctx context.Context, restaurantId int64) (*domain.Restaurant, error) {
+	var restaurant *Restaurant
+	if err := r.executeWithTimer(findDeletedById, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL").First(&restaurant, restaurantId).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return r.mapper.toDomainRestaurant(restaurant), nil
+}
+
+// Restore clears the deletion instant of a deleted restaurant if it's still at the
+// given version, increasing its version. Like bumpVersion, it returns zero rows if the
+// restaurant isn't deleted and domain.ErrConcurrentModification if it's at another
+// version.
+func (r *RestaurantPostgresRepository) Restore(ctx context.Context, restaurantId int64, version int64) (int64, error) {
+	var rowsAffected int64
+	if err := r.executeWithTimer(restore, func() error {
+		result := r.ctxGetter.DefaultTrOrDB(ctx, r.db).
+			Exec("UPDATE restaurant SET deleted_at = NULL, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NOT NULL", restaurantId, version)
+		if result.Error != nil || result.RowsAffected > 0 {
+			rowsAffected = result.RowsAffected
+			return result.Error
+		}
+
+		var count int64
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&Restaurant{}).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", restaurantId).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return domain.ErrConcurrentModification
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// Purge removes the restaurants deleted before an instant along with their relations.
+// The menu items are removed explicitly, while the rest of the relations (the versions
+// of the menu, the dayparts, the scheduled menus and the search documents) are removed
+// by the database in cascade.
+func (r *RestaurantPostgresRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var rowsAffected int64
+	if err := r.executeWithTimer(purge, func() error {
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).
+			Exec("DELETE FROM menu_item WHERE restaurant_id IN (SELECT id FROM restaurant WHERE deleted_at < ?)", before).Error; err != nil {
+			return err
+		}
+		result := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Unscoped().Where("deleted_at < ?", before).Delete(&Restaurant{})
+		rowsAffected = result.RowsAffected
+		return result.Error
+	}); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// bumpVersion increases the version of a restaurant if it's still at the given one
+// and returns the number of rows affected. It returns domain.ErrRestaurantNotFound if
+// the restaurant doesn't exist (or it's deleted), and domain.ErrConcurrentModification
+// if it's at another version.
+func (r *RestaurantPostgresRepository) bumpVersion(ctx context.Context, restaurantId int64, version int64) (int64, error) {
+	result := r.ctxGetter.DefaultTrOrDB(ctx, r.db).
+		Exec("UPDATE restaurant SET version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL", restaurantId, version)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.RowsAffected, result.Error
+	}
+
+	var count int64
+	if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&Restaurant{}).Where("id = ?", restaurantId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		return 0, domain.ErrConcurrentModification
+	}
+	return 0, domain.ErrRestaurantNotFound
+}
+
+// saveMenuVersion writes the current menu items of a restaurant as a new version of
+// its menu (valid from the start of the current transaction) and returns its number.
+// It must be called within the same transaction as the change of the menu.
+func (r *RestaurantPostgresRepository) saveMenuVersion(ctx context.Context, restaurantId int64) (int32, error) {
+	var version int32
+	if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).
+		Raw("UPDATE restaurant SET menu_version = menu_version + 1 WHERE id = ? RETURNING menu_version", restaurantId).
+		Scan(&version).Error; err != nil {
+		return 0, err
+	}
+
+	var menuItems []*MenuItem
+	if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ?", restaurantId).Order("id ASC").Find(&menuItems).Error; err != nil {
+		return 0, err
+	}
+
+	menuVersion := &MenuVersion{RestaurantID: restaurantId, Version: version, Items: menuItems}
+	if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Create(menuVersion).Error; err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
 // executeWithTimer executes a function using a tally timer if present.
 func (r *RestaurantPostgresRepository) executeWithTimer(t timerEnum, fn func() error) error {
 	if r.timers[t] != nil {
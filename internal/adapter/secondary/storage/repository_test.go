@@ -8,9 +8,11 @@ import (
 	"f4allgo-restaurant/internal/core/port"
 	"f4allgo-restaurant/test"
 	"fmt"
+	"math/big"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
@@ -26,10 +28,14 @@ import (
 const ROLLBACK_PLEASE string = "rollback to keep database clean from testcase to testcase"
 
 var (
-	database             *pgcontainer.PostgresContainer
-	db                   *gorm.DB
-	trManager            trm.Manager
-	restaurantRepository port.RestaurantRepository
+	database                 *pgcontainer.PostgresContainer
+	db                       *gorm.DB
+	trManager                trm.Manager
+	restaurantRepository     port.RestaurantRepository
+	ticketRepository         port.TicketRepository
+	scheduledMenuRepository  port.ScheduledMenuRepository
+	idempotencyKeyRepository port.IdempotencyKeyRepository
+	auditLogRepository       port.AuditLogRepository
 )
 
 var mapper Mapper = DefaultMapper{}
@@ -66,6 +72,10 @@ func TestMain(m *testing.M) {
 	sqlDB, _ := db.DB()
 	boot.InitTallyReporter(sqlDB)
 	restaurantRepository = NewRestaurantPostgresRepository(db, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+	ticketRepository = NewTicketPostgresRepository(db, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+	scheduledMenuRepository = NewScheduledMenuPostgresRepository(db, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+	idempotencyKeyRepository = NewIdempotencyKeyPostgresRepository(db, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
+	auditLogRepository = NewAuditLogPostgresRepository(db, trmgorm.DefaultCtxGetter, boot.GetTallyScope())
 
 	code := m.Run()
 
@@ -128,6 +138,7 @@ func TestNewRestaurantPostgresRepository(t *testing.T) {
 
 func TestFindAll(t *testing.T) {
 	type args struct {
+		query  *domain.RestaurantQuery
 		offset int
 		limit  int
 	}
@@ -174,6 +185,88 @@ func TestFindAll(t *testing.T) {
 			wantFirstElementId: 2000,
 			wantErr:            false,
 		},
+		{
+			name: "filter by name prefix ignoring case",
+			args: args{
+				query:  &domain.RestaurantQuery{NamePrefix: "RESTAURANT2"},
+				offset: 0,
+				limit:  100,
+			},
+			wantLen:            1,
+			wantTotal:          1,
+			wantFirstElementId: 2000,
+			wantErr:            false,
+		},
+		{
+			name: "filter by name prefix matching wildcards literally",
+			args: args{
+				query:  &domain.RestaurantQuery{NamePrefix: "restaurant_"},
+				offset: 0,
+				limit:  100,
+			},
+			wantLen:   0,
+			wantTotal: 0,
+			wantErr:   false,
+		},
+		{
+			name: "filter by city, state and zip",
+			args: args{
+				query:  &domain.RestaurantQuery{City: "City3", State: "state3", Zip: "zip3"},
+				offset: 0,
+				limit:  100,
+			},
+			wantLen:            1,
+			wantTotal:          1,
+			wantFirstElementId: 3000,
+			wantErr:            false,
+		},
+		{
+			name: "filter by menu item name",
+			args: args{
+				query:  &domain.RestaurantQuery{MenuItemName: "artículo"},
+				offset: 0,
+				limit:  100,
+			},
+			wantLen:            1,
+			wantTotal:          1,
+			wantFirstElementId: 2000,
+			wantErr:            false,
+		},
+		{
+			name: "filter by menu item name and price range",
+			args: args{
+				query:  &domain.RestaurantQuery{MenuItemName: "item1", MinPrice: big.NewFloat(15), MaxPrice: big.NewFloat(15.5)},
+				offset: 0,
+				limit:  100,
+			},
+			wantLen:            1,
+			wantTotal:          1,
+			wantFirstElementId: 1000,
+			wantErr:            false,
+		},
+		{
+			name: "filter by a price range no menu item is within",
+			args: args{
+				query:  &domain.RestaurantQuery{MinPrice: big.NewFloat(16)},
+				offset: 0,
+				limit:  100,
+			},
+			wantLen:   0,
+			wantTotal: 0,
+			wantErr:   false,
+		},
+		{
+			name: "sort by name descending",
+			args: args{
+				query:  &domain.RestaurantQuery{SortBy: domain.RestaurantSortByName, SortDirection: domain.SortDescending},
+				offset: 0,
+				limit:  2,
+			},
+			wantLen:            2,
+			wantTotal:          3,
+			wantFirstElementId: 3000,
+			wantErr:            false,
+		},
 		{
 			name: "simulate error when fetching restaurants with mocked DB",
 			args: args{
@@ -194,6 +287,7 @@ func TestFindAll(t *testing.T) {
 			},
 			mockExpectations: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`SELECT .+ FROM "restaurant" .+`).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "city", "state", "street", "zip"}).AddRow(1000, "restaurant1", "city1", "state1", "street1", "zip1"))
+				mock.ExpectQuery(`SELECT .+ FROM "daypart" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
 				mock.ExpectQuery(`SELECT .+ FROM "menu_item" .+`).WillReturnRows(sqlmock.NewRows(make([]string, 0)))
 				mock.ExpectQuery(`SELECT count.+ FROM "restaurant`).WillReturnError(errors.New("error#2"))
 			},
@@ -210,12 +304,169 @@ func TestFindAll(t *testing.T) {
 				repository, _, mock = createMockRepository()
 				tc.mockExpectations(mock)
 			}
-			restaurants, total, err := repository.FindAll(context.Background(), tc.args.offset, tc.args.limit)
+			query := tc.args.query
+			if query == nil {
+				query = &domain.RestaurantQuery{}
+			}
+			assert.NoError(t, query.Validate())
+			pagination := &domain.Pagination{Offset: tc.args.offset, Limit: tc.args.limit}
+			assert.NoError(t, pagination.Validate(query))
+			page, err := repository.FindAll(context.Background(), query, pagination)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Len(t, page.Restaurants, tc.wantLen)
+				assert.Equal(t, tc.wantTotal, *page.Total)
+				if tc.wantLen > 0 {
+					assert.Equal(t, tc.wantFirstElementId, page.Restaurants[0].Id)
+				}
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.wantErrMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestFindAllWithCursors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		query   *domain.RestaurantQuery
+		wantIds []int64
+	}{
+		{
+			name:    "walk the pages sorted by id",
+			query:   &domain.RestaurantQuery{},
+			wantIds: []int64{1000, 2000, 3000},
+		},
+		{
+			name:    "walk the pages sorted by name descending",
+			query:   &domain.RestaurantQuery{SortBy: domain.RestaurantSortByName, SortDirection: domain.SortDescending},
+			wantIds: []int64{3000, 2000, 1000},
+		},
+		{
+			name:    "walk the pages of a filtered listing",
+			query:   &domain.RestaurantQuery{MenuItemName: "item", SortBy: domain.RestaurantSortByCity},
+			wantIds: []int64{1000, 2000, 3000},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			assert.NoError(t, tc.query.Validate())
+
+			// Walk forward one restaurant at a time, without counting.
+			pagination := &domain.Pagination{Limit: 1, Count: domain.CountNone}
+			forwardIds := []int64{}
+			var pages []*domain.RestaurantPage
+			for {
+				page, err := restaurantRepository.FindAll(ctx, tc.query, pagination)
+				assert.NoError(t, err)
+				assert.Nil(t, page.Total)
+				assert.Len(t, page.Restaurants, 1)
+				forwardIds = append(forwardIds, page.Restaurants[0].Id)
+				pages = append(pages, page)
+				if page.NextCursor == nil {
+					break
+				}
+				pagination = &domain.Pagination{Limit: 1, Cursor: page.NextCursor, Count: domain.CountNone}
+			}
+			assert.Equal(t, tc.wantIds, forwardIds)
+			assert.Nil(t, pages[0].PrevCursor)
+
+			// Walk back from the last page.
+			backwardIds := []int64{}
+			for cursor := pages[len(pages)-1].PrevCursor; cursor != nil; {
+				encoded, err := domain.DecodeCursor(cursor.Encode())
+				assert.NoError(t, err)
+				page, err := restaurantRepository.FindAll(ctx, tc.query, &domain.Pagination{Limit: 1, Cursor: encoded, Count: domain.CountNone})
+				assert.NoError(t, err)
+				assert.Len(t, page.Restaurants, 1)
+				assert.NotNil(t, page.NextCursor)
+				backwardIds = append([]int64{page.Restaurants[0].Id}, backwardIds...)
+				cursor = page.PrevCursor
+			}
+			assert.Equal(t, tc.wantIds[:len(tc.wantIds)-1], backwardIds)
+		})
+	}
+
+	// A backward page bigger than the rest of the listing is not shortened.
+	query := &domain.RestaurantQuery{}
+	assert.NoError(t, query.Validate())
+	last, err := restaurantRepository.FindAll(context.Background(), query, &domain.Pagination{Offset: 2, Limit: 2, Count: domain.CountExact})
+	assert.NoError(t, err)
+	assert.Nil(t, last.NextCursor)
+	page, err := restaurantRepository.FindAll(context.Background(), query, &domain.Pagination{Limit: 5, Cursor: last.PrevCursor, Count: domain.CountEstimated})
+	assert.NoError(t, err)
+	assert.Len(t, page.Restaurants, 2)
+	assert.Nil(t, page.PrevCursor)
+	assert.NotNil(t, page.Total)
+}
+
+func TestFindById(t *testing.T) {
+	type args struct {
+		restaurantId int64
+		fetchMenu    bool
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		wantRestaurant   *Restaurant
+		wantDaypartNames []string
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name: "find a restaurant that exists fetching its menu",
+			args: args{
+				restaurantId: 1000,
+				fetchMenu:    true,
+			},
+			wantRestaurant:   newTestRestaurant(),
+			wantDaypartNames: []string{"breakfast", "dinner"},
+			wantErr:          false,
+		},
+		{
+			name: "find a restaurant that doesn't exist fetching its menu",
+			args: args{
+				restaurantId: 1001,
+				fetchMenu:    true,
+			},
+			wantRestaurant: nil,
+			wantErr:        true,
+			wantErrMsg:     "record not found",
+		},
+		{
+			name: "find a restaurant that exists not fetching its menu",
+			args: args{
+				restaurantId: 1000,
+				fetchMenu:    false,
+			},
+			wantRestaurant: newTestRestaurantWithoutMenu(),
+			wantErr:        false,
+		},
+		{
+			name: "find a restaurant that doesn't exist not fetching its menu",
+			args: args{
+				restaurantId: 1001,
+				fetchMenu:    false,
+			},
+			wantRestaurant: nil,
+			wantErr:        true,
+			wantErrMsg:     "record not found",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			restaurant, err := restaurantRepository.FindById(context.Background(), tc.args.restaurantId, tc.args.fetchMenu)
+			assert.Equal(t, tc.wantRestaurant, mapper.fromDomainRestaurant(restaurant))
 			if !tc.wantErr {
 				assert.NoError(t, err)
-				assert.Len(t, restaurants, tc.wantLen)
-				assert.Equal(t, tc.wantTotal, total)
-				assert.Equal(t, tc.wantFirstElementId, restaurants[0].Id)
+				var daypartNames []string
+				for _, daypart := range restaurant.Dayparts {
+					daypartNames = append(daypartNames, daypart.GetName())
+				}
+				assert.Equal(t, tc.wantDaypartNames, daypartNames)
 			} else {
 				assert.Error(t, err)
 				assert.Equal(t, tc.wantErrMsg, err.Error())
@@ -224,105 +475,849 @@ func TestFindAll(t *testing.T) {
 	}
 }
 
-func TestFindById(t *testing.T) {
+func TestFindByIdWithTranslations(t *testing.T) {
+	restaurant, err := restaurantRepository.FindById(context.Background(), 2000, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "es-ES", restaurant.DefaultLocale)
+	assert.Equal(t, "descripción2", restaurant.Description)
+	assert.Equal(t, map[string]string{"en-GB": "description2"}, restaurant.DescriptionTranslations)
+
+	item := restaurant.Menu.GetItem(1)
+	assert.Equal(t, "artículo2.1", item.GetName())
+	assert.Equal(t, "descripción2.1", item.GetDescription())
+	assert.Equal(t, map[string]*domain.Translation{"en-GB": domain.NewTranslation("item2.1", "description2.1")}, item.GetTranslations())
+	assert.Equal(t, []string{"es-ES", "en-GB"}, restaurant.Locales())
+}
+
+func TestFindNearby(t *testing.T) {
+	// Locations set up for the test restaurants: 1000 and 2000 are ~1.6 km away from
+	// each other, while 3000 is placed in a different city.
+	locations := map[int64][2]float64{
+		1000: {40.4168, -3.7038},
+		2000: {40.4153, -3.6845},
+		3000: {41.3874, 2.1686},
+	}
+	type args struct {
+		latitude  float64
+		longitude float64
+		radius    float64
+		offset    int
+		limit     int
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(sqlmock.Sqlmock)
+		wantIds          []int64
+		wantTotal        int64
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name:      "find restaurants within 3 km sorted by distance",
+			args:      args{latitude: 40.4160, longitude: -3.6900, radius: 3000, offset: 0, limit: 10},
+			wantIds:   []int64{2000, 1000},
+			wantTotal: 2,
+			wantErr:   false,
+		},
+		{
+			name:      "find restaurants within 500 m",
+			args:      args{latitude: 40.4168, longitude: -3.7038, radius: 500, offset: 0, limit: 10},
+			wantIds:   []int64{1000},
+			wantTotal: 1,
+			wantErr:   false,
+		},
+		{
+			name:      "find restaurants within 3 km paginated",
+			args:      args{latitude: 40.4160, longitude: -3.6900, radius: 3000, offset: 1, limit: 1},
+			wantIds:   []int64{1000},
+			wantTotal: 2,
+			wantErr:   false,
+		},
+		{
+			name: "simulate error when finding nearby restaurants",
+			args: args{latitude: 40.4168, longitude: -3.7038, radius: 500, offset: 0, limit: 10},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .+ FROM "restaurant" .+`).WillReturnError(errors.New("error#10"))
+			},
+			wantErr:    true,
+			wantErrMsg: "error#10",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				if tc.mockExpectations == nil {
+					for id, location := range locations {
+						restaurant, _ := repository.FindById(ctx, id, false)
+						point, _ := domain.NewGeoPoint(location[0], location[1])
+						restaurant.Address = restaurant.Address.WithLocation(point)
+						_, err := repository.UpdateProfile(ctx, restaurant)
+						assert.NoError(t, err)
+					}
+				}
+				point, _ := domain.NewGeoPoint(tc.args.latitude, tc.args.longitude)
+				restaurants, total, err := repository.FindNearby(ctx, point, tc.args.radius, tc.args.offset, tc.args.limit)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					assert.Equal(t, tc.wantTotal, total)
+					actualIds := []int64{}
+					for _, restaurant := range restaurants {
+						actualIds = append(actualIds, restaurant.Id)
+					}
+					assert.Equal(t, tc.wantIds, actualIds)
+				} else {
+					assert.Error(t, err)
+					if len(tc.wantErrMsg) > 0 {
+						assert.Equal(t, tc.wantErrMsg, err.Error())
+					}
+				}
+				return errors.New(ROLLBACK_PLEASE)
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	type args struct {
+		text   string
+		offset int
+		limit  int
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(sqlmock.Sqlmock)
+		wantIds          []int64
+		wantTotal        int64
+		wantHighlight    string
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name:          "search restaurants by name",
+			args:          args{text: "restaurant2", offset: 0, limit: 10},
+			wantIds:       []int64{2000},
+			wantTotal:     1,
+			wantHighlight: "<mark>restaurant2</mark>",
+		},
+		{
+			name:          "search restaurants by menu item and city",
+			args:          args{text: "vegan ramen city1", offset: 0, limit: 10},
+			wantIds:       []int64{1000},
+			wantTotal:     1,
+			wantHighlight: "<mark>vegan</mark> <mark>ramen</mark>",
+		},
+		{
+			name:      "search restaurants ranking the name matches first",
+			args:      args{text: "ramen or restaurant3", offset: 0, limit: 10},
+			wantIds:   []int64{3000, 1000},
+			wantTotal: 2,
+		},
+		{
+			name:      "search restaurants paginated",
+			args:      args{text: "ramen or restaurant3", offset: 1, limit: 1},
+			wantIds:   []int64{1000},
+			wantTotal: 2,
+		},
+		{
+			name:      "search restaurants excluding words",
+			args:      args{text: "ramen -city1", offset: 0, limit: 10},
+			wantIds:   []int64{},
+			wantTotal: 0,
+		},
+		{
+			name: "simulate error when searching restaurants",
+			args: args{text: "ramen", offset: 0, limit: 10},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .+ FROM "restaurant_search" .+`).WillReturnError(errors.New("error#11"))
+			},
+			wantErr:    true,
+			wantErrMsg: "error#11",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				if tc.mockExpectations == nil {
+					// The search index is maintained by triggers on every change.
+					err := repository.SaveMenuItem(ctx, 1000, 1, domain.NewMenuItem(4, "vegan ramen", big.NewFloat(9.5)))
+					assert.NoError(t, err)
+				}
+				results, total, err := repository.Search(ctx, tc.args.text, tc.args.offset, tc.args.limit)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					assert.Equal(t, tc.wantTotal, total)
+					actualIds := []int64{}
+					for _, result := range results {
+						actualIds = append(actualIds, result.GetRestaurant().Id)
+						assert.NotEmpty(t, result.GetRestaurant().Menu.GetItems())
+						assert.Greater(t, result.GetRank(), float32(0))
+					}
+					assert.Equal(t, tc.wantIds, actualIds)
+					if len(tc.wantHighlight) > 0 {
+						assert.Contains(t, results[0].GetHighlight(), tc.wantHighlight)
+					}
+				} else {
+					assert.Error(t, err)
+					assert.Equal(t, tc.wantErrMsg, err.Error())
+				}
+				return errors.New(ROLLBACK_PLEASE)
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFindMenuAt(t *testing.T) {
+	type args struct {
+		restaurantId int64
+		at           time.Time
+	}
+	testcases := []struct {
+		name            string
+		args            args
+		wantVersion     int32
+		wantValidFrom   time.Time
+		wantPrices      []string
+		wantErr         bool
+		wantErrMsg      string
+		wantMockedError bool
+	}{
+		{
+			name:          "find the first version of a menu",
+			args:          args{restaurantId: 1000, at: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			wantVersion:   1,
+			wantValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantPrices:    []string{"12.14", "13.15"},
+			wantErr:       false,
+		},
+		{
+			name:          "find the version of a menu valid from the given instant",
+			args:          args{restaurantId: 1000, at: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+			wantVersion:   2,
+			wantValidFrom: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			wantPrices:    []string{"13.14", "14.15", "15.16"},
+			wantErr:       false,
+		},
+		{
+			name:       "find a menu before its first version",
+			args:       args{restaurantId: 1000, at: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+			wantErr:    true,
+			wantErrMsg: "record not found",
+		},
+		{
+			name:            "simulate error with mocked DB",
+			args:            args{restaurantId: 1000, at: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			wantErr:         true,
+			wantErrMsg:      "error#14",
+			wantMockedError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var repository = restaurantRepository
+			if tc.wantMockedError {
+				var mock sqlmock.Sqlmock
+				repository, _, mock = createMockRepository()
+				mock.ExpectQuery(`SELECT .+ FROM "menu_version" .+`).WillReturnError(errors.New("error#14"))
+			}
+			menu, err := repository.FindMenuAt(context.Background(), tc.args.restaurantId, tc.args.at)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantVersion, menu.GetVersion())
+				assert.True(t, tc.wantValidFrom.Equal(*menu.GetValidFrom()))
+				prices := []string{}
+				for _, item := range menu.GetItems() {
+					prices = append(prices, item.GetPrice().Text('f', 2))
+				}
+				assert.Equal(t, tc.wantPrices, prices)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.wantErrMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestFindMenuVersions(t *testing.T) {
+	type args struct {
+		restaurantId int64
+		offset       int
+		limit        int
+	}
+	testcases := []struct {
+		name         string
+		args         args
+		wantVersions []int32
+		wantTotal    int64
+	}{
+		{
+			name:         "find all the versions of a menu, newest first",
+			args:         args{restaurantId: 1000, offset: 0, limit: 10},
+			wantVersions: []int32{2, 1},
+			wantTotal:    2,
+		},
+		{
+			name:         "find a page of the versions of a menu",
+			args:         args{restaurantId: 1000, offset: 1, limit: 10},
+			wantVersions: []int32{1},
+			wantTotal:    2,
+		},
+		{
+			name:         "find the versions of the menu of a restaurant that doesn't exist",
+			args:         args{restaurantId: 1001, offset: 0, limit: 10},
+			wantVersions: []int32{},
+			wantTotal:    0,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			menus, total, err := restaurantRepository.FindMenuVersions(context.Background(), tc.args.restaurantId, tc.args.offset, tc.args.limit)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantTotal, total)
+			versions := []int32{}
+			for _, menu := range menus {
+				versions = append(versions, menu.GetVersion())
+			}
+			assert.Equal(t, tc.wantVersions, versions)
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	type args struct {
+		restaurant *domain.Restaurant
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(sqlmock.Sqlmock)
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name: "save a restaurant successfully",
+			args: args{
+				restaurant: mapper.toDomainRestaurant(newTestRestaurantWithoutId()),
+			},
+			wantErr: false,
+		},
+		{
+			name: "simulate error with mocked DB",
+			args: args{
+				restaurant: mapper.toDomainRestaurant(newTestRestaurantWithoutId()),
+			},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO .+").WillReturnError(errors.New("error#3"))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantErrMsg: "error#3",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				assert.Equal(t, int64(0), tc.args.restaurant.Id)
+				err := repository.Save(ctx, tc.args.restaurant)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					page, _ := repository.FindAll(ctx, &domain.RestaurantQuery{SortBy: domain.RestaurantSortById, SortDirection: domain.SortAscending}, &domain.Pagination{Limit: 100, Count: domain.CountExact})
+					assert.Equal(t, int64(4), *page.Total)
+					actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurant.Id, true)
+					// The dayparts are preloaded, so a new restaurant has none rather than nil.
+					tc.args.restaurant.Dayparts = []*domain.Daypart{}
+					assert.True(t, reflect.DeepEqual(tc.args.restaurant, actualRestaurant))
+				} else {
+					assert.Error(t, err)
+					assert.Equal(t, tc.wantErrMsg, err.Error())
+				}
+
+				// Returning an error instructs the transaction manager to
+				// rollback the transaction so we keep clean the database for
+				// the other tests.
+				return errors.New(ROLLBACK_PLEASE)
+			})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	type args struct {
+		restaurant *domain.Restaurant
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(sqlmock.Sqlmock)
+		wantRowsAffected int64
+		wantMenuItems    int
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name: "update restaurant name",
+			args: args{
+				restaurant: func() *domain.Restaurant {
+					r := mapper.toDomainRestaurant(newTestRestaurant())
+					r.Name = "A different name"
+					return r
+				}(),
+			},
+			wantRowsAffected: 1,
+			wantMenuItems:    3,
+			wantErr:          false,
+		},
+		{
+			name: "update restaurant menu",
+			args: args{
+				restaurant: func() *domain.Restaurant {
+					r := mapper.toDomainRestaurant(newTestRestaurant())
+					r.Menu = mapper.toDomainMenu(newTestMenu()[0:2])
+					return r
+				}(),
+			},
+			wantRowsAffected: 1,
+			wantMenuItems:    2,
+			wantErr:          false,
+		},
+		{
+			name: "update a restaurant that doesn't exist",
+			args: args{
+				restaurant: func() *domain.Restaurant {
+					r := mapper.toDomainRestaurant(newTestRestaurant())
+					r.Id = 1001
+					return r
+				}(),
+			},
+			wantErr:    true,
+			wantErrMsg: domain.ErrRestaurantNotFound.Error(),
+		},
+		{
+			name: "update a restaurant modified concurrently",
+			args: args{
+				restaurant: func() *domain.Restaurant {
+					r := mapper.toDomainRestaurant(newTestRestaurant())
+					r.Version = 5
+					return r
+				}(),
+			},
+			wantErr:    true,
+			wantErrMsg: domain.ErrConcurrentModification.Error(),
+		},
+		{
+			name: "simulate error when deleting menu with mocked DB",
+			args: args{
+				restaurant: mapper.toDomainRestaurant(newTestRestaurant()),
+			},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				// The version is bumped with a raw statement, which Gorm doesn't wrap
+				// in a transaction of its own.
+				mock.ExpectExec("UPDATE restaurant SET version .+").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM .+").WillReturnError(errors.New("error#4"))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantErrMsg: "error#4",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				ra, err := repository.Update(ctx, tc.args.restaurant)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					assert.Equal(t, tc.wantRowsAffected, ra)
+					if ra > 0 {
+						actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurant.Id, true)
+						// The dayparts are left untouched by the update.
+						tc.args.restaurant.Dayparts = mapper.toDomainDayparts(newTestDayparts())
+						assert.True(t, reflect.DeepEqual(tc.args.restaurant, actualRestaurant))
+						assert.Len(t, actualRestaurant.Menu.GetItems(), tc.wantMenuItems)
+					}
+				} else {
+					assert.Error(t, err)
+					if len(tc.wantErrMsg) > 0 {
+						assert.Equal(t, tc.wantErrMsg, err.Error())
+					}
+				}
+
+				return errors.New(ROLLBACK_PLEASE)
+			})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	type args struct {
+		restaurant *domain.Restaurant
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(sqlmock.Sqlmock)
+		wantRowsAffected int64
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name: "rename and relocate a restaurant",
+			args: args{
+				restaurant: func() *domain.Restaurant {
+					r := mapper.toDomainRestaurant(newTestRestaurant())
+					r.Name = "A different name"
+					r.Address = domain.NewAddress("street2", "city2", "state2", "zip2")
+					return r
+				}(),
+			},
+			wantRowsAffected: 1,
+			wantErr:          false,
+		},
+		{
+			name: "describe a restaurant in several locales",
+			args: args{
+				restaurant: func() *domain.Restaurant {
+					r := mapper.toDomainRestaurant(newTestRestaurant())
+					r.DefaultLocale = "es-ES"
+					r.Description = "Cocina casera"
+					r.DescriptionTranslations = map[string]string{"en-GB": "Home cooking"}
+					return r
+				}(),
+			},
+			wantRowsAffected: 1,
+			wantErr:          false,
+		},
+		{
+			name: "change the time zone of a restaurant",
+			args: args{
+				restaurant: func() *domain.Restaurant {
+					r := mapper.toDomainRestaurant(newTestRestaurant())
+					r.TimeZone = "Europe/Madrid"
+					return r
+				}(),
+			},
+			wantRowsAffected: 1,
+			wantErr:          false,
+		},
+		{
+			name: "update a restaurant that doesn't exist",
+			args: args{
+				restaurant: func() *domain.Restaurant {
+					r := mapper.toDomainRestaurant(newTestRestaurant())
+					r.Id = 1001
+					return r
+				}(),
+			},
+			wantErr:    true,
+			wantErrMsg: domain.ErrRestaurantNotFound.Error(),
+		},
+		{
+			name: "simulate error when updating a restaurant",
+			args: args{
+				restaurant: mapper.toDomainRestaurant(newTestRestaurant()),
+			},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE restaurant SET version .+").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE .+").WillReturnError(errors.New("error#9"))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantErrMsg: "error#9",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				ra, err := repository.UpdateProfile(ctx, tc.args.restaurant)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					assert.Equal(t, tc.wantRowsAffected, ra)
+					if ra > 0 {
+						actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurant.Id, true)
+						assert.Equal(t, tc.args.restaurant.Name, actualRestaurant.Name)
+						assert.True(t, tc.args.restaurant.Address.Equals(actualRestaurant.Address))
+						assert.Equal(t, tc.args.restaurant.DefaultLocale, actualRestaurant.DefaultLocale)
+						assert.Equal(t, tc.args.restaurant.TimeZone, actualRestaurant.TimeZone)
+						assert.Equal(t, tc.args.restaurant.Description, actualRestaurant.Description)
+						assert.Equal(t, tc.args.restaurant.DescriptionTranslations, actualRestaurant.DescriptionTranslations)
+						assert.Len(t, actualRestaurant.Menu.GetItems(), 3)
+						assert.Equal(t, int64(2), actualRestaurant.Version)
+						assert.Equal(t, actualRestaurant.Version, tc.args.restaurant.Version)
+					}
+				} else {
+					assert.Error(t, err)
+					if len(tc.wantErrMsg) > 0 {
+						assert.Equal(t, tc.wantErrMsg, err.Error())
+					}
+				}
+
+				return errors.New(ROLLBACK_PLEASE)
+			})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUpdateDayparts(t *testing.T) {
+	type args struct {
+		restaurantId int64
+		dayparts     []*domain.Daypart
+	}
+	lunch := domain.NewDaypart("lunch", []*domain.TimeWindow{domain.NewTimeWindow(720, 960)},
+		domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "salad", big.NewFloat(8.5))}))
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(sqlmock.Sqlmock)
+		wantDaypartNames []string
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name:             "replace the dayparts of a restaurant",
+			args:             args{restaurantId: 1000, dayparts: []*domain.Daypart{lunch}},
+			wantDaypartNames: []string{"lunch"},
+			wantErr:          false,
+		},
+		{
+			name:             "remove the dayparts of a restaurant",
+			args:             args{restaurantId: 1000, dayparts: []*domain.Daypart{}},
+			wantDaypartNames: nil,
+			wantErr:          false,
+		},
+		{
+			name:       "add dayparts to a restaurant that doesn't exist",
+			args:       args{restaurantId: 1001, dayparts: []*domain.Daypart{lunch}},
+			wantErr:    true,
+			wantErrMsg: domain.ErrRestaurantNotFound.Error(),
+		},
+		{
+			name: "simulate error when replacing the dayparts of a restaurant",
+			args: args{restaurantId: 1000, dayparts: []*domain.Daypart{lunch}},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE restaurant SET version .+").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE .+").WillReturnError(errors.New("error#16"))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantErrMsg: "error#16",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				err := repository.UpdateDayparts(ctx, tc.args.restaurantId, 1, tc.args.dayparts)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurantId, true)
+					var daypartNames []string
+					for _, daypart := range actualRestaurant.Dayparts {
+						daypartNames = append(daypartNames, daypart.GetName())
+						assert.Len(t, daypart.GetMenu().GetItems(), 1)
+						assert.Equal(t, []*domain.TimeWindow{domain.NewTimeWindow(720, 960)}, daypart.GetWindows())
+					}
+					assert.Equal(t, tc.wantDaypartNames, daypartNames)
+				} else {
+					assert.Error(t, err)
+					assert.Contains(t, err.Error(), tc.wantErrMsg)
+				}
+
+				return errors.New(ROLLBACK_PLEASE)
+			})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSaveMenuItem(t *testing.T) {
 	type args struct {
 		restaurantId int64
-		fetchMenu    bool
+		menuItem     *domain.MenuItem
 	}
 	testcases := []struct {
-		name           string
-		args           args
-		wantRestaurant *Restaurant
-		wantErr        bool
-		wantErrMsg     string
+		name             string
+		args             args
+		mockExpectations func(sqlmock.Sqlmock)
+		wantErr          bool
+		wantErrMsg       string
 	}{
 		{
-			name: "find a restaurant that exists fetching its menu",
+			name: "add a new menu item",
 			args: args{
 				restaurantId: 1000,
-				fetchMenu:    true,
+				menuItem: domain.NewMenuItem(4, "item1.4", big.NewFloat(16.17)).
+					WithLabels([]domain.Allergen{domain.AllergenNuts}, []domain.DietaryTag{domain.DietaryTagVegan}),
 			},
-			wantRestaurant: newTestRestaurant(),
-			wantErr:        false,
+			wantErr: false,
 		},
 		{
-			name: "find a restaurant that doesn't exist fetching its menu",
+			name: "add a menu item that already exists",
 			args: args{
-				restaurantId: 1001,
-				fetchMenu:    true,
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(2, "item1.2", big.NewFloat(14.15)),
 			},
-			wantRestaurant: nil,
-			wantErr:        true,
-			wantErrMsg:     "record not found",
+			wantErr: true,
 		},
 		{
-			name: "find a restaurant that exists not fetching its menu",
+			name: "simulate error when adding a menu item",
 			args: args{
 				restaurantId: 1000,
-				fetchMenu:    false,
+				menuItem:     domain.NewMenuItem(4, "item1.4", big.NewFloat(16.17)),
 			},
-			wantRestaurant: newTestRestaurantWithoutMenu(),
-			wantErr:        false,
-		},
-		{
-			name: "find a restaurant that doesn't exist not fetching its menu",
-			args: args{
-				restaurantId: 1001,
-				fetchMenu:    false,
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE restaurant SET version .+").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO .+").WillReturnError(errors.New("error#7"))
+				mock.ExpectRollback()
 			},
-			wantRestaurant: nil,
-			wantErr:        true,
-			wantErrMsg:     "record not found",
+			wantErr:    true,
+			wantErrMsg: "error#7",
 		},
 	}
-
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			restaurant, err := restaurantRepository.FindById(context.Background(), tc.args.restaurantId, tc.args.fetchMenu)
-			assert.Equal(t, tc.wantRestaurant, mapper.fromDomainRestaurant(restaurant))
-			if !tc.wantErr {
-				assert.NoError(t, err)
-			} else {
-				assert.Error(t, err)
-				assert.Equal(t, tc.wantErrMsg, err.Error())
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
 			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				err := repository.SaveMenuItem(ctx, tc.args.restaurantId, 1, tc.args.menuItem)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurantId, true)
+					actualItem := actualRestaurant.Menu.GetItem(tc.args.menuItem.GetId())
+					assert.Equal(t, mapper.fromDomainMenuItem(tc.args.menuItem), mapper.fromDomainMenuItem(actualItem))
+				} else {
+					assert.Error(t, err)
+					if len(tc.wantErrMsg) > 0 {
+						assert.Equal(t, tc.wantErrMsg, err.Error())
+					}
+				}
+				return errors.New(ROLLBACK_PLEASE)
+			})
+			assert.Error(t, err)
 		})
 	}
 }
 
-func TestSave(t *testing.T) {
+func TestUpdateMenuItem(t *testing.T) {
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
 	type args struct {
-		restaurant *domain.Restaurant
+		restaurantId int64
+		menuItem     *domain.MenuItem
 	}
 	testcases := []struct {
 		name             string
 		args             args
 		mockExpectations func(sqlmock.Sqlmock)
+		wantRowsAffected int64
 		wantErr          bool
 		wantErrMsg       string
 	}{
 		{
-			name: "save a restaurant successfully",
+			name: "mark a menu item as not available",
 			args: args{
-				restaurant: mapper.toDomainRestaurant(newTestRestaurantWithoutId()),
+				restaurantId: 1000,
+				menuItem:     mapper.toDomainMenu(newTestMenu()).GetItem(2).WithAvailability(false, &until),
 			},
-			wantErr: false,
+			wantRowsAffected: 1,
+			wantErr:          false,
 		},
 		{
-			name: "simulate error with mocked DB",
+			name: "update a menu item that doesn't exist",
 			args: args{
-				restaurant: mapper.toDomainRestaurant(newTestRestaurantWithoutId()),
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(99, "item", big.NewFloat(1.0)),
+			},
+			wantRowsAffected: 0,
+			wantErr:          false,
+		},
+		{
+			name: "simulate error when updating a menu item",
+			args: args{
+				restaurantId: 1000,
+				menuItem:     mapper.toDomainMenu(newTestMenu()).GetItem(2),
 			},
 			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE restaurant SET version .+").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectBegin()
-				mock.ExpectExec("INSERT INTO .+").WillReturnError(errors.New("error#3"))
+				mock.ExpectExec("UPDATE .+").WillReturnError(errors.New("error#6"))
 				mock.ExpectRollback()
 			},
 			wantErr:    true,
-			wantErrMsg: "error#3",
+			wantErrMsg: "error#6",
 		},
 	}
-
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx := context.Background()
@@ -334,96 +1329,70 @@ func TestSave(t *testing.T) {
 				tc.mockExpectations(mock)
 			}
 			err := trm.Do(ctx, func(ctx context.Context) error {
-				assert.Equal(t, int64(0), tc.args.restaurant.Id)
-				err := repository.Save(ctx, tc.args.restaurant)
+				ra, err := repository.UpdateMenuItem(ctx, tc.args.restaurantId, 1, tc.args.menuItem)
 				if !tc.wantErr {
 					assert.NoError(t, err)
-					_, total, _ := repository.FindAll(ctx, 0, 100)
-					assert.Equal(t, int64(4), total)
-					actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurant.Id, true)
-					assert.True(t, reflect.DeepEqual(tc.args.restaurant, actualRestaurant))
+					assert.Equal(t, tc.wantRowsAffected, ra)
+					if ra > 0 {
+						actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurantId, true)
+						actualItem := actualRestaurant.Menu.GetItem(tc.args.menuItem.GetId())
+						assert.Equal(t, tc.args.menuItem.IsAvailable(), actualItem.IsAvailable())
+						assert.True(t, tc.args.menuItem.GetAvailableUntil().Equal(*actualItem.GetAvailableUntil()))
+					}
 				} else {
 					assert.Error(t, err)
-					assert.Equal(t, tc.wantErrMsg, err.Error())
+					if len(tc.wantErrMsg) > 0 {
+						assert.Equal(t, tc.wantErrMsg, err.Error())
+					}
 				}
-
-				// Returning an error instructs the transaction manager to
-				// rollback the transaction so we keep clean the database for
-				// the other tests.
 				return errors.New(ROLLBACK_PLEASE)
 			})
-
 			assert.Error(t, err)
 		})
 	}
 }
 
-func TestUpdate(t *testing.T) {
+func TestDeleteMenuItem(t *testing.T) {
 	type args struct {
-		restaurant *domain.Restaurant
+		restaurantId int64
+		menuItemId   int16
 	}
 	testcases := []struct {
 		name             string
 		args             args
 		mockExpectations func(sqlmock.Sqlmock)
 		wantRowsAffected int64
-		wantMenuItems    int
+		wantMenuVersion  int32
 		wantErr          bool
 		wantErrMsg       string
 	}{
 		{
-			name: "update restaurant name",
-			args: args{
-				restaurant: func() *domain.Restaurant {
-					r := mapper.toDomainRestaurant(newTestRestaurant())
-					r.Name = "A different name"
-					return r
-				}(),
-			},
-			wantRowsAffected: 1,
-			wantMenuItems:    3,
-			wantErr:          false,
-		},
-		{
-			name: "update restaurant menu",
-			args: args{
-				restaurant: func() *domain.Restaurant {
-					r := mapper.toDomainRestaurant(newTestRestaurant())
-					r.Menu = mapper.toDomainMenu(newTestMenu()[0:2])
-					return r
-				}(),
-			},
+			name:             "delete an existing menu item",
+			args:             args{restaurantId: 1000, menuItemId: 2},
 			wantRowsAffected: 1,
-			wantMenuItems:    2,
+			wantMenuVersion:  3,
 			wantErr:          false,
 		},
 		{
-			name: "update a restaurant that doesn't exist",
-			args: args{
-				restaurant: func() *domain.Restaurant {
-					r := mapper.toDomainRestaurant(newTestRestaurant())
-					r.Id = 1001
-					return r
-				}(),
-			},
+			name:             "delete a menu item that doesn't exist",
+			args:             args{restaurantId: 1000, menuItemId: 99},
 			wantRowsAffected: 0,
-			wantErr:          true,
+			wantMenuVersion:  2,
+			wantErr:          false,
 		},
 		{
-			name: "simulate error when deleting menu with mocked DB",
-			args: args{
-				restaurant: mapper.toDomainRestaurant(newTestRestaurant()),
-			},
+			name: "simulate error when deleting a menu item",
+			args: args{restaurantId: 1000, menuItemId: 2},
 			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE restaurant SET version .+").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectBegin()
-				mock.ExpectExec("DELETE FROM .+").WillReturnError(errors.New("error#4"))
+				mock.ExpectExec("DELETE FROM .+").WillReturnError(errors.New("error#8"))
 				mock.ExpectRollback()
 			},
 			wantErr:    true,
-			wantErrMsg: "error#4",
+			wantErrMsg: "error#8",
 		},
 	}
-
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx := context.Background()
@@ -435,25 +1404,21 @@ func TestUpdate(t *testing.T) {
 				tc.mockExpectations(mock)
 			}
 			err := trm.Do(ctx, func(ctx context.Context) error {
-				ra, err := repository.Update(ctx, tc.args.restaurant)
+				ra, err := repository.DeleteMenuItem(ctx, tc.args.restaurantId, 1, tc.args.menuItemId)
 				if !tc.wantErr {
 					assert.NoError(t, err)
 					assert.Equal(t, tc.wantRowsAffected, ra)
-					if ra > 0 {
-						actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurant.Id, true)
-						assert.True(t, reflect.DeepEqual(tc.args.restaurant, actualRestaurant))
-						assert.Len(t, actualRestaurant.Menu.GetItems(), tc.wantMenuItems)
-					}
+					actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurantId, true)
+					assert.Nil(t, actualRestaurant.Menu.GetItem(tc.args.menuItemId))
+					assert.Equal(t, tc.wantMenuVersion, actualRestaurant.Menu.GetVersion())
 				} else {
 					assert.Error(t, err)
 					if len(tc.wantErrMsg) > 0 {
 						assert.Equal(t, tc.wantErrMsg, err.Error())
 					}
 				}
-
 				return errors.New(ROLLBACK_PLEASE)
 			})
-
 			assert.Error(t, err)
 		})
 	}
@@ -484,8 +1449,8 @@ func TestDelete(t *testing.T) {
 			args: args{
 				restaurantId: 1001,
 			},
-			wantRowsAffected: 0,
-			wantErr:          false,
+			wantErr:    true,
+			wantErrMsg: domain.ErrRestaurantNotFound.Error(),
 		},
 		{
 			name: "simulate error when deleting a restaurant",
@@ -493,8 +1458,9 @@ func TestDelete(t *testing.T) {
 				restaurantId: 1001,
 			},
 			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE restaurant SET version .+").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectBegin()
-				mock.ExpectExec("DELETE FROM .+").WillReturnError(errors.New("error#5"))
+				mock.ExpectExec(`UPDATE "restaurant" SET "deleted_at".+`).WillReturnError(errors.New("error#5"))
 				mock.ExpectRollback()
 			},
 			wantRowsAffected: 0,
@@ -514,13 +1480,16 @@ func TestDelete(t *testing.T) {
 				tc.mockExpectations(mock)
 			}
 			err := trm.Do(ctx, func(ctx context.Context) error {
-				ra, err := repository.Delete(ctx, tc.args.restaurantId)
+				ra, err := repository.Delete(ctx, tc.args.restaurantId, 1)
 				if !tc.wantErr {
 					assert.NoError(t, err)
 					assert.Equal(t, tc.wantRowsAffected, ra)
 					if ra > 0 {
 						actualRestaurant, _ := repository.FindById(ctx, tc.args.restaurantId, false)
 						assert.Nil(t, actualRestaurant)
+						deletedRestaurant, err := repository.FindDeletedById(ctx, tc.args.restaurantId)
+						assert.NoError(t, err)
+						assert.True(t, deletedRestaurant.IsDeleted())
 					}
 				} else {
 					assert.Error(t, err)
@@ -537,6 +1506,148 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestRestore(t *testing.T) {
+	type args struct {
+		restaurantId int64
+		version      int64
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		deleteBefore     bool
+		mockExpectations func(sqlmock.Sqlmock)
+		wantRowsAffected int64
+		wantErr          error
+		wantErrMsg       string
+	}{
+		{
+			name:             "restore a deleted restaurant",
+			args:             args{restaurantId: 1000, version: 2},
+			deleteBefore:     true,
+			wantRowsAffected: 1,
+		},
+		{
+			name:             "restore a restaurant that isn't deleted",
+			args:             args{restaurantId: 1000, version: 1},
+			wantRowsAffected: 0,
+		},
+		{
+			name:         "restore a deleted restaurant modified concurrently",
+			args:         args{restaurantId: 1000, version: 1},
+			deleteBefore: true,
+			wantErr:      domain.ErrConcurrentModification,
+		},
+		{
+			name: "simulate error when restoring a restaurant",
+			args: args{restaurantId: 1000, version: 2},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE restaurant SET deleted_at = NULL.+").WillReturnError(errors.New("error#20"))
+			},
+			wantErrMsg: "error#20",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				if tc.deleteBefore {
+					_, err := repository.Delete(ctx, tc.args.restaurantId, 1)
+					assert.NoError(t, err)
+				}
+				ra, err := repository.Restore(ctx, tc.args.restaurantId, tc.args.version)
+				switch {
+				case tc.wantErr != nil:
+					assert.ErrorIs(t, err, tc.wantErr)
+				case len(tc.wantErrMsg) > 0:
+					assert.EqualError(t, err, tc.wantErrMsg)
+				default:
+					assert.NoError(t, err)
+					assert.Equal(t, tc.wantRowsAffected, ra)
+					if ra > 0 {
+						actualRestaurant, err := repository.FindById(ctx, tc.args.restaurantId, false)
+						assert.NoError(t, err)
+						assert.False(t, actualRestaurant.IsDeleted())
+						assert.Equal(t, tc.args.version+1, actualRestaurant.Version)
+					}
+				}
+
+				return errors.New(ROLLBACK_PLEASE)
+			})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	testcases := []struct {
+		name             string
+		before           time.Time
+		mockExpectations func(sqlmock.Sqlmock)
+		wantPurged       int64
+		wantErrMsg       string
+	}{
+		{
+			name:       "purge the restaurants deleted before an instant",
+			before:     time.Now().Add(time.Hour),
+			wantPurged: 1,
+		},
+		{
+			name:       "keep the restaurants deleted after an instant",
+			before:     time.Now().Add(-time.Hour),
+			wantPurged: 0,
+		},
+		{
+			name:   "simulate error when purging the restaurants",
+			before: time.Now(),
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM menu_item .+").WillReturnError(errors.New("error#21"))
+			},
+			wantErrMsg: "error#21",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = restaurantRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				if tc.mockExpectations == nil {
+					_, err := repository.Delete(ctx, 1000, 1)
+					assert.NoError(t, err)
+				}
+				purged, err := repository.Purge(ctx, tc.before)
+				if len(tc.wantErrMsg) > 0 {
+					assert.EqualError(t, err, tc.wantErrMsg)
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, tc.wantPurged, purged)
+					_, err = repository.FindDeletedById(ctx, 1000)
+					assert.Equal(t, tc.wantPurged > 0, errors.Is(err, gorm.ErrRecordNotFound))
+				}
+
+				return errors.New(ROLLBACK_PLEASE)
+			})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
 // createMockRepository creates a repository that uses gorm as usual but a sqlmock
 // connection as the underlying database connection, returning also the sqlmock instance
 // that we can use to define mockExpectations and a NOP transaction manager.
@@ -563,15 +1674,23 @@ func createMockRepository() (*RestaurantPostgresRepository, trm.Manager, sqlmock
 // --------------------------------------------------------------------------------
 
 func newTestRestaurant() *Restaurant {
-	return &Restaurant{ID: 1000, Name: "restaurant1", Address: newTestAddress(), Menu: newTestMenu()}
+	return &Restaurant{ID: 1000, TenantID: "tenant1", Name: "restaurant1", DefaultLocale: "en", TimeZone: "UTC", Address: newTestAddress(), Menu: newTestMenu(), MenuVersion: 2, Version: 1}
 }
 
 func newTestRestaurantWithoutMenu() *Restaurant {
-	return &Restaurant{ID: 1000, Name: "restaurant1", Address: newTestAddress(), Menu: nil}
+	return &Restaurant{ID: 1000, TenantID: "tenant1", Name: "restaurant1", DefaultLocale: "en", TimeZone: "UTC", Address: newTestAddress(), Menu: nil, Version: 1}
 }
 
 func newTestRestaurantWithoutId() *Restaurant {
-	return &Restaurant{Name: "restaurant1", Address: newTestAddress(), Menu: newTestMenu()}
+	return &Restaurant{TenantID: "tenant1", Name: "restaurant1", DefaultLocale: "en", TimeZone: "UTC", Address: newTestAddress(), Menu: newTestMenu(), Version: 1}
+}
+
+func newTestDayparts() []*Daypart {
+	breakfast := Daypart{RestaurantID: 1000, Name: "breakfast", Position: 0, Windows: []*TimeWindow{{Start: 420, End: 660}},
+		Items: []*MenuItem{{Id: 1, Name: "coffee", Price: "1.50", Available: true}, {Id: 2, Name: "toast", Price: "2.50", Available: true}}}
+	dinner := Daypart{RestaurantID: 1000, Name: "dinner", Position: 1, Windows: []*TimeWindow{{Start: 1200, End: 1440}, {Start: 0, End: 60}},
+		Items: []*MenuItem{{Id: 1, Name: "steak", Price: "21.50", Available: true}, {Id: 2, Name: "wine", Price: "5.50", Available: true}}}
+	return []*Daypart{&breakfast, &dinner}
 }
 
 func newTestAddress() *Address {
@@ -579,8 +1698,8 @@ func newTestAddress() *Address {
 }
 
 func newTestMenu() []*MenuItem {
-	item1 := MenuItem{Id: 1, Name: "item1.1", Price: "13.14"}
-	item2 := MenuItem{Id: 2, Name: "item1.2", Price: "14.15"}
-	item3 := MenuItem{Id: 3, Name: "item1.3", Price: "15.16"}
+	item1 := MenuItem{Id: 1, Name: "item1.1", Price: "13.14", Available: true}
+	item2 := MenuItem{Id: 2, Name: "item1.2", Price: "14.15", Available: true}
+	item3 := MenuItem{Id: 3, Name: "item1.3", Price: "15.16", Available: true}
 	return []*MenuItem{&item1, &item2, &item3}
 }
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+	"time"
+
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	tally "github.com/uber-go/tally/v4"
+	"gorm.io/gorm"
+)
+
+// Key of the Postgres advisory lock that coordinates the activation of the
+// scheduled menus across the replicas of the service.
+const scheduledMenuLockKey int64 = 7_402_951
+
+// Postgres implementation of the secondary port ScheduledMenuRepository. It uses
+// GORM to persist and retrieve scheduled menus from Postgres.
+type ScheduledMenuPostgresRepository struct {
+	mapper    Mapper
+	db        *gorm.DB
+	ctxGetter *trmgorm.CtxGetter
+	timers    map[timerEnum]tally.Timer
+}
+
+// Interface compliance verification.
+var _ port.ScheduledMenuRepository = (*ScheduledMenuPostgresRepository)(nil)
+
+// Enumeration of timers for scheduled menu repository operations.
+const (
+	findScheduledMenusByRestaurant timerEnum = iota
+	findDueScheduledMenus
+	saveScheduledMenu
+	deleteScheduledMenu
+	tryLockScheduledMenus
+)
+
+func NewScheduledMenuPostgresRepository(db *gorm.DB, ctxGetter *trmgorm.CtxGetter, scope tally.Scope) *ScheduledMenuPostgresRepository {
+	var timers map[timerEnum]tally.Timer
+	if scope != nil {
+		FindByRestaurant := scope.Tagged(map[string]string{"repository": "scheduled_menu", "operation": "FindByRestaurant"}).Timer("repository_latencies")
+		FindDue := scope.Tagged(map[string]string{"repository": "scheduled_menu", "operation": "FindDue"}).Timer("repository_latencies")
+		Save := scope.Tagged(map[string]string{"repository": "scheduled_menu", "operation": "Save"}).Timer("repository_latencies")
+		Delete := scope.Tagged(map[string]string{"repository": "scheduled_menu", "operation": "Delete"}).Timer("repository_latencies")
+		TryLock := scope.Tagged(map[string]string{"repository": "scheduled_menu", "operation": "TryLock"}).Timer("repository_latencies")
+
+		timers = make(map[timerEnum]tally.Timer)
+		timers[findScheduledMenusByRestaurant] = FindByRestaurant
+		timers[findDueScheduledMenus] = FindDue
+		timers[saveScheduledMenu] = Save
+		timers[deleteScheduledMenu] = Delete
+		timers[tryLockScheduledMenus] = TryLock
+	}
+	return &ScheduledMenuPostgresRepository{mapper: DefaultMapper{}, db: db, ctxGetter: ctxGetter, timers: timers}
+}
+
+// FindByRestaurant retrieves the pending scheduled menus of a restaurant, sorted by
+// the instant they become effective.
+func (r *ScheduledMenuPostgresRepository) FindByRestaurant(ctx context.Context, restaurantId int64) ([]*domain.ScheduledMenu, error) {
+	var scheduledMenus []*ScheduledMenu
+	if err := r.executeWithTimer(findScheduledMenusByRestaurant, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ?", restaurantId).Order("effective_from ASC, id ASC").Find(&scheduledMenus).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return r.mapper.toDomainScheduledMenus(scheduledMenus), nil
+}
+
+// FindDue retrieves the scheduled menus (of any restaurant) already effective at the
+// given instant, oldest first. The menus of deleted restaurants are left pending, so
+// that they are activated if the restaurant is restored (or removed when it's purged).
+func (r *ScheduledMenuPostgresRepository) FindDue(ctx context.Context, at time.Time, limit int) ([]*domain.ScheduledMenu, error) {
+	var scheduledMenus []*ScheduledMenu
+	if err := r.executeWithTimer(findDueScheduledMenus, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("effective_from <= ?", at).
+			Where("NOT EXISTS (SELECT 1 FROM restaurant WHERE restaurant.id = scheduled_menu.restaurant_id AND restaurant.deleted_at IS NOT NULL)").
+			Order("effective_from ASC, id ASC").Limit(limit).Find(&scheduledMenus).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return r.mapper.toDomainScheduledMenus(scheduledMenus), nil
+}
+
+// Save persists a scheduled menu in the database.
+func (r *ScheduledMenuPostgresRepository) Save(ctx context.Context, scheduledMenu *domain.ScheduledMenu) error {
+	scheduledMenuDto := r.mapper.fromDomainScheduledMenu(scheduledMenu)
+	if err := r.executeWithTimer(saveScheduledMenu, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Create(scheduledMenuDto).Error
+	}); err != nil {
+		return err
+	}
+	scheduledMenu.Id = scheduledMenuDto.ID
+	return nil
+}
+
+// Delete deletes a scheduled menu of a restaurant.
+func (r *ScheduledMenuPostgresRepository) Delete(ctx context.Context, restaurantId int64, scheduledMenuId int64) (int64, error) {
+	var result *gorm.DB
+	if err := r.executeWithTimer(deleteScheduledMenu, func() error {
+		result = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Where("restaurant_id = ? AND id = ?", restaurantId, scheduledMenuId).Delete(&ScheduledMenu{})
+		return result.Error
+	}); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+// TryLock tries to acquire a transaction level advisory lock, so it must be called
+// within a transaction (the lock is released when it finishes).
+func (r *ScheduledMenuPostgresRepository) TryLock(ctx context.Context) (bool, error) {
+	var acquired bool
+	if err := r.executeWithTimer(tryLockScheduledMenus, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Raw("SELECT pg_try_advisory_xact_lock(?)", scheduledMenuLockKey).Scan(&acquired).Error
+	}); err != nil {
+		return false, err
+	}
+
+	return acquired, nil
+}
+
+// executeWithTimer executes a function using a tally timer if present.
+func (r *ScheduledMenuPostgresRepository) executeWithTimer(t timerEnum, fn func() error) error {
+	if r.timers[t] != nil {
+		tsw := r.timers[t].Start()
+		defer tsw.Stop()
+	}
+	return fn()
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/test"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestFindScheduledMenusByRestaurant(t *testing.T) {
+	testcases := []struct {
+		name         string
+		restaurantId int64
+		wantIds      []int64
+	}{
+		{
+			name:         "find the scheduled menus of a restaurant that has some",
+			restaurantId: 1000,
+			wantIds:      []int64{1000, 2000},
+		},
+		{
+			name:         "find the scheduled menus of a restaurant that has none",
+			restaurantId: 2000,
+			wantIds:      []int64{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheduledMenus, err := scheduledMenuRepository.FindByRestaurant(context.Background(), tc.restaurantId)
+			assert.NoError(t, err)
+			ids := []int64{}
+			for _, scheduledMenu := range scheduledMenus {
+				ids = append(ids, scheduledMenu.Id)
+				assert.Equal(t, tc.restaurantId, scheduledMenu.RestaurantId)
+				assert.Len(t, scheduledMenu.Menu.GetItems(), 2)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
+func TestFindDueScheduledMenus(t *testing.T) {
+	testcases := []struct {
+		name    string
+		at      time.Time
+		wantIds []int64
+	}{
+		{
+			name:    "find the scheduled menus effective now",
+			at:      time.Now(),
+			wantIds: []int64{1000},
+		},
+		{
+			name:    "find the scheduled menus effective before any of them",
+			at:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantIds: []int64{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheduledMenus, err := scheduledMenuRepository.FindDue(context.Background(), tc.at, 10)
+			assert.NoError(t, err)
+			ids := []int64{}
+			for _, scheduledMenu := range scheduledMenus {
+				ids = append(ids, scheduledMenu.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
+func TestSaveScheduledMenu(t *testing.T) {
+	testcases := []struct {
+		name             string
+		scheduledMenu    *domain.ScheduledMenu
+		mockExpectations func(sqlmock.Sqlmock)
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name:          "save a new scheduled menu",
+			scheduledMenu: newTestDomainScheduledMenu(2000),
+			wantErr:       false,
+		},
+		{
+			name:          "save a scheduled menu of a restaurant that doesn't exist",
+			scheduledMenu: newTestDomainScheduledMenu(2001),
+			wantErr:       true,
+			wantErrMsg:    "violates foreign key constraint",
+		},
+		{
+			name:          "simulate error when saving a scheduled menu",
+			scheduledMenu: newTestDomainScheduledMenu(2000),
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO .+").WillReturnError(errors.New("error#15"))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantErrMsg: "error#15",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = scheduledMenuRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockScheduledMenuRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				err := repository.Save(ctx, tc.scheduledMenu)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					assert.NotZero(t, tc.scheduledMenu.Id)
+					actualScheduledMenus, _ := repository.FindByRestaurant(ctx, tc.scheduledMenu.RestaurantId)
+					assert.Len(t, actualScheduledMenus, 1)
+					assert.Equal(t, tc.scheduledMenu.Id, actualScheduledMenus[0].Id)
+					assert.WithinDuration(t, tc.scheduledMenu.EffectiveFrom, actualScheduledMenus[0].EffectiveFrom, time.Millisecond)
+					assert.Len(t, actualScheduledMenus[0].Menu.GetItems(), 1)
+				} else {
+					assert.Error(t, err)
+					assert.Contains(t, err.Error(), tc.wantErrMsg)
+				}
+				return errors.New(ROLLBACK_PLEASE)
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDeleteScheduledMenu(t *testing.T) {
+	testcases := []struct {
+		name             string
+		restaurantId     int64
+		scheduledMenuId  int64
+		wantRowsAffected int64
+	}{
+		{
+			name:             "delete a scheduled menu that exists",
+			restaurantId:     1000,
+			scheduledMenuId:  2000,
+			wantRowsAffected: 1,
+		},
+		{
+			name:             "delete a scheduled menu of another restaurant",
+			restaurantId:     2000,
+			scheduledMenuId:  2000,
+			wantRowsAffected: 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			err := trManager.Do(ctx, func(ctx context.Context) error {
+				ra, err := scheduledMenuRepository.Delete(ctx, tc.restaurantId, tc.scheduledMenuId)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantRowsAffected, ra)
+				return errors.New(ROLLBACK_PLEASE)
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTryLockScheduledMenus(t *testing.T) {
+	ctx := context.Background()
+	err := trManager.Do(ctx, func(ctx context.Context) error {
+		acquired, err := scheduledMenuRepository.TryLock(ctx)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+
+		// The lock is held by the outer transaction, so another session can't take it.
+		var acquiredByOther bool
+		db.Raw("SELECT pg_try_advisory_xact_lock(?)", scheduledMenuLockKey).Scan(&acquiredByOther)
+		assert.False(t, acquiredByOther)
+		return errors.New(ROLLBACK_PLEASE)
+	})
+	assert.Error(t, err)
+}
+
+func createMockScheduledMenuRepository() (*ScheduledMenuPostgresRepository, trm.Manager, sqlmock.Sqlmock) {
+	mockDb, mock, _ := sqlmock.New()
+	dialector := postgres.New(postgres.Config{
+		DriverName:           "go-sqlmock",
+		DSN:                  "go-sqlmock",
+		PreferSimpleProtocol: true,
+		WithoutReturning:     true,
+		Conn:                 mockDb,
+	})
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+	if err != nil {
+		panic("failed to connect to database")
+	}
+	return NewScheduledMenuPostgresRepository(db, trmgorm.DefaultCtxGetter, nil), test.NewNopTrManager(), mock
+}
+
+func newTestDomainScheduledMenu(restaurantId int64) *domain.ScheduledMenu {
+	return &domain.ScheduledMenu{
+		RestaurantId:  restaurantId,
+		EffectiveFrom: time.Now().Add(time.Hour),
+		Menu:          domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "item1", big.NewFloat(10.5))}),
+	}
+}
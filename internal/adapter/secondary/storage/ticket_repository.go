@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	tally "github.com/uber-go/tally/v4"
+	"gorm.io/gorm"
+)
+
+// Postgres implementation of the secondary port TicketRepository. It uses GORM
+// to persist and retrieve tickets from Postgres.
+type TicketPostgresRepository struct {
+	mapper    Mapper
+	db        *gorm.DB
+	ctxGetter *trmgorm.CtxGetter
+	timers    map[timerEnum]tally.Timer
+}
+
+// Interface compliance verification.
+var _ port.TicketRepository = (*TicketPostgresRepository)(nil)
+
+// Enumeration of timers for ticket repository operations.
+const (
+	findTicketById timerEnum = iota
+	findTicketByOrderId
+	findTicketsByRestaurant
+	saveTicket
+	updateTicketState
+)
+
+func NewTicketPostgresRepository(db *gorm.DB, ctxGetter *trmgorm.CtxGetter, scope tally.Scope) *TicketPostgresRepository {
+	var timers map[timerEnum]tally.Timer
+	if scope != nil {
+		FindById := scope.Tagged(map[string]string{"repository": "ticket", "operation": "FindById"}).Timer("repository_latencies")
+		FindByOrderId := scope.Tagged(map[string]string{"repository": "ticket", "operation": "FindByOrderId"}).Timer("repository_latencies")
+		FindByRestaurant := scope.Tagged(map[string]string{"repository": "ticket", "operation": "FindByRestaurant"}).Timer("repository_latencies")
+		Save := scope.Tagged(map[string]string{"repository": "ticket", "operation": "Save"}).Timer("repository_latencies")
+		UpdateState := scope.Tagged(map[string]string{"repository": "ticket", "operation": "UpdateState"}).Timer("repository_latencies")
+
+		timers = make(map[timerEnum]tally.Timer)
+		timers[findTicketById] = FindById
+		timers[findTicketByOrderId] = FindByOrderId
+		timers[findTicketsByRestaurant] = FindByRestaurant
+		timers[saveTicket] = Save
+		timers[updateTicketState] = UpdateState
+	}
+	return &TicketPostgresRepository{mapper: DefaultMapper{}, db: db, ctxGetter: ctxGetter, timers: timers}
+}
+
+// FindById retrieves a particular ticket with its line items by its identifier.
+func (r *TicketPostgresRepository) FindById(ctx context.Context, ticketId int64) (*domain.Ticket, error) {
+	var ticket *Ticket
+	if err := r.executeWithTimer(findTicketById, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Preload("LineItems").First(&ticket, ticketId).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return r.mapper.toDomainTicket(ticket), nil
+}
+
+// FindByOrderId retrieves the ticket with its line items created for an order.
+func (r *TicketPostgresRepository) FindByOrderId(ctx context.Context, orderId int64) (*domain.Ticket, error) {
+	var ticket *Ticket
+	if err := r.executeWithTimer(findTicketByOrderId, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Preload("LineItems").Where("order_id = ?", orderId).First(&ticket).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return r.mapper.toDomainTicket(ticket), nil
+}
+
+// FindByRestaurant retrieves the tickets of a restaurant (optionally filtered by state),
+// newest first.
+func (r *TicketPostgresRepository) FindByRestaurant(ctx context.Context, restaurantId int64, state *domain.TicketState, offset int, limit int) ([]*domain.Ticket, int64, error) {
+	var tickets []*Ticket
+	var total int64
+
+	byRestaurant := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("restaurant_id = ?", restaurantId)
+		if state != nil {
+			db = db.Where("state = ?", string(*state))
+		}
+		return db
+	}
+
+	if err := r.executeWithTimer(findTicketsByRestaurant, func() error {
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Scopes(byRestaurant).Preload("LineItems").Order("id DESC").Offset(offset).Limit(limit).Find(&tickets).Error; err != nil {
+			return err
+		}
+		if err := r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(&Ticket{}).Scopes(byRestaurant).Count(&total).Error; err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return r.mapper.toDomainTickets(tickets), total, nil
+}
+
+// Save persists a ticket and its line items in the database.
+func (r *TicketPostgresRepository) Save(ctx context.Context, ticket *domain.Ticket) error {
+	ticketDto := r.mapper.fromDomainTicket(ticket)
+	if err := r.executeWithTimer(saveTicket, func() error {
+		return r.ctxGetter.DefaultTrOrDB(ctx, r.db).Create(ticketDto).Error
+	}); err != nil {
+		return err
+	}
+	ticket.Id = ticketDto.ID
+	return nil
+}
+
+// UpdateState updates the state of a ticket and the instants of its lifecycle. The line
+// items are never modified once the ticket is created.
+func (r *TicketPostgresRepository) UpdateState(ctx context.Context, ticket *domain.Ticket) (int64, error) {
+	var result *gorm.DB
+	if err := r.executeWithTimer(updateTicketState, func() error {
+		ticketDto := r.mapper.fromDomainTicket(ticket)
+		ticketDto.LineItems = nil
+		result = r.ctxGetter.DefaultTrOrDB(ctx, r.db).Model(ticketDto).
+			Select("State", "ReadyBy", "AcceptedAt", "PreparingAt", "ReadyForPickupAt", "PickedUpAt", "CancelledAt").
+			Updates(ticketDto)
+		return result.Error
+	}); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+// executeWithTimer executes a function using a tally timer if present.
+func (r *TicketPostgresRepository) executeWithTimer(t timerEnum, fn func() error) error {
+	if r.timers[t] != nil {
+		tsw := r.timers[t].Start()
+		defer tsw.Stop()
+	}
+	return fn()
+}
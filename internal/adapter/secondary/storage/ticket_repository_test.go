@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/test"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestFindTicketById(t *testing.T) {
+	testcases := []struct {
+		name         string
+		ticketId     int64
+		wantState    domain.TicketState
+		wantItemsLen int
+		wantErr      bool
+		wantErrMsg   string
+	}{
+		{
+			name:         "find a ticket that exists",
+			ticketId:     1000,
+			wantState:    domain.TicketStateCreated,
+			wantItemsLen: 2,
+			wantErr:      false,
+		},
+		{
+			name:       "find a ticket that doesn't exist",
+			ticketId:   1001,
+			wantErr:    true,
+			wantErrMsg: "record not found",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ticket, err := ticketRepository.FindById(context.Background(), tc.ticketId)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.ticketId, ticket.Id)
+				assert.Equal(t, int64(1000), ticket.RestaurantId)
+				assert.Equal(t, tc.wantState, ticket.State)
+				assert.Len(t, ticket.LineItems, tc.wantItemsLen)
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, ticket)
+				assert.Equal(t, tc.wantErrMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestFindTicketByOrderId(t *testing.T) {
+	testcases := []struct {
+		name         string
+		orderId      int64
+		wantTicketId int64
+		wantErr      bool
+		wantErrMsg   string
+	}{
+		{
+			name:         "find the ticket of an order that has one",
+			orderId:      1000,
+			wantTicketId: 1000,
+			wantErr:      false,
+		},
+		{
+			name:       "find the ticket of an order that doesn't have one",
+			orderId:    1001,
+			wantErr:    true,
+			wantErrMsg: "record not found",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ticket, err := ticketRepository.FindByOrderId(context.Background(), tc.orderId)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantTicketId, ticket.Id)
+				assert.Equal(t, tc.orderId, ticket.OrderId)
+				assert.Len(t, ticket.LineItems, 2)
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, ticket)
+				assert.Equal(t, tc.wantErrMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestFindTicketsByRestaurant(t *testing.T) {
+	accepted := domain.TicketStateAccepted
+	testcases := []struct {
+		name             string
+		restaurantId     int64
+		state            *domain.TicketState
+		mockExpectations func(sqlmock.Sqlmock)
+		wantIds          []int64
+		wantTotal        int64
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name:         "find all the tickets of a restaurant, newest first",
+			restaurantId: 1000,
+			wantIds:      []int64{2000, 1000},
+			wantTotal:    2,
+		},
+		{
+			name:         "find the accepted tickets of a restaurant",
+			restaurantId: 1000,
+			state:        &accepted,
+			wantIds:      []int64{2000},
+			wantTotal:    1,
+		},
+		{
+			name:         "find the tickets of a restaurant without tickets",
+			restaurantId: 2000,
+			wantIds:      []int64{},
+			wantTotal:    0,
+		},
+		{
+			name:         "simulate error when finding the tickets of a restaurant",
+			restaurantId: 1000,
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT .+").WillReturnError(errors.New("error#11"))
+			},
+			wantErr:    true,
+			wantErrMsg: "error#11",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var repository = ticketRepository
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, _, mock = createMockTicketRepository()
+				tc.mockExpectations(mock)
+			}
+			tickets, total, err := repository.FindByRestaurant(context.Background(), tc.restaurantId, tc.state, 0, 10)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				ids := []int64{}
+				for _, ticket := range tickets {
+					ids = append(ids, ticket.Id)
+				}
+				assert.Equal(t, tc.wantIds, ids)
+				assert.Equal(t, tc.wantTotal, total)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.wantErrMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestSaveTicket(t *testing.T) {
+	testcases := []struct {
+		name             string
+		ticket           *domain.Ticket
+		mockExpectations func(sqlmock.Sqlmock)
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name:    "save a new ticket",
+			ticket:  newTestDomainTicket(3000),
+			wantErr: false,
+		},
+		{
+			name:       "save a ticket for an order that already has one",
+			ticket:     newTestDomainTicket(1000),
+			wantErr:    true,
+			wantErrMsg: "duplicate key value violates unique constraint",
+		},
+		{
+			name:   "simulate error when saving a ticket",
+			ticket: newTestDomainTicket(3000),
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO .+").WillReturnError(errors.New("error#12"))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantErrMsg: "error#12",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = ticketRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockTicketRepository()
+				tc.mockExpectations(mock)
+			}
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				err := repository.Save(ctx, tc.ticket)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					assert.NotZero(t, tc.ticket.Id)
+					actualTicket, _ := repository.FindById(ctx, tc.ticket.Id)
+					assert.Equal(t, tc.ticket.OrderId, actualTicket.OrderId)
+					assert.Equal(t, tc.ticket.LineItems, actualTicket.LineItems)
+				} else {
+					assert.Error(t, err)
+					assert.Contains(t, err.Error(), tc.wantErrMsg)
+				}
+				return errors.New(ROLLBACK_PLEASE)
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUpdateTicketState(t *testing.T) {
+	testcases := []struct {
+		name             string
+		ticket           func() *domain.Ticket
+		mockExpectations func(sqlmock.Sqlmock)
+		wantRowsAffected int64
+		wantErr          bool
+		wantErrMsg       string
+	}{
+		{
+			name: "update the state of an existing ticket",
+			ticket: func() *domain.Ticket {
+				ticket := newTestDomainTicket(1000)
+				ticket.Id = 1000
+				ticket.Accept(time.Now().Add(time.Hour))
+				return ticket
+			},
+			wantRowsAffected: 1,
+			wantErr:          false,
+		},
+		{
+			name: "update the state of a ticket that doesn't exist",
+			ticket: func() *domain.Ticket {
+				ticket := newTestDomainTicket(1001)
+				ticket.Id = 1001
+				ticket.Cancel()
+				return ticket
+			},
+			wantRowsAffected: 0,
+			wantErr:          false,
+		},
+		{
+			name: "simulate error when updating the state of a ticket",
+			ticket: func() *domain.Ticket {
+				ticket := newTestDomainTicket(1000)
+				ticket.Id = 1000
+				ticket.Cancel()
+				return ticket
+			},
+			mockExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE .+").WillReturnError(errors.New("error#13"))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantErrMsg: "error#13",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var repository = ticketRepository
+			var trm = trManager
+			if tc.mockExpectations != nil {
+				var mock sqlmock.Sqlmock
+				repository, trm, mock = createMockTicketRepository()
+				tc.mockExpectations(mock)
+			}
+			ticket := tc.ticket()
+			err := trm.Do(ctx, func(ctx context.Context) error {
+				ra, err := repository.UpdateState(ctx, ticket)
+				if !tc.wantErr {
+					assert.NoError(t, err)
+					assert.Equal(t, tc.wantRowsAffected, ra)
+					if ra > 0 {
+						actualTicket, _ := repository.FindById(ctx, ticket.Id)
+						assert.Equal(t, ticket.State, actualTicket.State)
+						assert.WithinDuration(t, *ticket.ReadyBy, *actualTicket.ReadyBy, time.Millisecond)
+						assert.Len(t, actualTicket.LineItems, 2)
+					}
+				} else {
+					assert.Error(t, err)
+					assert.Equal(t, tc.wantErrMsg, err.Error())
+				}
+				return errors.New(ROLLBACK_PLEASE)
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func createMockTicketRepository() (*TicketPostgresRepository, trm.Manager, sqlmock.Sqlmock) {
+	mockDb, mock, _ := sqlmock.New()
+	dialector := postgres.New(postgres.Config{
+		DriverName:           "go-sqlmock",
+		DSN:                  "go-sqlmock",
+		PreferSimpleProtocol: true,
+		WithoutReturning:     true,
+		Conn:                 mockDb,
+	})
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+	if err != nil {
+		panic("failed to connect to database")
+	}
+	return NewTicketPostgresRepository(db, trmgorm.DefaultCtxGetter, nil), test.NewNopTrManager(), mock
+}
+
+func newTestDomainTicket(orderId int64) *domain.Ticket {
+	now := time.Now()
+	return &domain.Ticket{
+		RestaurantId: 1000,
+		OrderId:      orderId,
+		State:        domain.TicketStateCreated,
+		CreatedAt:    &now,
+		LineItems: []*domain.TicketLineItem{
+			domain.NewTicketLineItem(1, "item1.1", 2),
+			domain.NewTicketLineItem(3, "item1.3", 1),
+		},
+	}
+}
@@ -15,10 +15,38 @@ var config Config
 // Config holds all the application configuration parameters. With the help of
 // 'envconfig' module we load them from ENV, also doing some basic validation.
 type Config struct {
-	AppName                 string `split_words:"true" default:"unknown"`
-	AppBanner               bool   `split_words:"true" default:"false"`
-	AppInitOutboxDispatcher bool   `split_words:"true" default:"false"`
-	AppPort                 int    `split_words:"true" default:"8080"`
+	AppName                  string        `split_words:"true" default:"unknown"`
+	AppBanner                bool          `split_words:"true" default:"false"`
+	AppInitOutboxDispatcher  bool          `split_words:"true" default:"false"`
+	AppInitSagaConsumer      bool          `split_words:"true" default:"false"`
+	AppInitMenuScheduler     bool          `split_words:"true" default:"false"`
+	AppInitRestaurantPurger  bool          `split_words:"true" default:"false"`
+	AppInitIdempotencyPurger bool          `split_words:"true" default:"false"`
+	AppPort                  int           `split_words:"true" default:"8080"`
+	AppGeocoderFile          string        `split_words:"true"`
+	AppMenuSchedulerInterval time.Duration `split_words:"true" default:"30s"`
+
+	AppRestaurantPurgeAfterDays int           `split_words:"true" default:"30"`
+	AppRestaurantPurgeInterval  time.Duration `split_words:"true" default:"1h"`
+
+	AppIdempotencyKeyRetention     time.Duration `split_words:"true" default:"24h"`
+	AppIdempotencyKeyPurgeInterval time.Duration `split_words:"true" default:"1h"`
+
+	AppRestaurantRepository       string `split_words:"true" default:"postgres"`
+	AppEventStoreSnapshotInterval int    `split_words:"true" default:"50"`
+
+	AppAuthEnabled             bool          `split_words:"true" default:"false"`
+	AppAuthJwksFile            string        `split_words:"true"`
+	AppAuthJwksUrl             string        `split_words:"true"`
+	AppAuthJwksRefreshInterval time.Duration `split_words:"true" default:"15m"`
+	AppAuthIssuer              string        `split_words:"true"`
+	AppAuthAudience            string        `split_words:"true"`
+	AppAuthTenantClaim         string        `split_words:"true" default:"tenant_id"`
+	AppAuthRolesClaim          string        `split_words:"true" default:"roles"`
+	AppAuthLeeway              time.Duration `split_words:"true" default:"30s"`
+	AppAuthExemptProbes        bool          `split_words:"true" default:"true"`
+	AppAuthTrustGatewayHeaders bool          `split_words:"true" default:"false"`
+	AppAuthPolicyFile          string        `split_words:"true"`
 
 	LogLevel    int  `split_words:"true" default:"1"`
 	LogBeautify bool `split_words:"true" default:"false"`
@@ -35,8 +63,10 @@ type Config struct {
 
 	GinMode string `envconfig:"GIN_MODE" split_words:"true" default:"release"`
 
-	KafkaBootstrapServers string `split_words:"true"`
-	KafkaSchemaRegistry   string `split_words:"true" required:"true"`
+	KafkaBootstrapServers   string `split_words:"true"`
+	KafkaSchemaRegistry     string `split_words:"true" required:"true"`
+	KafkaConsumerGroup      string `split_words:"true" default:"f4allgo-restaurant"`
+	KafkaSagaCommandChannel string `split_words:"true" default:"restaurant-service-commands"`
 }
 
 // LoadConfig bootstraps the application configuration (setting the ones not
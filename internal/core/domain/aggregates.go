@@ -1,6 +1,65 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum number of items allowed in a menu.
+	maxMenuItems = 1000
+
+	// Maximum length of a restaurant name.
+	maxRestaurantNameLength = 255
+
+	// Maximum length of a menu item name.
+	maxMenuItemNameLength = 255
+
+	// Maximum length of a description (of a restaurant or a menu item).
+	maxDescriptionLength = 1000
+
+	// Maximum number of dayparts of a restaurant.
+	maxDayparts = 10
+
+	// Maximum length of a daypart name.
+	maxDaypartNameLength = 50
+
+	// Maximum number of line items allowed in a ticket.
+	maxTicketLineItems = 100
+)
+
+var (
+	// ErrMenuItemNotFound is returned by the aggregate when an operation refers to
+	// a menu item that is not present in the restaurant's menu.
+	ErrMenuItemNotFound = errors.New("menu item not found")
+
+	// ErrMenuItemAlreadyExists is returned by the aggregate when trying to add a
+	// menu item whose identifier is already present in the restaurant's menu.
+	ErrMenuItemAlreadyExists = errors.New("menu item already exists")
+
+	// ErrOverlappingDaypartWindows is returned by the aggregate when the time
+	// windows of its dayparts overlap, so more than one menu would be active at the
+	// same time of day.
+	ErrOverlappingDaypartWindows = errors.New("the time windows of the dayparts overlap")
+
+	// ErrInvalidTicketStateTransition is returned by the ticket aggregate when an
+	// operation is not allowed in the current state of the ticket.
+	ErrInvalidTicketStateTransition = errors.New("invalid ticket state transition")
+
+	// ErrConcurrentModification is returned by the repositories when an aggregate
+	// was modified by someone else since it was read, so the changes would be lost.
+	ErrConcurrentModification = errors.New("the restaurant was modified concurrently")
+
+	// ErrRestaurantNotFound is returned by the repositories when an aggregate to be
+	// modified doesn't exist (or it's deleted).
+	ErrRestaurantNotFound = errors.New("restaurant not found")
+)
 
 // --------------------------------------------------------------------------------
 // Aggregate Root :: Restaurant
@@ -8,12 +67,193 @@ import "errors"
 
 // Restaurant is an entity representing a restaurant in the system that is placed
 // in an address and offers to customers a menu. It's also the aggregate root to
-// manage addresses and menus.
+// manage addresses and menus. A restaurant may offer different menus by time of
+// day (dayparts), in which case Menu is the one offered when no daypart is active.
+// The description and the texts of the menu items are written in the default
+// locale of the restaurant, and they may be translated into other locales. Every
+// restaurant is owned by a tenant, which is the only one allowed to manage it. The
+// version of the restaurant is increased on every change, so that concurrent changes
+// can be detected. Deleted restaurants are kept (with the instant they were deleted)
+// until they are purged, so that they can be restored meanwhile.
 type Restaurant struct {
-	Id      int64
-	Name    string
-	Address *Address
-	Menu    *Menu
+	Id                      int64
+	TenantId                string
+	Name                    string
+	Description             string
+	DescriptionTranslations map[string]string
+	DefaultLocale           string
+	TimeZone                string
+	Address                 *Address
+	Menu                    *Menu
+	Dayparts                []*Daypart
+	Version                 int64
+	DeletedAt               *time.Time
+}
+
+// IsDeleted returns true if the restaurant has been deleted and is waiting to be
+// purged.
+func (r *Restaurant) IsDeleted() bool {
+	return r.DeletedAt != nil
+}
+
+// Snapshot returns the fields of the restaurant (including its menus) flattened
+// into a snapshot, so that it can be compared with the restaurant after a change.
+func (r *Restaurant) Snapshot() RestaurantSnapshot {
+	s := RestaurantSnapshot{}
+	s.put("tenantId", r.TenantId)
+	s.put("name", r.Name)
+	s.put("defaultLocale", r.DefaultLocale)
+	s.put("timeZone", r.TimeZone)
+	s.put("description", r.Description)
+	for locale, description := range r.DescriptionTranslations {
+		s.put("descriptionTranslations."+locale, description)
+	}
+	if r.Address != nil {
+		s.put("address.street", r.Address.Street())
+		s.put("address.city", r.Address.City())
+		s.put("address.state", r.Address.State())
+		s.put("address.zip", r.Address.Zip())
+		if location := r.Address.Location(); location != nil {
+			s.put("address.latitude", strconv.FormatFloat(location.Latitude(), 'f', -1, 64))
+			s.put("address.longitude", strconv.FormatFloat(location.Longitude(), 'f', -1, 64))
+		}
+	}
+	s.putMenu("menu", r.Menu)
+	for _, daypart := range r.Dayparts {
+		windows := make([]string, 0, len(daypart.GetWindows()))
+		for _, window := range daypart.GetWindows() {
+			windows = append(windows, FormatTimeOfDay(window.GetStart())+"-"+FormatTimeOfDay(window.GetEnd()))
+		}
+		s.put("dayparts."+daypart.GetName()+".windows", strings.Join(windows, ","))
+		s.putMenu("dayparts."+daypart.GetName()+".menu", daypart.GetMenu())
+	}
+	if r.DeletedAt != nil {
+		s.put("deletedAt", r.DeletedAt.UTC().Format(time.RFC3339))
+	}
+	return s
+}
+
+// IsManageableBy returns true if the caller is allowed to modify the restaurant,
+// which happens when it belongs to the owning tenant or it's an admin.
+func (r *Restaurant) IsManageableBy(identity *Identity) bool {
+	if identity == nil {
+		return false
+	}
+	return identity.IsAdmin() || (identity.GetTenantId() != "" && identity.GetTenantId() == r.TenantId)
+}
+
+// Rename changes the name of a restaurant, enforcing that it's not empty and
+// doesn't exceed the maximum length.
+func (r *Restaurant) Rename(name string) error {
+	if len(name) == 0 || len(name) > maxRestaurantNameLength {
+		return errors.New("invalid restaurant name")
+	}
+	r.Name = name
+	return nil
+}
+
+// Describe changes the default locale, the description and/or the translations of
+// the description of a restaurant (nil arguments are left untouched), enforcing
+// that the locales are valid and the descriptions don't exceed the maximum length.
+// It returns true if anything changed.
+func (r *Restaurant) Describe(defaultLocale *string, description *string, translations *map[string]string) (bool, error) {
+	changed := false
+	if defaultLocale != nil && *defaultLocale != r.DefaultLocale {
+		if !IsValidLocale(*defaultLocale) {
+			return false, fmt.Errorf("invalid locale: %s", *defaultLocale)
+		}
+		r.DefaultLocale = *defaultLocale
+		changed = true
+	}
+	if description != nil && *description != r.Description {
+		if len(*description) > maxDescriptionLength {
+			return false, errors.New("invalid restaurant description")
+		}
+		r.Description = *description
+		changed = true
+	}
+	if translations != nil && !reflect.DeepEqual(*translations, r.DescriptionTranslations) {
+		for locale, translation := range *translations {
+			if !IsValidLocale(locale) || len(translation) > maxDescriptionLength {
+				return false, fmt.Errorf("invalid restaurant description for locale %s", locale)
+			}
+		}
+		r.DescriptionTranslations = *translations
+		changed = true
+	}
+	return changed, nil
+}
+
+// Locales returns the locales in which the texts of the restaurant are available,
+// with the default locale first.
+func (r *Restaurant) Locales() []string {
+	defaultLocale := r.DefaultLocale
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+	locales := []string{defaultLocale}
+	seen := map[string]bool{defaultLocale: true}
+	candidates := r.Menu.locales()
+	for locale := range r.DescriptionTranslations {
+		candidates = append(candidates, locale)
+	}
+	for _, daypart := range r.Dayparts {
+		candidates = append(candidates, daypart.menu.locales()...)
+	}
+	sort.Strings(candidates)
+	for _, locale := range candidates {
+		if !seen[locale] {
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// Localize returns a copy of the restaurant whose description and menus have the
+// texts of the provided locale, falling back to the default locale for the texts
+// that are not translated.
+func (r *Restaurant) Localize(locale string) *Restaurant {
+	copy := *r
+	if translation, ok := r.DescriptionTranslations[locale]; ok {
+		copy.Description = translation
+	}
+	copy.Menu = r.Menu.Localize(locale)
+	copy.Dayparts = nil
+	for _, daypart := range r.Dayparts {
+		copy.Dayparts = append(copy.Dayparts, daypart.Localize(locale))
+	}
+	return &copy
+}
+
+// Relocate changes the address of a restaurant, enforcing that all the address
+// fields are informed.
+func (r *Restaurant) Relocate(address *Address) error {
+	if !isValidAddress(address) {
+		return errors.New("invalid restaurant address")
+	}
+	r.Address = address
+	return nil
+}
+
+// ChangeTimeZone changes the time zone in which the dayparts of a restaurant are
+// evaluated, enforcing that it's a name of the IANA Time Zone database.
+func (r *Restaurant) ChangeTimeZone(timeZone string) error {
+	if !IsValidTimeZone(timeZone) {
+		return fmt.Errorf("invalid time zone: %s", timeZone)
+	}
+	r.TimeZone = timeZone
+	return nil
+}
+
+// Location returns the location of the time zone of the restaurant, or UTC if it
+// doesn't declare a valid one.
+func (r *Restaurant) Location() *time.Location {
+	if !IsValidTimeZone(r.TimeZone) {
+		return time.UTC
+	}
+	location, _ := time.LoadLocation(r.TimeZone)
+	return location
 }
 
 // UpdateMenu updates the menu of a restaurant, enforcing some invariants on
@@ -28,6 +268,572 @@ func (r *Restaurant) UpdateMenu(menu *Menu) error {
 	return nil
 }
 
+// ScheduleMenu prepares a menu to replace the current one at a future instant,
+// enforcing the same invariants as UpdateMenu.
+func (r *Restaurant) ScheduleMenu(menu *Menu, effectiveFrom time.Time, now time.Time) (*ScheduledMenu, error) {
+	if !isValidMenu(menu) {
+		return nil, errors.New("invalid menu. Check the requirements of a menu in isValidMenu(menu *Menu) func")
+	}
+	if !effectiveFrom.After(now) {
+		return nil, errors.New("a scheduled menu must be effective from a future instant")
+	}
+	return &ScheduledMenu{RestaurantId: r.Id, Menu: menu, EffectiveFrom: effectiveFrom}, nil
+}
+
+// ActivateScheduledMenu replaces the menu of a restaurant with a menu scheduled
+// for it.
+func (r *Restaurant) ActivateScheduledMenu(scheduledMenu *ScheduledMenu) error {
+	if scheduledMenu.RestaurantId != r.Id {
+		return errors.New("the scheduled menu belongs to another restaurant")
+	}
+	return r.UpdateMenu(scheduledMenu.Menu)
+}
+
+// UpdateDayparts replaces the dayparts of a restaurant, enforcing that their names
+// are unique, their menus are valid and their time windows don't overlap (so at
+// most one daypart is active at any time of day). An empty slice removes them all.
+func (r *Restaurant) UpdateDayparts(dayparts []*Daypart) error {
+	if len(dayparts) > maxDayparts {
+		return fmt.Errorf("a restaurant cannot have more than %d dayparts", maxDayparts)
+	}
+
+	names := map[string]bool{}
+	windows := []*TimeWindow{}
+	for _, daypart := range dayparts {
+		if daypart == nil || len(daypart.name) == 0 || len(daypart.name) > maxDaypartNameLength || names[daypart.name] {
+			return errors.New("invalid daypart name. Names are required and unique within a restaurant")
+		}
+		names[daypart.name] = true
+
+		if !isValidMenu(daypart.menu) {
+			return fmt.Errorf("invalid menu of daypart %s. Check the requirements of a menu in isValidMenu(menu *Menu) func", daypart.name)
+		}
+		if len(daypart.windows) == 0 {
+			return fmt.Errorf("daypart %s has no time windows", daypart.name)
+		}
+		for _, window := range daypart.windows {
+			if !window.isValid() {
+				return fmt.Errorf("invalid time window of daypart %s", daypart.name)
+			}
+			for _, other := range windows {
+				if window.overlaps(other) {
+					return ErrOverlappingDaypartWindows
+				}
+			}
+			windows = append(windows, window)
+		}
+	}
+
+	r.Dayparts = dayparts
+	return nil
+}
+
+// MenuAt returns the menu offered by a restaurant at a given instant: the one of
+// the daypart active at its time of day (in the time zone of the restaurant) or
+// the restaurant's menu if there is none.
+func (r *Restaurant) MenuAt(at time.Time) *Menu {
+	at = at.In(r.Location())
+	for _, daypart := range r.Dayparts {
+		if daypart.IsActiveAt(at) {
+			return daypart.menu
+		}
+	}
+	return r.Menu
+}
+
+// SetItemAvailability changes the availability of a single menu item without
+// replacing the rest of the menu, returning the updated menu item.
+func (r *Restaurant) SetItemAvailability(menuItemId int16, available bool, availableUntil *time.Time) (*MenuItem, error) {
+	if r.Menu == nil || r.Menu.GetItem(menuItemId) == nil {
+		return nil, ErrMenuItemNotFound
+	}
+
+	updated := r.Menu.GetItem(menuItemId).WithAvailability(available, availableUntil)
+	r.Menu = r.Menu.withItem(updated)
+	return updated, nil
+}
+
+// AddMenuItem adds a single item to the restaurant's menu, enforcing that its
+// identifier is positive and unique, its name, price, labels and texts are valid
+// and the menu doesn't exceed the maximum number of items.
+func (r *Restaurant) AddMenuItem(menuItem *MenuItem) error {
+	if menuItem == nil || !hasValidAttributes(menuItem) || !hasValidLabels(menuItem) || !hasValidTexts(menuItem) {
+		return errors.New("invalid menu item")
+	}
+
+	var items []*MenuItem
+	if r.Menu != nil {
+		if r.Menu.GetItem(menuItem.id) != nil {
+			return ErrMenuItemAlreadyExists
+		}
+		items = append(items, r.Menu.items...)
+	}
+
+	if len(items) >= maxMenuItems {
+		return errors.New("the menu cannot have more than 1000 items")
+	}
+
+	r.Menu = NewMenu(append(items, menuItem))
+	return nil
+}
+
+// UpdateMenuItem applies the provided changes to a single menu item, enforcing
+// the same invariants as AddMenuItem, and returns the previous and the updated
+// versions of the menu item.
+func (r *Restaurant) UpdateMenuItem(menuItemId int16, changes *MenuItemChanges) (*MenuItem, *MenuItem, error) {
+	if r.Menu == nil || r.Menu.GetItem(menuItemId) == nil {
+		return nil, nil, ErrMenuItemNotFound
+	}
+
+	previous := r.Menu.GetItem(menuItemId)
+	updated := *previous
+	if changes.Name != nil {
+		updated.name = *changes.Name
+	}
+	if changes.Description != nil {
+		updated.description = *changes.Description
+	}
+	if changes.Translations != nil {
+		updated.translations = *changes.Translations
+	}
+	if changes.Price != nil {
+		updated.price = changes.Price
+	}
+	if changes.Allergens != nil {
+		updated.allergens = *changes.Allergens
+	}
+	if changes.DietaryTags != nil {
+		updated.dietaryTags = *changes.DietaryTags
+	}
+
+	if !hasValidAttributes(&updated) {
+		return nil, nil, errors.New("invalid menu item name or price")
+	}
+	if !hasValidLabels(&updated) {
+		return nil, nil, errors.New("invalid menu item labels")
+	}
+	if !hasValidTexts(&updated) {
+		return nil, nil, errors.New("invalid menu item texts")
+	}
+
+	r.Menu = r.Menu.withItem(&updated)
+	return previous, &updated, nil
+}
+
+// RemoveMenuItem removes a single item from the restaurant's menu, enforcing
+// that the menu doesn't become empty.
+func (r *Restaurant) RemoveMenuItem(menuItemId int16) error {
+	if r.Menu == nil || r.Menu.GetItem(menuItemId) == nil {
+		return ErrMenuItemNotFound
+	}
+
+	if len(r.Menu.items) == 1 {
+		return errors.New("the menu cannot be empty")
+	}
+
+	items := make([]*MenuItem, 0, len(r.Menu.items)-1)
+	for _, item := range r.Menu.items {
+		if item.id != menuItemId {
+			items = append(items, item)
+		}
+	}
+	r.Menu = NewMenu(items)
+	return nil
+}
+
+// CreateTicket creates a kitchen ticket for an order, enforcing that every line
+// item refers to an available item of the menu the restaurant offers right now
+// (see MenuAt). The names of the menu items are copied into the line items.
+func (r *Restaurant) CreateTicket(orderId int64, lineItems []*TicketLineItem) (*Ticket, error) {
+	if orderId <= 0 {
+		return nil, errors.New("invalid order id")
+	}
+	if len(lineItems) == 0 || len(lineItems) > maxTicketLineItems {
+		return nil, errors.New("a ticket must have between 1 and 100 line items")
+	}
+
+	now := time.Now()
+	menu := r.MenuAt(now)
+	seen := map[int16]bool{}
+	items := make([]*TicketLineItem, 0, len(lineItems))
+	for _, lineItem := range lineItems {
+		if lineItem == nil || lineItem.quantity <= 0 {
+			return nil, errors.New("invalid ticket line item")
+		}
+		if seen[lineItem.menuItemId] {
+			return nil, fmt.Errorf("duplicated menu item %d", lineItem.menuItemId)
+		}
+		seen[lineItem.menuItemId] = true
+
+		var menuItem *MenuItem
+		if menu != nil {
+			menuItem = menu.GetItem(lineItem.menuItemId)
+		}
+		if menuItem == nil {
+			return nil, fmt.Errorf("menu item %d not found", lineItem.menuItemId)
+		}
+		if !menuItem.IsAvailableAt(now) {
+			return nil, fmt.Errorf("menu item %d is not available", lineItem.menuItemId)
+		}
+		items = append(items, NewTicketLineItem(lineItem.menuItemId, menuItem.GetName(), lineItem.quantity))
+	}
+
+	return &Ticket{RestaurantId: r.Id, OrderId: orderId, State: TicketStateCreated, LineItems: items, CreatedAt: &now}, nil
+}
+
+// Quote validates an order against the menu the restaurant offers at a given
+// instant (see MenuAt), pricing every line with the stored menu prices. Lines
+// referring to unknown, unavailable or already quoted menu items, or with non
+// positive quantities, are rejected with a reason and excluded from the total.
+func (r *Restaurant) Quote(lines []*OrderLine, at time.Time) (*Quote, error) {
+	if len(lines) == 0 || len(lines) > maxTicketLineItems {
+		return nil, errors.New("an order must have between 1 and 100 lines")
+	}
+
+	menu := r.MenuAt(at)
+	total := new(big.Float)
+	seen := map[int16]bool{}
+	quoteLines := make([]*QuoteLine, 0, len(lines))
+	for _, line := range lines {
+		if line == nil {
+			return nil, errors.New("invalid order line")
+		}
+		quoteLine := &QuoteLine{menuItemId: line.menuItemId, quantity: line.quantity}
+		quoteLines = append(quoteLines, quoteLine)
+
+		var menuItem *MenuItem
+		if menu != nil {
+			menuItem = menu.GetItem(line.menuItemId)
+		}
+		if menuItem != nil {
+			quoteLine.name = menuItem.GetName()
+		}
+
+		var rejection OrderLineRejection
+		switch {
+		case menuItem == nil:
+			rejection = OrderLineRejectionMenuItemNotFound
+		case line.quantity <= 0:
+			rejection = OrderLineRejectionInvalidQuantity
+		case seen[line.menuItemId]:
+			rejection = OrderLineRejectionDuplicatedMenuItem
+		case !menuItem.IsAvailableAt(at):
+			rejection = OrderLineRejectionMenuItemNotAvailable
+		}
+		seen[line.menuItemId] = true
+		if rejection != "" {
+			quoteLine.rejection = &rejection
+			continue
+		}
+
+		quoteLine.unitPrice = menuItem.GetPrice()
+		quoteLine.lineTotal = new(big.Float).Mul(menuItem.GetPrice(), new(big.Float).SetInt64(int64(line.quantity)))
+		total.Add(total, quoteLine.lineTotal)
+	}
+
+	return &Quote{restaurantId: r.Id, lines: quoteLines, total: total}, nil
+}
+
+// CreatePendingTicket creates a kitchen ticket for an order that is still being
+// validated by other services (e.g. in a saga). It enforces the same rules as
+// CreateTicket, but the ticket remains pending until its creation is confirmed or
+// cancelled.
+func (r *Restaurant) CreatePendingTicket(orderId int64, lineItems []*TicketLineItem) (*Ticket, error) {
+	ticket, err := r.CreateTicket(orderId, lineItems)
+	if err != nil {
+		return nil, err
+	}
+	ticket.State = TicketStateCreatePending
+	return ticket, nil
+}
+
+// RestaurantChanges holds the changes to apply to the profile of a restaurant.
+// Nil fields are left untouched.
+type RestaurantChanges struct {
+	Name                    *string
+	Address                 *Address
+	DefaultLocale           *string
+	TimeZone                *string
+	Description             *string
+	DescriptionTranslations *map[string]string
+}
+
+// MenuItemChanges holds the changes to apply to a menu item. Nil fields are
+// left untouched.
+type MenuItemChanges struct {
+	Name         *string
+	Description  *string
+	Translations *map[string]*Translation
+	Price        *big.Float
+	Allergens    *[]Allergen
+	DietaryTags  *[]DietaryTag
+}
+
+// --------------------------------------------------------------------------------
+// Entity :: ScheduledMenu
+// --------------------------------------------------------------------------------
+
+// ScheduledMenu is a menu prepared in advance (see Restaurant.ScheduleMenu) that
+// replaces the menu of a restaurant once the effective instant is reached.
+type ScheduledMenu struct {
+	Id            int64
+	RestaurantId  int64
+	Menu          *Menu
+	EffectiveFrom time.Time
+}
+
+// Snapshot returns the fields of the scheduled menu as fields of its restaurant (see
+// Restaurant.Snapshot), under "scheduledMenus.<id>", so that scheduling it or
+// cancelling it can be audited.
+func (sm *ScheduledMenu) Snapshot() RestaurantSnapshot {
+	s := RestaurantSnapshot{}
+	prefix := fmt.Sprintf("scheduledMenus.%d.", sm.Id)
+	s.put(prefix+"effectiveFrom", sm.EffectiveFrom.UTC().Format(time.RFC3339))
+	s.putMenu(prefix+"menu", sm.Menu)
+	return s
+}
+
+// --------------------------------------------------------------------------------
+// Entity :: AuditEntry
+// --------------------------------------------------------------------------------
+
+// AuditOperation is an enumerated value with the mutations of restaurants that are
+// recorded in the audit log.
+type AuditOperation string
+
+const (
+	AuditCreateRestaurant    AuditOperation = "CreateRestaurant"
+	AuditUpdateRestaurant    AuditOperation = "UpdateRestaurant"
+	AuditUpdateMenu          AuditOperation = "UpdateMenu"
+	AuditUpdateDayparts      AuditOperation = "UpdateDayparts"
+	AuditSetItemAvailability AuditOperation = "SetItemAvailability"
+	AuditAddMenuItem         AuditOperation = "AddMenuItem"
+	AuditUpdateMenuItem      AuditOperation = "UpdateMenuItem"
+	AuditRemoveMenuItem      AuditOperation = "RemoveMenuItem"
+	AuditDeleteRestaurant    AuditOperation = "DeleteRestaurant"
+	AuditRestoreRestaurant   AuditOperation = "RestoreRestaurant"
+
+	AuditScheduleMenu          AuditOperation = "ScheduleMenu"
+	AuditCancelScheduledMenu   AuditOperation = "CancelScheduledMenu"
+	AuditActivateScheduledMenu AuditOperation = "ActivateScheduledMenu"
+)
+
+// AuditEntry records a mutation of a restaurant: the caller that made it (its
+// subject and tenant, empty if anonymous), the request it was part of and the
+// fields of the restaurant it changed (see RestaurantSnapshot.Diff). The entries
+// are kept after the restaurant is purged.
+type AuditEntry struct {
+	Id           int64
+	RestaurantId int64
+	Operation    AuditOperation
+	Actor        string
+	TenantId     string
+	Changes      map[string]*FieldChange
+	RequestId    string
+	Source       RequestSource
+	CreatedAt    time.Time
+}
+
+// NewAuditEntry builds the entry of a mutation of a restaurant made by a caller
+// (nil if anonymous) within a request (nil if unknown).
+func NewAuditEntry(restaurantId int64, operation AuditOperation, identity *Identity, metadata *RequestMetadata, changes map[string]*FieldChange, at time.Time) *AuditEntry {
+	entry := &AuditEntry{RestaurantId: restaurantId, Operation: operation, Changes: changes, CreatedAt: at}
+	if identity != nil {
+		entry.Actor = identity.GetSubject()
+		entry.TenantId = identity.GetTenantId()
+	}
+	if metadata != nil {
+		entry.RequestId = metadata.GetRequestId()
+		entry.Source = metadata.GetSource()
+	}
+	return entry
+}
+
+// --------------------------------------------------------------------------------
+// Aggregate Root :: Ticket
+// --------------------------------------------------------------------------------
+
+// Ticket is an entity representing the order of a customer as seen by the kitchen
+// of a restaurant. It's created by the restaurant aggregate (see CreateTicket) and
+// then it follows its own lifecycle:
+//
+//	create-pending -> created (confirmed) or cancelled (creation cancelled)
+//	created -> accepted -> preparing -> ready-for-pickup -> picked-up
+//	created -> cancelled (rejected or cancelled)
+//	accepted -> cancelled
+type Ticket struct {
+	Id               int64
+	RestaurantId     int64
+	OrderId          int64
+	State            TicketState
+	LineItems        []*TicketLineItem
+	ReadyBy          *time.Time
+	CreatedAt        *time.Time
+	AcceptedAt       *time.Time
+	PreparingAt      *time.Time
+	ReadyForPickupAt *time.Time
+	PickedUpAt       *time.Time
+	CancelledAt      *time.Time
+}
+
+// ConfirmCreate confirms the creation of a pending ticket, so it can be accepted
+// by the restaurant.
+func (t *Ticket) ConfirmCreate() error {
+	return t.transition(TicketStateCreated, TicketStateCreatePending)
+}
+
+// CancelCreate cancels the creation of a pending ticket.
+func (t *Ticket) CancelCreate() error {
+	if err := t.transition(TicketStateCancelled, TicketStateCreatePending); err != nil {
+		return err
+	}
+	now := time.Now()
+	t.CancelledAt = &now
+	return nil
+}
+
+// Accept accepts a created ticket, committing the restaurant to have it ready by
+// the provided instant (that must be in the future).
+func (t *Ticket) Accept(readyBy time.Time) error {
+	now := time.Now()
+	if !readyBy.After(now) {
+		return errors.New("the ready by instant must be in the future")
+	}
+	if err := t.transition(TicketStateAccepted, TicketStateCreated); err != nil {
+		return err
+	}
+	t.ReadyBy = &readyBy
+	t.AcceptedAt = &now
+	return nil
+}
+
+// Reject rejects a created ticket, so it's cancelled before being accepted.
+func (t *Ticket) Reject() error {
+	if err := t.transition(TicketStateCancelled, TicketStateCreated); err != nil {
+		return err
+	}
+	now := time.Now()
+	t.CancelledAt = &now
+	return nil
+}
+
+// StartPreparing marks an accepted ticket as being prepared by the kitchen.
+func (t *Ticket) StartPreparing() error {
+	if err := t.transition(TicketStatePreparing, TicketStateAccepted); err != nil {
+		return err
+	}
+	now := time.Now()
+	t.PreparingAt = &now
+	return nil
+}
+
+// MarkReadyForPickup marks a ticket being prepared as ready to be picked up.
+func (t *Ticket) MarkReadyForPickup() error {
+	if err := t.transition(TicketStateReadyForPickup, TicketStatePreparing); err != nil {
+		return err
+	}
+	now := time.Now()
+	t.ReadyForPickupAt = &now
+	return nil
+}
+
+// PickUp marks a ticket ready for pickup as picked up by the courier.
+func (t *Ticket) PickUp() error {
+	if err := t.transition(TicketStatePickedUp, TicketStateReadyForPickup); err != nil {
+		return err
+	}
+	now := time.Now()
+	t.PickedUpAt = &now
+	return nil
+}
+
+// Cancel cancels a ticket that hasn't started to be prepared yet.
+func (t *Ticket) Cancel() error {
+	if err := t.transition(TicketStateCancelled, TicketStateCreated, TicketStateAccepted); err != nil {
+		return err
+	}
+	now := time.Now()
+	t.CancelledAt = &now
+	return nil
+}
+
+// transition moves the ticket to a new state if its current state is one of the
+// allowed ones.
+func (t *Ticket) transition(to TicketState, allowed ...TicketState) error {
+	for _, state := range allowed {
+		if t.State == state {
+			t.State = to
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot move a ticket from '%s' to '%s'", ErrInvalidTicketStateTransition, t.State, to)
+}
+
+func isValidAddress(address *Address) bool {
+	return address != nil && address.street != "" && address.city != "" && address.state != "" && address.zip != ""
+}
+
 func isValidMenu(menu *Menu) bool {
-	return menu != nil && menu.items != nil && len(menu.items) > 0 && len(menu.items) <= 1000
+	if menu == nil || menu.items == nil || len(menu.items) == 0 || len(menu.items) > maxMenuItems {
+		return false
+	}
+	for _, item := range menu.items {
+		if !hasValidAttributes(item) || !hasValidLabels(item) || !hasValidTexts(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasValidAttributes checks that a menu item has a positive identifier, a name
+// that doesn't exceed the maximum length and a price that isn't negative.
+func hasValidAttributes(item *MenuItem) bool {
+	if item.id <= 0 || len(item.name) == 0 || len(item.name) > maxMenuItemNameLength {
+		return false
+	}
+	return item.price != nil && item.price.Sign() >= 0
+}
+
+// hasValidTexts checks that the description of a menu item doesn't exceed the
+// maximum length, and that its translations are keyed by valid locales and
+// include a name.
+func hasValidTexts(item *MenuItem) bool {
+	if len(item.description) > maxDescriptionLength {
+		return false
+	}
+	for locale, translation := range item.translations {
+		if !IsValidLocale(locale) || translation == nil {
+			return false
+		}
+		if len(translation.name) == 0 || len(translation.name) > maxMenuItemNameLength || len(translation.description) > maxDescriptionLength {
+			return false
+		}
+	}
+	return true
+}
+
+// hasValidLabels checks that the allergens and dietary tags of a menu item are
+// known values and that the dietary tags don't contradict the allergens (e.g.
+// a vegan item cannot contain milk).
+func hasValidLabels(item *MenuItem) bool {
+	for _, a := range item.allergens {
+		if !a.IsValid() {
+			return false
+		}
+	}
+	for _, t := range item.dietaryTags {
+		if !t.IsValid() {
+			return false
+		}
+	}
+	if item.HasDietaryTag(DietaryTagVegan) && item.ContainsAnyAllergen([]Allergen{AllergenMilk, AllergenEggs, AllergenFish, AllergenCrustaceans, AllergenMolluscs}) {
+		return false
+	}
+	if item.HasDietaryTag(DietaryTagVegetarian) && item.ContainsAnyAllergen([]Allergen{AllergenFish, AllergenCrustaceans, AllergenMolluscs}) {
+		return false
+	}
+	if item.HasDietaryTag(DietaryTagGlutenFree) && item.ContainsAnyAllergen([]Allergen{AllergenGluten}) {
+		return false
+	}
+	return true
 }
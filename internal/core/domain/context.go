@@ -0,0 +1,190 @@
+package domain
+
+import "context"
+
+// --------------------------------------------------------------------------------
+// VO :: Identity
+// --------------------------------------------------------------------------------
+
+// Role is an enumerated value object with the roles a caller may have.
+type Role string
+
+const (
+	// RoleAdmin is granted to operators, who can manage the restaurants of any tenant.
+	RoleAdmin Role = "admin"
+	// RoleManager is granted to the staff of a tenant managing its restaurants.
+	RoleManager Role = "manager"
+	// RoleViewer is granted to the callers that can only read restaurants.
+	RoleViewer Role = "viewer"
+)
+
+// Permission is an enumerated value object with the operations on restaurants (and
+// their menus and tickets) the callers may be granted by an authorization policy.
+type Permission string
+
+const (
+	PermissionReadRestaurant   Permission = "restaurant:read"
+	PermissionCreateRestaurant Permission = "restaurant:create"
+	PermissionUpdateRestaurant Permission = "restaurant:update"
+	PermissionDeleteRestaurant Permission = "restaurant:delete"
+	PermissionUpdateMenu       Permission = "menu:update"
+	PermissionReadAuditLog     Permission = "audit:read"
+	PermissionReadTicket       Permission = "ticket:read"
+	PermissionUpdateTicket     Permission = "ticket:update"
+)
+
+// Identity is a value object with the authenticated caller of an operation: the
+// subject (e.g. a user id), the tenant it belongs to and its roles. The primary
+// adapters propagate it to the core through the context of the operation.
+type Identity struct {
+	subject  string
+	tenantId string
+	roles    []Role
+}
+
+func NewIdentity(subject string, tenantId string, roles []Role) *Identity {
+	return &Identity{subject: subject, tenantId: tenantId, roles: roles}
+}
+
+func (i *Identity) GetSubject() string {
+	return i.subject
+}
+
+func (i *Identity) GetTenantId() string {
+	return i.tenantId
+}
+
+func (i *Identity) GetRoles() []Role {
+	return i.roles
+}
+
+// HasRole returns true if the caller has been granted the role.
+func (i *Identity) HasRole(role Role) bool {
+	for _, r := range i.roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin returns true if the caller has the admin role.
+func (i *Identity) IsAdmin() bool {
+	return i.HasRole(RoleAdmin)
+}
+
+// identityKey is the key of the identity in a context. It's unexported so that the
+// identity can only be set through ContextWithIdentity.
+type identityKey struct{}
+
+// ContextWithIdentity returns a copy of the context carrying the identity of the caller.
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity of the caller carried by the context,
+// or nil if the caller is anonymous.
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// expectedVersionKey is the key of the expected version of a restaurant in a context.
+type expectedVersionKey struct{}
+
+// ContextWithExpectedVersion returns a copy of the context carrying the version of the
+// restaurant the caller expects to modify, which is the one it last read.
+func ContextWithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+// ExpectedVersionFromContext returns the version of the restaurant the caller expects
+// to modify, or nil if the caller doesn't expect any version.
+func ExpectedVersionFromContext(ctx context.Context) *int64 {
+	version, ok := ctx.Value(expectedVersionKey{}).(int64)
+	if !ok {
+		return nil
+	}
+	return &version
+}
+
+// versionRecorderKey is the key of the recorder of the version of a restaurant in a
+// context.
+type versionRecorderKey struct{}
+
+// VersionRecorder holds the version a restaurant is left at by the change made within
+// a context, so that the primary adapters can report it to the caller.
+type VersionRecorder struct {
+	version *int64
+}
+
+// GetVersion returns the recorded version, or nil if no restaurant was changed.
+func (vr *VersionRecorder) GetVersion() *int64 {
+	return vr.version
+}
+
+// ContextWithVersionRecorder returns a copy of the context carrying a recorder of the
+// version a restaurant is left at by the change made within it.
+func ContextWithVersionRecorder(ctx context.Context) (context.Context, *VersionRecorder) {
+	recorder := &VersionRecorder{}
+	return context.WithValue(ctx, versionRecorderKey{}, recorder), recorder
+}
+
+// RecordVersion records the version a restaurant is left at by a change, if the
+// context carries a recorder.
+func RecordVersion(ctx context.Context, version int64) {
+	if recorder, ok := ctx.Value(versionRecorderKey{}).(*VersionRecorder); ok {
+		recorder.version = &version
+	}
+}
+
+// --------------------------------------------------------------------------------
+// VO :: RequestMetadata
+// --------------------------------------------------------------------------------
+
+// RequestSource is an enumerated value object with the primary adapters the
+// requests are received through.
+type RequestSource string
+
+const (
+	SourceRest RequestSource = "REST"
+	SourceGrpc RequestSource = "gRPC"
+	SourceCli  RequestSource = "CLI"
+)
+
+// RequestMetadata is a value object with the request an operation is part of: its
+// identifier (to correlate the traces of the request) and the adapter that received
+// it. The primary adapters propagate it to the core through the context of the
+// operation.
+type RequestMetadata struct {
+	requestId string
+	source    RequestSource
+}
+
+func NewRequestMetadata(requestId string, source RequestSource) *RequestMetadata {
+	return &RequestMetadata{requestId: requestId, source: source}
+}
+
+func (m *RequestMetadata) GetRequestId() string {
+	return m.requestId
+}
+
+func (m *RequestMetadata) GetSource() RequestSource {
+	return m.source
+}
+
+// requestMetadataKey is the key of the request metadata in a context.
+type requestMetadataKey struct{}
+
+// ContextWithRequestMetadata returns a copy of the context carrying the metadata of
+// the request.
+func ContextWithRequestMetadata(ctx context.Context, metadata *RequestMetadata) context.Context {
+	return context.WithValue(ctx, requestMetadataKey{}, metadata)
+}
+
+// RequestMetadataFromContext returns the metadata of the request carried by the
+// context, or nil if it's unknown.
+func RequestMetadataFromContext(ctx context.Context) *RequestMetadata {
+	metadata, _ := ctx.Value(requestMetadataKey{}).(*RequestMetadata)
+	return metadata
+}
@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"math/big"
+	"time"
+)
+
 type DomainEvent interface {
 	GetType() string
 }
@@ -44,6 +49,27 @@ func (r *RestaurantDeleted) GetType() string {
 	return "RestaurantDeleted"
 }
 
+// --------------------------------------------------------------------------------
+// Event :: RestaurantRestored
+// --------------------------------------------------------------------------------
+
+// RestaurantRestored event is raised every time a deleted restaurant is restored
+// before being purged.
+type RestaurantRestored struct {
+	RestaurantId int64
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*RestaurantRestored)(nil)
+
+func NewRestaurantRestored(restaurantId int64) *RestaurantRestored {
+	return &RestaurantRestored{RestaurantId: restaurantId}
+}
+
+func (r *RestaurantRestored) GetType() string {
+	return "RestaurantRestored"
+}
+
 // --------------------------------------------------------------------------------
 // Event :: RestaurantMenuUpdated
 // --------------------------------------------------------------------------------
@@ -64,3 +90,393 @@ func NewRestaurantMenuUpdated(restaurantId int64, menu *Menu) *RestaurantMenuUpd
 func (r *RestaurantMenuUpdated) GetType() string {
 	return "RestaurantMenuUpdated"
 }
+
+// --------------------------------------------------------------------------------
+// Event :: RestaurantDaypartsUpdated
+// --------------------------------------------------------------------------------
+
+// RestaurantDaypartsUpdated event is raised every time a restaurant replaces the
+// menus it offers by time of day.
+type RestaurantDaypartsUpdated struct {
+	RestaurantId int64
+	Dayparts     []*Daypart
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*RestaurantDaypartsUpdated)(nil)
+
+func NewRestaurantDaypartsUpdated(restaurantId int64, dayparts []*Daypart) *RestaurantDaypartsUpdated {
+	return &RestaurantDaypartsUpdated{RestaurantId: restaurantId, Dayparts: dayparts}
+}
+
+func (r *RestaurantDaypartsUpdated) GetType() string {
+	return "RestaurantDaypartsUpdated"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: MenuItemAvailabilityChanged
+// --------------------------------------------------------------------------------
+
+// MenuItemAvailabilityChanged event is raised every time a single menu item is
+// marked as available or unavailable. It's a lightweight alternative to
+// RestaurantMenuUpdated that doesn't carry the whole menu.
+type MenuItemAvailabilityChanged struct {
+	RestaurantId   int64
+	MenuItemId     int16
+	Available      bool
+	AvailableUntil *time.Time
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*MenuItemAvailabilityChanged)(nil)
+
+func NewMenuItemAvailabilityChanged(restaurantId int64, menuItem *MenuItem) *MenuItemAvailabilityChanged {
+	return &MenuItemAvailabilityChanged{
+		RestaurantId:   restaurantId,
+		MenuItemId:     menuItem.GetId(),
+		Available:      menuItem.IsAvailable(),
+		AvailableUntil: menuItem.GetAvailableUntil(),
+	}
+}
+
+func (e *MenuItemAvailabilityChanged) GetType() string {
+	return "MenuItemAvailabilityChanged"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: MenuItemAdded
+// --------------------------------------------------------------------------------
+
+// MenuItemAdded event is raised every time a single item is added to a restaurant's
+// menu.
+type MenuItemAdded struct {
+	RestaurantId int64
+	MenuItem     *MenuItem
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*MenuItemAdded)(nil)
+
+func NewMenuItemAdded(restaurantId int64, menuItem *MenuItem) *MenuItemAdded {
+	return &MenuItemAdded{RestaurantId: restaurantId, MenuItem: menuItem}
+}
+
+func (e *MenuItemAdded) GetType() string {
+	return "MenuItemAdded"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: MenuItemUpdated
+// --------------------------------------------------------------------------------
+
+// MenuItemUpdated event is raised every time the details of a single menu item
+// (other than its price or availability) are changed.
+type MenuItemUpdated struct {
+	RestaurantId int64
+	MenuItem     *MenuItem
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*MenuItemUpdated)(nil)
+
+func NewMenuItemUpdated(restaurantId int64, menuItem *MenuItem) *MenuItemUpdated {
+	return &MenuItemUpdated{RestaurantId: restaurantId, MenuItem: menuItem}
+}
+
+func (e *MenuItemUpdated) GetType() string {
+	return "MenuItemUpdated"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: MenuItemPriceChanged
+// --------------------------------------------------------------------------------
+
+// MenuItemPriceChanged event is raised every time the price of a single menu item
+// is changed.
+type MenuItemPriceChanged struct {
+	RestaurantId int64
+	MenuItemId   int16
+	OldPrice     *big.Float
+	NewPrice     *big.Float
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*MenuItemPriceChanged)(nil)
+
+func NewMenuItemPriceChanged(restaurantId int64, menuItemId int16, oldPrice *big.Float, newPrice *big.Float) *MenuItemPriceChanged {
+	return &MenuItemPriceChanged{RestaurantId: restaurantId, MenuItemId: menuItemId, OldPrice: oldPrice, NewPrice: newPrice}
+}
+
+func (e *MenuItemPriceChanged) GetType() string {
+	return "MenuItemPriceChanged"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: MenuItemRemoved
+// --------------------------------------------------------------------------------
+
+// MenuItemRemoved event is raised every time a single item is removed from a
+// restaurant's menu.
+type MenuItemRemoved struct {
+	RestaurantId int64
+	MenuItemId   int16
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*MenuItemRemoved)(nil)
+
+func NewMenuItemRemoved(restaurantId int64, menuItemId int16) *MenuItemRemoved {
+	return &MenuItemRemoved{RestaurantId: restaurantId, MenuItemId: menuItemId}
+}
+
+func (e *MenuItemRemoved) GetType() string {
+	return "MenuItemRemoved"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: RestaurantRenamed
+// --------------------------------------------------------------------------------
+
+// RestaurantRenamed event is raised every time the name of a restaurant is changed.
+type RestaurantRenamed struct {
+	RestaurantId int64
+	Name         string
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*RestaurantRenamed)(nil)
+
+func NewRestaurantRenamed(restaurantId int64, name string) *RestaurantRenamed {
+	return &RestaurantRenamed{RestaurantId: restaurantId, Name: name}
+}
+
+func (e *RestaurantRenamed) GetType() string {
+	return "RestaurantRenamed"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: RestaurantAddressChanged
+// --------------------------------------------------------------------------------
+
+// RestaurantAddressChanged event is raised every time a restaurant is relocated
+// to a different address.
+type RestaurantAddressChanged struct {
+	RestaurantId int64
+	Address      *Address
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*RestaurantAddressChanged)(nil)
+
+func NewRestaurantAddressChanged(restaurantId int64, address *Address) *RestaurantAddressChanged {
+	return &RestaurantAddressChanged{RestaurantId: restaurantId, Address: address}
+}
+
+func (e *RestaurantAddressChanged) GetType() string {
+	return "RestaurantAddressChanged"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: RestaurantDescriptionChanged
+// --------------------------------------------------------------------------------
+
+// RestaurantDescriptionChanged event is raised every time the default locale, the
+// description or the translations of the description of a restaurant change. It
+// carries the resulting values of all of them.
+type RestaurantDescriptionChanged struct {
+	RestaurantId  int64
+	DefaultLocale string
+	Description   string
+	Translations  map[string]string
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*RestaurantDescriptionChanged)(nil)
+
+func NewRestaurantDescriptionChanged(restaurantId int64, defaultLocale string, description string, translations map[string]string) *RestaurantDescriptionChanged {
+	return &RestaurantDescriptionChanged{RestaurantId: restaurantId, DefaultLocale: defaultLocale, Description: description, Translations: translations}
+}
+
+func (e *RestaurantDescriptionChanged) GetType() string {
+	return "RestaurantDescriptionChanged"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: RestaurantTimeZoneChanged
+// --------------------------------------------------------------------------------
+
+// RestaurantTimeZoneChanged event is raised every time the time zone in which the
+// dayparts of a restaurant are evaluated changes.
+type RestaurantTimeZoneChanged struct {
+	RestaurantId int64
+	TimeZone     string
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*RestaurantTimeZoneChanged)(nil)
+
+func NewRestaurantTimeZoneChanged(restaurantId int64, timeZone string) *RestaurantTimeZoneChanged {
+	return &RestaurantTimeZoneChanged{RestaurantId: restaurantId, TimeZone: timeZone}
+}
+
+func (e *RestaurantTimeZoneChanged) GetType() string {
+	return "RestaurantTimeZoneChanged"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: TicketCreated
+// --------------------------------------------------------------------------------
+
+// TicketCreated event is raised every time a restaurant creates a kitchen ticket
+// for an order.
+type TicketCreated struct {
+	Ticket *Ticket
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*TicketCreated)(nil)
+
+func NewTicketCreated(ticket *Ticket) *TicketCreated {
+	return &TicketCreated{Ticket: ticket}
+}
+
+func (e *TicketCreated) GetType() string {
+	return "TicketCreated"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: TicketAccepted
+// --------------------------------------------------------------------------------
+
+// TicketAccepted event is raised every time a restaurant accepts a ticket,
+// committing to have it ready by a given instant.
+type TicketAccepted struct {
+	TicketId     int64
+	RestaurantId int64
+	OrderId      int64
+	ReadyBy      time.Time
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*TicketAccepted)(nil)
+
+func NewTicketAccepted(ticket *Ticket) *TicketAccepted {
+	return &TicketAccepted{TicketId: ticket.Id, RestaurantId: ticket.RestaurantId, OrderId: ticket.OrderId, ReadyBy: *ticket.ReadyBy}
+}
+
+func (e *TicketAccepted) GetType() string {
+	return "TicketAccepted"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: TicketRejected
+// --------------------------------------------------------------------------------
+
+// TicketRejected event is raised every time a restaurant rejects a ticket before
+// accepting it.
+type TicketRejected struct {
+	TicketId     int64
+	RestaurantId int64
+	OrderId      int64
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*TicketRejected)(nil)
+
+func NewTicketRejected(ticket *Ticket) *TicketRejected {
+	return &TicketRejected{TicketId: ticket.Id, RestaurantId: ticket.RestaurantId, OrderId: ticket.OrderId}
+}
+
+func (e *TicketRejected) GetType() string {
+	return "TicketRejected"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: TicketPreparationStarted
+// --------------------------------------------------------------------------------
+
+// TicketPreparationStarted event is raised every time the kitchen starts to
+// prepare a ticket.
+type TicketPreparationStarted struct {
+	TicketId     int64
+	RestaurantId int64
+	OrderId      int64
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*TicketPreparationStarted)(nil)
+
+func NewTicketPreparationStarted(ticket *Ticket) *TicketPreparationStarted {
+	return &TicketPreparationStarted{TicketId: ticket.Id, RestaurantId: ticket.RestaurantId, OrderId: ticket.OrderId}
+}
+
+func (e *TicketPreparationStarted) GetType() string {
+	return "TicketPreparationStarted"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: TicketReadyForPickup
+// --------------------------------------------------------------------------------
+
+// TicketReadyForPickup event is raised every time a ticket is ready to be picked
+// up by the courier.
+type TicketReadyForPickup struct {
+	TicketId     int64
+	RestaurantId int64
+	OrderId      int64
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*TicketReadyForPickup)(nil)
+
+func NewTicketReadyForPickup(ticket *Ticket) *TicketReadyForPickup {
+	return &TicketReadyForPickup{TicketId: ticket.Id, RestaurantId: ticket.RestaurantId, OrderId: ticket.OrderId}
+}
+
+func (e *TicketReadyForPickup) GetType() string {
+	return "TicketReadyForPickup"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: TicketPickedUp
+// --------------------------------------------------------------------------------
+
+// TicketPickedUp event is raised every time a ticket is picked up by the courier.
+type TicketPickedUp struct {
+	TicketId     int64
+	RestaurantId int64
+	OrderId      int64
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*TicketPickedUp)(nil)
+
+func NewTicketPickedUp(ticket *Ticket) *TicketPickedUp {
+	return &TicketPickedUp{TicketId: ticket.Id, RestaurantId: ticket.RestaurantId, OrderId: ticket.OrderId}
+}
+
+func (e *TicketPickedUp) GetType() string {
+	return "TicketPickedUp"
+}
+
+// --------------------------------------------------------------------------------
+// Event :: TicketCancelled
+// --------------------------------------------------------------------------------
+
+// TicketCancelled event is raised every time a ticket is cancelled.
+type TicketCancelled struct {
+	TicketId     int64
+	RestaurantId int64
+	OrderId      int64
+}
+
+// Interface compliance verification.
+var _ DomainEvent = (*TicketCancelled)(nil)
+
+func NewTicketCancelled(ticket *Ticket) *TicketCancelled {
+	return &TicketCancelled{TicketId: ticket.Id, RestaurantId: ticket.RestaurantId, OrderId: ticket.OrderId}
+}
+
+func (e *TicketCancelled) GetType() string {
+	return "TicketCancelled"
+}
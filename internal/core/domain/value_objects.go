@@ -1,23 +1,44 @@
 package domain
 
-import "math/big"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	// The runtime images have no time zone database.
+	_ "time/tzdata"
+
+	"golang.org/x/text/language"
+)
 
 // --------------------------------------------------------------------------------
 // VO :: Address
 // --------------------------------------------------------------------------------
 
 // Address is a value object to represent addresses (e.g. a restaurant Address).
+// The geographic location of the address is optional.
 type Address struct {
-	street string
-	city   string
-	state  string
-	zip    string
+	street   string
+	city     string
+	state    string
+	zip      string
+	location *GeoPoint
 }
 
 func NewAddress(street string, city string, state string, zip string) *Address {
 	return &Address{street: street, city: city, state: state, zip: zip}
 }
 
+// WithLocation returns a copy of the address placed at the provided location.
+func (a *Address) WithLocation(location *GeoPoint) *Address {
+	copy := *a
+	copy.location = location
+	return &copy
+}
+
 func (a *Address) Street() string {
 	return a.street
 }
@@ -34,36 +55,203 @@ func (a *Address) Zip() string {
 	return a.zip
 }
 
+// Location returns the geographic location of the address, if known.
+func (a *Address) Location() *GeoPoint {
+	return a.location
+}
+
+// Equals returns true if both addresses have the same values.
+func (a *Address) Equals(other *Address) bool {
+	if a == nil || other == nil {
+		return a == other
+	}
+	return a.street == other.street && a.city == other.city && a.state == other.state &&
+		a.zip == other.zip && a.location.Equals(other.location)
+}
+
+// --------------------------------------------------------------------------------
+// VO :: GeoPoint
+// --------------------------------------------------------------------------------
+
+// GeoPoint is a value object to represent a geographic location by its latitude
+// and longitude (in decimal degrees).
+type GeoPoint struct {
+	latitude  float64
+	longitude float64
+}
+
+// NewGeoPoint builds a GeoPoint validating that the latitude is within [-90, 90]
+// and the longitude within [-180, 180].
+func NewGeoPoint(latitude float64, longitude float64) (*GeoPoint, error) {
+	// Written as negated ranges so that NaN values are rejected too.
+	if !(latitude >= -90 && latitude <= 90) {
+		return nil, fmt.Errorf("invalid latitude: %v", latitude)
+	}
+	if !(longitude >= -180 && longitude <= 180) {
+		return nil, fmt.Errorf("invalid longitude: %v", longitude)
+	}
+	return &GeoPoint{latitude: latitude, longitude: longitude}, nil
+}
+
+func (p *GeoPoint) Latitude() float64 {
+	return p.latitude
+}
+
+func (p *GeoPoint) Longitude() float64 {
+	return p.longitude
+}
+
+// Equals returns true if both points have the same coordinates.
+func (p *GeoPoint) Equals(other *GeoPoint) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	return *p == *other
+}
+
 // --------------------------------------------------------------------------------
 // VO :: Menu
 // --------------------------------------------------------------------------------
 
 // Menu is a value object to represent restaurant menus. Is composed by menu items.
+// Every change on a menu produces a new immutable version of it, numbered from 1.
+// The version (and the instant since when it's valid) is unknown (zero) for the
+// menus that haven't been persisted yet.
 type Menu struct {
-	items []*MenuItem
+	items     []*MenuItem
+	version   int32
+	validFrom *time.Time
 }
 
 func NewMenu(items []*MenuItem) *Menu {
 	return &Menu{items: items}
 }
 
+// WithVersion returns a copy of the menu identified as the provided version.
+func (m *Menu) WithVersion(version int32, validFrom *time.Time) *Menu {
+	copy := *m
+	copy.version = version
+	copy.validFrom = validFrom
+	return &copy
+}
+
 func (m *Menu) GetItems() []*MenuItem {
 	return m.items
 }
 
+func (m *Menu) GetVersion() int32 {
+	return m.version
+}
+
+// GetValidFrom returns the instant since when this version of the menu is valid,
+// if known.
+func (m *Menu) GetValidFrom() *time.Time {
+	return m.validFrom
+}
+
+// WithoutAllergens returns a new menu including only the items that don't
+// contain any of the provided allergens.
+func (m *Menu) WithoutAllergens(allergens []Allergen) *Menu {
+	if m == nil || len(allergens) == 0 {
+		return m
+	}
+	items := []*MenuItem{}
+	for _, item := range m.items {
+		if !item.ContainsAnyAllergen(allergens) {
+			items = append(items, item)
+		}
+	}
+	return NewMenu(items).WithVersion(m.version, m.validFrom)
+}
+
+// Localize returns a new menu whose items have the names and descriptions of the
+// provided locale, if translated.
+func (m *Menu) Localize(locale string) *Menu {
+	if m == nil {
+		return m
+	}
+	items := []*MenuItem{}
+	for _, item := range m.items {
+		items = append(items, item.Localize(locale))
+	}
+	return NewMenu(items).WithVersion(m.version, m.validFrom)
+}
+
+// locales returns the locales in which any of the items of the menu is translated.
+func (m *Menu) locales() []string {
+	if m == nil {
+		return nil
+	}
+	locales := []string{}
+	for _, item := range m.items {
+		for locale := range item.translations {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// withItem returns a new menu where the item with the same identifier as the
+// provided one is replaced by it.
+func (m *Menu) withItem(menuItem *MenuItem) *Menu {
+	items := make([]*MenuItem, 0, len(m.items))
+	for _, item := range m.items {
+		if item.id == menuItem.id {
+			item = menuItem
+		}
+		items = append(items, item)
+	}
+	return NewMenu(items)
+}
+
+// GetItem returns the menu item with the given identifier (nil if not found).
+func (m *Menu) GetItem(id int16) *MenuItem {
+	for _, item := range m.items {
+		if item.id == id {
+			return item
+		}
+	}
+	return nil
+}
+
 // --------------------------------------------------------------------------------
 // VO :: MenuItem
 // --------------------------------------------------------------------------------
 
-// MenuItem is a value object to represent a menu item.
+// MenuItem is a value object to represent a menu item. A menu item can be
+// temporarily marked as not available (e.g. sold out for today) optionally
+// until a given instant, after which it is considered available again. The name
+// and description are written in the default locale of the restaurant, and they
+// may be translated into other locales (keyed by BCP 47 language tag).
 type MenuItem struct {
-	id    int16
-	name  string
-	price *big.Float
+	id             int16
+	name           string
+	description    string
+	translations   map[string]*Translation
+	price          *big.Float
+	available      bool
+	availableUntil *time.Time
+	allergens      []Allergen
+	dietaryTags    []DietaryTag
 }
 
+// NewMenuItem creates an available menu item.
 func NewMenuItem(id int16, name string, price *big.Float) *MenuItem {
-	return &MenuItem{id: id, name: name, price: price}
+	return &MenuItem{id: id, name: name, price: price, available: true}
+}
+
+// WithAvailability returns a copy of the menu item with the provided availability.
+// The availableUntil instant only makes sense for unavailable items, so it is
+// discarded when the item is marked as available.
+func (mi *MenuItem) WithAvailability(available bool, availableUntil *time.Time) *MenuItem {
+	c := *mi
+	c.available = available
+	if available {
+		c.availableUntil = nil
+	} else {
+		c.availableUntil = availableUntil
+	}
+	return &c
 }
 
 func (mi *MenuItem) GetId() int16 {
@@ -77,3 +265,1102 @@ func (mi *MenuItem) GetName() string {
 func (mi *MenuItem) GetPrice() *big.Float {
 	return mi.price
 }
+
+// WithLabels returns a copy of the menu item with the provided allergens and
+// dietary tags.
+func (mi *MenuItem) WithLabels(allergens []Allergen, dietaryTags []DietaryTag) *MenuItem {
+	c := *mi
+	c.allergens = allergens
+	c.dietaryTags = dietaryTags
+	return &c
+}
+
+// WithTexts returns a copy of the menu item with the provided description and
+// translations.
+func (mi *MenuItem) WithTexts(description string, translations map[string]*Translation) *MenuItem {
+	c := *mi
+	c.description = description
+	c.translations = translations
+	return &c
+}
+
+// GetDescription returns the description of the menu item in the default locale.
+func (mi *MenuItem) GetDescription() string {
+	return mi.description
+}
+
+// GetTranslations returns the translations of the menu item keyed by locale.
+func (mi *MenuItem) GetTranslations() map[string]*Translation {
+	return mi.translations
+}
+
+// Localize returns a copy of the menu item with the name and description of the
+// provided locale. The texts in the default locale are kept if there is no
+// translation for the locale, and so is the description if it's not translated.
+func (mi *MenuItem) Localize(locale string) *MenuItem {
+	translation, ok := mi.translations[locale]
+	if !ok {
+		return mi
+	}
+	c := *mi
+	c.name = translation.name
+	if translation.description != "" {
+		c.description = translation.description
+	}
+	return &c
+}
+
+// IsAvailable returns the raw availability flag of the menu item.
+func (mi *MenuItem) IsAvailable() bool {
+	return mi.available
+}
+
+// GetAvailableUntil returns the instant until which the menu item is not
+// available (nil if the item is available or has no expiration).
+func (mi *MenuItem) GetAvailableUntil() *time.Time {
+	return mi.availableUntil
+}
+
+// IsAvailableAt returns true if the menu item can be ordered at the given instant.
+func (mi *MenuItem) IsAvailableAt(t time.Time) bool {
+	return mi.available || (mi.availableUntil != nil && !t.Before(*mi.availableUntil))
+}
+
+// GetAllergens returns the allergens contained in the menu item.
+func (mi *MenuItem) GetAllergens() []Allergen {
+	return mi.allergens
+}
+
+// GetDietaryTags returns the dietary tags of the menu item.
+func (mi *MenuItem) GetDietaryTags() []DietaryTag {
+	return mi.dietaryTags
+}
+
+// ContainsAnyAllergen returns true if the menu item contains any of the
+// provided allergens.
+func (mi *MenuItem) ContainsAnyAllergen(allergens []Allergen) bool {
+	for _, a := range mi.allergens {
+		for _, b := range allergens {
+			if a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasDietaryTag returns true if the menu item is labelled with the provided
+// dietary tag.
+func (mi *MenuItem) HasDietaryTag(tag DietaryTag) bool {
+	for _, t := range mi.dietaryTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// --------------------------------------------------------------------------------
+// VO :: Translation
+// --------------------------------------------------------------------------------
+
+// Translation is a value object to represent the texts of a menu item in a locale
+// other than the default one. The description is optional.
+type Translation struct {
+	name        string
+	description string
+}
+
+func NewTranslation(name string, description string) *Translation {
+	return &Translation{name: name, description: description}
+}
+
+func (t *Translation) GetName() string {
+	return t.name
+}
+
+func (t *Translation) GetDescription() string {
+	return t.description
+}
+
+// --------------------------------------------------------------------------------
+// VO :: Locale
+// --------------------------------------------------------------------------------
+
+// DefaultLocale is the locale of the texts of a restaurant that doesn't declare
+// its own.
+const DefaultLocale = "en"
+
+// IsValidLocale returns true if the locale is a well-formed BCP 47 language tag.
+func IsValidLocale(locale string) bool {
+	_, err := language.Parse(locale)
+	return err == nil
+}
+
+// NegotiateLocale returns the available locale that best matches the preferred
+// ones (BCP 47 language tags in order of preference). The first available locale
+// is taken as the default, and it's returned if nothing matches.
+func NegotiateLocale(available []string, preferred []string) string {
+	if len(available) == 0 {
+		return ""
+	}
+	supported := []language.Tag{}
+	for _, locale := range available {
+		supported = append(supported, language.Make(locale))
+	}
+	desired := []language.Tag{}
+	for _, locale := range preferred {
+		if tag, err := language.Parse(locale); err == nil {
+			desired = append(desired, tag)
+		}
+	}
+	_, index, confidence := language.NewMatcher(supported).Match(desired...)
+	if confidence == language.No {
+		return available[0]
+	}
+	return available[index]
+}
+
+// --------------------------------------------------------------------------------
+// VO :: TimeWindow
+// --------------------------------------------------------------------------------
+
+// Number of minutes in a day, which is also the end of the last possible window.
+const minutesPerDay = 24 * 60
+
+// TimeWindow is a value object to represent a daily interval of time, in minutes
+// since midnight. The start is inclusive and the end exclusive, so a window that
+// lasts until midnight ends at 24:00. Windows don't cross midnight: a late dinner
+// is represented by two windows (e.g. 20:00-24:00 and 00:00-01:00).
+type TimeWindow struct {
+	start int
+	end   int
+}
+
+func NewTimeWindow(start int, end int) *TimeWindow {
+	return &TimeWindow{start: start, end: end}
+}
+
+func (w *TimeWindow) GetStart() int {
+	return w.start
+}
+
+func (w *TimeWindow) GetEnd() int {
+	return w.end
+}
+
+// Contains returns true if the time of day of the instant (in its own location)
+// falls within the window.
+func (w *TimeWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	return minute >= w.start && minute < w.end
+}
+
+// isValid returns true if the window is not empty and lies within a day.
+func (w *TimeWindow) isValid() bool {
+	return w != nil && w.start >= 0 && w.start < w.end && w.end <= minutesPerDay
+}
+
+// overlaps returns true if both windows share any minute of the day.
+func (w *TimeWindow) overlaps(other *TimeWindow) bool {
+	return w.start < other.end && other.start < w.end
+}
+
+// ParseTimeOfDay parses a time of day in HH:MM format (from 00:00 to 24:00) into
+// minutes since midnight.
+func ParseTimeOfDay(s string) (int, error) {
+	if s == "24:00" {
+		return minutesPerDay, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day: %s", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatTimeOfDay formats minutes since midnight as a time of day in HH:MM format.
+func FormatTimeOfDay(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// --------------------------------------------------------------------------------
+// VO :: TimeZone
+// --------------------------------------------------------------------------------
+
+// DefaultTimeZone is the time zone of a restaurant that doesn't declare its own.
+const DefaultTimeZone = "UTC"
+
+// IsValidTimeZone returns true if the time zone is a name of the IANA Time Zone
+// database (e.g. Europe/Madrid). The local time zone of the server isn't allowed.
+func IsValidTimeZone(timeZone string) bool {
+	if timeZone == "" || timeZone == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(timeZone)
+	return err == nil
+}
+
+// --------------------------------------------------------------------------------
+// VO :: Daypart
+// --------------------------------------------------------------------------------
+
+// Daypart is a value object to represent a named menu (e.g. breakfast) that a
+// restaurant offers during some time windows of the day. The name identifies the
+// daypart within its restaurant.
+type Daypart struct {
+	name    string
+	windows []*TimeWindow
+	menu    *Menu
+}
+
+func NewDaypart(name string, windows []*TimeWindow, menu *Menu) *Daypart {
+	return &Daypart{name: name, windows: windows, menu: menu}
+}
+
+func (d *Daypart) GetName() string {
+	return d.name
+}
+
+func (d *Daypart) GetWindows() []*TimeWindow {
+	return d.windows
+}
+
+func (d *Daypart) GetMenu() *Menu {
+	return d.menu
+}
+
+// IsActiveAt returns true if the time of day of the instant falls within any of
+// the windows of the daypart.
+func (d *Daypart) IsActiveAt(t time.Time) bool {
+	for _, window := range d.windows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutAllergens returns a copy of the daypart whose menu includes only the
+// items that don't contain any of the provided allergens.
+func (d *Daypart) WithoutAllergens(allergens []Allergen) *Daypart {
+	copy := *d
+	copy.menu = d.menu.WithoutAllergens(allergens)
+	return &copy
+}
+
+// Localize returns a copy of the daypart whose menu has the texts of the provided
+// locale.
+func (d *Daypart) Localize(locale string) *Daypart {
+	copy := *d
+	copy.menu = d.menu.Localize(locale)
+	return &copy
+}
+
+// --------------------------------------------------------------------------------
+// VO :: Allergen
+// --------------------------------------------------------------------------------
+
+// Allergen is an enumerated value object with the 14 allergens that must be
+// declared according to the EU regulation (No 1169/2011).
+type Allergen string
+
+const (
+	AllergenCelery      Allergen = "celery"
+	AllergenGluten      Allergen = "gluten"
+	AllergenCrustaceans Allergen = "crustaceans"
+	AllergenEggs        Allergen = "eggs"
+	AllergenFish        Allergen = "fish"
+	AllergenLupin       Allergen = "lupin"
+	AllergenMilk        Allergen = "milk"
+	AllergenMolluscs    Allergen = "molluscs"
+	AllergenMustard     Allergen = "mustard"
+	AllergenNuts        Allergen = "nuts"
+	AllergenPeanuts     Allergen = "peanuts"
+	AllergenSesame      Allergen = "sesame"
+	AllergenSoybeans    Allergen = "soybeans"
+	AllergenSulphites   Allergen = "sulphites"
+)
+
+// Allergens returns all the supported allergens.
+func Allergens() []Allergen {
+	return []Allergen{
+		AllergenCelery, AllergenGluten, AllergenCrustaceans, AllergenEggs, AllergenFish,
+		AllergenLupin, AllergenMilk, AllergenMolluscs, AllergenMustard, AllergenNuts,
+		AllergenPeanuts, AllergenSesame, AllergenSoybeans, AllergenSulphites,
+	}
+}
+
+// ParseAllergen converts a string into an Allergen, returning an error if the
+// value is not one of the supported allergens.
+func ParseAllergen(s string) (Allergen, error) {
+	a := Allergen(s)
+	if !a.IsValid() {
+		return "", fmt.Errorf("unknown allergen '%s'", s)
+	}
+	return a, nil
+}
+
+// IsValid returns true if the allergen is one of the supported allergens.
+func (a Allergen) IsValid() bool {
+	for _, v := range Allergens() {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
+
+// --------------------------------------------------------------------------------
+// VO :: DietaryTag
+// --------------------------------------------------------------------------------
+
+// DietaryTag is an enumerated value object to label menu items that are
+// suitable for a particular diet.
+type DietaryTag string
+
+const (
+	DietaryTagVegan      DietaryTag = "vegan"
+	DietaryTagVegetarian DietaryTag = "vegetarian"
+	DietaryTagGlutenFree DietaryTag = "gluten-free"
+)
+
+// DietaryTags returns all the supported dietary tags.
+func DietaryTags() []DietaryTag {
+	return []DietaryTag{DietaryTagVegan, DietaryTagVegetarian, DietaryTagGlutenFree}
+}
+
+// ParseDietaryTag converts a string into a DietaryTag, returning an error if
+// the value is not one of the supported dietary tags.
+func ParseDietaryTag(s string) (DietaryTag, error) {
+	t := DietaryTag(s)
+	if !t.IsValid() {
+		return "", fmt.Errorf("unknown dietary tag '%s'", s)
+	}
+	return t, nil
+}
+
+// IsValid returns true if the dietary tag is one of the supported tags.
+func (t DietaryTag) IsValid() bool {
+	for _, v := range DietaryTags() {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// --------------------------------------------------------------------------------
+// VO :: TicketState
+// --------------------------------------------------------------------------------
+
+// TicketState is an enumerated value object with the states of the lifecycle of
+// a kitchen ticket.
+type TicketState string
+
+const (
+	TicketStateCreatePending  TicketState = "create-pending"
+	TicketStateCreated        TicketState = "created"
+	TicketStateAccepted       TicketState = "accepted"
+	TicketStatePreparing      TicketState = "preparing"
+	TicketStateReadyForPickup TicketState = "ready-for-pickup"
+	TicketStatePickedUp       TicketState = "picked-up"
+	TicketStateCancelled      TicketState = "cancelled"
+)
+
+// TicketStates returns all the supported ticket states.
+func TicketStates() []TicketState {
+	return []TicketState{
+		TicketStateCreatePending, TicketStateCreated, TicketStateAccepted, TicketStatePreparing,
+		TicketStateReadyForPickup, TicketStatePickedUp, TicketStateCancelled,
+	}
+}
+
+// ParseTicketState converts a string into a TicketState, returning an error if
+// the value is not one of the supported states.
+func ParseTicketState(s string) (TicketState, error) {
+	ts := TicketState(s)
+	if !ts.IsValid() {
+		return "", fmt.Errorf("unknown ticket state '%s'", s)
+	}
+	return ts, nil
+}
+
+// IsValid returns true if the ticket state is one of the supported states.
+func (ts TicketState) IsValid() bool {
+	for _, v := range TicketStates() {
+		if ts == v {
+			return true
+		}
+	}
+	return false
+}
+
+// --------------------------------------------------------------------------------
+// VO :: TicketLineItem
+// --------------------------------------------------------------------------------
+
+// TicketLineItem is a value object to represent a line of a kitchen ticket. It
+// references a menu item of the restaurant by its identifier and keeps a copy of
+// its name so that the kitchen doesn't depend on later menu changes.
+type TicketLineItem struct {
+	menuItemId int16
+	name       string
+	quantity   int32
+}
+
+func NewTicketLineItem(menuItemId int16, name string, quantity int32) *TicketLineItem {
+	return &TicketLineItem{menuItemId: menuItemId, name: name, quantity: quantity}
+}
+
+func (li *TicketLineItem) GetMenuItemId() int16 {
+	return li.menuItemId
+}
+
+func (li *TicketLineItem) GetName() string {
+	return li.name
+}
+
+func (li *TicketLineItem) GetQuantity() int32 {
+	return li.quantity
+}
+
+// --------------------------------------------------------------------------------
+// VO :: OrderLine
+// --------------------------------------------------------------------------------
+
+// OrderLine is a value object to represent a line of a basket that a customer
+// wants to order: a menu item of the restaurant and the requested quantity.
+type OrderLine struct {
+	menuItemId int16
+	quantity   int32
+}
+
+func NewOrderLine(menuItemId int16, quantity int32) *OrderLine {
+	return &OrderLine{menuItemId: menuItemId, quantity: quantity}
+}
+
+func (ol *OrderLine) GetMenuItemId() int16 {
+	return ol.menuItemId
+}
+
+func (ol *OrderLine) GetQuantity() int32 {
+	return ol.quantity
+}
+
+// --------------------------------------------------------------------------------
+// VO :: OrderLineRejection
+// --------------------------------------------------------------------------------
+
+// OrderLineRejection is an enumerated value object with the reasons why a line
+// of an order can be rejected when it is validated against a menu.
+type OrderLineRejection string
+
+const (
+	OrderLineRejectionMenuItemNotFound     OrderLineRejection = "menu-item-not-found"
+	OrderLineRejectionMenuItemNotAvailable OrderLineRejection = "menu-item-not-available"
+	OrderLineRejectionInvalidQuantity      OrderLineRejection = "invalid-quantity"
+	OrderLineRejectionDuplicatedMenuItem   OrderLineRejection = "duplicated-menu-item"
+)
+
+// --------------------------------------------------------------------------------
+// VO :: Quote
+// --------------------------------------------------------------------------------
+
+// QuoteLine is a value object with the result of validating an order line: the
+// unit price and the line total computed from the stored menu prices, or the
+// reason why the line was rejected (in which case there are no prices).
+type QuoteLine struct {
+	menuItemId int16
+	name       string
+	quantity   int32
+	unitPrice  *big.Float
+	lineTotal  *big.Float
+	rejection  *OrderLineRejection
+}
+
+func (ql *QuoteLine) GetMenuItemId() int16 {
+	return ql.menuItemId
+}
+
+func (ql *QuoteLine) GetName() string {
+	return ql.name
+}
+
+func (ql *QuoteLine) GetQuantity() int32 {
+	return ql.quantity
+}
+
+// GetUnitPrice returns the stored price of the menu item (nil if rejected).
+func (ql *QuoteLine) GetUnitPrice() *big.Float {
+	return ql.unitPrice
+}
+
+// GetLineTotal returns the unit price multiplied by the quantity (nil if rejected).
+func (ql *QuoteLine) GetLineTotal() *big.Float {
+	return ql.lineTotal
+}
+
+// GetRejection returns the reason why the line was rejected (nil if accepted).
+func (ql *QuoteLine) GetRejection() *OrderLineRejection {
+	return ql.rejection
+}
+
+// Quote is a value object with the result of validating an order against the menu
+// of a restaurant. The total only includes the lines that were not rejected.
+type Quote struct {
+	restaurantId int64
+	lines        []*QuoteLine
+	total        *big.Float
+}
+
+func (q *Quote) GetRestaurantId() int64 {
+	return q.restaurantId
+}
+
+func (q *Quote) GetLines() []*QuoteLine {
+	return q.lines
+}
+
+func (q *Quote) GetTotal() *big.Float {
+	return q.total
+}
+
+// IsValid returns true if none of the lines of the quote was rejected, which means
+// that the order can be placed as is.
+func (q *Quote) IsValid() bool {
+	for _, line := range q.lines {
+		if line.rejection != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// --------------------------------------------------------------------------------
+// VO :: RestaurantSnapshot
+// --------------------------------------------------------------------------------
+
+// RestaurantSnapshot is a value object with the fields of a restaurant at a given
+// moment, flattened into dotted paths (e.g. "menu.items.1.price") with their values
+// as text. Empty fields are left out.
+type RestaurantSnapshot map[string]string
+
+// put sets the value of a field, unless it's empty.
+func (s RestaurantSnapshot) put(field string, value string) {
+	if value != "" {
+		s[field] = value
+	}
+}
+
+// putMenu sets the fields of the items of a menu under the given prefix.
+func (s RestaurantSnapshot) putMenu(prefix string, menu *Menu) {
+	if menu == nil {
+		return
+	}
+	for _, item := range menu.GetItems() {
+		itemPrefix := fmt.Sprintf("%s.items.%d.", prefix, item.GetId())
+		s.put(itemPrefix+"name", item.GetName())
+		s.put(itemPrefix+"description", item.GetDescription())
+		if item.GetPrice() != nil {
+			s.put(itemPrefix+"price", item.GetPrice().Text('f', -1))
+		}
+		s.put(itemPrefix+"available", fmt.Sprint(item.IsAvailable()))
+		if item.GetAvailableUntil() != nil {
+			s.put(itemPrefix+"availableUntil", item.GetAvailableUntil().UTC().Format(time.RFC3339))
+		}
+		allergens := make([]string, 0, len(item.GetAllergens()))
+		for _, allergen := range item.GetAllergens() {
+			allergens = append(allergens, string(allergen))
+		}
+		s.put(itemPrefix+"allergens", strings.Join(allergens, ","))
+		dietaryTags := make([]string, 0, len(item.GetDietaryTags()))
+		for _, dietaryTag := range item.GetDietaryTags() {
+			dietaryTags = append(dietaryTags, string(dietaryTag))
+		}
+		s.put(itemPrefix+"dietaryTags", strings.Join(dietaryTags, ","))
+		for locale, translation := range item.GetTranslations() {
+			s.put(itemPrefix+"translations."+locale+".name", translation.GetName())
+			s.put(itemPrefix+"translations."+locale+".description", translation.GetDescription())
+		}
+	}
+}
+
+// Diff returns the fields that differ between this snapshot and a later one, keyed
+// by their paths. A nil snapshot stands for a restaurant that didn't exist.
+func (s RestaurantSnapshot) Diff(after RestaurantSnapshot) map[string]*FieldChange {
+	changes := map[string]*FieldChange{}
+	for field, value := range s {
+		before := value
+		if value, ok := after[field]; !ok {
+			changes[field] = NewFieldChange(&before, nil)
+		} else if value != before {
+			changes[field] = NewFieldChange(&before, &value)
+		}
+	}
+	for field, value := range after {
+		if _, ok := s[field]; !ok {
+			value := value
+			changes[field] = NewFieldChange(nil, &value)
+		}
+	}
+	return changes
+}
+
+// FieldChange is a value object with the values of a field of a restaurant before
+// and after a change (nil if the field was empty).
+type FieldChange struct {
+	before *string
+	after  *string
+}
+
+func NewFieldChange(before *string, after *string) *FieldChange {
+	return &FieldChange{before: before, after: after}
+}
+
+func (c *FieldChange) GetBefore() *string {
+	return c.before
+}
+
+func (c *FieldChange) GetAfter() *string {
+	return c.after
+}
+
+// --------------------------------------------------------------------------------
+// VO :: RestaurantQuery
+// --------------------------------------------------------------------------------
+
+// RestaurantSortField is an enumerated value object with the fields the restaurants
+// can be sorted by.
+type RestaurantSortField string
+
+const (
+	RestaurantSortById    RestaurantSortField = "id"
+	RestaurantSortByName  RestaurantSortField = "name"
+	RestaurantSortByCity  RestaurantSortField = "city"
+	RestaurantSortByState RestaurantSortField = "state"
+	RestaurantSortByZip   RestaurantSortField = "zip"
+)
+
+// RestaurantSortFields returns all the supported sort fields.
+func RestaurantSortFields() []RestaurantSortField {
+	return []RestaurantSortField{RestaurantSortById, RestaurantSortByName, RestaurantSortByCity, RestaurantSortByState, RestaurantSortByZip}
+}
+
+// ParseRestaurantSortField converts a string into a RestaurantSortField, returning
+// an error if the value is not one of the supported fields.
+func ParseRestaurantSortField(s string) (RestaurantSortField, error) {
+	f := RestaurantSortField(s)
+	if !f.IsValid() {
+		return "", fmt.Errorf("unknown sort field '%s'", s)
+	}
+	return f, nil
+}
+
+// IsValid returns true if the sort field is one of the supported fields.
+func (f RestaurantSortField) IsValid() bool {
+	for _, v := range RestaurantSortFields() {
+		if f == v {
+			return true
+		}
+	}
+	return false
+}
+
+// SortDirection is an enumerated value object with the directions of a sort.
+type SortDirection string
+
+const (
+	SortAscending  SortDirection = "asc"
+	SortDescending SortDirection = "desc"
+)
+
+// ParseSortDirection converts a string into a SortDirection, returning an error if
+// the value is not one of the supported directions.
+func ParseSortDirection(s string) (SortDirection, error) {
+	d := SortDirection(s)
+	if d != SortAscending && d != SortDescending {
+		return "", fmt.Errorf("unknown sort direction '%s'", s)
+	}
+	return d, nil
+}
+
+// RestaurantQuery is a value object with the criteria to search restaurants. Empty
+// criteria match any restaurant. The name matches by prefix, while the city, state
+// and zip match exactly (ignoring case except for the zip). The menu item criteria
+// (a text contained in the name and a price range) match the restaurants offering
+// at least one menu item meeting all of them. The results are sorted by id unless
+// another field is provided, breaking ties by id. The deleted restaurants are only
+// included on demand (which is restricted to admins).
+type RestaurantQuery struct {
+	NamePrefix     string
+	City           string
+	State          string
+	Zip            string
+	MenuItemName   string
+	MinPrice       *big.Float
+	MaxPrice       *big.Float
+	SortBy         RestaurantSortField
+	SortDirection  SortDirection
+	IncludeDeleted bool
+}
+
+// Validate checks the criteria of the query, filling the default sort when absent.
+func (q *RestaurantQuery) Validate() error {
+	for _, criterion := range []string{q.NamePrefix, q.City, q.State, q.Zip, q.MenuItemName} {
+		if len(criterion) > maxRestaurantNameLength {
+			return fmt.Errorf("the search criteria can't exceed %d characters", maxRestaurantNameLength)
+		}
+	}
+	if (q.MinPrice != nil && q.MinPrice.Sign() < 0) || (q.MaxPrice != nil && q.MaxPrice.Sign() < 0) {
+		return fmt.Errorf("the price range can't be negative")
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && q.MinPrice.Cmp(q.MaxPrice) > 0 {
+		return fmt.Errorf("the minimum price can't be greater than the maximum price")
+	}
+	if q.SortBy == "" {
+		q.SortBy = RestaurantSortById
+	}
+	if !q.SortBy.IsValid() {
+		return fmt.Errorf("unknown sort field '%s'", q.SortBy)
+	}
+	if q.SortDirection == "" {
+		q.SortDirection = SortAscending
+	}
+	if _, err := ParseSortDirection(string(q.SortDirection)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// HasMenuItemCriteria returns true if the query filters the restaurants by their
+// menu items.
+func (q *RestaurantQuery) HasMenuItemCriteria() bool {
+	return q.MenuItemName != "" || q.MinPrice != nil || q.MaxPrice != nil
+}
+
+// HasCriteria returns true if the query filters the restaurants in any way.
+func (q *RestaurantQuery) HasCriteria() bool {
+	return q.NamePrefix != "" || q.City != "" || q.State != "" || q.Zip != "" || q.HasMenuItemCriteria()
+}
+
+// sortValue returns the value of the sort field of the query for a restaurant (empty
+// when sorted by id, since the id is always part of the sort key).
+func (q *RestaurantQuery) sortValue(restaurant *Restaurant) string {
+	switch q.SortBy {
+	case RestaurantSortByName:
+		return restaurant.Name
+	case RestaurantSortByCity:
+		return restaurant.Address.City()
+	case RestaurantSortByState:
+		return restaurant.Address.State()
+	case RestaurantSortByZip:
+		return restaurant.Address.Zip()
+	default:
+		return ""
+	}
+}
+
+// --------------------------------------------------------------------------------
+// VO :: Pagination
+// --------------------------------------------------------------------------------
+
+// CountMode is an enumerated value object with the ways the total number of items
+// of a paginated listing can be computed.
+type CountMode string
+
+const (
+	// CountExact counts all the matching items (the default).
+	CountExact CountMode = "exact"
+	// CountEstimated takes the estimate of the database statistics when the listing
+	// isn't filtered, which is much cheaper on large tables. Filtered listings are
+	// counted exactly.
+	CountEstimated CountMode = "estimated"
+	// CountNone doesn't compute the total.
+	CountNone CountMode = "none"
+)
+
+// ParseCountMode converts a string into a CountMode, returning an error if the value
+// is not one of the supported modes.
+func ParseCountMode(s string) (CountMode, error) {
+	m := CountMode(s)
+	if m != CountExact && m != CountEstimated && m != CountNone {
+		return "", fmt.Errorf("unknown count mode '%s'", s)
+	}
+	return m, nil
+}
+
+// Cursor is a value object pointing to a position in a listing of restaurants: the
+// sort key (the value of the sort field and the id) of the first or last restaurant
+// of a page. The next page has the restaurants placed after that position, while
+// the previous one has those placed before it (backward). Unlike offsets, cursors
+// are not affected by the restaurants inserted or deleted between requests.
+type Cursor struct {
+	sortBy        RestaurantSortField
+	sortDirection SortDirection
+	value         string
+	id            int64
+	backward      bool
+}
+
+// encodedCursor is the representation of a cursor before being made opaque.
+type encodedCursor struct {
+	SortBy        RestaurantSortField `json:"s"`
+	SortDirection SortDirection       `json:"d"`
+	Value         string              `json:"v,omitempty"`
+	Id            int64               `json:"i"`
+	Backward      bool                `json:"b,omitempty"`
+}
+
+// NewCursorAt builds a cursor pointing to a restaurant of a listing sorted by a
+// (validated) query, to read the restaurants placed after it (or before it if
+// backward).
+func NewCursorAt(query *RestaurantQuery, restaurant *Restaurant, backward bool) *Cursor {
+	return &Cursor{sortBy: query.SortBy, sortDirection: query.SortDirection, value: query.sortValue(restaurant), id: restaurant.Id, backward: backward}
+}
+
+// DecodeCursor converts the opaque representation of a cursor back into a Cursor,
+// returning an error if it's malformed.
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	var ec encodedCursor
+	if err := json.Unmarshal(data, &ec); err != nil || !ec.SortBy.IsValid() || (ec.SortDirection != SortAscending && ec.SortDirection != SortDescending) {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return &Cursor{sortBy: ec.SortBy, sortDirection: ec.SortDirection, value: ec.Value, id: ec.Id, backward: ec.Backward}, nil
+}
+
+// Encode returns the opaque representation of the cursor given to the clients.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(encodedCursor{SortBy: c.sortBy, SortDirection: c.sortDirection, Value: c.value, Id: c.id, Backward: c.backward})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// GetValue returns the value of the sort field at the position of the cursor.
+func (c *Cursor) GetValue() string {
+	return c.value
+}
+
+// GetId returns the id of the restaurant at the position of the cursor.
+func (c *Cursor) GetId() int64 {
+	return c.id
+}
+
+// IsBackward returns true if the cursor reads the restaurants placed before its
+// position.
+func (c *Cursor) IsBackward() bool {
+	return c.backward
+}
+
+// Pagination is a value object with the page of a listing requested by a client:
+// the items after (or before) a cursor if present or, otherwise, the items after an
+// offset. The count mode tells how the total number of items is computed.
+type Pagination struct {
+	Offset int
+	Limit  int
+	Cursor *Cursor
+	Count  CountMode
+}
+
+// Validate checks the pagination against the (validated) query of the listing,
+// filling the default count mode when absent. A cursor can only be used with the
+// sort of the query it was obtained from.
+func (p *Pagination) Validate(query *RestaurantQuery) error {
+	if p.Offset < 0 || p.Limit < 1 {
+		return fmt.Errorf("the offset can't be negative and the limit must be positive")
+	}
+	if p.Count == "" {
+		p.Count = CountExact
+	}
+	if _, err := ParseCountMode(string(p.Count)); err != nil {
+		return err
+	}
+	if p.Cursor != nil && (p.Cursor.sortBy != query.SortBy || p.Cursor.sortDirection != query.SortDirection) {
+		return fmt.Errorf("the cursor doesn't match the sort of the query")
+	}
+	return nil
+}
+
+// RestaurantPage is a value object with a page of a listing of restaurants. The
+// total is nil if it wasn't counted, and the cursors are nil if there are no more
+// restaurants in their direction.
+type RestaurantPage struct {
+	Restaurants []*Restaurant
+	Total       *int64
+	Estimated   bool
+	NextCursor  *Cursor
+	PrevCursor  *Cursor
+}
+
+// --------------------------------------------------------------------------------
+// VO :: RestaurantSearchResult
+// --------------------------------------------------------------------------------
+
+const (
+	// maxSearchTextLength is the maximum length of the texts of full-text searches.
+	maxSearchTextLength = 255
+	// HighlightStart marks the start of a matched word in the highlights.
+	HighlightStart = "<mark>"
+	// HighlightStop marks the end of a matched word in the highlights.
+	HighlightStop = "</mark>"
+)
+
+// ValidateSearchText checks the text of a full-text search, which can't be blank.
+func ValidateSearchText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("the search text can't be empty")
+	}
+	if len(text) > maxSearchTextLength {
+		return fmt.Errorf("the search text can't exceed %d characters", maxSearchTextLength)
+	}
+	return nil
+}
+
+// RestaurantSearchResult is a value object with a restaurant matching a full-text
+// search. The rank measures the relevance of the restaurant (between 0 and 1, the
+// higher the better), while the highlight contains the fragments of its indexed
+// texts (name, menu items and address) where the words of the search were found,
+// delimited by HighlightStart and HighlightStop.
+type RestaurantSearchResult struct {
+	restaurant *Restaurant
+	rank       float32
+	highlight  string
+}
+
+func NewRestaurantSearchResult(restaurant *Restaurant, rank float32, highlight string) *RestaurantSearchResult {
+	return &RestaurantSearchResult{restaurant: restaurant, rank: rank, highlight: highlight}
+}
+
+func (r *RestaurantSearchResult) GetRestaurant() *Restaurant {
+	return r.restaurant
+}
+
+func (r *RestaurantSearchResult) GetRank() float32 {
+	return r.rank
+}
+
+func (r *RestaurantSearchResult) GetHighlight() string {
+	return r.highlight
+}
+
+// --------------------------------------------------------------------------------
+// VO :: Batch
+// --------------------------------------------------------------------------------
+
+// BatchMode is an enumerated value object with the transaction semantics of the
+// operations on batches of restaurants.
+type BatchMode string
+
+const (
+	// BatchAllOrNothing applies the whole batch in a single transaction, so nothing
+	// is applied if any restaurant fails.
+	BatchAllOrNothing BatchMode = "all-or-nothing"
+	// BatchBestEffort applies each restaurant in its own transaction, so the failures
+	// don't prevent the rest of the batch from being applied.
+	BatchBestEffort BatchMode = "best-effort"
+)
+
+// ParseBatchMode converts a string into a BatchMode, returning an error if the value
+// is not one of the supported modes.
+func ParseBatchMode(s string) (BatchMode, error) {
+	m := BatchMode(s)
+	if m != BatchAllOrNothing && m != BatchBestEffort {
+		return "", fmt.Errorf("unknown batch mode '%s'", s)
+	}
+	return m, nil
+}
+
+// CreationResult is a value object with the outcome of creating a restaurant of a
+// batch: the id of the restaurant if it was created, or the error that prevented it
+// otherwise.
+type CreationResult struct {
+	restaurantId int64
+	err          error
+}
+
+func NewCreationResult(restaurantId int64, err error) *CreationResult {
+	if err != nil {
+		restaurantId = 0
+	}
+	return &CreationResult{restaurantId: restaurantId, err: err}
+}
+
+func (r *CreationResult) GetRestaurantId() int64 {
+	return r.restaurantId
+}
+
+func (r *CreationResult) GetError() error {
+	return r.err
+}
+
+// IsCreated returns true if the restaurant was created.
+func (r *CreationResult) IsCreated() bool {
+	return r.err == nil
+}
+
+// --------------------------------------------------------------------------------
+// VO :: Idempotency
+// --------------------------------------------------------------------------------
+
+// Maximum length of an idempotency key.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyKey is a value object with the key given by a client to make a request
+// idempotent, along with the hash of the request. Retries of the request with the
+// same key get the response of its first execution.
+type IdempotencyKey struct {
+	key         string
+	requestHash string
+}
+
+// NewIdempotencyKey returns a new idempotency key of a request, checking that the
+// key is not empty nor too long.
+func NewIdempotencyKey(key string, requestHash string) (*IdempotencyKey, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("the idempotency key must have between 1 and %d characters", maxIdempotencyKeyLength)
+	}
+	return &IdempotencyKey{key: key, requestHash: requestHash}, nil
+}
+
+func (k *IdempotencyKey) GetKey() string {
+	return k.key
+}
+
+func (k *IdempotencyKey) GetRequestHash() string {
+	return k.requestHash
+}
+
+// IdempotencyRecord is a value object with the response of the first execution of
+// an idempotent request, stored under its key. Keys are scoped by caller, so that
+// different callers can't get the responses of each other.
+type IdempotencyRecord struct {
+	scope     string
+	key       *IdempotencyKey
+	response  []byte
+	createdAt time.Time
+}
+
+func NewIdempotencyRecord(scope string, key *IdempotencyKey, response []byte, createdAt time.Time) *IdempotencyRecord {
+	return &IdempotencyRecord{scope: scope, key: key, response: response, createdAt: createdAt}
+}
+
+func (r *IdempotencyRecord) GetScope() string {
+	return r.scope
+}
+
+func (r *IdempotencyRecord) GetKey() *IdempotencyKey {
+	return r.key
+}
+
+// GetResponse returns the response of the request, encoded by the adapter that
+// received it.
+func (r *IdempotencyRecord) GetResponse() []byte {
+	return r.response
+}
+
+func (r *IdempotencyRecord) GetCreatedAt() time.Time {
+	return r.createdAt
+}
@@ -2,26 +2,190 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"f4allgo-restaurant/internal/core/domain"
 )
 
 // RestaurantService exposes operations on restaurants. These operations are
-// implemented in the service layer.
+// implemented in the service layer. The operations modifying a restaurant are only
+// allowed to the caller carried by the context (see domain.IdentityFromContext) if
+// it belongs to the tenant owning the restaurant or it's an admin. Besides, every
+// operation can be subject to an AuthorizationPolicy granting permissions by role.
 type RestaurantService interface {
 
-	// FindAll gets the complete list of registered restaurants.
-	FindAll(ctx context.Context, offset int, limit int) ([]*domain.Restaurant, int64, error)
+	// FindAll gets a page of the registered restaurants matching the query (all of them
+	// if nil), either after an offset or after/before a cursor of a previous page. The
+	// menu items containing any of the excluded allergens (if any) are filtered out.
+	// Only admins can include the deleted restaurants in the query.
+	FindAll(ctx context.Context, query *domain.RestaurantQuery, pagination *domain.Pagination, excludedAllergens []domain.Allergen) (*domain.RestaurantPage, error)
 
-	// FindById gets a restaurant by its identifier.
-	FindById(ctx context.Context, restaurantId int64) (*domain.Restaurant, error)
+	// Export walks all the restaurants matching the query (all of them if nil) in batches
+	// of the given size, calling the function with each batch until it returns an error
+	// or there are no more restaurants. Only a batch is kept in memory at once.
+	Export(ctx context.Context, query *domain.RestaurantQuery, batchSize int, fn func([]*domain.Restaurant) error) error
 
-	// Create creates and persist a restaurant.
+	// FindById gets a restaurant by its identifier. If an instant is provided, the
+	// menu of the restaurant is the one offered at that time of day (see dayparts).
+	// The menu items containing any of the excluded allergens (if any) are filtered out.
+	FindById(ctx context.Context, restaurantId int64, at *time.Time, excludedAllergens []domain.Allergen) (*domain.Restaurant, error)
+
+	// FindNearby gets the restaurants located within a radius (in meters) of a point,
+	// sorted by distance. Restaurants without a known location are never returned.
+	FindNearby(ctx context.Context, point *domain.GeoPoint, radius float64, offset int, limit int, excludedAllergens []domain.Allergen) ([]*domain.Restaurant, int64, error)
+
+	// Search gets the restaurants whose name, menu items or address match a full-text
+	// search, sorted by relevance. The text follows the web search syntax: quoted
+	// phrases, 'or' and '-' to exclude words are supported.
+	Search(ctx context.Context, text string, offset int, limit int, excludedAllergens []domain.Allergen) ([]*domain.RestaurantSearchResult, int64, error)
+
+	// FindMenuAt gets the version of a restaurant's menu that was in effect at the
+	// given instant.
+	FindMenuAt(ctx context.Context, restaurantId int64, at time.Time) (*domain.Menu, error)
+
+	// FindMenuVersions gets the versions of a restaurant's menu, newest first.
+	FindMenuVersions(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.Menu, int64, error)
+
+	// Create creates and persist a restaurant owned by the tenant of the caller. Admins
+	// can create restaurants on behalf of other tenants.
 	Create(ctx context.Context, restaurant *domain.Restaurant) (int64, error)
 
+	// CreateMany creates a batch of restaurants as Create does, returning the outcome
+	// of each one in the same order. In all-or-nothing mode either all of them are
+	// created or none, while in best-effort mode each one is created independently.
+	// The error is only returned if the batch couldn't be processed at all.
+	CreateMany(ctx context.Context, restaurants []*domain.Restaurant, mode domain.BatchMode) ([]*domain.CreationResult, error)
+
+	// UpdateRestaurant renames and/or relocates a restaurant. Nil changes are left
+	// untouched.
+	UpdateRestaurant(ctx context.Context, restaurantId int64, changes *domain.RestaurantChanges) error
+
 	// UpdateMenu updates a restaurant's menu enforcing some invariants.
 	UpdateMenu(ctx context.Context, restaurantId int64, menu *domain.Menu) error
 
-	// Delete deletes a restaurant.
+	// UpdateDayparts replaces the menus a restaurant offers by time of day, enforcing
+	// that their time windows don't overlap.
+	UpdateDayparts(ctx context.Context, restaurantId int64, dayparts []*domain.Daypart) error
+
+	// SetItemAvailability marks a single menu item as available or unavailable
+	// (optionally until a given instant) without replacing the whole menu.
+	SetItemAvailability(ctx context.Context, restaurantId int64, menuItemId int16, available bool, availableUntil *time.Time) error
+
+	// AddMenuItem adds a single item to a restaurant's menu.
+	AddMenuItem(ctx context.Context, restaurantId int64, menuItem *domain.MenuItem) error
+
+	// UpdateMenuItem applies partial changes to a single item of a restaurant's menu.
+	UpdateMenuItem(ctx context.Context, restaurantId int64, menuItemId int16, changes *domain.MenuItemChanges) error
+
+	// RemoveMenuItem removes a single item from a restaurant's menu.
+	RemoveMenuItem(ctx context.Context, restaurantId int64, menuItemId int16) error
+
+	// ValidateOrder checks an order against a restaurant's menu, computing the price
+	// of every line and the total with the stored menu prices. Invalid lines are not
+	// an error: they are returned with a rejection reason and excluded from the total.
+	ValidateOrder(ctx context.Context, restaurantId int64, lines []*domain.OrderLine) (*domain.Quote, error)
+
+	// Delete deletes a restaurant. The restaurant is kept until it's purged, so it can
+	// be restored meanwhile.
 	Delete(ctx context.Context, restaurantId int64) error
+
+	// Restore restores a deleted restaurant that hasn't been purged yet. It follows the
+	// same rules as Delete.
+	Restore(ctx context.Context, restaurantId int64) error
+
+	// FindAuditEntries gets the audit entries of the mutations of a restaurant (deleted
+	// or not), newest first. It follows the same ownership rules as the mutations.
+	FindAuditEntries(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.AuditEntry, int64, error)
+
+	// Purge removes for good the restaurants deleted before the given instant, returning
+	// how many were purged. It's meant to be run periodically by the service itself, so
+	// it's not subject to the ownership of the restaurants.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TicketService exposes operations on the kitchen tickets of restaurants. These
+// operations are implemented in the service layer. Except for the ones driven by
+// the create ticket saga, they can be subject to an AuthorizationPolicy too.
+type TicketService interface {
+
+	// FindById gets a ticket by its identifier.
+	FindById(ctx context.Context, ticketId int64) (*domain.Ticket, error)
+
+	// FindByRestaurant gets the tickets of a restaurant, optionally filtered by state
+	// (nil to get all of them), newest first.
+	FindByRestaurant(ctx context.Context, restaurantId int64, state *domain.TicketState, offset int, limit int) ([]*domain.Ticket, int64, error)
+
+	// Create creates and persist a ticket for an order. The line items must refer to
+	// available items of the restaurant's menu.
+	Create(ctx context.Context, restaurantId int64, orderId int64, lineItems []*domain.TicketLineItem) (int64, error)
+
+	// CreatePending creates and persist a ticket for an order that is pending of
+	// confirmation (e.g. by a saga). It's idempotent: if the order already has a
+	// ticket its identifier is returned.
+	CreatePending(ctx context.Context, restaurantId int64, orderId int64, lineItems []*domain.TicketLineItem) (int64, error)
+
+	// ConfirmCreate confirms the creation of a pending ticket. Confirming a ticket
+	// already confirmed does nothing.
+	ConfirmCreate(ctx context.Context, ticketId int64) error
+
+	// CancelCreate cancels the creation of a pending ticket. Cancelling a ticket
+	// already cancelled does nothing.
+	CancelCreate(ctx context.Context, ticketId int64) error
+
+	// Accept accepts a created ticket, committing to have it ready by a given instant.
+	Accept(ctx context.Context, ticketId int64, readyBy time.Time) error
+
+	// Reject rejects a created ticket.
+	Reject(ctx context.Context, ticketId int64) error
+
+	// StartPreparing marks an accepted ticket as being prepared.
+	StartPreparing(ctx context.Context, ticketId int64) error
+
+	// MarkReadyForPickup marks a ticket being prepared as ready for pickup.
+	MarkReadyForPickup(ctx context.Context, ticketId int64) error
+
+	// PickUp marks a ticket ready for pickup as picked up.
+	PickUp(ctx context.Context, ticketId int64) error
+
+	// Cancel cancels a ticket that hasn't started to be prepared yet.
+	Cancel(ctx context.Context, ticketId int64) error
+}
+
+// MenuScheduleService exposes operations to prepare the menus of restaurants in
+// advance. These operations are implemented in the service layer. Scheduling and
+// cancelling menus follow the same ownership rules as RestaurantService, and every
+// operation called by a caller can be subject to an AuthorizationPolicy too.
+type MenuScheduleService interface {
+
+	// Schedule stores a menu that will replace the current menu of a restaurant once
+	// the effective instant (that must be in the future) is reached.
+	Schedule(ctx context.Context, restaurantId int64, menu *domain.Menu, effectiveFrom time.Time) (int64, error)
+
+	// FindByRestaurant gets the pending scheduled menus of a restaurant, sorted by the
+	// instant they become effective.
+	FindByRestaurant(ctx context.Context, restaurantId int64) ([]*domain.ScheduledMenu, error)
+
+	// Cancel cancels a pending scheduled menu of a restaurant.
+	Cancel(ctx context.Context, restaurantId int64, scheduledMenuId int64) error
+
+	// ActivateDue replaces the menus of the restaurants with the scheduled menus that
+	// are effective at the given instant, returning how many were activated. Nothing
+	// is activated if another instance of the service is already doing it.
+	ActivateDue(ctx context.Context, at time.Time) (int, error)
+}
+
+// IdempotencyService exposes operations to make the requests of the clients
+// idempotent, so that they can be safely retried. These operations are implemented
+// in the service layer.
+type IdempotencyService interface {
+
+	// Execute executes an operation once per idempotency key in the scope of the caller,
+	// storing its response in the same transaction. Retries with the same key get the
+	// stored response instead (flagged as replayed), unless the key was given with another
+	// request. The operation must run within the context it receives.
+	Execute(ctx context.Context, key *domain.IdempotencyKey, operation func(ctx context.Context) ([]byte, error)) ([]byte, bool, error)
+
+	// Purge removes the idempotency keys stored before the given instant, returning how
+	// many were purged. It's meant to be run periodically by the service itself.
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
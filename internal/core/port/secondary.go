@@ -3,19 +3,48 @@ package port
 import (
 	"context"
 	"f4allgo-restaurant/internal/core/domain"
+	"time"
 )
 
 // RestaurantRepository manages persistent operations on restaurants dealing
-// with an external storage system.
+// with an external storage system. Every operation changing a restaurant's menu
+// writes a new immutable version of it, keeping the previous ones. Deleted restaurants
+// are kept until they are purged, but the operations ignore them (as if they didn't
+// exist) unless stated otherwise.
+//
+// All the operations modifying a restaurant check that it's still at the version it
+// was read at (the version of the restaurant, or the one given along with its
+// identifier), returning domain.ErrConcurrentModification otherwise, and increase its
+// version. They return domain.ErrRestaurantNotFound if the restaurant doesn't exist
+// (or it's deleted).
 type RestaurantRepository interface {
 
-	// FindAll restrieves all the registered restaurants with their menus.
-	FindAll(ctx context.Context, offset int, limit int) ([]*domain.Restaurant, int64, error)
+	// FindAll restrieves a page of the registered restaurants matching the (already
+	// validated) query with their menus (and dayparts), sorted as requested by the
+	// query, along with the cursors of the adjacent pages and the requested count. The
+	// deleted restaurants are included if the query asks for them.
+	FindAll(ctx context.Context, query *domain.RestaurantQuery, pagination *domain.Pagination) (*domain.RestaurantPage, error)
 
 	// FindById retrieves a particular restaurant by its identifier. The method
-	// allows the client to decide if the restaurant's menu should be fetched or not.
+	// allows the client to decide if the restaurant's menus (the default one and
+	// the dayparts) should be fetched or not.
 	FindById(ctx context.Context, restaurantId int64, fetchMenu bool) (*domain.Restaurant, error)
 
+	// FindNearby retrieves the restaurants (with their menus and dayparts) located within a radius (in
+	// meters) of a point, sorted by distance.
+	FindNearby(ctx context.Context, point *domain.GeoPoint, radius float64, offset int, limit int) ([]*domain.Restaurant, int64, error)
+
+	// Search retrieves the restaurants (with their menus and dayparts) matching a
+	// full-text search, sorted by relevance, along with the highlighted matches.
+	Search(ctx context.Context, text string, offset int, limit int) ([]*domain.RestaurantSearchResult, int64, error)
+
+	// FindMenuAt retrieves the version of a restaurant's menu that was in effect at the
+	// given instant.
+	FindMenuAt(ctx context.Context, restaurantId int64, at time.Time) (*domain.Menu, error)
+
+	// FindMenuVersions retrieves the versions of a restaurant's menu, newest first.
+	FindMenuVersions(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.Menu, int64, error)
+
 	// Save persists a restaurant in the external storage and sets its generated identifier
 	// to the restaurant instance input argument.
 	Save(ctx context.Context, restaurant *domain.Restaurant) error
@@ -27,9 +56,117 @@ type RestaurantRepository interface {
 	// a restaurant's menu.
 	Update(ctx context.Context, restaurant *domain.Restaurant) (int64, error)
 
-	// Delete deletes a restaurant from the external storage and return the number of rows
+	// UpdateProfile updates the name and address of a restaurant (without touching its
+	// menu) and returns the number of rows affected.
+	UpdateProfile(ctx context.Context, restaurant *domain.Restaurant) (int64, error)
+
+	// UpdateDayparts replaces all the dayparts of an existing restaurant.
+	UpdateDayparts(ctx context.Context, restaurantId int64, version int64, dayparts []*domain.Daypart) error
+
+	// SaveMenuItem persists a single new menu item of an existing restaurant.
+	SaveMenuItem(ctx context.Context, restaurantId int64, version int64, menuItem *domain.MenuItem) error
+
+	// UpdateMenuItem updates a single menu item of a restaurant in place (without touching
+	// the rest of the menu) and returns the number of rows affected.
+	UpdateMenuItem(ctx context.Context, restaurantId int64, version int64, menuItem *domain.MenuItem) (int64, error)
+
+	// DeleteMenuItem deletes a single menu item of a restaurant and returns the number of
+	// rows affected.
+	DeleteMenuItem(ctx context.Context, restaurantId int64, version int64, menuItemId int16) (int64, error)
+
+	// Delete deletes a restaurant and return the number of rows affected. The restaurant
+	// is only marked as deleted, so it can be restored until it's purged.
+	Delete(ctx context.Context, restaurantId int64, version int64) (int64, error)
+
+	// FindDeletedById retrieves a deleted restaurant (not purged yet) by its identifier,
+	// without its menus.
+	FindDeletedById(ctx context.Context, restaurantId int64) (*domain.Restaurant, error)
+
+	// Restore restores a deleted restaurant (not purged yet) and returns the number of
+	// rows affected.
+	Restore(ctx context.Context, restaurantId int64, version int64) (int64, error)
+
+	// Purge removes from the external storage the restaurants deleted before the given
+	// instant, with all their relations, and returns how many were purged.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TicketRepository manages persistent operations on kitchen tickets dealing with an
+// external storage system.
+type TicketRepository interface {
+
+	// FindById retrieves a particular ticket (with its line items) by its identifier.
+	FindById(ctx context.Context, ticketId int64) (*domain.Ticket, error)
+
+	// FindByOrderId retrieves the ticket (with its line items) created for an order.
+	FindByOrderId(ctx context.Context, orderId int64) (*domain.Ticket, error)
+
+	// FindByRestaurant retrieves the tickets of a restaurant, optionally filtered by state,
+	// newest first.
+	FindByRestaurant(ctx context.Context, restaurantId int64, state *domain.TicketState, offset int, limit int) ([]*domain.Ticket, int64, error)
+
+	// Save persists a ticket in the external storage and sets its generated identifier to
+	// the ticket instance input argument.
+	Save(ctx context.Context, ticket *domain.Ticket) error
+
+	// UpdateState updates the state (and the related instants) of a ticket and returns the
+	// number of rows affected.
+	UpdateState(ctx context.Context, ticket *domain.Ticket) (int64, error)
+}
+
+// ScheduledMenuRepository manages persistent operations on the menus scheduled to
+// replace the menu of the restaurants, dealing with an external storage system.
+type ScheduledMenuRepository interface {
+
+	// FindByRestaurant retrieves the pending scheduled menus of a restaurant, sorted by
+	// the instant they become effective.
+	FindByRestaurant(ctx context.Context, restaurantId int64) ([]*domain.ScheduledMenu, error)
+
+	// FindDue retrieves up to limit scheduled menus (of any restaurant) already effective
+	// at the given instant, oldest first.
+	FindDue(ctx context.Context, at time.Time, limit int) ([]*domain.ScheduledMenu, error)
+
+	// Save persists a scheduled menu in the external storage and sets its generated
+	// identifier to the scheduled menu instance input argument.
+	Save(ctx context.Context, scheduledMenu *domain.ScheduledMenu) error
+
+	// Delete deletes a scheduled menu of a restaurant and returns the number of rows
 	// affected.
-	Delete(ctx context.Context, restaurantId int64) (int64, error)
+	Delete(ctx context.Context, restaurantId int64, scheduledMenuId int64) (int64, error)
+
+	// TryLock tries to acquire the lock that coordinates the activation of the scheduled
+	// menus across several instances of the service, without waiting for it. The lock is
+	// held until the end of the current transaction.
+	TryLock(ctx context.Context) (bool, error)
+}
+
+// IdempotencyKeyRepository manages persistent operations on the responses of the
+// idempotent requests, dealing with an external storage system.
+type IdempotencyKeyRepository interface {
+
+	// Find retrieves the record of an idempotency key in a scope, or nil if there's none.
+	Find(ctx context.Context, scope string, key string) (*domain.IdempotencyRecord, error)
+
+	// Save persists the record of an idempotency key unless there's already one for the
+	// key in its scope, returning whether it was saved.
+	Save(ctx context.Context, record *domain.IdempotencyRecord) (bool, error)
+
+	// Purge removes from the external storage the records of the idempotency keys
+	// saved before the given instant, and returns how many were purged.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AuditLogRepository manages persistent operations on the audit log of the
+// restaurants, dealing with an external storage system. The entries are only
+// appended, never modified.
+type AuditLogRepository interface {
+
+	// Save persists an audit entry in the external storage and sets its generated
+	// identifier to the audit entry instance input argument.
+	Save(ctx context.Context, entry *domain.AuditEntry) error
+
+	// FindByRestaurant retrieves the audit entries of a restaurant, newest first.
+	FindByRestaurant(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.AuditEntry, int64, error)
 }
 
 // DomainEventPublisher publishes domain events to the outside world dealing with a
@@ -39,3 +176,20 @@ type DomainEventPublisher interface {
 	// Publish publishes a domain event to the outside world.
 	Publish(ctx context.Context, e domain.DomainEvent) error
 }
+
+// Geocoder resolves the geographic location of addresses dealing with an external
+// geocoding system.
+type Geocoder interface {
+
+	// Geocode returns the location of an address, or nil if it's unknown.
+	Geocode(ctx context.Context, address *domain.Address) (*domain.GeoPoint, error)
+}
+
+// AuthorizationPolicy decides which operations the callers are allowed to perform,
+// dealing with an external source of permissions.
+type AuthorizationPolicy interface {
+
+	// IsAllowed returns true if the caller (nil if anonymous) has been granted the
+	// permission. Implementations are expected to audit the denied requests.
+	IsAllowed(ctx context.Context, identity *domain.Identity, permission domain.Permission) (bool, error)
+}
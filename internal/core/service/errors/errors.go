@@ -12,6 +12,18 @@ func (r *RestaurantNotFoundError) Error() string {
 	return ""
 }
 
+// ForbiddenError is returned when the caller is not allowed to perform an operation
+// (e.g. modifying a restaurant owned by another tenant).
+type ForbiddenError struct{}
+
+func NewForbiddenError() *ForbiddenError {
+	return &ForbiddenError{}
+}
+
+func (f *ForbiddenError) Error() string {
+	return "forbidden"
+}
+
 // RepositoryError is returned when an unexpected error occurr using
 // repositories.
 type RepositoryError struct {
@@ -53,3 +65,126 @@ func NewCoreError(err error) *CoreError {
 func (e *CoreError) Error() string {
 	return e.err.Error()
 }
+
+// MenuItemNotFoundError is returned when an operation refers to a menu item
+// that doesn't exist in the restaurant's menu.
+type MenuItemNotFoundError struct{}
+
+func NewMenuItemNotFoundError() *MenuItemNotFoundError {
+	return &MenuItemNotFoundError{}
+}
+
+func (m *MenuItemNotFoundError) Error() string {
+	return "menu item not found"
+}
+
+// MenuItemAlreadyExistsError is returned when trying to add a menu item whose
+// identifier is already present in the restaurant's menu.
+type MenuItemAlreadyExistsError struct{}
+
+func NewMenuItemAlreadyExistsError() *MenuItemAlreadyExistsError {
+	return &MenuItemAlreadyExistsError{}
+}
+
+func (m *MenuItemAlreadyExistsError) Error() string {
+	return "menu item already exists"
+}
+
+// MenuVersionNotFoundError is returned when searching for the version of a menu in
+// effect at an instant and the restaurant had no menu yet.
+type MenuVersionNotFoundError struct{}
+
+func NewMenuVersionNotFoundError() *MenuVersionNotFoundError {
+	return &MenuVersionNotFoundError{}
+}
+
+func (m *MenuVersionNotFoundError) Error() string {
+	return "menu version not found"
+}
+
+// ScheduledMenuNotFoundError is returned when searching for a particular scheduled
+// menu of a restaurant and no results are found matching the criteria.
+type ScheduledMenuNotFoundError struct{}
+
+func NewScheduledMenuNotFoundError() *ScheduledMenuNotFoundError {
+	return &ScheduledMenuNotFoundError{}
+}
+
+func (s *ScheduledMenuNotFoundError) Error() string {
+	return "scheduled menu not found"
+}
+
+// TicketNotFoundError is returned when searching for a particular ticket in the
+// database and no results are found matching the criteria.
+type TicketNotFoundError struct{}
+
+func NewTicketNotFoundError() *TicketNotFoundError {
+	return &TicketNotFoundError{}
+}
+
+func (t *TicketNotFoundError) Error() string {
+	return "ticket not found"
+}
+
+// InvalidTicketStateError is returned when an operation on a ticket is not
+// allowed in its current state (e.g. accepting a cancelled ticket).
+type InvalidTicketStateError struct {
+	err error
+}
+
+func NewInvalidTicketStateError(err error) *InvalidTicketStateError {
+	return &InvalidTicketStateError{err: err}
+}
+
+func (e *InvalidTicketStateError) Error() string {
+	return e.err.Error()
+}
+
+// BatchAbortedError is returned for the restaurants of an all-or-nothing batch that
+// were not created because another restaurant of the batch failed.
+type BatchAbortedError struct{}
+
+func NewBatchAbortedError() *BatchAbortedError {
+	return &BatchAbortedError{}
+}
+
+func (b *BatchAbortedError) Error() string {
+	return "not created because another restaurant of the batch failed"
+}
+
+// IdempotencyKeyReusedError is returned when an idempotency key is given again with
+// a request other than the one it was first given with.
+type IdempotencyKeyReusedError struct{}
+
+func NewIdempotencyKeyReusedError() *IdempotencyKeyReusedError {
+	return &IdempotencyKeyReusedError{}
+}
+
+func (i *IdempotencyKeyReusedError) Error() string {
+	return "the idempotency key was already used with a different request"
+}
+
+// IdempotencyKeyConflictError is returned when a request is executed concurrently
+// with another one with the same idempotency key, which prevails.
+type IdempotencyKeyConflictError struct{}
+
+func NewIdempotencyKeyConflictError() *IdempotencyKeyConflictError {
+	return &IdempotencyKeyConflictError{}
+}
+
+func (i *IdempotencyKeyConflictError) Error() string {
+	return "a request with the same idempotency key was executed concurrently"
+}
+
+// ConcurrencyConflictError is returned when a restaurant was modified by someone else
+// since the caller read it, either because it's not at the version expected by the
+// caller or because it changed while the operation was running.
+type ConcurrencyConflictError struct{}
+
+func NewConcurrencyConflictError() *ConcurrencyConflictError {
+	return &ConcurrencyConflictError{}
+}
+
+func (c *ConcurrencyConflictError) Error() string {
+	return "the restaurant was modified concurrently"
+}
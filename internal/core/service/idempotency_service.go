@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/rs/zerolog/log"
+)
+
+// Default implementation of the primary port IdempotencyService.
+type DefaultIdempotencyService struct {
+	idempotencyKeyRepository port.IdempotencyKeyRepository
+	trManager                trm.Manager
+}
+
+// Interface compliance verification.
+var _ port.IdempotencyService = (*DefaultIdempotencyService)(nil)
+
+// Provides an instance of DefaultIdempotencyService.
+func NewDefaultIdempotencyService(idempotencyKeyRepository port.IdempotencyKeyRepository, trManager trm.Manager) *DefaultIdempotencyService {
+	return &DefaultIdempotencyService{idempotencyKeyRepository: idempotencyKeyRepository, trManager: trManager}
+}
+
+func (is *DefaultIdempotencyService) Execute(ctx context.Context, key *domain.IdempotencyKey, operation func(ctx context.Context) ([]byte, error)) ([]byte, bool, error) {
+	scope := idempotencyScope(ctx)
+	if scope == "" {
+		return nil, false, coreerrors.NewCoreError(errors.New("idempotency keys are only accepted from identified callers"))
+	}
+	var response []byte
+	replayed := false
+	err := is.trManager.Do(ctx, func(ctx context.Context) error {
+		record, err := is.idempotencyKeyRepository.Find(ctx, scope, key.GetKey())
+		if err != nil {
+			log.Error().Msg("an error occurred while fetching an idempotency key: " + err.Error())
+			return coreerrors.NewRepositoryError(err)
+		}
+		if record != nil {
+			if record.GetKey().GetRequestHash() != key.GetRequestHash() {
+				return coreerrors.NewIdempotencyKeyReusedError()
+			}
+			response, replayed = record.GetResponse(), true
+			return nil
+		}
+
+		// The operation joins the transaction, so it's rolled back if the key can't
+		// be saved (e.g. when the same request is being retried concurrently).
+		if response, err = operation(ctx); err != nil {
+			return err
+		}
+		saved, err := is.idempotencyKeyRepository.Save(ctx, domain.NewIdempotencyRecord(scope, key, response, time.Now()))
+		if err != nil {
+			log.Error().Msg("an error occurred while saving an idempotency key: " + err.Error())
+			return coreerrors.NewRepositoryError(err)
+		}
+		if !saved {
+			return coreerrors.NewIdempotencyKeyConflictError()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return response, replayed, nil
+}
+
+func (is *DefaultIdempotencyService) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := is.trManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if purged, err = is.idempotencyKeyRepository.Purge(ctx, before); err != nil {
+			log.Error().Msg("an error occurred while purging the idempotency keys: " + err.Error())
+			return coreerrors.NewRepositoryError(err)
+		}
+		return nil
+	})
+
+	return purged, err
+}
+
+// InitIdempotencyKeyPurger initializes a background process (inside a go routine)
+// that periodically purges the idempotency keys stored longer than the retention
+// period ago, after which the requests are no longer deduplicated. Several instances
+// of the service can run it at the same time, since purging the same keys twice does
+// nothing.
+func (is *DefaultIdempotencyService) InitIdempotencyKeyPurger(retention time.Duration, interval time.Duration) {
+	log.Debug().Msg("initializing the idempotency key purger")
+	go func() {
+		ticker := time.NewTicker(interval)
+		for range ticker.C {
+			purged, err := is.Purge(context.Background(), time.Now().Add(-retention))
+			if err != nil {
+				log.Error().Msg("an error occurred while purging the idempotency keys: " + err.Error())
+			} else if purged > 0 {
+				log.Info().Msgf("%d idempotency keys were purged", purged)
+			}
+		}
+	}()
+}
+
+// idempotencyScope returns the scope of the idempotency keys of the caller, which is
+// empty for anonymous callers. Their keys are rejected, since a shared scope would
+// let a caller replay the responses of another one.
+func idempotencyScope(ctx context.Context) string {
+	if identity := domain.IdentityFromContext(ctx); identity != nil {
+		return identity.GetSubject()
+	}
+	return ""
+}
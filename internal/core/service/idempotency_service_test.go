@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+	"f4allgo-restaurant/internal/core/service/mocks"
+	"f4allgo-restaurant/test"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExecute(t *testing.T) {
+	key, _ := domain.NewIdempotencyKey("key1", "hash1")
+	anotherRequest, _ := domain.NewIdempotencyKey("key1", "hash2")
+	type args struct {
+		ctx context.Context
+		key *domain.IdempotencyKey
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockIdempotencyKeyRepository)
+		operationErr     error
+		wantExecuted     bool
+		wantResponse     []byte
+		wantReplayed     bool
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a first execution",
+			args: args{ctx: ownerCtx, key: key},
+			mockExpectations: func(args args, mi *mocks.MockIdempotencyKeyRepository) {
+				mi.EXPECT().Find(args.ctx, "owner", "key1").Return(nil, nil).Once()
+				mi.EXPECT().Save(args.ctx, mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+					return r.GetScope() == "owner" && r.GetKey() == args.key && string(r.GetResponse()) == "response"
+				})).Return(true, nil).Once()
+			},
+			wantExecuted: true,
+			wantResponse: []byte("response"),
+		},
+		{
+			name: "mock a retry",
+			args: args{ctx: ownerCtx, key: key},
+			mockExpectations: func(args args, mi *mocks.MockIdempotencyKeyRepository) {
+				mi.EXPECT().Find(args.ctx, "owner", "key1").Return(domain.NewIdempotencyRecord("owner", key, []byte("stored"), time.Now()), nil).Once()
+			},
+			wantResponse: []byte("stored"),
+			wantReplayed: true,
+		},
+		{
+			name: "mock a key reused with another request",
+			args: args{ctx: ownerCtx, key: anotherRequest},
+			mockExpectations: func(args args, mi *mocks.MockIdempotencyKeyRepository) {
+				mi.EXPECT().Find(args.ctx, "owner", "key1").Return(domain.NewIdempotencyRecord("owner", key, []byte("stored"), time.Now()), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.IdempotencyKeyReusedError{},
+		},
+		{
+			name: "mock a concurrent execution with the same key",
+			args: args{ctx: ownerCtx, key: key},
+			mockExpectations: func(args args, mi *mocks.MockIdempotencyKeyRepository) {
+				mi.EXPECT().Find(args.ctx, "owner", "key1").Return(nil, nil).Once()
+				mi.EXPECT().Save(args.ctx, mock.Anything).Return(false, nil).Once()
+			},
+			wantExecuted: true,
+			wantErr:      true,
+			wantErrType:  &coreerrors.IdempotencyKeyConflictError{},
+		},
+		{
+			name: "mock a failed operation",
+			args: args{ctx: ownerCtx, key: key},
+			mockExpectations: func(args args, mi *mocks.MockIdempotencyKeyRepository) {
+				mi.EXPECT().Find(args.ctx, "owner", "key1").Return(nil, nil).Once()
+			},
+			operationErr: coreerrors.NewForbiddenError(),
+			wantExecuted: true,
+			wantErr:      true,
+			wantErrType:  &coreerrors.ForbiddenError{},
+		},
+		{
+			name:             "provide a key from an anonymous caller",
+			args:             args{ctx: context.Background(), key: key},
+			mockExpectations: func(args args, mi *mocks.MockIdempotencyKeyRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+		},
+		{
+			name: "mock an IdempotencyKeyRepository failure",
+			args: args{ctx: ownerCtx, key: key},
+			mockExpectations: func(args args, mi *mocks.MockIdempotencyKeyRepository) {
+				mi.EXPECT().Find(args.ctx, "owner", "key1").Return(nil, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := mocks.NewMockIdempotencyKeyRepository(t)
+			tc.mockExpectations(tc.args, mockRepository)
+			is := NewDefaultIdempotencyService(mockRepository, test.NewNopTrManager())
+			executed := false
+			response, replayed, err := is.Execute(tc.args.ctx, tc.args.key, func(ctx context.Context) ([]byte, error) {
+				executed = true
+				return []byte("response"), tc.operationErr
+			})
+			assert.Equal(t, tc.wantExecuted, executed)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantResponse, response)
+				assert.Equal(t, tc.wantReplayed, replayed)
+			}
+		})
+	}
+}
+
+func TestIdempotencyPurge(t *testing.T) {
+	before := time.Now().Add(-24 * time.Hour)
+	testcases := []struct {
+		name             string
+		mockExpectations func(*mocks.MockIdempotencyKeyRepository)
+		wantPurged       int64
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			mockExpectations: func(mi *mocks.MockIdempotencyKeyRepository) {
+				mi.EXPECT().Purge(mock.Anything, before).Return(3, nil).Once()
+			},
+			wantPurged: 3,
+		},
+		{
+			name: "mock an IdempotencyKeyRepository failure",
+			mockExpectations: func(mi *mocks.MockIdempotencyKeyRepository) {
+				mi.EXPECT().Purge(mock.Anything, before).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := mocks.NewMockIdempotencyKeyRepository(t)
+			tc.mockExpectations(mockRepository)
+			is := NewDefaultIdempotencyService(mockRepository, test.NewNopTrManager())
+			purged, err := is.Purge(context.Background(), before)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantPurged, purged)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/rs/zerolog/log"
+)
+
+// Maximum number of scheduled menus activated in a single transaction.
+const activationBatchSize int = 100
+
+// Default implementation of the primary port MenuScheduleService.
+type DefaultMenuScheduleService struct {
+	scheduledMenuRepository port.ScheduledMenuRepository
+	restaurantRepository    port.RestaurantRepository
+	domainEventPublisher    port.DomainEventPublisher
+	trManager               trm.Manager
+	policy                  port.AuthorizationPolicy
+	auditLogRepository      port.AuditLogRepository
+}
+
+// Interface compliance verification.
+var _ port.MenuScheduleService = (*DefaultMenuScheduleService)(nil)
+
+// Provides an instance of DefaultMenuScheduleService.
+func NewDefaultMenuScheduleService(scheduledMenuRepository port.ScheduledMenuRepository, restaurantRepository port.RestaurantRepository, domainEventPublisher port.DomainEventPublisher, trManager trm.Manager) *DefaultMenuScheduleService {
+	return &DefaultMenuScheduleService{scheduledMenuRepository: scheduledMenuRepository, restaurantRepository: restaurantRepository, domainEventPublisher: domainEventPublisher, trManager: trManager}
+}
+
+// WithAuthorizationPolicy sets an optional policy deciding which operations the
+// callers are allowed to perform, as in DefaultRestaurantService. The activation of
+// the scheduled menus isn't subject to it, since no caller performs it.
+func (ms *DefaultMenuScheduleService) WithAuthorizationPolicy(policy port.AuthorizationPolicy) *DefaultMenuScheduleService {
+	ms.policy = policy
+	return ms
+}
+
+// WithAuditLog sets an optional audit log where the scheduled menus, their
+// cancellations and their activations are recorded, along with the rest of the
+// mutations of the restaurants (see DefaultRestaurantService.WithAuditLog).
+func (ms *DefaultMenuScheduleService) WithAuditLog(auditLogRepository port.AuditLogRepository) *DefaultMenuScheduleService {
+	ms.auditLogRepository = auditLogRepository
+	return ms
+}
+
+// InitMenuScheduler initializes a background process (inside a go routine) that
+// periodically activates the scheduled menus that are already effective. Several
+// instances of the service can run it at the same time (see ActivateDue).
+func (ms *DefaultMenuScheduleService) InitMenuScheduler(interval time.Duration) {
+	log.Debug().Msg("initializing the menu scheduler")
+	go func() {
+		ticker := time.NewTicker(interval)
+		for range ticker.C {
+			activated, err := ms.ActivateDue(context.Background(), time.Now())
+			if err != nil {
+				log.Error().Msg("an error occurred while activating the scheduled menus: " + err.Error())
+			} else if activated > 0 {
+				log.Info().Msgf("%d scheduled menus were activated", activated)
+			}
+		}
+	}()
+}
+
+func (ms *DefaultMenuScheduleService) Schedule(ctx context.Context, restaurantId int64, menu *domain.Menu, effectiveFrom time.Time) (int64, error) {
+	if err := authorize(ctx, ms.policy, domain.PermissionUpdateMenu); err != nil {
+		return 0, err
+	}
+
+	var scheduledMenuId int64
+	err := ms.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := ms.findRestaurant(ctx, restaurantId, false)
+		if err != nil {
+			return err
+		}
+		if !restaurant.IsManageableBy(domain.IdentityFromContext(ctx)) {
+			return coreerrors.NewForbiddenError()
+		}
+
+		scheduledMenu, err := restaurant.ScheduleMenu(menu, effectiveFrom, time.Now())
+		if err != nil {
+			return coreerrors.NewCoreError(err)
+		}
+
+		if err := ms.scheduledMenuRepository.Save(ctx, scheduledMenu); err != nil {
+			log.Error().Msg("an error occurred while persisting a scheduled menu: " + err.Error())
+			return coreerrors.NewRepositoryError(err)
+		}
+
+		if err := ms.audit(ctx, restaurantId, domain.AuditScheduleMenu, nil, scheduledMenu.Snapshot()); err != nil {
+			return err
+		}
+
+		scheduledMenuId = scheduledMenu.Id
+		return nil
+	})
+
+	return scheduledMenuId, err
+}
+
+func (ms *DefaultMenuScheduleService) FindByRestaurant(ctx context.Context, restaurantId int64) ([]*domain.ScheduledMenu, error) {
+	if err := authorize(ctx, ms.policy, domain.PermissionReadRestaurant); err != nil {
+		return nil, err
+	}
+
+	if _, err := ms.findRestaurant(ctx, restaurantId, false); err != nil {
+		return nil, err
+	}
+
+	scheduledMenus, err := ms.scheduledMenuRepository.FindByRestaurant(ctx, restaurantId)
+	if err != nil {
+		log.Error().Msg("an error occurred while fetching the scheduled menus of a restaurant: " + err.Error())
+		return nil, coreerrors.NewRepositoryError(err)
+	}
+
+	return scheduledMenus, nil
+}
+
+func (ms *DefaultMenuScheduleService) Cancel(ctx context.Context, restaurantId int64, scheduledMenuId int64) error {
+	if err := authorize(ctx, ms.policy, domain.PermissionUpdateMenu); err != nil {
+		return err
+	}
+
+	return ms.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := ms.findRestaurant(ctx, restaurantId, false)
+		if err != nil {
+			return err
+		}
+		if !restaurant.IsManageableBy(domain.IdentityFromContext(ctx)) {
+			return coreerrors.NewForbiddenError()
+		}
+
+		// The scheduled menu is only needed to audit its cancellation.
+		var scheduledMenu *domain.ScheduledMenu
+		if ms.auditLogRepository != nil {
+			if scheduledMenu, err = ms.findScheduledMenu(ctx, restaurantId, scheduledMenuId); err != nil {
+				return err
+			}
+		}
+
+		rowsAffected, err := ms.scheduledMenuRepository.Delete(ctx, restaurantId, scheduledMenuId)
+		if err != nil {
+			return coreerrors.NewRepositoryError(err)
+		}
+		if rowsAffected == 0 {
+			return coreerrors.NewScheduledMenuNotFoundError()
+		}
+
+		if scheduledMenu != nil {
+			return ms.audit(ctx, restaurantId, domain.AuditCancelScheduledMenu, scheduledMenu.Snapshot(), nil)
+		}
+		return nil
+	})
+}
+
+func (ms *DefaultMenuScheduleService) ActivateDue(ctx context.Context, at time.Time) (int, error) {
+	var activated int
+	err := ms.trManager.Do(ctx, func(ctx context.Context) error {
+		// Only one instance of the service activates the scheduled menus at a time. The
+		// lock is released when the transaction finishes.
+		acquired, err := ms.scheduledMenuRepository.TryLock(ctx)
+		if err != nil {
+			return coreerrors.NewRepositoryError(err)
+		}
+		if !acquired {
+			return nil
+		}
+
+		scheduledMenus, err := ms.scheduledMenuRepository.FindDue(ctx, at, activationBatchSize)
+		if err != nil {
+			return coreerrors.NewRepositoryError(err)
+		}
+
+		for _, scheduledMenu := range scheduledMenus {
+			if err := ms.activate(ctx, scheduledMenu); err != nil {
+				return err
+			}
+		}
+
+		activated = len(scheduledMenus)
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	return activated, nil
+}
+
+// activate replaces the menu of a restaurant with a scheduled menu, publishing the
+// change as any other menu update.
+func (ms *DefaultMenuScheduleService) activate(ctx context.Context, scheduledMenu *domain.ScheduledMenu) error {
+	// The current menu is only needed to audit the change.
+	restaurant, err := ms.findRestaurant(ctx, scheduledMenu.RestaurantId, ms.auditLogRepository != nil)
+	if err != nil {
+		return err
+	}
+	var before domain.RestaurantSnapshot
+	if ms.auditLogRepository != nil {
+		before = restaurant.Snapshot()
+	}
+
+	if err := restaurant.ActivateScheduledMenu(scheduledMenu); err != nil {
+		return coreerrors.NewCoreError(err)
+	}
+
+	if _, err := ms.restaurantRepository.Update(ctx, restaurant); err != nil {
+		return toRepositoryCoreError(err)
+	}
+
+	if err := ms.audit(ctx, restaurant.Id, domain.AuditActivateScheduledMenu, before, restaurant.Snapshot()); err != nil {
+		return err
+	}
+
+	if _, err := ms.scheduledMenuRepository.Delete(ctx, scheduledMenu.RestaurantId, scheduledMenu.Id); err != nil {
+		return coreerrors.NewRepositoryError(err)
+	}
+
+	if err := ms.domainEventPublisher.Publish(ctx, domain.NewRestaurantMenuUpdated(restaurant.Id, restaurant.Menu)); err != nil {
+		return coreerrors.NewEventPublisherError(err)
+	}
+
+	return nil
+}
+
+// findRestaurant finds a restaurant (with or without its menu) translating the errors
+// into core errors.
+func (ms *DefaultMenuScheduleService) findRestaurant(ctx context.Context, restaurantId int64, fetchMenu bool) (*domain.Restaurant, error) {
+	restaurant, err := ms.restaurantRepository.FindById(ctx, restaurantId, fetchMenu)
+	if err != nil {
+		if err.Error() == "record not found" {
+			return nil, coreerrors.NewRestaurantNotFoundError()
+		}
+		return nil, coreerrors.NewRepositoryError(err)
+	}
+
+	return restaurant, nil
+}
+
+// findScheduledMenu finds a pending scheduled menu of a restaurant translating the
+// errors into core errors.
+func (ms *DefaultMenuScheduleService) findScheduledMenu(ctx context.Context, restaurantId int64, scheduledMenuId int64) (*domain.ScheduledMenu, error) {
+	scheduledMenus, err := ms.scheduledMenuRepository.FindByRestaurant(ctx, restaurantId)
+	if err != nil {
+		return nil, coreerrors.NewRepositoryError(err)
+	}
+	for _, scheduledMenu := range scheduledMenus {
+		if scheduledMenu.Id == scheduledMenuId {
+			return scheduledMenu, nil
+		}
+	}
+	return nil, coreerrors.NewScheduledMenuNotFoundError()
+}
+
+// audit records in the audit log (if any) a change to the scheduled menus of a
+// restaurant, given the snapshots before and after it. It must be called within the
+// transaction of the change.
+func (ms *DefaultMenuScheduleService) audit(ctx context.Context, restaurantId int64, operation domain.AuditOperation, before domain.RestaurantSnapshot, after domain.RestaurantSnapshot) error {
+	if ms.auditLogRepository == nil {
+		return nil
+	}
+	return audit(ctx, ms.auditLogRepository, restaurantId, operation, before.Diff(after))
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+	"f4allgo-restaurant/internal/core/service/mocks"
+	"f4allgo-restaurant/test"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSchedule(t *testing.T) {
+	type args struct {
+		ctx           context.Context
+		restaurantId  int64
+		menu          *domain.Menu
+		effectiveFrom time.Time
+	}
+	menu := domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))})
+	testcases := []struct {
+		name                string
+		args                args
+		mockExpectations    func(args, *mocks.MockScheduledMenuRepository, *mocks.MockRestaurantRepository)
+		wantScheduledMenuId int64
+		wantErr             bool
+		wantErrType         error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menu: menu, effectiveFrom: time.Now().Add(time.Hour)},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				ms.EXPECT().Save(args.ctx, &domain.ScheduledMenu{RestaurantId: 1000, Menu: args.menu, EffectiveFrom: args.effectiveFrom}).
+					Run(func(_ context.Context, scheduledMenu *domain.ScheduledMenu) {
+						scheduledMenu.Id = 10
+					}).Return(nil).Once()
+			},
+			wantScheduledMenuId: 10,
+		},
+		{
+			name: "provide an effective instant in the past",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menu: menu, effectiveFrom: time.Now().Add(-time.Hour)},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+		},
+		{
+			name: "provide an invalid menu not compliant with invariants",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menu: &domain.Menu{}, effectiveFrom: time.Now().Add(time.Hour)},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+		},
+		{
+			name: "mock a restaurant not found",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menu: menu, effectiveFrom: time.Now().Add(time.Hour)},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+		},
+		{
+			name: "mock a caller of another tenant",
+			args: args{ctx: anotherUserCtx, restaurantId: 1000, menu: menu, effectiveFrom: time.Now().Add(time.Hour)},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+		},
+		{
+			name: "mock a ScheduledMenuRepository failure",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menu: menu, effectiveFrom: time.Now().Add(time.Hour)},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				ms.EXPECT().Save(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := mocks.NewMockScheduledMenuRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, ms, mr)
+			ss := NewDefaultMenuScheduleService(ms, mr, mocks.NewMockDomainEventPublisher(t), test.NewNopTrManager())
+			scheduledMenuId, err := ss.Schedule(tc.args.ctx, tc.args.restaurantId, tc.args.menu, tc.args.effectiveFrom)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantScheduledMenuId, scheduledMenuId)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	type args struct {
+		ctx             context.Context
+		restaurantId    int64
+		scheduledMenuId int64
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockScheduledMenuRepository, *mocks.MockRestaurantRepository)
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: ownerCtx, restaurantId: 1000, scheduledMenuId: 10},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				ms.EXPECT().Delete(args.ctx, args.restaurantId, args.scheduledMenuId).Return(1, nil).Once()
+			},
+		},
+		{
+			name: "mock a scheduled menu not found",
+			args: args{ctx: ownerCtx, restaurantId: 1000, scheduledMenuId: 10},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				ms.EXPECT().Delete(args.ctx, args.restaurantId, args.scheduledMenuId).Return(0, nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ScheduledMenuNotFoundError{},
+		},
+		{
+			name: "mock a ScheduledMenuRepository failure",
+			args: args{ctx: ownerCtx, restaurantId: 1000, scheduledMenuId: 10},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				ms.EXPECT().Delete(args.ctx, args.restaurantId, args.scheduledMenuId).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+		{
+			name: "mock a caller of another tenant",
+			args: args{ctx: anotherUserCtx, restaurantId: 1000, scheduledMenuId: 10},
+			mockExpectations: func(args args, ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := mocks.NewMockScheduledMenuRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, ms, mr)
+			ss := NewDefaultMenuScheduleService(ms, mr, mocks.NewMockDomainEventPublisher(t), test.NewNopTrManager())
+			err := ss.Cancel(tc.args.ctx, tc.args.restaurantId, tc.args.scheduledMenuId)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestActivateDue(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	menu := domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))})
+	scheduledMenu := &domain.ScheduledMenu{Id: 10, RestaurantId: 1000, Menu: menu, EffectiveFrom: at}
+	testcases := []struct {
+		name             string
+		mockExpectations func(*mocks.MockScheduledMenuRepository, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantActivated    int
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			mockExpectations: func(ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				ms.EXPECT().TryLock(ctx).Return(true, nil).Once()
+				ms.EXPECT().FindDue(ctx, at, activationBatchSize).Return([]*domain.ScheduledMenu{scheduledMenu}, nil).Once()
+				r := newTestRestaurant()
+				mr.EXPECT().FindById(ctx, int64(1000), false).Return(r, nil).Once()
+				rr := &domain.Restaurant{Id: r.Id, TenantId: r.TenantId, Name: r.Name, Address: r.Address, Menu: menu}
+				mr.EXPECT().Update(ctx, rr).Return(1, nil).Once()
+				ms.EXPECT().Delete(ctx, int64(1000), int64(10)).Return(1, nil).Once()
+				mp.EXPECT().Publish(ctx, domain.NewRestaurantMenuUpdated(1000, menu)).Return(nil).Once()
+			},
+			wantActivated: 1,
+		},
+		{
+			name: "mock the lock held by another instance",
+			mockExpectations: func(ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				ms.EXPECT().TryLock(ctx).Return(false, nil).Once()
+			},
+			wantActivated: 0,
+		},
+		{
+			name: "mock a RestaurantRepository failure when updating",
+			mockExpectations: func(ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				ms.EXPECT().TryLock(ctx).Return(true, nil).Once()
+				ms.EXPECT().FindDue(ctx, at, activationBatchSize).Return([]*domain.ScheduledMenu{scheduledMenu}, nil).Once()
+				mr.EXPECT().FindById(ctx, int64(1000), false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Update(ctx, mock.Anything).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+		{
+			name: "mock a ScheduledMenuRepository failure",
+			mockExpectations: func(ms *mocks.MockScheduledMenuRepository, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				ms.EXPECT().TryLock(ctx).Return(true, nil).Once()
+				ms.EXPECT().FindDue(ctx, at, activationBatchSize).Return(nil, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := mocks.NewMockScheduledMenuRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(ms, mr, mp)
+			ss := NewDefaultMenuScheduleService(ms, mr, mp, test.NewNopTrManager())
+			activated, err := ss.ActivateDue(ctx, at)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantActivated, activated)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestMenuScheduleAuthorizationPolicy(t *testing.T) {
+	operations := []struct {
+		name       string
+		permission domain.Permission
+		call       func(*DefaultMenuScheduleService) error
+	}{
+		{name: "Schedule", permission: domain.PermissionUpdateMenu, call: func(ms *DefaultMenuScheduleService) error {
+			_, err := ms.Schedule(ownerCtx, 1, newTestMenu(), time.Now().Add(time.Hour))
+			return err
+		}},
+		{name: "FindByRestaurant", permission: domain.PermissionReadRestaurant, call: func(ms *DefaultMenuScheduleService) error {
+			_, err := ms.FindByRestaurant(ownerCtx, 1)
+			return err
+		}},
+		{name: "Cancel", permission: domain.PermissionUpdateMenu, call: func(ms *DefaultMenuScheduleService) error {
+			return ms.Cancel(ownerCtx, 1, 10)
+		}},
+	}
+	identity := domain.IdentityFromContext(ownerCtx)
+	for _, op := range operations {
+		t.Run("mock a denied "+op.name, func(t *testing.T) {
+			ms := mocks.NewMockScheduledMenuRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			ma := mocks.NewMockAuthorizationPolicy(t)
+			ma.EXPECT().IsAllowed(ownerCtx, identity, op.permission).Return(false, nil).Once()
+			s := NewDefaultMenuScheduleService(ms, mr, mp, test.NewNopTrManager()).WithAuthorizationPolicy(ma)
+			assert.IsType(t, &coreerrors.ForbiddenError{}, op.call(s))
+		})
+	}
+}
+
+func TestMenuScheduleAuditLog(t *testing.T) {
+	metadata := domain.NewRequestMetadata("request1", domain.SourceRest)
+	ctx := domain.ContextWithRequestMetadata(ownerCtx, metadata)
+	value := func(s string) *string { return &s }
+	effectiveFrom := time.Now().Add(time.Hour).Truncate(time.Second)
+	menu := domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.5))})
+
+	t.Run("mock the scheduling of a menu", func(t *testing.T) {
+		ms := mocks.NewMockScheduledMenuRepository(t)
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		var entry *domain.AuditEntry
+		mr.EXPECT().FindById(ctx, int64(1000), false).Return(newTestRestaurant(), nil).Once()
+		ms.EXPECT().Save(ctx, mock.Anything).Run(func(_ context.Context, scheduledMenu *domain.ScheduledMenu) {
+			scheduledMenu.Id = 10
+		}).Return(nil).Once()
+		ma.EXPECT().Save(ctx, mock.Anything).Run(func(_ context.Context, e *domain.AuditEntry) {
+			entry = e
+		}).Return(nil).Once()
+		s := NewDefaultMenuScheduleService(ms, mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		_, err := s.Schedule(ctx, 1000, menu, effectiveFrom)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1000), entry.RestaurantId)
+		assert.Equal(t, domain.AuditScheduleMenu, entry.Operation)
+		assert.Equal(t, "owner", entry.Actor)
+		assert.Equal(t, "request1", entry.RequestId)
+		assert.Equal(t, domain.NewFieldChange(nil, value(effectiveFrom.UTC().Format(time.RFC3339))), entry.Changes["scheduledMenus.10.effectiveFrom"])
+		assert.Equal(t, domain.NewFieldChange(nil, value("1.5")), entry.Changes["scheduledMenus.10.menu.items.1.price"])
+	})
+
+	t.Run("mock the cancellation of a scheduled menu", func(t *testing.T) {
+		ms := mocks.NewMockScheduledMenuRepository(t)
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		var entry *domain.AuditEntry
+		scheduledMenu := &domain.ScheduledMenu{Id: 10, RestaurantId: 1000, Menu: menu, EffectiveFrom: effectiveFrom}
+		mr.EXPECT().FindById(ctx, int64(1000), false).Return(newTestRestaurant(), nil).Once()
+		ms.EXPECT().FindByRestaurant(ctx, int64(1000)).Return([]*domain.ScheduledMenu{scheduledMenu}, nil).Once()
+		ms.EXPECT().Delete(ctx, int64(1000), int64(10)).Return(1, nil).Once()
+		ma.EXPECT().Save(ctx, mock.Anything).Run(func(_ context.Context, e *domain.AuditEntry) {
+			entry = e
+		}).Return(nil).Once()
+		s := NewDefaultMenuScheduleService(ms, mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		assert.NoError(t, s.Cancel(ctx, 1000, 10))
+		assert.Equal(t, domain.AuditCancelScheduledMenu, entry.Operation)
+		assert.Equal(t, domain.NewFieldChange(value("1.5"), nil), entry.Changes["scheduledMenus.10.menu.items.1.price"])
+	})
+
+	t.Run("mock the cancellation of a scheduled menu not found", func(t *testing.T) {
+		ms := mocks.NewMockScheduledMenuRepository(t)
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		mr.EXPECT().FindById(ctx, int64(1000), false).Return(newTestRestaurant(), nil).Once()
+		ms.EXPECT().FindByRestaurant(ctx, int64(1000)).Return([]*domain.ScheduledMenu{}, nil).Once()
+		s := NewDefaultMenuScheduleService(ms, mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		assert.IsType(t, &coreerrors.ScheduledMenuNotFoundError{}, s.Cancel(ctx, 1000, 10))
+	})
+
+	t.Run("mock the activation of a scheduled menu", func(t *testing.T) {
+		at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		bgCtx := context.Background()
+		ms := mocks.NewMockScheduledMenuRepository(t)
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		var entry *domain.AuditEntry
+		scheduledMenu := &domain.ScheduledMenu{Id: 10, RestaurantId: 1000, Menu: menu, EffectiveFrom: at}
+		ms.EXPECT().TryLock(bgCtx).Return(true, nil).Once()
+		ms.EXPECT().FindDue(bgCtx, at, activationBatchSize).Return([]*domain.ScheduledMenu{scheduledMenu}, nil).Once()
+		// The current menu is fetched to be compared with the scheduled one.
+		mr.EXPECT().FindById(bgCtx, int64(1000), true).Return(newTestRestaurant(), nil).Once()
+		mr.EXPECT().Update(bgCtx, mock.Anything).Return(1, nil).Once()
+		ma.EXPECT().Save(bgCtx, mock.Anything).Run(func(_ context.Context, e *domain.AuditEntry) {
+			entry = e
+		}).Return(nil).Once()
+		ms.EXPECT().Delete(bgCtx, int64(1000), int64(10)).Return(1, nil).Once()
+		mp.EXPECT().Publish(bgCtx, mock.Anything).Return(nil).Once()
+		s := NewDefaultMenuScheduleService(ms, mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		activated, err := s.ActivateDue(bgCtx, at)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, activated)
+		assert.Equal(t, domain.AuditActivateScheduledMenu, entry.Operation)
+		assert.Equal(t, "", entry.Actor)
+		assert.Equal(t, domain.NewFieldChange(value("13.14"), value("1.5")), entry.Changes["menu.items.1.price"])
+	})
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"f4allgo-restaurant/internal/core/domain"
 	"f4allgo-restaurant/internal/core/port"
 	coreerrors "f4allgo-restaurant/internal/core/service/errors"
 
 	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/avito-tech/go-transaction-manager/trm/settings"
 	"github.com/rs/zerolog/log"
 )
 
@@ -16,60 +20,442 @@ type DefaultRestaurantService struct {
 	restaurantRepository port.RestaurantRepository
 	domainEventPublisher port.DomainEventPublisher
 	trManager            trm.Manager
+	geocoder             port.Geocoder
+	policy               port.AuthorizationPolicy
+	auditLogRepository   port.AuditLogRepository
 }
 
 // Interface compliance verification.
 var _ port.RestaurantService = (*DefaultRestaurantService)(nil)
 
+// Runs each row of a best-effort batch under its own savepoint, so that a failing row
+// neither leaks its partial writes nor aborts an enclosing transaction.
+var nestedSettings = settings.Must(settings.WithPropagation(trm.PropagationNested))
+
 // Provides an instance of DefaultRestaurantService.
 func NewDefaultRestaurantService(restaurantRepository port.RestaurantRepository, domainEventPublisher port.DomainEventPublisher, trManager trm.Manager) *DefaultRestaurantService {
 	return &DefaultRestaurantService{restaurantRepository: restaurantRepository, domainEventPublisher: domainEventPublisher, trManager: trManager}
 }
 
-func (rs *DefaultRestaurantService) FindAll(ctx context.Context, offset int, limit int) ([]*domain.Restaurant, int64, error) {
-	restaurants, total, err := rs.restaurantRepository.FindAll(ctx, offset, limit)
+// WithGeocoder sets an optional geocoder used to locate the addresses provided
+// without coordinates.
+func (rs *DefaultRestaurantService) WithGeocoder(geocoder port.Geocoder) *DefaultRestaurantService {
+	rs.geocoder = geocoder
+	return rs
+}
+
+// WithAuthorizationPolicy sets an optional policy deciding which operations the
+// callers are allowed to perform. It's evaluated before each operation, on top of
+// the ownership of the restaurants (which is always checked).
+func (rs *DefaultRestaurantService) WithAuthorizationPolicy(policy port.AuthorizationPolicy) *DefaultRestaurantService {
+	rs.policy = policy
+	return rs
+}
+
+// WithAuditLog sets an optional audit log where every mutation of a restaurant is
+// recorded, within the transaction of the mutation.
+func (rs *DefaultRestaurantService) WithAuditLog(auditLogRepository port.AuditLogRepository) *DefaultRestaurantService {
+	rs.auditLogRepository = auditLogRepository
+	return rs
+}
+
+func (rs *DefaultRestaurantService) FindAll(ctx context.Context, query *domain.RestaurantQuery, pagination *domain.Pagination, excludedAllergens []domain.Allergen) (*domain.RestaurantPage, error) {
+	if err := rs.authorize(ctx, domain.PermissionReadRestaurant); err != nil {
+		return nil, err
+	}
+
+	if query == nil {
+		query = &domain.RestaurantQuery{}
+	}
+	if err := query.Validate(); err != nil {
+		return nil, coreerrors.NewCoreError(err)
+	}
+	if err := authorizeQuery(ctx, query); err != nil {
+		return nil, err
+	}
+	if err := pagination.Validate(query); err != nil {
+		return nil, coreerrors.NewCoreError(err)
+	}
+
+	page, err := rs.restaurantRepository.FindAll(ctx, query, pagination)
 	if err != nil {
 		log.Error().Msg("an error occurred while fetching all the restaurant: " + err.Error())
+		return nil, coreerrors.NewRepositoryError(err)
+	}
+
+	for _, restaurant := range page.Restaurants {
+		withoutAllergens(restaurant, excludedAllergens)
+	}
+
+	return page, nil
+}
+
+func (rs *DefaultRestaurantService) Export(ctx context.Context, query *domain.RestaurantQuery, batchSize int, fn func([]*domain.Restaurant) error) error {
+	if err := rs.authorize(ctx, domain.PermissionReadRestaurant); err != nil {
+		return err
+	}
+
+	if query == nil {
+		query = &domain.RestaurantQuery{}
+	}
+	if err := query.Validate(); err != nil {
+		return coreerrors.NewCoreError(err)
+	}
+	if err := authorizeQuery(ctx, query); err != nil {
+		return err
+	}
+	pagination := &domain.Pagination{Limit: batchSize, Count: domain.CountNone}
+	if err := pagination.Validate(query); err != nil {
+		return coreerrors.NewCoreError(err)
+	}
+
+	// The batches are walked with cursors, so restaurants created or deleted meanwhile
+	// don't shift the following batches.
+	for {
+		page, err := rs.restaurantRepository.FindAll(ctx, query, pagination)
+		if err != nil {
+			log.Error().Msg("an error occurred while exporting the restaurants: " + err.Error())
+			return coreerrors.NewRepositoryError(err)
+		}
+		if len(page.Restaurants) > 0 {
+			if err := fn(page.Restaurants); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == nil {
+			return nil
+		}
+		pagination.Cursor = page.NextCursor
+	}
+}
+
+func (rs *DefaultRestaurantService) FindById(ctx context.Context, restaurantId int64, at *time.Time, excludedAllergens []domain.Allergen) (*domain.Restaurant, error) {
+	if err := rs.authorize(ctx, domain.PermissionReadRestaurant); err != nil {
+		return nil, err
+	}
+
+	restaurant, err := rs.findById(ctx, restaurantId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if at != nil {
+		restaurant.Menu = restaurant.MenuAt(*at)
+	}
+	withoutAllergens(restaurant, excludedAllergens)
+	return restaurant, nil
+}
+
+func (rs *DefaultRestaurantService) FindNearby(ctx context.Context, point *domain.GeoPoint, radius float64, offset int, limit int, excludedAllergens []domain.Allergen) ([]*domain.Restaurant, int64, error) {
+	if err := rs.authorize(ctx, domain.PermissionReadRestaurant); err != nil {
+		return nil, 0, err
+	}
+
+	restaurants, total, err := rs.restaurantRepository.FindNearby(ctx, point, radius, offset, limit)
+	if err != nil {
+		log.Error().Msg("an error occurred while fetching nearby restaurants: " + err.Error())
 		return nil, 0, coreerrors.NewRepositoryError(err)
 	}
 
+	for _, restaurant := range restaurants {
+		withoutAllergens(restaurant, excludedAllergens)
+	}
+
 	return restaurants, total, nil
 }
 
-func (rs *DefaultRestaurantService) FindById(ctx context.Context, restaurantId int64) (*domain.Restaurant, error) {
-	return rs.findById(ctx, restaurantId, true)
+func (rs *DefaultRestaurantService) Search(ctx context.Context, text string, offset int, limit int, excludedAllergens []domain.Allergen) ([]*domain.RestaurantSearchResult, int64, error) {
+	if err := rs.authorize(ctx, domain.PermissionReadRestaurant); err != nil {
+		return nil, 0, err
+	}
+
+	if err := domain.ValidateSearchText(text); err != nil {
+		return nil, 0, coreerrors.NewCoreError(err)
+	}
+
+	results, total, err := rs.restaurantRepository.Search(ctx, strings.TrimSpace(text), offset, limit)
+	if err != nil {
+		log.Error().Msg("an error occurred while searching restaurants: " + err.Error())
+		return nil, 0, coreerrors.NewRepositoryError(err)
+	}
+
+	for _, result := range results {
+		withoutAllergens(result.GetRestaurant(), excludedAllergens)
+	}
+
+	return results, total, nil
 }
 
-func (rs *DefaultRestaurantService) Create(ctx context.Context, restaurant *domain.Restaurant) (int64, error) {
-	err := rs.trManager.Do(ctx, func(ctx context.Context) error {
-		if err := rs.restaurantRepository.Save(ctx, restaurant); err != nil {
-			log.Error().Msg("an error occurred while persisting the new created restaurant: " + err.Error())
-			return coreerrors.NewRepositoryError(err)
+func (rs *DefaultRestaurantService) FindMenuAt(ctx context.Context, restaurantId int64, at time.Time) (*domain.Menu, error) {
+	if err := rs.authorize(ctx, domain.PermissionReadRestaurant); err != nil {
+		return nil, err
+	}
+
+	if _, err := rs.findById(ctx, restaurantId, false); err != nil {
+		return nil, err
+	}
+
+	menu, err := rs.restaurantRepository.FindMenuAt(ctx, restaurantId, at)
+	if err != nil {
+		if err.Error() == "record not found" {
+			return nil, coreerrors.NewMenuVersionNotFoundError()
 		}
+		return nil, coreerrors.NewRepositoryError(err)
+	}
 
-		if err := rs.domainEventPublisher.Publish(ctx, domain.NewRestaurantCreated(restaurant)); err != nil {
-			return coreerrors.NewEventPublisherError(err)
+	return menu, nil
+}
+
+func (rs *DefaultRestaurantService) FindMenuVersions(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.Menu, int64, error) {
+	if err := rs.authorize(ctx, domain.PermissionReadRestaurant); err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := rs.findById(ctx, restaurantId, false); err != nil {
+		return nil, 0, err
+	}
+
+	menus, total, err := rs.restaurantRepository.FindMenuVersions(ctx, restaurantId, offset, limit)
+	if err != nil {
+		log.Error().Msg("an error occurred while fetching the menu versions: " + err.Error())
+		return nil, 0, coreerrors.NewRepositoryError(err)
+	}
+
+	return menus, total, nil
+}
+
+func (rs *DefaultRestaurantService) FindAuditEntries(ctx context.Context, restaurantId int64, offset int, limit int) ([]*domain.AuditEntry, int64, error) {
+	if err := rs.authorize(ctx, domain.PermissionReadAuditLog); err != nil {
+		return nil, 0, err
+	}
+
+	// The audit log of the deleted restaurants can still be read until they are purged.
+	restaurant, err := rs.restaurantRepository.FindById(ctx, restaurantId, false)
+	if err != nil && err.Error() == "record not found" {
+		restaurant, err = rs.restaurantRepository.FindDeletedById(ctx, restaurantId)
+	}
+	if err != nil {
+		if err.Error() == "record not found" {
+			return nil, 0, coreerrors.NewRestaurantNotFoundError()
 		}
+		return nil, 0, coreerrors.NewRepositoryError(err)
+	}
+	if !restaurant.IsManageableBy(domain.IdentityFromContext(ctx)) {
+		return nil, 0, coreerrors.NewForbiddenError()
+	}
 
-		return nil
+	if rs.auditLogRepository == nil {
+		return []*domain.AuditEntry{}, 0, nil
+	}
+	entries, total, err := rs.auditLogRepository.FindByRestaurant(ctx, restaurantId, offset, limit)
+	if err != nil {
+		log.Error().Msg("an error occurred while fetching the audit entries: " + err.Error())
+		return nil, 0, coreerrors.NewRepositoryError(err)
+	}
+
+	return entries, total, nil
+}
+
+func (rs *DefaultRestaurantService) Create(ctx context.Context, restaurant *domain.Restaurant) (int64, error) {
+	identity, err := rs.authorizeCreation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rs.prepare(ctx, identity, restaurant)
+	err = rs.trManager.Do(ctx, func(ctx context.Context) error {
+		return rs.save(ctx, restaurant)
 	})
 
 	return restaurant.Id, err
 }
 
+func (rs *DefaultRestaurantService) CreateMany(ctx context.Context, restaurants []*domain.Restaurant, mode domain.BatchMode) ([]*domain.CreationResult, error) {
+	identity, err := rs.authorizeCreation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := domain.ParseBatchMode(string(mode)); err != nil {
+		return nil, coreerrors.NewCoreError(err)
+	}
+
+	for _, restaurant := range restaurants {
+		rs.prepare(ctx, identity, restaurant)
+	}
+
+	results := make([]*domain.CreationResult, len(restaurants))
+	if mode == domain.BatchBestEffort {
+		for i, restaurant := range restaurants {
+			err := rs.trManager.DoWithSettings(ctx, nestedSettings, func(ctx context.Context) error {
+				return rs.save(ctx, restaurant)
+			})
+			results[i] = domain.NewCreationResult(restaurant.Id, err)
+		}
+		return results, nil
+	}
+
+	failed := -1
+	err = rs.trManager.Do(ctx, func(ctx context.Context) error {
+		for i, restaurant := range restaurants {
+			if err := rs.save(ctx, restaurant); err != nil {
+				failed = i
+				return err
+			}
+		}
+		return nil
+	})
+	for i, restaurant := range restaurants {
+		switch {
+		case err == nil:
+			results[i] = domain.NewCreationResult(restaurant.Id, nil)
+		case i == failed || failed < 0:
+			// The error of the commit applies to every restaurant.
+			results[i] = domain.NewCreationResult(0, err)
+		default:
+			results[i] = domain.NewCreationResult(0, coreerrors.NewBatchAbortedError())
+		}
+	}
+	return results, nil
+}
+
+// authorizeCreation checks that the caller can create restaurants, returning its
+// identity. Callers without tenant can't own restaurants, unless they're admins.
+func (rs *DefaultRestaurantService) authorizeCreation(ctx context.Context) (*domain.Identity, error) {
+	if err := rs.authorize(ctx, domain.PermissionCreateRestaurant); err != nil {
+		return nil, err
+	}
+
+	identity := domain.IdentityFromContext(ctx)
+	if identity == nil || (identity.GetTenantId() == "" && !identity.IsAdmin()) {
+		return nil, coreerrors.NewForbiddenError()
+	}
+	return identity, nil
+}
+
+// prepare makes a new restaurant owned by the tenant of the caller and locates its
+// address. It's done before the transaction, since locating involves a remote call.
+func (rs *DefaultRestaurantService) prepare(ctx context.Context, identity *domain.Identity, restaurant *domain.Restaurant) {
+	// Only admins can create restaurants on behalf of other tenants.
+	if restaurant.TenantId == "" || !identity.IsAdmin() {
+		restaurant.TenantId = identity.GetTenantId()
+	}
+	restaurant.Address = rs.locate(ctx, restaurant.Address)
+}
+
+// save persists a new restaurant, records it in the audit log and publishes its
+// creation. It must be called within a transaction.
+func (rs *DefaultRestaurantService) save(ctx context.Context, restaurant *domain.Restaurant) error {
+	// The restaurants without a time zone are in the default one.
+	if restaurant.TimeZone != "" {
+		if err := restaurant.ChangeTimeZone(restaurant.TimeZone); err != nil {
+			return coreerrors.NewCoreError(err)
+		}
+	}
+
+	if err := rs.restaurantRepository.Save(ctx, restaurant); err != nil {
+		log.Error().Msg("an error occurred while persisting the new created restaurant: " + err.Error())
+		return coreerrors.NewRepositoryError(err)
+	}
+
+	if err := rs.audit(ctx, domain.AuditCreateRestaurant, restaurant, nil); err != nil {
+		return err
+	}
+
+	if err := rs.domainEventPublisher.Publish(ctx, domain.NewRestaurantCreated(restaurant)); err != nil {
+		return coreerrors.NewEventPublisherError(err)
+	}
+
+	return nil
+}
+
+func (rs *DefaultRestaurantService) UpdateRestaurant(ctx context.Context, restaurantId int64, changes *domain.RestaurantChanges) error {
+	if err := rs.authorize(ctx, domain.PermissionUpdateRestaurant); err != nil {
+		return err
+	}
+
+	return rs.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := rs.findManageableById(ctx, restaurantId, false)
+		if err != nil {
+			return err
+		}
+		before := rs.snapshot(restaurant)
+
+		var events []domain.DomainEvent
+		if changes.Name != nil && *changes.Name != restaurant.Name {
+			if err := restaurant.Rename(*changes.Name); err != nil {
+				return coreerrors.NewCoreError(err)
+			}
+			events = append(events, domain.NewRestaurantRenamed(restaurantId, restaurant.Name))
+		}
+		if address := rs.locate(ctx, changes.Address); address != nil && !address.Equals(restaurant.Address) {
+			if err := restaurant.Relocate(address); err != nil {
+				return coreerrors.NewCoreError(err)
+			}
+			events = append(events, domain.NewRestaurantAddressChanged(restaurantId, restaurant.Address))
+		}
+		changed, err := restaurant.Describe(changes.DefaultLocale, changes.Description, changes.DescriptionTranslations)
+		if err != nil {
+			return coreerrors.NewCoreError(err)
+		}
+		if changed {
+			events = append(events, domain.NewRestaurantDescriptionChanged(restaurantId, restaurant.DefaultLocale, restaurant.Description, restaurant.DescriptionTranslations))
+		}
+		if changes.TimeZone != nil && *changes.TimeZone != restaurant.TimeZone {
+			if err := restaurant.ChangeTimeZone(*changes.TimeZone); err != nil {
+				return coreerrors.NewCoreError(err)
+			}
+			events = append(events, domain.NewRestaurantTimeZoneChanged(restaurantId, restaurant.TimeZone))
+		}
+
+		// Nothing changed, so there is nothing to persist or publish.
+		if len(events) == 0 {
+			domain.RecordVersion(ctx, restaurant.Version)
+			return nil
+		}
+
+		if _, err := rs.restaurantRepository.UpdateProfile(ctx, restaurant); err != nil {
+			return toRepositoryCoreError(err)
+		}
+		domain.RecordVersion(ctx, restaurant.Version)
+
+		if err := rs.audit(ctx, domain.AuditUpdateRestaurant, restaurant, before); err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := rs.domainEventPublisher.Publish(ctx, event); err != nil {
+				return coreerrors.NewEventPublisherError(err)
+			}
+		}
+
+		return nil
+	})
+}
+
 func (rs *DefaultRestaurantService) UpdateMenu(ctx context.Context, restaurantId int64, menu *domain.Menu) error {
+	if err := rs.authorize(ctx, domain.PermissionUpdateMenu); err != nil {
+		return err
+	}
+
 	return rs.trManager.Do(ctx, func(ctx context.Context) error {
-		restaurant, err := rs.findById(ctx, restaurantId, false)
+		// The current menu is only needed to audit the change.
+		restaurant, err := rs.findManageableById(ctx, restaurantId, rs.auditLogRepository != nil)
 		if err != nil {
 			return err
 		}
+		before := rs.snapshot(restaurant)
 
 		if err := restaurant.UpdateMenu(menu); err != nil {
 			return coreerrors.NewCoreError(err)
 		}
 
 		if _, err := rs.restaurantRepository.Update(ctx, restaurant); err != nil {
-			return coreerrors.NewRepositoryError(err)
+			return toRepositoryCoreError(err)
+		}
+		domain.RecordVersion(ctx, restaurant.Version)
+
+		if err := rs.audit(ctx, domain.AuditUpdateMenu, restaurant, before); err != nil {
+			return err
 		}
 
 		if err := rs.domainEventPublisher.Publish(ctx, domain.NewRestaurantMenuUpdated(restaurantId, menu)); err != nil {
@@ -80,19 +466,227 @@ func (rs *DefaultRestaurantService) UpdateMenu(ctx context.Context, restaurantId
 	})
 }
 
+func (rs *DefaultRestaurantService) UpdateDayparts(ctx context.Context, restaurantId int64, dayparts []*domain.Daypart) error {
+	if err := rs.authorize(ctx, domain.PermissionUpdateMenu); err != nil {
+		return err
+	}
+
+	return rs.trManager.Do(ctx, func(ctx context.Context) error {
+		// The current dayparts are only needed to audit the change.
+		restaurant, err := rs.findManageableById(ctx, restaurantId, rs.auditLogRepository != nil)
+		if err != nil {
+			return err
+		}
+		before := rs.snapshot(restaurant)
+
+		if err := restaurant.UpdateDayparts(dayparts); err != nil {
+			return coreerrors.NewCoreError(err)
+		}
+
+		if err := rs.restaurantRepository.UpdateDayparts(ctx, restaurantId, restaurant.Version, dayparts); err != nil {
+			return toRepositoryCoreError(err)
+		}
+		// The repository increases the version it's given.
+		domain.RecordVersion(ctx, restaurant.Version+1)
+
+		if err := rs.audit(ctx, domain.AuditUpdateDayparts, restaurant, before); err != nil {
+			return err
+		}
+
+		if err := rs.domainEventPublisher.Publish(ctx, domain.NewRestaurantDaypartsUpdated(restaurantId, dayparts)); err != nil {
+			return coreerrors.NewEventPublisherError(err)
+		}
+
+		return nil
+	})
+}
+
+func (rs *DefaultRestaurantService) SetItemAvailability(ctx context.Context, restaurantId int64, menuItemId int16, available bool, availableUntil *time.Time) error {
+	if err := rs.authorize(ctx, domain.PermissionUpdateMenu); err != nil {
+		return err
+	}
+
+	return rs.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := rs.findManageableById(ctx, restaurantId, true)
+		if err != nil {
+			return err
+		}
+		before := rs.snapshot(restaurant)
+
+		menuItem, err := restaurant.SetItemAvailability(menuItemId, available, availableUntil)
+		if err != nil {
+			return toMenuItemCoreError(err)
+		}
+
+		if _, err := rs.restaurantRepository.UpdateMenuItem(ctx, restaurantId, restaurant.Version, menuItem); err != nil {
+			return toRepositoryCoreError(err)
+		}
+		domain.RecordVersion(ctx, restaurant.Version+1)
+
+		if err := rs.audit(ctx, domain.AuditSetItemAvailability, restaurant, before); err != nil {
+			return err
+		}
+
+		if err := rs.domainEventPublisher.Publish(ctx, domain.NewMenuItemAvailabilityChanged(restaurantId, menuItem)); err != nil {
+			return coreerrors.NewEventPublisherError(err)
+		}
+
+		return nil
+	})
+}
+
+func (rs *DefaultRestaurantService) AddMenuItem(ctx context.Context, restaurantId int64, menuItem *domain.MenuItem) error {
+	if err := rs.authorize(ctx, domain.PermissionUpdateMenu); err != nil {
+		return err
+	}
+
+	return rs.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := rs.findManageableById(ctx, restaurantId, true)
+		if err != nil {
+			return err
+		}
+		before := rs.snapshot(restaurant)
+
+		if err := restaurant.AddMenuItem(menuItem); err != nil {
+			return toMenuItemCoreError(err)
+		}
+
+		if err := rs.restaurantRepository.SaveMenuItem(ctx, restaurantId, restaurant.Version, menuItem); err != nil {
+			return toRepositoryCoreError(err)
+		}
+		domain.RecordVersion(ctx, restaurant.Version+1)
+
+		if err := rs.audit(ctx, domain.AuditAddMenuItem, restaurant, before); err != nil {
+			return err
+		}
+
+		if err := rs.domainEventPublisher.Publish(ctx, domain.NewMenuItemAdded(restaurantId, menuItem)); err != nil {
+			return coreerrors.NewEventPublisherError(err)
+		}
+
+		return nil
+	})
+}
+
+func (rs *DefaultRestaurantService) UpdateMenuItem(ctx context.Context, restaurantId int64, menuItemId int16, changes *domain.MenuItemChanges) error {
+	if err := rs.authorize(ctx, domain.PermissionUpdateMenu); err != nil {
+		return err
+	}
+
+	return rs.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := rs.findManageableById(ctx, restaurantId, true)
+		if err != nil {
+			return err
+		}
+		before := rs.snapshot(restaurant)
+
+		previous, updated, err := restaurant.UpdateMenuItem(menuItemId, changes)
+		if err != nil {
+			return toMenuItemCoreError(err)
+		}
+
+		if _, err := rs.restaurantRepository.UpdateMenuItem(ctx, restaurantId, restaurant.Version, updated); err != nil {
+			return toRepositoryCoreError(err)
+		}
+		domain.RecordVersion(ctx, restaurant.Version+1)
+
+		if err := rs.audit(ctx, domain.AuditUpdateMenuItem, restaurant, before); err != nil {
+			return err
+		}
+
+		// Price changes are published on their own so that consumers interested only
+		// in prices don't need to diff the whole menu item.
+		var events []domain.DomainEvent
+		if previous.GetPrice().Cmp(updated.GetPrice()) != 0 {
+			events = append(events, domain.NewMenuItemPriceChanged(restaurantId, menuItemId, previous.GetPrice(), updated.GetPrice()))
+		}
+		if changes.Name != nil || changes.Description != nil || changes.Translations != nil || changes.Allergens != nil || changes.DietaryTags != nil {
+			events = append(events, domain.NewMenuItemUpdated(restaurantId, updated))
+		}
+		for _, event := range events {
+			if err := rs.domainEventPublisher.Publish(ctx, event); err != nil {
+				return coreerrors.NewEventPublisherError(err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (rs *DefaultRestaurantService) RemoveMenuItem(ctx context.Context, restaurantId int64, menuItemId int16) error {
+	if err := rs.authorize(ctx, domain.PermissionUpdateMenu); err != nil {
+		return err
+	}
+
+	return rs.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := rs.findManageableById(ctx, restaurantId, true)
+		if err != nil {
+			return err
+		}
+		before := rs.snapshot(restaurant)
+
+		if err := restaurant.RemoveMenuItem(menuItemId); err != nil {
+			return toMenuItemCoreError(err)
+		}
+
+		if _, err := rs.restaurantRepository.DeleteMenuItem(ctx, restaurantId, restaurant.Version, menuItemId); err != nil {
+			return toRepositoryCoreError(err)
+		}
+		domain.RecordVersion(ctx, restaurant.Version+1)
+
+		if err := rs.audit(ctx, domain.AuditRemoveMenuItem, restaurant, before); err != nil {
+			return err
+		}
+
+		if err := rs.domainEventPublisher.Publish(ctx, domain.NewMenuItemRemoved(restaurantId, menuItemId)); err != nil {
+			return coreerrors.NewEventPublisherError(err)
+		}
+
+		return nil
+	})
+}
+
+func (rs *DefaultRestaurantService) ValidateOrder(ctx context.Context, restaurantId int64, lines []*domain.OrderLine) (*domain.Quote, error) {
+	restaurant, err := rs.FindById(ctx, restaurantId, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := restaurant.Quote(lines, time.Now())
+	if err != nil {
+		return nil, coreerrors.NewCoreError(err)
+	}
+	return quote, nil
+}
+
 func (rs *DefaultRestaurantService) Delete(ctx context.Context, restaurantId int64) error {
+	if err := rs.authorize(ctx, domain.PermissionDeleteRestaurant); err != nil {
+		return err
+	}
+
 	var rowsAffected int64
-	var err error
 
 	return rs.trManager.Do(ctx, func(ctx context.Context) error {
-		if rowsAffected, err = rs.restaurantRepository.Delete(ctx, restaurantId); err != nil {
-			return coreerrors.NewRepositoryError(err)
+		restaurant, err := rs.findManageableById(ctx, restaurantId, false)
+		if err != nil {
+			return err
+		}
+		before := rs.snapshot(restaurant)
+
+		if rowsAffected, err = rs.restaurantRepository.Delete(ctx, restaurantId, restaurant.Version); err != nil {
+			return toRepositoryCoreError(err)
 		}
 
 		if rowsAffected == 0 {
 			return coreerrors.NewRestaurantNotFoundError()
 		}
 
+		deletedAt := time.Now()
+		restaurant.DeletedAt = &deletedAt
+		if err := rs.audit(ctx, domain.AuditDeleteRestaurant, restaurant, before); err != nil {
+			return err
+		}
+
 		if err := rs.domainEventPublisher.Publish(ctx, domain.NewRestaurantDeleted(restaurantId)); err != nil {
 			return coreerrors.NewEventPublisherError(err)
 		}
@@ -101,6 +695,80 @@ func (rs *DefaultRestaurantService) Delete(ctx context.Context, restaurantId int
 	})
 }
 
+func (rs *DefaultRestaurantService) Restore(ctx context.Context, restaurantId int64) error {
+	if err := rs.authorize(ctx, domain.PermissionDeleteRestaurant); err != nil {
+		return err
+	}
+
+	return rs.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := rs.restaurantRepository.FindDeletedById(ctx, restaurantId)
+		if err != nil {
+			if err.Error() == "record not found" {
+				return coreerrors.NewRestaurantNotFoundError()
+			}
+			return coreerrors.NewRepositoryError(err)
+		}
+
+		if err := checkManageable(ctx, restaurant); err != nil {
+			return err
+		}
+		before := rs.snapshot(restaurant)
+
+		rowsAffected, err := rs.restaurantRepository.Restore(ctx, restaurantId, restaurant.Version)
+		if err != nil {
+			return toRepositoryCoreError(err)
+		}
+
+		if rowsAffected == 0 {
+			return coreerrors.NewRestaurantNotFoundError()
+		}
+
+		restaurant.DeletedAt = nil
+		if err := rs.audit(ctx, domain.AuditRestoreRestaurant, restaurant, before); err != nil {
+			return err
+		}
+
+		if err := rs.domainEventPublisher.Publish(ctx, domain.NewRestaurantRestored(restaurantId)); err != nil {
+			return coreerrors.NewEventPublisherError(err)
+		}
+
+		return nil
+	})
+}
+
+func (rs *DefaultRestaurantService) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := rs.trManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if purged, err = rs.restaurantRepository.Purge(ctx, before); err != nil {
+			log.Error().Msg("an error occurred while purging the deleted restaurants: " + err.Error())
+			return coreerrors.NewRepositoryError(err)
+		}
+		return nil
+	})
+
+	return purged, err
+}
+
+// InitRestaurantPurger initializes a background process (inside a go routine) that
+// periodically purges the restaurants deleted longer than the retention period ago.
+// Several instances of the service can run it at the same time, since purging the
+// same restaurants twice does nothing.
+func (rs *DefaultRestaurantService) InitRestaurantPurger(retention time.Duration, interval time.Duration) {
+	log.Debug().Msg("initializing the restaurant purger")
+	go func() {
+		ticker := time.NewTicker(interval)
+		for range ticker.C {
+			purged, err := rs.Purge(context.Background(), time.Now().Add(-retention))
+			if err != nil {
+				log.Error().Msg("an error occurred while purging the deleted restaurants: " + err.Error())
+			} else if purged > 0 {
+				log.Info().Msgf("%d deleted restaurants were purged", purged)
+			}
+		}
+	}()
+}
+
 // findById is a private function to find restaurants. It returns core service errors
 // for any database access error and for restaurants not found in the database.
 func (rs *DefaultRestaurantService) findById(ctx context.Context, restaurantId int64, fetchMenu bool) (*domain.Restaurant, error) {
@@ -115,3 +783,156 @@ func (rs *DefaultRestaurantService) findById(ctx context.Context, restaurantId i
 
 	return restaurant, nil
 }
+
+// findManageableById finds a restaurant to be modified by the caller carried by the
+// context, returning a ForbiddenError if the caller is not allowed to do it.
+func (rs *DefaultRestaurantService) findManageableById(ctx context.Context, restaurantId int64, fetchMenu bool) (*domain.Restaurant, error) {
+	restaurant, err := rs.findById(ctx, restaurantId, fetchMenu)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkManageable(ctx, restaurant); err != nil {
+		return nil, err
+	}
+
+	return restaurant, nil
+}
+
+// checkManageable checks that the caller carried by the context is allowed to modify
+// the restaurant, returning a ForbiddenError otherwise, and that the restaurant is at
+// the version expected by the caller (if any), returning a ConcurrencyConflictError
+// otherwise.
+func checkManageable(ctx context.Context, restaurant *domain.Restaurant) error {
+	if !restaurant.IsManageableBy(domain.IdentityFromContext(ctx)) {
+		return coreerrors.NewForbiddenError()
+	}
+
+	// The caller may require the restaurant not to have changed since it read it.
+	if version := domain.ExpectedVersionFromContext(ctx); version != nil && *version != restaurant.Version {
+		return coreerrors.NewConcurrencyConflictError()
+	}
+
+	return nil
+}
+
+// authorizeQuery checks that the caller carried by the context is allowed to run the
+// query, returning a ForbiddenError otherwise. Only admins can see the deleted
+// restaurants.
+func authorizeQuery(ctx context.Context, query *domain.RestaurantQuery) error {
+	if !query.IncludeDeleted {
+		return nil
+	}
+	if identity := domain.IdentityFromContext(ctx); identity == nil || !identity.IsAdmin() {
+		return coreerrors.NewForbiddenError()
+	}
+	return nil
+}
+
+// authorize checks with the authorization policy (if any) that the caller carried by
+// the context has been granted the permission, returning a ForbiddenError otherwise.
+// Failures evaluating the policy deny the operation.
+func (rs *DefaultRestaurantService) authorize(ctx context.Context, permission domain.Permission) error {
+	return authorize(ctx, rs.policy, permission)
+}
+
+// authorize is shared by the services to check a permission with an optional
+// authorization policy.
+func authorize(ctx context.Context, policy port.AuthorizationPolicy, permission domain.Permission) error {
+	if policy == nil {
+		return nil
+	}
+	allowed, err := policy.IsAllowed(ctx, domain.IdentityFromContext(ctx), permission)
+	if err != nil {
+		log.Error().Msg("an error occurred while evaluating the authorization policy: " + err.Error())
+		return coreerrors.NewForbiddenError()
+	}
+	if !allowed {
+		return coreerrors.NewForbiddenError()
+	}
+	return nil
+}
+
+// snapshot returns the snapshot of a restaurant before a mutation, to be compared with
+// the restaurant after it in the audit log. It's nil if there's no audit log.
+func (rs *DefaultRestaurantService) snapshot(restaurant *domain.Restaurant) domain.RestaurantSnapshot {
+	if rs.auditLogRepository == nil {
+		return nil
+	}
+	return restaurant.Snapshot()
+}
+
+// audit records in the audit log (if any) a mutation of a restaurant made by the caller
+// carried by the context, given the snapshot of the restaurant before the mutation. It
+// must be called within the transaction of the mutation, so that the entry is only kept
+// if the mutation is committed.
+func (rs *DefaultRestaurantService) audit(ctx context.Context, operation domain.AuditOperation, restaurant *domain.Restaurant, before domain.RestaurantSnapshot) error {
+	if rs.auditLogRepository == nil {
+		return nil
+	}
+	return audit(ctx, rs.auditLogRepository, restaurant.Id, operation, before.Diff(restaurant.Snapshot()))
+}
+
+// audit is shared by the services to record an audit entry with the changes made to
+// a restaurant by the caller carried by the context.
+func audit(ctx context.Context, auditLogRepository port.AuditLogRepository, restaurantId int64, operation domain.AuditOperation, changes map[string]*domain.FieldChange) error {
+	entry := domain.NewAuditEntry(restaurantId, operation, domain.IdentityFromContext(ctx), domain.RequestMetadataFromContext(ctx), changes, time.Now())
+	if err := auditLogRepository.Save(ctx, entry); err != nil {
+		log.Error().Msg("an error occurred while recording an audit entry: " + err.Error())
+		return coreerrors.NewRepositoryError(err)
+	}
+	return nil
+}
+
+// withoutAllergens filters out the items of the menus (the default one and the
+// dayparts) of a restaurant containing any of the excluded allergens.
+func withoutAllergens(restaurant *domain.Restaurant, excludedAllergens []domain.Allergen) {
+	restaurant.Menu = restaurant.Menu.WithoutAllergens(excludedAllergens)
+	for i, daypart := range restaurant.Dayparts {
+		restaurant.Dayparts[i] = daypart.WithoutAllergens(excludedAllergens)
+	}
+}
+
+// locate returns the provided address with its location resolved by the geocoder
+// (if any). Geocoding is best effort: on failure the address is returned as is.
+func (rs *DefaultRestaurantService) locate(ctx context.Context, address *domain.Address) *domain.Address {
+	if rs.geocoder == nil || address == nil || address.Location() != nil {
+		return address
+	}
+	location, err := rs.geocoder.Geocode(ctx, address)
+	if err != nil {
+		log.Warn().Msg("an error occurred while geocoding an address: " + err.Error())
+		return address
+	}
+	if location == nil {
+		return address
+	}
+	return address.WithLocation(location)
+}
+
+// toMenuItemCoreError maps the errors returned by the menu item operations of the
+// restaurant aggregate into core service errors.
+func toMenuItemCoreError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrMenuItemNotFound):
+		return coreerrors.NewMenuItemNotFoundError()
+	case errors.Is(err, domain.ErrMenuItemAlreadyExists):
+		return coreerrors.NewMenuItemAlreadyExistsError()
+	default:
+		return coreerrors.NewCoreError(err)
+	}
+}
+
+// toRepositoryCoreError translates the errors of the restaurant repository into core
+// errors, reporting the restaurants modified concurrently as conflicts and the ones
+// missing as not found.
+func toRepositoryCoreError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrConcurrentModification):
+		return coreerrors.NewConcurrencyConflictError()
+	case errors.Is(err, domain.ErrRestaurantNotFound):
+		return coreerrors.NewRestaurantNotFoundError()
+	default:
+		return coreerrors.NewRepositoryError(err)
+	}
+}
@@ -7,57 +7,179 @@ import (
 	coreerrors "f4allgo-restaurant/internal/core/service/errors"
 	"f4allgo-restaurant/internal/core/service/mocks"
 	"f4allgo-restaurant/test"
+	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/avito-tech/go-transaction-manager/trm/manager"
+	"github.com/avito-tech/go-transaction-manager/trm/settings"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Contexts carrying the identities of the callers: the tenant owning the test
+// restaurants, another tenant and an admin of another tenant.
+var (
+	ownerCtx       = domain.ContextWithIdentity(context.Background(), domain.NewIdentity("owner", "tenant1", nil))
+	anotherUserCtx = domain.ContextWithIdentity(context.Background(), domain.NewIdentity("another", "tenant2", nil))
+	adminCtx       = domain.ContextWithIdentity(context.Background(), domain.NewIdentity("admin", "tenant2", []domain.Role{domain.RoleAdmin}))
 )
 
 func TestFindAll(t *testing.T) {
+	sortedById := &domain.RestaurantQuery{SortBy: domain.RestaurantSortById, SortDirection: domain.SortAscending}
+	sortedByName := &domain.RestaurantQuery{SortBy: domain.RestaurantSortByName, SortDirection: domain.SortAscending}
+	total := int64(10)
 	type args struct {
-		ctx    context.Context
-		offset int
-		limit  int
+		ctx               context.Context
+		query             *domain.RestaurantQuery
+		pagination        *domain.Pagination
+		excludedAllergens []domain.Allergen
 	}
 	testcases := []struct {
 		name             string
 		args             args
 		mockExpectations func(args, *mocks.MockRestaurantRepository)
-		wantRestaurants  []*domain.Restaurant
-		wantTotal        int64
+		wantPage         *domain.RestaurantPage
 		wantErr          bool
 		wantErrType      error
 	}{
 		{
 			name: "mock a successful execution",
 			args: args{
-				ctx:    context.Background(),
-				offset: 0,
-				limit:  100,
+				ctx:        context.Background(),
+				pagination: &domain.Pagination{Offset: 0, Limit: 100},
 			},
 			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
-				repository.EXPECT().FindAll(args.ctx, args.offset, args.limit).Return([]*domain.Restaurant{newTestRestaurant()}, int64(10), nil).Once()
+				pagination := &domain.Pagination{Offset: 0, Limit: 100, Count: domain.CountExact}
+				repository.EXPECT().FindAll(args.ctx, sortedById, pagination).Return(&domain.RestaurantPage{Restaurants: []*domain.Restaurant{newTestRestaurant()}, Total: &total}, nil).Once()
 			},
-			wantRestaurants: []*domain.Restaurant{newTestRestaurant()},
-			wantTotal:       10,
-			wantErr:         false,
+			wantPage: &domain.RestaurantPage{Restaurants: []*domain.Restaurant{newTestRestaurant()}, Total: &total},
+			wantErr:  false,
+		},
+		{
+			name: "mock a successful execution with a query",
+			args: args{
+				ctx:        context.Background(),
+				query:      &domain.RestaurantQuery{NamePrefix: "Bur", City: "Madrid", MenuItemName: "burger", MinPrice: big.NewFloat(5), MaxPrice: big.NewFloat(10), SortBy: domain.RestaurantSortByName, SortDirection: domain.SortDescending},
+				pagination: &domain.Pagination{Offset: 0, Limit: 100, Count: domain.CountNone},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindAll(args.ctx, args.query, args.pagination).Return(&domain.RestaurantPage{Restaurants: []*domain.Restaurant{newTestRestaurant()}}, nil).Once()
+			},
+			wantPage: &domain.RestaurantPage{Restaurants: []*domain.Restaurant{newTestRestaurant()}},
+			wantErr:  false,
+		},
+		{
+			name: "mock a successful execution with a cursor",
+			args: args{
+				ctx:        context.Background(),
+				query:      sortedByName,
+				pagination: &domain.Pagination{Limit: 1, Cursor: domain.NewCursorAt(sortedByName, newTestRestaurant(), false)},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindAll(args.ctx, args.query, args.pagination).Return(&domain.RestaurantPage{Restaurants: []*domain.Restaurant{newTestRestaurant()}, Total: &total}, nil).Once()
+			},
+			wantPage: &domain.RestaurantPage{Restaurants: []*domain.Restaurant{newTestRestaurant()}, Total: &total},
+			wantErr:  false,
+		},
+		{
+			name: "mock a cursor not matching the sort of the query",
+			args: args{
+				ctx:        context.Background(),
+				query:      &domain.RestaurantQuery{SortBy: domain.RestaurantSortByCity},
+				pagination: &domain.Pagination{Limit: 1, Cursor: domain.NewCursorAt(sortedByName, newTestRestaurant(), false)},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+		},
+		{
+			name: "mock an invalid count mode",
+			args: args{
+				ctx:        context.Background(),
+				pagination: &domain.Pagination{Limit: 1, Count: "approximate"},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+		},
+		{
+			name: "mock an invalid price range",
+			args: args{
+				ctx:        context.Background(),
+				query:      &domain.RestaurantQuery{MinPrice: big.NewFloat(10), MaxPrice: big.NewFloat(5)},
+				pagination: &domain.Pagination{Offset: 0, Limit: 100},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+		},
+		{
+			name: "mock an invalid sort field",
+			args: args{
+				ctx:        context.Background(),
+				query:      &domain.RestaurantQuery{SortBy: "rating"},
+				pagination: &domain.Pagination{Offset: 0, Limit: 100},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+		},
+		{
+			name: "mock an admin including the deleted restaurants",
+			args: args{
+				ctx:        adminCtx,
+				query:      &domain.RestaurantQuery{IncludeDeleted: true},
+				pagination: &domain.Pagination{Offset: 0, Limit: 100, Count: domain.CountNone},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindAll(args.ctx, args.query, args.pagination).Return(&domain.RestaurantPage{Restaurants: []*domain.Restaurant{newTestRestaurant()}}, nil).Once()
+			},
+			wantPage: &domain.RestaurantPage{Restaurants: []*domain.Restaurant{newTestRestaurant()}},
+			wantErr:  false,
+		},
+		{
+			name: "mock a caller who isn't an admin including the deleted restaurants",
+			args: args{
+				ctx:        ownerCtx,
+				query:      &domain.RestaurantQuery{IncludeDeleted: true},
+				pagination: &domain.Pagination{Offset: 0, Limit: 100},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.ForbiddenError{},
+		},
+		{
+			name: "mock an anonymous caller including the deleted restaurants",
+			args: args{
+				ctx:        context.Background(),
+				query:      &domain.RestaurantQuery{IncludeDeleted: true},
+				pagination: &domain.Pagination{Offset: 0, Limit: 100},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.ForbiddenError{},
 		},
 		{
 			name: "mock a failure execution",
 			args: args{
-				ctx:    context.Background(),
-				offset: 0,
-				limit:  100,
+				ctx:        context.Background(),
+				pagination: &domain.Pagination{Offset: 0, Limit: 100},
 			},
 			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
-				repository.EXPECT().FindAll(args.ctx, args.offset, args.limit).Return(nil, 0, errors.New("error")).Once()
+				repository.EXPECT().FindAll(args.ctx, mock.Anything, args.pagination).Return(nil, errors.New("error")).Once()
 			},
-			wantRestaurants: nil,
-			wantTotal:       0,
-			wantErr:         true,
-			wantErrType:     &coreerrors.RepositoryError{},
+			wantPage:    nil,
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
 		},
 	}
 	for _, tc := range testcases {
@@ -65,11 +187,10 @@ func TestFindAll(t *testing.T) {
 			mockRepository := mocks.NewMockRestaurantRepository(t)
 			tc.mockExpectations(tc.args, mockRepository)
 			rs := NewDefaultRestaurantService(mockRepository, nil, nil)
-			actualRestaurants, actualTotal, err := rs.FindAll(tc.args.ctx, 0, 100)
+			actualPage, err := rs.FindAll(tc.args.ctx, tc.args.query, tc.args.pagination, tc.args.excludedAllergens)
 			if !tc.wantErr {
 				assert.NoError(t, err)
-				assert.True(t, reflect.DeepEqual(tc.wantRestaurants, actualRestaurants))
-				assert.Equal(t, tc.wantTotal, actualTotal)
+				assert.True(t, reflect.DeepEqual(tc.wantPage, actualPage))
 			} else {
 				assert.Error(t, err)
 				assert.IsType(t, tc.wantErrType, err)
@@ -78,10 +199,100 @@ func TestFindAll(t *testing.T) {
 	}
 }
 
+func TestExport(t *testing.T) {
+	sortedById := &domain.RestaurantQuery{SortBy: domain.RestaurantSortById, SortDirection: domain.SortAscending}
+	restaurants := newTestRestaurants(3)
+	for i, restaurant := range restaurants {
+		restaurant.Id = int64(i + 1)
+	}
+	firstBatch := mock.MatchedBy(func(p *domain.Pagination) bool { return p.Cursor == nil && p.Count == domain.CountNone })
+	secondBatch := mock.MatchedBy(func(p *domain.Pagination) bool { return p.Cursor != nil && p.Cursor.GetId() == 2 })
+	type args struct {
+		ctx       context.Context
+		query     *domain.RestaurantQuery
+		batchSize int
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockRestaurantRepository)
+		fnErr            error
+		wantBatches      [][]*domain.Restaurant
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: context.Background(), batchSize: 2},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindAll(args.ctx, sortedById, firstBatch).
+					Return(&domain.RestaurantPage{Restaurants: restaurants[:2], NextCursor: domain.NewCursorAt(sortedById, restaurants[1], false)}, nil).Once()
+				repository.EXPECT().FindAll(args.ctx, sortedById, secondBatch).Return(&domain.RestaurantPage{Restaurants: restaurants[2:]}, nil).Once()
+			},
+			wantBatches: [][]*domain.Restaurant{restaurants[:2], restaurants[2:]},
+		},
+		{
+			name: "mock a failure of the function",
+			args: args{ctx: context.Background(), batchSize: 2},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindAll(args.ctx, sortedById, firstBatch).
+					Return(&domain.RestaurantPage{Restaurants: restaurants[:2], NextCursor: domain.NewCursorAt(sortedById, restaurants[1], false)}, nil).Once()
+			},
+			fnErr:       errors.New("error"),
+			wantBatches: [][]*domain.Restaurant{restaurants[:2]},
+			wantErr:     true,
+		},
+		{
+			name: "mock a RestaurantRepository failure",
+			args: args{ctx: context.Background(), batchSize: 2},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindAll(args.ctx, sortedById, firstBatch).Return(nil, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+		{
+			name:             "mock an invalid batch size",
+			args:             args{ctx: context.Background(), batchSize: 0},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, mockRepository)
+			rs := NewDefaultRestaurantService(mockRepository, nil, nil)
+			batches := [][]*domain.Restaurant{}
+			err := rs.Export(tc.args.ctx, tc.args.query, tc.args.batchSize, func(batch []*domain.Restaurant) error {
+				batches = append(batches, batch)
+				return tc.fnErr
+			})
+			if tc.wantErr {
+				assert.Error(t, err)
+				if tc.wantErrType != nil {
+					assert.IsType(t, tc.wantErrType, err)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.wantBatches != nil {
+				assert.Equal(t, tc.wantBatches, batches)
+			}
+		})
+	}
+}
+
 func TestFindById(t *testing.T) {
+	breakfastTime := time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)
+	dinnerTime := time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC)
+	newYorkBreakfastTime := time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC)
 	type args struct {
-		ctx          context.Context
-		restaurantId int64
+		ctx               context.Context
+		restaurantId      int64
+		at                *time.Time
+		excludedAllergens []domain.Allergen
 	}
 	testcases := []struct {
 		name             string
@@ -103,6 +314,88 @@ func TestFindById(t *testing.T) {
 			wantRestaurant: newTestRestaurant(),
 			wantErr:        false,
 		},
+		{
+			name: "filter menu items by excluded allergens",
+			args: args{
+				ctx:               context.Background(),
+				restaurantId:      1000,
+				excludedAllergens: []domain.Allergen{domain.AllergenNuts},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				r := newTestRestaurant()
+				items := r.Menu.GetItems()
+				items[1] = items[1].WithLabels([]domain.Allergen{domain.AllergenNuts, domain.AllergenMilk}, nil)
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+			},
+			wantRestaurant: func() *domain.Restaurant {
+				r := newTestRestaurant()
+				items := r.Menu.GetItems()
+				r.Menu = domain.NewMenu([]*domain.MenuItem{items[0], items[2]})
+				return r
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "return the menu of the daypart active at the instant",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				at:           &breakfastTime,
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				r := newTestRestaurant()
+				r.Dayparts = newTestDayparts()
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+			},
+			wantRestaurant: func() *domain.Restaurant {
+				r := newTestRestaurant()
+				r.Dayparts = newTestDayparts()
+				r.Menu = r.Dayparts[0].GetMenu()
+				return r
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "evaluate the dayparts in the time zone of the restaurant",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				at:           &newYorkBreakfastTime,
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				r := newTestRestaurant()
+				r.TimeZone = "America/New_York"
+				r.Dayparts = newTestDayparts()
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+			},
+			wantRestaurant: func() *domain.Restaurant {
+				r := newTestRestaurant()
+				r.TimeZone = "America/New_York"
+				r.Dayparts = newTestDayparts()
+				r.Menu = r.Dayparts[0].GetMenu()
+				return r
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "return the default menu if no daypart is active at the instant",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				at:           &dinnerTime,
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				r := newTestRestaurant()
+				r.Dayparts = newTestDayparts()
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+			},
+			wantRestaurant: func() *domain.Restaurant {
+				r := newTestRestaurant()
+				r.Dayparts = newTestDayparts()
+				return r
+			}(),
+			wantErr: false,
+		},
 		{
 			name: "mock record not found",
 			args: args{
@@ -133,7 +426,7 @@ func TestFindById(t *testing.T) {
 			mockRepository := mocks.NewMockRestaurantRepository(t)
 			tc.mockExpectations(tc.args, mockRepository)
 			rs := NewDefaultRestaurantService(mockRepository, nil, nil)
-			actualRestaurant, err := rs.FindById(tc.args.ctx, tc.args.restaurantId)
+			actualRestaurant, err := rs.FindById(tc.args.ctx, tc.args.restaurantId, tc.args.at, tc.args.excludedAllergens)
 			if !tc.wantErr {
 				assert.NoError(t, err)
 				assert.True(t, reflect.DeepEqual(tc.wantRestaurant, actualRestaurant))
@@ -145,67 +438,2131 @@ func TestFindById(t *testing.T) {
 	}
 }
 
-func TestCreate(t *testing.T) {
+func TestFindNearby(t *testing.T) {
+	point, _ := domain.NewGeoPoint(40.4168, -3.7038)
 	type args struct {
-		ctx        context.Context
-		restaurant *domain.Restaurant
+		ctx               context.Context
+		point             *domain.GeoPoint
+		radius            float64
+		excludedAllergens []domain.Allergen
 	}
 	testcases := []struct {
-		name                 string
-		args                 args
-		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
-		wantErr              bool
-		wantErrType          error
-		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockRestaurantRepository)
+		wantRestaurants  []*domain.Restaurant
+		wantTotal        int64
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{
+				ctx:    context.Background(),
+				point:  point,
+				radius: 3000,
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindNearby(args.ctx, args.point, args.radius, 0, 100).Return([]*domain.Restaurant{newTestRestaurant()}, int64(1), nil).Once()
+			},
+			wantRestaurants: []*domain.Restaurant{newTestRestaurant()},
+			wantTotal:       1,
+			wantErr:         false,
+		},
+		{
+			name: "mock a failure execution",
+			args: args{
+				ctx:    context.Background(),
+				point:  point,
+				radius: 3000,
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindNearby(args.ctx, args.point, args.radius, 0, 100).Return(nil, 0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, mockRepository)
+			rs := NewDefaultRestaurantService(mockRepository, nil, nil)
+			actualRestaurants, actualTotal, err := rs.FindNearby(tc.args.ctx, tc.args.point, tc.args.radius, 0, 100, tc.args.excludedAllergens)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.True(t, reflect.DeepEqual(tc.wantRestaurants, actualRestaurants))
+				assert.Equal(t, tc.wantTotal, actualTotal)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	type args struct {
+		ctx  context.Context
+		text string
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockRestaurantRepository)
+		wantResults      []*domain.RestaurantSearchResult
+		wantTotal        int64
+		wantErr          bool
+		wantErrType      error
 	}{
 		{
-			name: "mock a successful execution",
+			name: "mock a successful execution",
+			args: args{
+				ctx:  context.Background(),
+				text: " vegan ramen ",
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().Search(args.ctx, "vegan ramen", 0, 100).Return([]*domain.RestaurantSearchResult{domain.NewRestaurantSearchResult(newTestRestaurant(), 0.5, "<mark>ramen</mark>")}, int64(1), nil).Once()
+			},
+			wantResults: []*domain.RestaurantSearchResult{domain.NewRestaurantSearchResult(newTestRestaurant(), 0.5, "<mark>ramen</mark>")},
+			wantTotal:   1,
+			wantErr:     false,
+		},
+		{
+			name: "mock a failure execution due to an empty text",
+			args: args{
+				ctx:  context.Background(),
+				text: "  ",
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+		},
+		{
+			name: "mock a failure execution",
+			args: args{
+				ctx:  context.Background(),
+				text: "ramen",
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().Search(args.ctx, args.text, 0, 100).Return(nil, 0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, mockRepository)
+			rs := NewDefaultRestaurantService(mockRepository, nil, nil)
+			actualResults, actualTotal, err := rs.Search(tc.args.ctx, tc.args.text, 0, 100, nil)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.True(t, reflect.DeepEqual(tc.wantResults, actualResults))
+				assert.Equal(t, tc.wantTotal, actualTotal)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestFindMenuAt(t *testing.T) {
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		at           time.Time
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockRestaurantRepository)
+		wantMenu         *domain.Menu
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: context.Background(), restaurantId: 1, at: at},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				repository.EXPECT().FindMenuAt(args.ctx, args.restaurantId, args.at).Return(newTestMenu().WithVersion(1, &validFrom), nil).Once()
+			},
+			wantMenu: newTestMenu().WithVersion(1, &validFrom),
+			wantErr:  false,
+		},
+		{
+			name: "mock a restaurant not found",
+			args: args{ctx: context.Background(), restaurantId: 1, at: at},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+		},
+		{
+			name: "mock a menu version not found",
+			args: args{ctx: context.Background(), restaurantId: 1, at: at},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				repository.EXPECT().FindMenuAt(args.ctx, args.restaurantId, args.at).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.MenuVersionNotFoundError{},
+		},
+		{
+			name: "mock a failure execution",
+			args: args{ctx: context.Background(), restaurantId: 1, at: at},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				repository.EXPECT().FindMenuAt(args.ctx, args.restaurantId, args.at).Return(nil, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, mockRepository)
+			rs := NewDefaultRestaurantService(mockRepository, nil, nil)
+			menu, err := rs.FindMenuAt(tc.args.ctx, tc.args.restaurantId, tc.args.at)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.True(t, reflect.DeepEqual(tc.wantMenu, menu))
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestFindMenuVersions(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockRestaurantRepository)
+		wantMenus        []*domain.Menu
+		wantTotal        int64
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: context.Background(), restaurantId: 1},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				repository.EXPECT().FindMenuVersions(args.ctx, args.restaurantId, 0, 10).Return([]*domain.Menu{newTestMenu().WithVersion(2, nil), newTestMenu().WithVersion(1, nil)}, int64(2), nil).Once()
+			},
+			wantMenus: []*domain.Menu{newTestMenu().WithVersion(2, nil), newTestMenu().WithVersion(1, nil)},
+			wantTotal: 2,
+			wantErr:   false,
+		},
+		{
+			name: "mock a restaurant not found",
+			args: args{ctx: context.Background(), restaurantId: 1},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+		},
+		{
+			name: "mock a failure execution",
+			args: args{ctx: context.Background(), restaurantId: 1},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				repository.EXPECT().FindMenuVersions(args.ctx, args.restaurantId, 0, 10).Return(nil, 0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, mockRepository)
+			rs := NewDefaultRestaurantService(mockRepository, nil, nil)
+			menus, total, err := rs.FindMenuVersions(tc.args.ctx, tc.args.restaurantId, 0, 10)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantTotal, total)
+				assert.True(t, reflect.DeepEqual(tc.wantMenus, menus))
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	type args struct {
+		ctx        context.Context
+		restaurant *domain.Restaurant
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{
+				ctx:        ownerCtx,
+				restaurant: newTestRestaurant(),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().Save(args.ctx, args.restaurant).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantCreated(args.restaurant)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{
+				ctx:        ownerCtx,
+				restaurant: newTestRestaurant(),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().Save(args.ctx, args.restaurant).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantCreated(args.restaurant)).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+		{
+			name: "mock a RestaurantRepository failure",
+			args: args{
+				ctx:        ownerCtx,
+				restaurant: &domain.Restaurant{},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().Save(args.ctx, args.restaurant).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, _ *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a restaurant of another tenant created by a caller",
+			args: args{
+				ctx:        ownerCtx,
+				restaurant: &domain.Restaurant{TenantId: "tenant2", Name: "restaurant1", Address: newTestAddress(), Menu: newTestMenu()},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().Save(args.ctx, args.restaurant).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(nil).Once()
+			},
+			wantErr: false,
+			additionalAssertions: func(args args, _ *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				assert.Equal(t, "tenant1", args.restaurant.TenantId)
+			},
+		},
+		{
+			name: "mock a restaurant of another tenant created by an admin",
+			args: args{
+				ctx:        adminCtx,
+				restaurant: newTestRestaurant(),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().Save(args.ctx, args.restaurant).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(nil).Once()
+			},
+			wantErr: false,
+			additionalAssertions: func(args args, _ *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				assert.Equal(t, "tenant1", args.restaurant.TenantId)
+			},
+		},
+		{
+			name: "mock an invalid time zone",
+			args: args{
+				ctx: ownerCtx,
+				restaurant: func() *domain.Restaurant {
+					r := newTestRestaurant()
+					r.TimeZone = "Europe/Nowhere"
+					return r
+				}(),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock an anonymous caller",
+			args: args{
+				ctx:        context.Background(),
+				restaurant: newTestRestaurant(),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.ForbiddenError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+			_, err := rs.Create(tc.args.ctx, tc.args.restaurant)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(tc.args, mr, mp)
+			}
+		})
+	}
+}
+
+func TestCreateMany(t *testing.T) {
+	type args struct {
+		ctx         context.Context
+		restaurants []*domain.Restaurant
+		mode        domain.BatchMode
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		want             []error
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful all-or-nothing execution",
+			args: args{
+				ctx:         ownerCtx,
+				restaurants: newTestRestaurants(2),
+				mode:        domain.BatchAllOrNothing,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().Save(args.ctx, mock.Anything).Return(nil).Twice()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(nil).Twice()
+			},
+			want: []error{nil, nil},
+		},
+		{
+			name: "mock a failed all-or-nothing execution",
+			args: args{
+				ctx:         ownerCtx,
+				restaurants: newTestRestaurants(3),
+				mode:        domain.BatchAllOrNothing,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().Save(args.ctx, args.restaurants[0]).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(nil).Once()
+				mr.EXPECT().Save(args.ctx, args.restaurants[1]).Return(errors.New("error")).Once()
+			},
+			want: []error{&coreerrors.BatchAbortedError{}, &coreerrors.RepositoryError{}, &coreerrors.BatchAbortedError{}},
+		},
+		{
+			name: "mock a partially failed best-effort execution",
+			args: args{
+				ctx:         ownerCtx,
+				restaurants: newTestRestaurants(3),
+				mode:        domain.BatchBestEffort,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().Save(args.ctx, args.restaurants[0]).Return(nil).Once()
+				mr.EXPECT().Save(args.ctx, args.restaurants[1]).Return(errors.New("error")).Once()
+				mr.EXPECT().Save(args.ctx, args.restaurants[2]).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(nil).Twice()
+			},
+			want: []error{nil, &coreerrors.RepositoryError{}, nil},
+		},
+		{
+			name: "mock an invalid mode",
+			args: args{
+				ctx:         ownerCtx,
+				restaurants: []*domain.Restaurant{newTestRestaurant()},
+				mode:        "invalid",
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.CoreError{},
+		},
+		{
+			name: "mock an anonymous caller",
+			args: args{
+				ctx:         context.Background(),
+				restaurants: []*domain.Restaurant{newTestRestaurant()},
+				mode:        domain.BatchBestEffort,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {},
+			wantErr:          true,
+			wantErrType:      &coreerrors.ForbiddenError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+			results, err := rs.CreateMany(tc.args.ctx, tc.args.restaurants, tc.args.mode)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, results, len(tc.want))
+			for i, result := range results {
+				if tc.want[i] == nil {
+					assert.True(t, result.IsCreated())
+				} else {
+					assert.False(t, result.IsCreated())
+					assert.IsType(t, tc.want[i], result.GetError())
+				}
+			}
+		})
+	}
+}
+
+func TestCreateWithGeocoder(t *testing.T) {
+	location, _ := domain.NewGeoPoint(40.4168, -3.7038)
+	testcases := []struct {
+		name             string
+		mockExpectations func(*mocks.MockGeocoder)
+		wantLocation     *domain.GeoPoint
+	}{
+		{
+			name: "mock a resolved address",
+			mockExpectations: func(mg *mocks.MockGeocoder) {
+				mg.EXPECT().Geocode(mock.Anything, mock.Anything).Return(location, nil).Once()
+			},
+			wantLocation: location,
+		},
+		{
+			name: "mock an unknown address",
+			mockExpectations: func(mg *mocks.MockGeocoder) {
+				mg.EXPECT().Geocode(mock.Anything, mock.Anything).Return(nil, nil).Once()
+			},
+			wantLocation: nil,
+		},
+		{
+			name: "mock a Geocoder failure",
+			mockExpectations: func(mg *mocks.MockGeocoder) {
+				mg.EXPECT().Geocode(mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
+			},
+			wantLocation: nil,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := ownerCtx
+			restaurant := newTestRestaurant()
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			mg := mocks.NewMockGeocoder(t)
+			tc.mockExpectations(mg)
+			mr.EXPECT().Save(ctx, restaurant).Return(nil).Once()
+			mp.EXPECT().Publish(ctx, mock.Anything).Return(nil).Once()
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithGeocoder(mg)
+			_, err := rs.Create(ctx, restaurant)
+			assert.NoError(t, err)
+			assert.True(t, tc.wantLocation.Equals(restaurant.Address.Location()))
+		})
+	}
+}
+
+func TestAuthorizationPolicy(t *testing.T) {
+	operations := []struct {
+		name       string
+		permission domain.Permission
+		call       func(*DefaultRestaurantService) error
+	}{
+		{name: "FindAll", permission: domain.PermissionReadRestaurant, call: func(rs *DefaultRestaurantService) error {
+			_, err := rs.FindAll(ownerCtx, nil, &domain.Pagination{Limit: 10}, nil)
+			return err
+		}},
+		{name: "FindById", permission: domain.PermissionReadRestaurant, call: func(rs *DefaultRestaurantService) error {
+			_, err := rs.FindById(ownerCtx, 1, nil, nil)
+			return err
+		}},
+		{name: "ValidateOrder", permission: domain.PermissionReadRestaurant, call: func(rs *DefaultRestaurantService) error {
+			_, err := rs.ValidateOrder(ownerCtx, 1, nil)
+			return err
+		}},
+		{name: "Create", permission: domain.PermissionCreateRestaurant, call: func(rs *DefaultRestaurantService) error {
+			_, err := rs.Create(ownerCtx, newTestRestaurant())
+			return err
+		}},
+		{name: "UpdateRestaurant", permission: domain.PermissionUpdateRestaurant, call: func(rs *DefaultRestaurantService) error {
+			return rs.UpdateRestaurant(ownerCtx, 1, &domain.RestaurantChanges{})
+		}},
+		{name: "UpdateMenu", permission: domain.PermissionUpdateMenu, call: func(rs *DefaultRestaurantService) error {
+			return rs.UpdateMenu(ownerCtx, 1, newTestMenu())
+		}},
+		{name: "RemoveMenuItem", permission: domain.PermissionUpdateMenu, call: func(rs *DefaultRestaurantService) error {
+			return rs.RemoveMenuItem(ownerCtx, 1, 1)
+		}},
+		{name: "Delete", permission: domain.PermissionDeleteRestaurant, call: func(rs *DefaultRestaurantService) error {
+			return rs.Delete(ownerCtx, 1)
+		}},
+		{name: "FindAuditEntries", permission: domain.PermissionReadAuditLog, call: func(rs *DefaultRestaurantService) error {
+			_, _, err := rs.FindAuditEntries(ownerCtx, 1, 0, 10)
+			return err
+		}},
+	}
+	identity := domain.IdentityFromContext(ownerCtx)
+	for _, op := range operations {
+		t.Run("mock a denied "+op.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			ma := mocks.NewMockAuthorizationPolicy(t)
+			ma.EXPECT().IsAllowed(ownerCtx, identity, op.permission).Return(false, nil).Once()
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuthorizationPolicy(ma)
+			assert.IsType(t, &coreerrors.ForbiddenError{}, op.call(rs))
+		})
+	}
+
+	t.Run("mock an allowed operation", func(t *testing.T) {
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuthorizationPolicy(t)
+		ma.EXPECT().IsAllowed(ownerCtx, identity, domain.PermissionDeleteRestaurant).Return(true, nil).Once()
+		mr.EXPECT().FindById(ownerCtx, int64(1), false).Return(newTestRestaurant(), nil).Once()
+		mr.EXPECT().Delete(ownerCtx, int64(1), int64(0)).Return(1, nil).Once()
+		mp.EXPECT().Publish(ownerCtx, domain.NewRestaurantDeleted(1)).Return(nil).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuthorizationPolicy(ma)
+		assert.NoError(t, rs.Delete(ownerCtx, 1))
+	})
+
+	t.Run("mock an allowed operation on a restaurant of another tenant", func(t *testing.T) {
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuthorizationPolicy(t)
+		ma.EXPECT().IsAllowed(anotherUserCtx, domain.IdentityFromContext(anotherUserCtx), domain.PermissionDeleteRestaurant).Return(true, nil).Once()
+		mr.EXPECT().FindById(anotherUserCtx, int64(1), false).Return(newTestRestaurant(), nil).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuthorizationPolicy(ma)
+		assert.IsType(t, &coreerrors.ForbiddenError{}, rs.Delete(anotherUserCtx, 1))
+	})
+
+	t.Run("mock an AuthorizationPolicy failure", func(t *testing.T) {
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuthorizationPolicy(t)
+		ma.EXPECT().IsAllowed(ownerCtx, identity, domain.PermissionReadRestaurant).Return(false, errors.New("error")).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuthorizationPolicy(ma)
+		_, err := rs.FindAll(ownerCtx, nil, &domain.Pagination{Limit: 10}, nil)
+		assert.IsType(t, &coreerrors.ForbiddenError{}, err)
+	})
+}
+
+func TestRecordVersion(t *testing.T) {
+	t.Run("mock a change recording the version of the restaurant", func(t *testing.T) {
+		ctx, recorder := domain.ContextWithVersionRecorder(ownerCtx)
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		mr.EXPECT().FindById(ctx, int64(1000), true).Return(newTestRestaurant(), nil).Once()
+		mr.EXPECT().DeleteMenuItem(ctx, int64(1000), int64(0), int16(2)).Return(1, nil).Once()
+		mp.EXPECT().Publish(ctx, domain.NewMenuItemRemoved(1000, 2)).Return(nil).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+		assert.NoError(t, rs.RemoveMenuItem(ctx, 1000, 2))
+		assert.Equal(t, int64(1), *recorder.GetVersion())
+	})
+
+	t.Run("mock a failed change not recording any version", func(t *testing.T) {
+		ctx, recorder := domain.ContextWithVersionRecorder(ownerCtx)
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		mr.EXPECT().FindById(ctx, int64(1000), true).Return(newTestRestaurant(), nil).Once()
+		mr.EXPECT().DeleteMenuItem(ctx, int64(1000), int64(0), int16(2)).Return(0, domain.ErrRestaurantNotFound).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+		assert.IsType(t, &coreerrors.RestaurantNotFoundError{}, rs.RemoveMenuItem(ctx, 1000, 2))
+		assert.Nil(t, recorder.GetVersion())
+	})
+}
+
+func TestUpdateRestaurant(t *testing.T) {
+	name := "restaurant1 renamed"
+	sameName := "restaurant1"
+	emptyName := ""
+	address := domain.NewAddress("street2", "city2", "state2", "zip2")
+	locale, invalidLocale := "es-ES", "not a locale"
+	description := "Cocina casera"
+	translations := map[string]string{"en-GB": "Home cooking"}
+	timeZone, invalidTimeZone := "Europe/Madrid", "Local"
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		changes      *domain.RestaurantChanges
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful rename",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Name: &name},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateProfile(args.ctx, mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantRenamed(args.restaurantId, name)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a caller of another tenant",
+			args: args{
+				ctx:          anotherUserCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Name: &name},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock an anonymous caller",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Name: &name},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+		},
+		{
+			name: "mock a successful rename and relocation",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Name: &name, Address: address},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateProfile(args.ctx, mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantRenamed(args.restaurantId, name)).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantAddressChanged(args.restaurantId, address)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a successful change of the description and its translations",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{DefaultLocale: &locale, Description: &description, DescriptionTranslations: &translations},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateProfile(args.ctx, mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantDescriptionChanged(args.restaurantId, locale, description, translations)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a successful change of the time zone",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{TimeZone: &timeZone},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateProfile(args.ctx, mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantTimeZoneChanged(args.restaurantId, timeZone)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock an invalid time zone",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{TimeZone: &invalidTimeZone},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock an invalid locale",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{DescriptionTranslations: &map[string]string{invalidLocale: "Home cooking"}},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock changes equal to the current values",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Name: &sameName, Address: newTestAddress()},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr: false,
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock an invalid name",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Name: &emptyName},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock an invalid address",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Address: domain.NewAddress("", "city2", "state2", "zip2")},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a RestaurantNotFound failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Name: &name},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+		},
+		{
+			name: "mock a RestaurantRepository failure when updating",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Name: &name},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateProfile(args.ctx, mock.Anything).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				changes:      &domain.RestaurantChanges{Address: address},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateProfile(args.ctx, mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+			err := rs.UpdateRestaurant(tc.args.ctx, tc.args.restaurantId, tc.args.changes)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(tc.args, mr, mp)
+			}
+		})
+	}
+}
+
+func TestUpdateMenu(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		menu         *domain.Menu
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				r := newTestRestaurant()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(r, nil).Once()
+				rr := &domain.Restaurant{Id: r.Id, TenantId: r.TenantId, Name: r.Name, Address: r.Address, Menu: args.menu}
+				mr.EXPECT().Update(args.ctx, rr).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantMenuUpdated(args.restaurantId, args.menu)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock an admin of another tenant",
+			args: args{
+				ctx:          adminCtx,
+				restaurantId: 1000,
+				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Update(args.ctx, mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantMenuUpdated(args.restaurantId, args.menu)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a caller of another tenant",
+			args: args{
+				ctx:          anotherUserCtx,
+				restaurantId: 1000,
+				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				r := newTestRestaurant()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(r, nil).Once()
+				rr := &domain.Restaurant{Id: r.Id, TenantId: r.TenantId, Name: r.Name, Address: r.Address, Menu: args.menu}
+				mr.EXPECT().Update(args.ctx, rr).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantMenuUpdated(args.restaurantId, args.menu)).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+		{
+			name: "mock a RestaurantRepository failure when updating",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				r := newTestRestaurant()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(r, nil).Once()
+				rr := &domain.Restaurant{Id: r.Id, TenantId: r.TenantId, Name: r.Name, Address: r.Address, Menu: args.menu}
+				mr.EXPECT().Update(args.ctx, rr).Return(1, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "provide an invalid menu not compliant with invariants",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menu:         &domain.Menu{},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				restaurant := newTestRestaurant()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(restaurant, nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				restaurant := newTestRestaurant()
+				restaurant.Menu = args.menu
+				mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "provide a menu with dietary tags contradicting the allergens",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menu: domain.NewMenu([]*domain.MenuItem{
+					domain.NewMenuItem(1, "one", big.NewFloat(1.0)).WithLabels([]domain.Allergen{domain.AllergenMilk}, []domain.DietaryTag{domain.DietaryTagVegan}),
+				}),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a RestaurantRepository failure when fetching",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := &DefaultRestaurantService{
+				restaurantRepository: mr,
+				domainEventPublisher: mp,
+				trManager:            test.NewNopTrManager(),
+			}
+			err := rs.UpdateMenu(tc.args.ctx, tc.args.restaurantId, tc.args.menu)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(tc.args, mr, mp)
+			}
+		})
+	}
+}
+
+func TestUpdateDayparts(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		dayparts     []*domain.Daypart
+	}
+	overlapping := func() []*domain.Daypart {
+		dayparts := newTestDayparts()
+		brunch := domain.NewDaypart("brunch", []*domain.TimeWindow{domain.NewTimeWindow(10*60, 13*60)}, dayparts[0].GetMenu())
+		return append(dayparts, brunch)
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: ownerCtx, restaurantId: 1000, dayparts: newTestDayparts()},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateDayparts(args.ctx, args.restaurantId, int64(0), args.dayparts).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantDaypartsUpdated(args.restaurantId, args.dayparts)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "provide dayparts with overlapping time windows",
+			args: args{ctx: ownerCtx, restaurantId: 1000, dayparts: overlapping()},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateDayparts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "provide dayparts with the same name",
+			args: args{ctx: ownerCtx, restaurantId: 1000, dayparts: func() []*domain.Daypart {
+				dayparts := newTestDayparts()
+				return []*domain.Daypart{dayparts[0], domain.NewDaypart("breakfast", dayparts[1].GetWindows(), dayparts[1].GetMenu())}
+			}()},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateDayparts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a restaurant not found",
+			args: args{ctx: ownerCtx, restaurantId: 1000, dayparts: newTestDayparts()},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+		},
+		{
+			name: "mock a RestaurantRepository failure when updating",
+			args: args{ctx: ownerCtx, restaurantId: 1000, dayparts: newTestDayparts()},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateDayparts(args.ctx, args.restaurantId, int64(0), args.dayparts).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{ctx: ownerCtx, restaurantId: 1000, dayparts: newTestDayparts()},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateDayparts(args.ctx, args.restaurantId, int64(0), args.dayparts).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := &DefaultRestaurantService{
+				restaurantRepository: mr,
+				domainEventPublisher: mp,
+				trManager:            test.NewNopTrManager(),
+			}
+			err := rs.UpdateDayparts(tc.args.ctx, tc.args.restaurantId, tc.args.dayparts)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(tc.args, mr, mp)
+			}
+		})
+	}
+}
+
+func TestSetItemAvailability(t *testing.T) {
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	type args struct {
+		ctx            context.Context
+		restaurantId   int64
+		menuItemId     int16
+		available      bool
+		availableUntil *time.Time
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{
+				ctx:            ownerCtx,
+				restaurantId:   1000,
+				menuItemId:     2,
+				available:      false,
+				availableUntil: &until,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				r := newTestRestaurant()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+				item := r.Menu.GetItem(args.menuItemId).WithAvailability(args.available, args.availableUntil)
+				mr.EXPECT().UpdateMenuItem(args.ctx, args.restaurantId, int64(0), item).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewMenuItemAvailabilityChanged(args.restaurantId, item)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a menu item not found",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   99,
+				available:    false,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.MenuItemNotFoundError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a RestaurantRepository failure when updating",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				available:    true,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateMenuItem(args.ctx, args.restaurantId, int64(0), mock.Anything).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				available:    true,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateMenuItem(args.ctx, args.restaurantId, int64(0), mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+		{
+			name: "mock a RestaurantNotFound failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				available:    true,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+			err := rs.SetItemAvailability(tc.args.ctx, tc.args.restaurantId, tc.args.menuItemId, tc.args.available, tc.args.availableUntil)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(tc.args, mr, mp)
+			}
+		})
+	}
+}
+
+func TestAddMenuItem(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		menuItem     *domain.MenuItem
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(4, "item1.4", big.NewFloat(16.17)),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().SaveMenuItem(args.ctx, args.restaurantId, int64(0), args.menuItem).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewMenuItemAdded(args.restaurantId, args.menuItem)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a menu item already exists",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(2, "item1.2", big.NewFloat(16.17)),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.MenuItemAlreadyExistsError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "SaveMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a menu item with labels contradicting its allergens",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItem: domain.NewMenuItem(4, "item1.4", big.NewFloat(16.17)).
+					WithLabels([]domain.Allergen{domain.AllergenMilk}, []domain.DietaryTag{domain.DietaryTagVegan}),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "SaveMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a menu item with a negative price",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(4, "item1.4", big.NewFloat(-1)),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "SaveMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a menu item with an empty name",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(4, "", big.NewFloat(16.17)),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "SaveMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a menu item with a non-positive id",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(0, "item1.4", big.NewFloat(16.17)),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "SaveMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a RestaurantRepository failure when saving",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(4, "item1.4", big.NewFloat(16.17)),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().SaveMenuItem(args.ctx, args.restaurantId, int64(0), args.menuItem).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItem:     domain.NewMenuItem(4, "item1.4", big.NewFloat(16.17)),
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().SaveMenuItem(args.ctx, args.restaurantId, int64(0), args.menuItem).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+			err := rs.AddMenuItem(tc.args.ctx, tc.args.restaurantId, tc.args.menuItem)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(tc.args, mr, mp)
+			}
+		})
+	}
+}
+
+func TestUpdateMenuItem(t *testing.T) {
+	name := "item1.2 renamed"
+	price := big.NewFloat(20)
+	vegan := []domain.DietaryTag{domain.DietaryTagVegan}
+	milk := []domain.Allergen{domain.AllergenMilk}
+	translations := map[string]*domain.Translation{"es": domain.NewTranslation("artículo1.2", "")}
+	invalidTranslations := map[string]*domain.Translation{"es": domain.NewTranslation("", "sin nombre")}
+	emptyName := ""
+	negativePrice := big.NewFloat(-0.01)
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		menuItemId   int16
+		changes      *domain.MenuItemChanges
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful price change",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Price: price},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				r := newTestRestaurant()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+				mr.EXPECT().UpdateMenuItem(args.ctx, args.restaurantId, int64(0), mock.Anything).Return(1, nil).Once()
+				oldPrice := r.Menu.GetItem(args.menuItemId).GetPrice()
+				mp.EXPECT().Publish(args.ctx, domain.NewMenuItemPriceChanged(args.restaurantId, args.menuItemId, oldPrice, price)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a successful name and price change",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Name: &name, Price: price},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateMenuItem(args.ctx, args.restaurantId, int64(0), mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.AnythingOfType("*domain.MenuItemPriceChanged")).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.AnythingOfType("*domain.MenuItemUpdated")).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a menu item not found",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   99,
+				changes:      &domain.MenuItemChanges{Name: &name},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.MenuItemNotFoundError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a successful change of the translations",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Translations: &translations},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				r := newTestRestaurant()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+				mr.EXPECT().UpdateMenuItem(args.ctx, args.restaurantId, int64(0), mock.Anything).Return(1, nil).Once()
+				updated := r.Menu.GetItem(args.menuItemId).WithTexts("", translations)
+				mp.EXPECT().Publish(args.ctx, domain.NewMenuItemUpdated(args.restaurantId, updated)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a change to an empty name",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Name: &emptyName},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a change to a negative price",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Price: negativePrice},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a translation without name",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Translations: &invalidTranslations},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock changes with labels contradicting allergens",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Allergens: &milk, DietaryTags: &vegan},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "UpdateMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a RestaurantRepository failure when updating",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Name: &name},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateMenuItem(args.ctx, args.restaurantId, int64(0), mock.Anything).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1000,
+				menuItemId:   2,
+				changes:      &domain.MenuItemChanges{Name: &name},
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().UpdateMenuItem(args.ctx, args.restaurantId, int64(0), mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+			err := rs.UpdateMenuItem(tc.args.ctx, tc.args.restaurantId, tc.args.menuItemId, tc.args.changes)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(tc.args, mr, mp)
+			}
+		})
+	}
+}
+
+func TestRemoveMenuItem(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		menuItemId   int16
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menuItemId: 2},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().DeleteMenuItem(args.ctx, args.restaurantId, int64(0), args.menuItemId).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewMenuItemRemoved(args.restaurantId, args.menuItemId)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a menu item not found",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menuItemId: 99},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.MenuItemNotFoundError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "DeleteMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock removing the last menu item",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menuItemId: 1},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				r := newTestRestaurant()
+				r.Menu = domain.NewMenu([]*domain.MenuItem{r.Menu.GetItem(1)})
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "DeleteMenuItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a RestaurantRepository failure when deleting",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menuItemId: 2},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().DeleteMenuItem(args.ctx, args.restaurantId, int64(0), args.menuItemId).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{ctx: ownerCtx, restaurantId: 1000, menuItemId: 2},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().DeleteMenuItem(args.ctx, args.restaurantId, int64(0), args.menuItemId).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mr, mp)
+			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
+			err := rs.RemoveMenuItem(tc.args.ctx, tc.args.restaurantId, tc.args.menuItemId)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(tc.args, mr, mp)
+			}
+		})
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		lines        []*domain.OrderLine
+	}
+	notFound := domain.OrderLineRejectionMenuItemNotFound
+	notAvailable := domain.OrderLineRejectionMenuItemNotAvailable
+	invalidQuantity := domain.OrderLineRejectionInvalidQuantity
+	duplicated := domain.OrderLineRejectionDuplicatedMenuItem
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockRestaurantRepository)
+		wantTotal        string
+		wantValid        bool
+		wantRejections   []*domain.OrderLineRejection
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				lines:        []*domain.OrderLine{domain.NewOrderLine(1, 2), domain.NewOrderLine(3, 1)},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantTotal:      "41.44",
+			wantValid:      true,
+			wantRejections: []*domain.OrderLineRejection{nil, nil},
+			wantErr:        false,
+		},
+		{
+			name: "reject invalid lines and exclude them from the total",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				lines: []*domain.OrderLine{
+					domain.NewOrderLine(1, 1),
+					domain.NewOrderLine(9, 1),
+					domain.NewOrderLine(2, 1),
+					domain.NewOrderLine(3, 0),
+					domain.NewOrderLine(1, 1),
+				},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				r := newTestRestaurant()
+				r.SetItemAvailability(2, false, nil)
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+			},
+			wantTotal:      "13.14",
+			wantValid:      false,
+			wantRejections: []*domain.OrderLineRejection{nil, &notFound, &notAvailable, &invalidQuantity, &duplicated},
+			wantErr:        false,
+		},
+		{
+			name: "price the lines with the menu of the active daypart",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				lines:        []*domain.OrderLine{domain.NewOrderLine(1, 2), domain.NewOrderLine(3, 1)},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				r := newTestRestaurant()
+				allDay := domain.NewDaypart("all-day", []*domain.TimeWindow{domain.NewTimeWindow(0, 24*60)},
+					domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "coffee", big.NewFloat(1.5))}))
+				r.Dayparts = []*domain.Daypart{allDay}
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+			},
+			wantTotal:      "3.00",
+			wantValid:      false,
+			wantRejections: []*domain.OrderLineRejection{nil, &notFound},
+			wantErr:        false,
+		},
+		{
+			name: "mock an order without lines",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				lines:        []*domain.OrderLine{},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+		},
+		{
+			name: "mock record not found",
+			args: args{
+				ctx:          context.Background(),
+				restaurantId: 1000,
+				lines:        []*domain.OrderLine{domain.NewOrderLine(1, 1)},
+			},
+			mockExpectations: func(args args, repository *mocks.MockRestaurantRepository) {
+				repository.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, mockRepository)
+			rs := NewDefaultRestaurantService(mockRepository, nil, nil)
+			quote, err := rs.ValidateOrder(tc.args.ctx, tc.args.restaurantId, tc.args.lines)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantTotal, quote.GetTotal().Text('f', 2))
+				assert.Equal(t, tc.wantValid, quote.IsValid())
+				assert.Len(t, quote.GetLines(), len(tc.wantRejections))
+				for i, line := range quote.GetLines() {
+					assert.Equal(t, tc.wantRejections[i], line.GetRejection())
+					assert.Equal(t, line.GetRejection() == nil, line.GetLineTotal() != nil)
+				}
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Delete(args.ctx, args.restaurantId, int64(0)).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantDeleted(args.restaurantId)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Delete(args.ctx, args.restaurantId, int64(0)).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantDeleted(args.restaurantId)).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+		{
+			name: "mock a RestaurantRepository failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Delete(args.ctx, args.restaurantId, int64(0)).Return(1, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a RestaurantNotFound failure",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a restaurant deleted concurrently",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Delete(args.ctx, args.restaurantId, int64(0)).Return(0, nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a restaurant at another version than expected",
+			args: args{
+				ctx:          domain.ContextWithExpectedVersion(ownerCtx, 3),
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ConcurrencyConflictError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a restaurant at the expected version",
 			args: args{
-				ctx:        context.Background(),
-				restaurant: newTestRestaurant(),
+				ctx:          domain.ContextWithExpectedVersion(ownerCtx, 0),
+				restaurantId: 1,
 			},
 			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mr.EXPECT().Save(args.ctx, args.restaurant).Return(nil).Once()
-				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantCreated(args.restaurant)).Return(nil).Once()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Delete(args.ctx, args.restaurantId, int64(0)).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantDeleted(args.restaurantId)).Return(nil).Once()
 			},
 			wantErr: false,
 		},
 		{
-			name: "mock a DomainEventPublisher failure",
+			name: "mock a restaurant modified concurrently",
 			args: args{
-				ctx:        context.Background(),
-				restaurant: newTestRestaurant(),
+				ctx:          ownerCtx,
+				restaurantId: 1,
 			},
 			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mr.EXPECT().Save(args.ctx, args.restaurant).Return(nil).Once()
-				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantCreated(args.restaurant)).Return(errors.New("error")).Once()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Delete(args.ctx, args.restaurantId, int64(0)).Return(0, domain.ErrConcurrentModification).Once()
 			},
 			wantErr:     true,
-			wantErrType: &coreerrors.EventPublisherError{},
+			wantErrType: &coreerrors.ConcurrencyConflictError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
 		},
 		{
-			name: "mock a RestaurantRepository failure",
+			name: "mock a caller of another tenant",
 			args: args{
-				ctx:        context.Background(),
-				restaurant: &domain.Restaurant{},
+				ctx:          anotherUserCtx,
+				restaurantId: 1,
 			},
 			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mr.EXPECT().Save(args.ctx, args.restaurant).Return(errors.New("error")).Once()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
 			},
 			wantErr:     true,
-			wantErrType: &coreerrors.RepositoryError{},
-			additionalAssertions: func(args args, _ *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+			wantErrType: &coreerrors.ForbiddenError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
 				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
 			},
 		},
+		{
+			name: "mock an admin of another tenant",
+			args: args{
+				ctx:          adminCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mr.EXPECT().Delete(args.ctx, args.restaurantId, int64(0)).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantDeleted(args.restaurantId)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			mr := mocks.NewMockRestaurantRepository(t)
 			mp := mocks.NewMockDomainEventPublisher(t)
 			tc.mockExpectations(tc.args, mr, mp)
-			rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager())
-			_, err := rs.Create(tc.args.ctx, tc.args.restaurant)
+			rs := &DefaultRestaurantService{
+				restaurantRepository: mr,
+				domainEventPublisher: mp,
+				trManager:            test.NewNopTrManager(),
+			}
+			err := rs.Delete(tc.args.ctx, tc.args.restaurantId)
 			if !tc.wantErr {
 				assert.NoError(t, err)
 			} else {
@@ -219,11 +2576,18 @@ func TestCreate(t *testing.T) {
 	}
 }
 
-func TestUpdateMenu(t *testing.T) {
+func TestRestore(t *testing.T) {
+	deletedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	newDeletedRestaurant := func() *domain.Restaurant {
+		restaurant := newTestRestaurant()
+		restaurant.Menu = nil
+		restaurant.Version = 2
+		restaurant.DeletedAt = &deletedAt
+		return restaurant
+	}
 	type args struct {
 		ctx          context.Context
 		restaurantId int64
-		menu         *domain.Menu
 	}
 	testcases := []struct {
 		name                 string
@@ -236,92 +2600,139 @@ func TestUpdateMenu(t *testing.T) {
 		{
 			name: "mock a successful execution",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1000,
-				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+				ctx:          ownerCtx,
+				restaurantId: 1,
 			},
 			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				r := newTestRestaurant()
-				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(r, nil).Once()
-				rr := &domain.Restaurant{Id: r.Id, Name: r.Name, Address: r.Address, Menu: args.menu}
-				mr.EXPECT().Update(args.ctx, rr).Return(1, nil).Once()
-				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantMenuUpdated(args.restaurantId, args.menu)).Return(nil).Once()
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(newDeletedRestaurant(), nil).Once()
+				mr.EXPECT().Restore(args.ctx, args.restaurantId, int64(2)).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantRestored(args.restaurantId)).Return(nil).Once()
 			},
 			wantErr: false,
 		},
 		{
 			name: "mock a DomainEventPublisher failure",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1000,
-				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+				ctx:          ownerCtx,
+				restaurantId: 1,
 			},
 			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				r := newTestRestaurant()
-				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(r, nil).Once()
-				rr := &domain.Restaurant{Id: r.Id, Name: r.Name, Address: r.Address, Menu: args.menu}
-				mr.EXPECT().Update(args.ctx, rr).Return(1, nil).Once()
-				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantMenuUpdated(args.restaurantId, args.menu)).Return(errors.New("error")).Once()
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(newDeletedRestaurant(), nil).Once()
+				mr.EXPECT().Restore(args.ctx, args.restaurantId, int64(2)).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantRestored(args.restaurantId)).Return(errors.New("error")).Once()
 			},
 			wantErr:     true,
 			wantErrType: &coreerrors.EventPublisherError{},
 		},
 		{
-			name: "mock a RestaurantRepository failure when updating",
+			name: "mock a RestaurantRepository failure",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1000,
-				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+				ctx:          ownerCtx,
+				restaurantId: 1,
 			},
 			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				r := newTestRestaurant()
-				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(r, nil).Once()
-				rr := &domain.Restaurant{Id: r.Id, Name: r.Name, Address: r.Address, Menu: args.menu}
-				mr.EXPECT().Update(args.ctx, rr).Return(1, errors.New("error")).Once()
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(nil, errors.New("error")).Once()
 			},
 			wantErr:     true,
 			wantErrType: &coreerrors.RepositoryError{},
 			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
 				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
 			},
 		},
 		{
-			name: "provide an invalid menu not compliant with invariants",
+			name: "mock a restaurant not deleted (or already purged)",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1000,
-				menu:         &domain.Menu{},
+				ctx:          ownerCtx,
+				restaurantId: 1,
 			},
-			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
-				restaurant := newTestRestaurant()
-				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(restaurant, nil).Once()
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(nil, errors.New("record not found")).Once()
 			},
 			wantErr:     true,
-			wantErrType: &coreerrors.CoreError{},
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
 			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				restaurant := newTestRestaurant()
-				restaurant.Menu = args.menu
-				mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				mr.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
 				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
 			},
 		},
 		{
-			name: "mock a RestaurantRepository failure when fetching",
+			name: "mock a restaurant restored or purged concurrently",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1000,
-				menu:         domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "one", big.NewFloat(1.0))}),
+				ctx:          ownerCtx,
+				restaurantId: 1,
 			},
-			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
-				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("error")).Once()
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(newDeletedRestaurant(), nil).Once()
+				mr.EXPECT().Restore(args.ctx, args.restaurantId, int64(2)).Return(0, nil).Once()
 			},
 			wantErr:     true,
-			wantErrType: &coreerrors.RepositoryError{},
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
 			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
 				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
 			},
 		},
+		{
+			name: "mock a restaurant modified concurrently",
+			args: args{
+				ctx:          ownerCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(newDeletedRestaurant(), nil).Once()
+				mr.EXPECT().Restore(args.ctx, args.restaurantId, int64(2)).Return(0, domain.ErrConcurrentModification).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ConcurrencyConflictError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a restaurant at another version than expected",
+			args: args{
+				ctx:          domain.ContextWithExpectedVersion(ownerCtx, 1),
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(newDeletedRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ConcurrencyConflictError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a caller of another tenant",
+			args: args{
+				ctx:          anotherUserCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(newDeletedRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock an admin of another tenant",
+			args: args{
+				ctx:          adminCtx,
+				restaurantId: 1,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(newDeletedRestaurant(), nil).Once()
+				mr.EXPECT().Restore(args.ctx, args.restaurantId, int64(2)).Return(1, nil).Once()
+				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantRestored(args.restaurantId)).Return(nil).Once()
+			},
+			wantErr: false,
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -333,7 +2744,7 @@ func TestUpdateMenu(t *testing.T) {
 				domainEventPublisher: mp,
 				trManager:            test.NewNopTrManager(),
 			}
-			err := rs.UpdateMenu(tc.args.ctx, tc.args.restaurantId, tc.args.menu)
+			err := rs.Restore(tc.args.ctx, tc.args.restaurantId)
 			if !tc.wantErr {
 				assert.NoError(t, err)
 			} else {
@@ -347,95 +2758,246 @@ func TestUpdateMenu(t *testing.T) {
 	}
 }
 
-func TestDelete(t *testing.T) {
+func TestPurge(t *testing.T) {
+	before := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	testcases := []struct {
+		name             string
+		mockExpectations func(*mocks.MockRestaurantRepository)
+		wantPurged       int64
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			mockExpectations: func(mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().Purge(mock.Anything, before).Return(3, nil).Once()
+			},
+			wantPurged: 3,
+			wantErr:    false,
+		},
+		{
+			name: "mock a RestaurantRepository failure",
+			mockExpectations: func(mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().Purge(mock.Anything, before).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(mr)
+			rs := NewDefaultRestaurantService(mr, nil, test.NewNopTrManager())
+			purged, err := rs.Purge(context.Background(), before)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantPurged, purged)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	metadata := domain.NewRequestMetadata("request1", domain.SourceRest)
+	ctx := domain.ContextWithRequestMetadata(ownerCtx, metadata)
+	value := func(s string) *string { return &s }
+
+	t.Run("mock the creation of a restaurant", func(t *testing.T) {
+		restaurant := newTestRestaurant()
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		var entry *domain.AuditEntry
+		mr.EXPECT().Save(ctx, restaurant).Return(nil).Once()
+		ma.EXPECT().Save(ctx, mock.Anything).Run(func(_ context.Context, e *domain.AuditEntry) {
+			entry = e
+		}).Return(nil).Once()
+		mp.EXPECT().Publish(ctx, mock.Anything).Return(nil).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		_, err := rs.Create(ctx, restaurant)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1000), entry.RestaurantId)
+		assert.Equal(t, domain.AuditCreateRestaurant, entry.Operation)
+		assert.Equal(t, "owner", entry.Actor)
+		assert.Equal(t, "tenant1", entry.TenantId)
+		assert.Equal(t, "request1", entry.RequestId)
+		assert.Equal(t, domain.SourceRest, entry.Source)
+		assert.Equal(t, domain.NewFieldChange(nil, value("restaurant1")), entry.Changes["name"])
+		assert.Equal(t, domain.NewFieldChange(nil, value("13.14")), entry.Changes["menu.items.1.price"])
+	})
+
+	t.Run("mock the change of the price of a menu item", func(t *testing.T) {
+		price := big.NewFloat(20)
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		var entry *domain.AuditEntry
+		mr.EXPECT().FindById(ctx, int64(1000), true).Return(newTestRestaurant(), nil).Once()
+		mr.EXPECT().UpdateMenuItem(ctx, int64(1000), int64(0), mock.Anything).Return(1, nil).Once()
+		ma.EXPECT().Save(ctx, mock.Anything).Run(func(_ context.Context, e *domain.AuditEntry) {
+			entry = e
+		}).Return(nil).Once()
+		mp.EXPECT().Publish(ctx, mock.Anything).Return(nil).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		assert.NoError(t, rs.UpdateMenuItem(ctx, 1000, 1, &domain.MenuItemChanges{Price: price}))
+		assert.Equal(t, domain.AuditUpdateMenuItem, entry.Operation)
+		assert.Equal(t, map[string]*domain.FieldChange{"menu.items.1.price": domain.NewFieldChange(value("13.14"), value("20"))}, entry.Changes)
+	})
+
+	t.Run("mock the update of a menu", func(t *testing.T) {
+		menu := domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "item1.1", big.NewFloat(13.5))})
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		var entry *domain.AuditEntry
+		// The current menu is fetched to be compared with the new one.
+		mr.EXPECT().FindById(ctx, int64(1000), true).Return(newTestRestaurant(), nil).Once()
+		mr.EXPECT().Update(ctx, mock.Anything).Return(1, nil).Once()
+		ma.EXPECT().Save(ctx, mock.Anything).Run(func(_ context.Context, e *domain.AuditEntry) {
+			entry = e
+		}).Return(nil).Once()
+		mp.EXPECT().Publish(ctx, mock.Anything).Return(nil).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		assert.NoError(t, rs.UpdateMenu(ctx, 1000, menu))
+		assert.Equal(t, domain.NewFieldChange(value("13.14"), value("13.5")), entry.Changes["menu.items.1.price"])
+		assert.Equal(t, domain.NewFieldChange(value("item1.2"), nil), entry.Changes["menu.items.2.name"])
+		assert.NotContains(t, entry.Changes, "menu.items.1.name")
+	})
+
+	t.Run("mock the deletion of a restaurant", func(t *testing.T) {
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		var entry *domain.AuditEntry
+		mr.EXPECT().FindById(ctx, int64(1000), false).Return(newTestRestaurant(), nil).Once()
+		mr.EXPECT().Delete(ctx, int64(1000), int64(0)).Return(1, nil).Once()
+		ma.EXPECT().Save(ctx, mock.Anything).Run(func(_ context.Context, e *domain.AuditEntry) {
+			entry = e
+		}).Return(nil).Once()
+		mp.EXPECT().Publish(ctx, domain.NewRestaurantDeleted(1000)).Return(nil).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		assert.NoError(t, rs.Delete(ctx, 1000))
+		assert.Equal(t, domain.AuditDeleteRestaurant, entry.Operation)
+		assert.Len(t, entry.Changes, 1)
+		assert.Nil(t, entry.Changes["deletedAt"].GetBefore())
+		assert.NotNil(t, entry.Changes["deletedAt"].GetAfter())
+	})
+
+	t.Run("mock an AuditLogRepository failure", func(t *testing.T) {
+		mr := mocks.NewMockRestaurantRepository(t)
+		mp := mocks.NewMockDomainEventPublisher(t)
+		ma := mocks.NewMockAuditLogRepository(t)
+		mr.EXPECT().FindById(ctx, int64(1000), true).Return(newTestRestaurant(), nil).Once()
+		mr.EXPECT().DeleteMenuItem(ctx, int64(1000), int64(0), int16(1)).Return(1, nil).Once()
+		ma.EXPECT().Save(ctx, mock.Anything).Return(errors.New("error")).Once()
+		rs := NewDefaultRestaurantService(mr, mp, test.NewNopTrManager()).WithAuditLog(ma)
+		assert.IsType(t, &coreerrors.RepositoryError{}, rs.RemoveMenuItem(ctx, 1000, 1))
+		mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFindAuditEntries(t *testing.T) {
+	deletedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []*domain.AuditEntry{{Id: 2, RestaurantId: 1000, Operation: domain.AuditDeleteRestaurant}, {Id: 1, RestaurantId: 1000, Operation: domain.AuditCreateRestaurant}}
 	type args struct {
 		ctx          context.Context
 		restaurantId int64
 	}
 	testcases := []struct {
-		name                 string
-		args                 args
-		mockExpectations     func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
-		wantErr              bool
-		wantErrType          error
-		additionalAssertions func(args, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockRestaurantRepository, *mocks.MockAuditLogRepository)
+		wantEntries      []*domain.AuditEntry
+		wantErr          bool
+		wantErrType      error
 	}{
 		{
 			name: "mock a successful execution",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1,
+				ctx:          ownerCtx,
+				restaurantId: 1000,
 			},
-			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mr.EXPECT().Delete(args.ctx, args.restaurantId).Return(1, nil).Once()
-				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantDeleted(args.restaurantId)).Return(nil).Once()
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, ma *mocks.MockAuditLogRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				ma.EXPECT().FindByRestaurant(args.ctx, args.restaurantId, 0, 10).Return(entries, 2, nil).Once()
 			},
-			wantErr: false,
+			wantEntries: entries,
+			wantErr:     false,
 		},
 		{
-			name: "mock a DomainEventPublisher failure",
+			name: "mock a deleted restaurant",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1,
+				ctx:          ownerCtx,
+				restaurantId: 1000,
 			},
-			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mr.EXPECT().Delete(args.ctx, args.restaurantId).Return(1, nil).Once()
-				mp.EXPECT().Publish(args.ctx, domain.NewRestaurantDeleted(args.restaurantId)).Return(errors.New("error")).Once()
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, ma *mocks.MockAuditLogRepository) {
+				restaurant := newTestRestaurant()
+				restaurant.DeletedAt = &deletedAt
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(restaurant, nil).Once()
+				ma.EXPECT().FindByRestaurant(args.ctx, args.restaurantId, 0, 10).Return(entries, 2, nil).Once()
 			},
-			wantErr:     true,
-			wantErrType: &coreerrors.EventPublisherError{},
+			wantEntries: entries,
+			wantErr:     false,
 		},
 		{
-			name: "mock a RestaurantRepository failure",
+			name: "mock a restaurant not found",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1,
+				ctx:          ownerCtx,
+				restaurantId: 1000,
 			},
-			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mr.EXPECT().Delete(args.ctx, args.restaurantId).Return(1, errors.New("error")).Once()
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, ma *mocks.MockAuditLogRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+				mr.EXPECT().FindDeletedById(args.ctx, args.restaurantId).Return(nil, errors.New("record not found")).Once()
 			},
 			wantErr:     true,
-			wantErrType: &coreerrors.RepositoryError{},
-			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
-			},
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
 		},
 		{
-			name: "mock a RestaurantNotFound failure",
+			name: "mock a caller of another tenant",
 			args: args{
-				ctx:          context.Background(),
-				restaurantId: 1,
+				ctx:          anotherUserCtx,
+				restaurantId: 1000,
 			},
-			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mr.EXPECT().Delete(args.ctx, args.restaurantId).Return(0, nil).Once()
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, ma *mocks.MockAuditLogRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
 			},
 			wantErr:     true,
-			wantErrType: &coreerrors.RestaurantNotFoundError{},
-			additionalAssertions: func(args args, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
-				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			wantErrType: &coreerrors.ForbiddenError{},
+		},
+		{
+			name: "mock an AuditLogRepository failure",
+			args: args{
+				ctx:          adminCtx,
+				restaurantId: 1000,
+			},
+			mockExpectations: func(args args, mr *mocks.MockRestaurantRepository, ma *mocks.MockAuditLogRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				ma.EXPECT().FindByRestaurant(args.ctx, args.restaurantId, 0, 10).Return(nil, 0, errors.New("error")).Once()
 			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
 		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			mr := mocks.NewMockRestaurantRepository(t)
-			mp := mocks.NewMockDomainEventPublisher(t)
-			tc.mockExpectations(tc.args, mr, mp)
-			rs := &DefaultRestaurantService{
-				restaurantRepository: mr,
-				domainEventPublisher: mp,
-				trManager:            test.NewNopTrManager(),
-			}
-			err := rs.Delete(tc.args.ctx, tc.args.restaurantId)
+			ma := mocks.NewMockAuditLogRepository(t)
+			tc.mockExpectations(tc.args, mr, ma)
+			rs := NewDefaultRestaurantService(mr, nil, test.NewNopTrManager()).WithAuditLog(ma)
+			gotEntries, total, err := rs.FindAuditEntries(tc.args.ctx, tc.args.restaurantId, 0, 10)
 			if !tc.wantErr {
 				assert.NoError(t, err)
+				assert.Equal(t, tc.wantEntries, gotEntries)
+				assert.Equal(t, int64(len(tc.wantEntries)), total)
 			} else {
 				assert.Error(t, err)
 				assert.IsType(t, tc.wantErrType, err)
 			}
-			if tc.additionalAssertions != nil {
-				tc.additionalAssertions(tc.args, mr, mp)
-			}
 		})
 	}
 }
@@ -445,7 +3007,25 @@ func TestDelete(t *testing.T) {
 // --------------------------------------------------------------------------------
 
 func newTestRestaurant() *domain.Restaurant {
-	return &domain.Restaurant{Id: 1000, Name: "restaurant1", Address: newTestAddress(), Menu: newTestMenu()}
+	return &domain.Restaurant{Id: 1000, TenantId: "tenant1", Name: "restaurant1", Address: newTestAddress(), Menu: newTestMenu()}
+}
+
+// newTestRestaurants returns n new restaurants that differ by name.
+func newTestRestaurants(n int) []*domain.Restaurant {
+	restaurants := make([]*domain.Restaurant, n)
+	for i := range restaurants {
+		restaurants[i] = &domain.Restaurant{TenantId: "tenant1", Name: fmt.Sprintf("restaurant%d", i+1), Address: newTestAddress(), Menu: newTestMenu()}
+	}
+	return restaurants
+}
+
+// newTestDayparts returns a breakfast (07:00-11:00) and a lunch (12:00-16:00) daypart.
+func newTestDayparts() []*domain.Daypart {
+	breakfast := domain.NewDaypart("breakfast", []*domain.TimeWindow{domain.NewTimeWindow(7*60, 11*60)},
+		domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "coffee", big.NewFloat(1.5))}))
+	lunch := domain.NewDaypart("lunch", []*domain.TimeWindow{domain.NewTimeWindow(12*60, 16*60)},
+		domain.NewMenu([]*domain.MenuItem{domain.NewMenuItem(1, "salad", big.NewFloat(8.5))}))
+	return []*domain.Daypart{breakfast, lunch}
 }
 
 func newTestAddress() *domain.Address {
@@ -458,9 +3038,59 @@ func newTestMenu() *domain.Menu {
 	item1 := domain.NewMenuItem(1, "item1.1", f)
 	f = new(big.Float)
 	f.SetString("14.15")
-	item2 := domain.NewMenuItem(1, "item1.2", f)
+	item2 := domain.NewMenuItem(2, "item1.2", f)
 	f = new(big.Float)
 	f.SetString("15.16")
-	item3 := domain.NewMenuItem(1, "item1.3", f)
+	item3 := domain.NewMenuItem(3, "item1.3", f)
 	return domain.NewMenu([]*domain.MenuItem{item1, item2, item3})
 }
+
+func TestCreateManyWithinTransaction(t *testing.T) {
+	mockDb, sqlMock, _ := sqlmock.New()
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DriverName:           "go-sqlmock",
+		DSN:                  "go-sqlmock",
+		PreferSimpleProtocol: true,
+		Conn:                 mockDb,
+	}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		panic("failed to connect to database")
+	}
+	trManager := manager.Must(
+		trmgorm.NewDefaultFactory(db),
+		manager.WithSettings(trmgorm.MustSettings(settings.Must(settings.WithPropagation(trm.PropagationRequired)))),
+	)
+
+	// The failing restaurant rolls back to its own savepoint, so that the enclosing
+	// transaction (e.g. the one of an idempotent request) can still be committed.
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`SAVEPOINT .+`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec(`INSERT INTO restaurant .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(`SAVEPOINT .+`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec(`INSERT INTO restaurant .+`).WillReturnError(errors.New("error"))
+	sqlMock.ExpectExec(`ROLLBACK TO SAVEPOINT .+`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec(`SAVEPOINT .+`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec(`INSERT INTO restaurant .+`).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	restaurants := newTestRestaurants(3)
+	mr := mocks.NewMockRestaurantRepository(t)
+	mp := mocks.NewMockDomainEventPublisher(t)
+	mr.EXPECT().Save(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, restaurant *domain.Restaurant) error {
+		return trmgorm.DefaultCtxGetter.DefaultTrOrDB(ctx, db).Exec("INSERT INTO restaurant (name) VALUES (?)", restaurant.Name).Error
+	}).Times(3)
+	mp.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Twice()
+	rs := NewDefaultRestaurantService(mr, mp, trManager)
+
+	var results []*domain.CreationResult
+	err = trManager.Do(ownerCtx, func(ctx context.Context) error {
+		results, err = rs.CreateMany(ctx, restaurants, domain.BatchBestEffort)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.True(t, results[0].IsCreated())
+	assert.False(t, results[1].IsCreated())
+	assert.True(t, results[2].IsCreated())
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
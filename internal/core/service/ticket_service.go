@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"f4allgo-restaurant/internal/core/domain"
+	"f4allgo-restaurant/internal/core/port"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/rs/zerolog/log"
+)
+
+// Default implementation of the primary port TicketService.
+type DefaultTicketService struct {
+	ticketRepository     port.TicketRepository
+	restaurantRepository port.RestaurantRepository
+	domainEventPublisher port.DomainEventPublisher
+	trManager            trm.Manager
+	policy               port.AuthorizationPolicy
+}
+
+// Interface compliance verification.
+var _ port.TicketService = (*DefaultTicketService)(nil)
+
+// Provides an instance of DefaultTicketService.
+func NewDefaultTicketService(ticketRepository port.TicketRepository, restaurantRepository port.RestaurantRepository, domainEventPublisher port.DomainEventPublisher, trManager trm.Manager) *DefaultTicketService {
+	return &DefaultTicketService{ticketRepository: ticketRepository, restaurantRepository: restaurantRepository, domainEventPublisher: domainEventPublisher, trManager: trManager}
+}
+
+// WithAuthorizationPolicy sets an optional policy deciding which operations the
+// callers are allowed to perform, as in DefaultRestaurantService. The operations of
+// the create ticket saga (CreatePending, ConfirmCreate and CancelCreate) aren't
+// subject to it, since they are driven by the saga orchestrator.
+func (ts *DefaultTicketService) WithAuthorizationPolicy(policy port.AuthorizationPolicy) *DefaultTicketService {
+	ts.policy = policy
+	return ts
+}
+
+func (ts *DefaultTicketService) FindById(ctx context.Context, ticketId int64) (*domain.Ticket, error) {
+	if err := authorize(ctx, ts.policy, domain.PermissionReadTicket); err != nil {
+		return nil, err
+	}
+
+	ticket, err := ts.findById(ctx, ticketId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ts.findManageableRestaurant(ctx, ticket.RestaurantId, false); err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
+
+func (ts *DefaultTicketService) FindByRestaurant(ctx context.Context, restaurantId int64, state *domain.TicketState, offset int, limit int) ([]*domain.Ticket, int64, error) {
+	if err := authorize(ctx, ts.policy, domain.PermissionReadTicket); err != nil {
+		return nil, 0, err
+	}
+	if _, err := ts.findManageableRestaurant(ctx, restaurantId, false); err != nil {
+		return nil, 0, err
+	}
+
+	tickets, total, err := ts.ticketRepository.FindByRestaurant(ctx, restaurantId, state, offset, limit)
+	if err != nil {
+		log.Error().Msg("an error occurred while fetching the tickets of a restaurant: " + err.Error())
+		return nil, 0, coreerrors.NewRepositoryError(err)
+	}
+
+	return tickets, total, nil
+}
+
+func (ts *DefaultTicketService) Create(ctx context.Context, restaurantId int64, orderId int64, lineItems []*domain.TicketLineItem) (int64, error) {
+	if err := authorize(ctx, ts.policy, domain.PermissionUpdateTicket); err != nil {
+		return 0, err
+	}
+
+	var ticket *domain.Ticket
+	err := ts.trManager.Do(ctx, func(ctx context.Context) error {
+		restaurant, err := ts.findManageableRestaurant(ctx, restaurantId, true)
+		if err != nil {
+			return err
+		}
+
+		if ticket, err = restaurant.CreateTicket(orderId, lineItems); err != nil {
+			return coreerrors.NewCoreError(err)
+		}
+
+		if err := ts.ticketRepository.Save(ctx, ticket); err != nil {
+			log.Error().Msg("an error occurred while persisting the new created ticket: " + err.Error())
+			return coreerrors.NewRepositoryError(err)
+		}
+
+		if err := ts.domainEventPublisher.Publish(ctx, domain.NewTicketCreated(ticket)); err != nil {
+			return coreerrors.NewEventPublisherError(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return ticket.Id, nil
+}
+
+func (ts *DefaultTicketService) CreatePending(ctx context.Context, restaurantId int64, orderId int64, lineItems []*domain.TicketLineItem) (int64, error) {
+	var ticket *domain.Ticket
+	err := ts.trManager.Do(ctx, func(ctx context.Context) error {
+		existing, err := ts.ticketRepository.FindByOrderId(ctx, orderId)
+		if err == nil {
+			ticket = existing
+			return nil
+		} else if err.Error() != "record not found" {
+			return coreerrors.NewRepositoryError(err)
+		}
+
+		restaurant, err := ts.findRestaurant(ctx, restaurantId, true)
+		if err != nil {
+			return err
+		}
+
+		if ticket, err = restaurant.CreatePendingTicket(orderId, lineItems); err != nil {
+			return coreerrors.NewCoreError(err)
+		}
+
+		// The ticket is not visible to the outside world until it's confirmed, so
+		// there is no event to publish here.
+		if err := ts.ticketRepository.Save(ctx, ticket); err != nil {
+			log.Error().Msg("an error occurred while persisting the new pending ticket: " + err.Error())
+			return coreerrors.NewRepositoryError(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return ticket.Id, nil
+}
+
+func (ts *DefaultTicketService) ConfirmCreate(ctx context.Context, ticketId int64) error {
+	return ts.resolvePending(ctx, ticketId, domain.TicketStateCreated,
+		func(t *domain.Ticket) error { return t.ConfirmCreate() },
+		func(t *domain.Ticket) domain.DomainEvent { return domain.NewTicketCreated(t) })
+}
+
+func (ts *DefaultTicketService) CancelCreate(ctx context.Context, ticketId int64) error {
+	return ts.resolvePending(ctx, ticketId, domain.TicketStateCancelled,
+		func(t *domain.Ticket) error { return t.CancelCreate() },
+		func(t *domain.Ticket) domain.DomainEvent { return domain.NewTicketCancelled(t) })
+}
+
+func (ts *DefaultTicketService) Accept(ctx context.Context, ticketId int64, readyBy time.Time) error {
+	return ts.changeState(ctx, ticketId,
+		func(t *domain.Ticket) error { return t.Accept(readyBy) },
+		func(t *domain.Ticket) domain.DomainEvent { return domain.NewTicketAccepted(t) })
+}
+
+func (ts *DefaultTicketService) Reject(ctx context.Context, ticketId int64) error {
+	return ts.changeState(ctx, ticketId,
+		func(t *domain.Ticket) error { return t.Reject() },
+		func(t *domain.Ticket) domain.DomainEvent { return domain.NewTicketRejected(t) })
+}
+
+func (ts *DefaultTicketService) StartPreparing(ctx context.Context, ticketId int64) error {
+	return ts.changeState(ctx, ticketId,
+		func(t *domain.Ticket) error { return t.StartPreparing() },
+		func(t *domain.Ticket) domain.DomainEvent { return domain.NewTicketPreparationStarted(t) })
+}
+
+func (ts *DefaultTicketService) MarkReadyForPickup(ctx context.Context, ticketId int64) error {
+	return ts.changeState(ctx, ticketId,
+		func(t *domain.Ticket) error { return t.MarkReadyForPickup() },
+		func(t *domain.Ticket) domain.DomainEvent { return domain.NewTicketReadyForPickup(t) })
+}
+
+func (ts *DefaultTicketService) PickUp(ctx context.Context, ticketId int64) error {
+	return ts.changeState(ctx, ticketId,
+		func(t *domain.Ticket) error { return t.PickUp() },
+		func(t *domain.Ticket) domain.DomainEvent { return domain.NewTicketPickedUp(t) })
+}
+
+func (ts *DefaultTicketService) Cancel(ctx context.Context, ticketId int64) error {
+	return ts.changeState(ctx, ticketId,
+		func(t *domain.Ticket) error { return t.Cancel() },
+		func(t *domain.Ticket) domain.DomainEvent { return domain.NewTicketCancelled(t) })
+}
+
+// changeState is a private function that drives every transition of the ticket
+// lifecycle in the same way: it checks the permission of the caller, loads the
+// ticket, checks that the caller manages its restaurant, applies the transition,
+// persists the new state and publishes the resulting event within a single
+// transaction.
+func (ts *DefaultTicketService) changeState(ctx context.Context, ticketId int64, transition func(*domain.Ticket) error, event func(*domain.Ticket) domain.DomainEvent) error {
+	if err := authorize(ctx, ts.policy, domain.PermissionUpdateTicket); err != nil {
+		return err
+	}
+
+	return ts.trManager.Do(ctx, func(ctx context.Context) error {
+		ticket, err := ts.findById(ctx, ticketId)
+		if err != nil {
+			return err
+		}
+		if _, err := ts.findManageableRestaurant(ctx, ticket.RestaurantId, false); err != nil {
+			return err
+		}
+
+		return ts.applyTransition(ctx, ticket, transition, event)
+	})
+}
+
+// resolvePending is a private function to confirm or cancel the creation of a
+// pending ticket. Unlike changeState it's idempotent: if the ticket is already in
+// the target state nothing is done, so that redelivered saga commands are harmless.
+func (ts *DefaultTicketService) resolvePending(ctx context.Context, ticketId int64, target domain.TicketState, transition func(*domain.Ticket) error, event func(*domain.Ticket) domain.DomainEvent) error {
+	return ts.trManager.Do(ctx, func(ctx context.Context) error {
+		ticket, err := ts.findById(ctx, ticketId)
+		if err != nil {
+			return err
+		}
+
+		if ticket.State == target {
+			return nil
+		}
+
+		return ts.applyTransition(ctx, ticket, transition, event)
+	})
+}
+
+// applyTransition is a private function that applies a transition to a ticket,
+// persists its new state and publishes the resulting event. It must be called
+// within a transaction.
+func (ts *DefaultTicketService) applyTransition(ctx context.Context, ticket *domain.Ticket, transition func(*domain.Ticket) error, event func(*domain.Ticket) domain.DomainEvent) error {
+	if err := transition(ticket); err != nil {
+		if errors.Is(err, domain.ErrInvalidTicketStateTransition) {
+			return coreerrors.NewInvalidTicketStateError(err)
+		}
+		return coreerrors.NewCoreError(err)
+	}
+
+	if _, err := ts.ticketRepository.UpdateState(ctx, ticket); err != nil {
+		return coreerrors.NewRepositoryError(err)
+	}
+
+	if err := ts.domainEventPublisher.Publish(ctx, event(ticket)); err != nil {
+		return coreerrors.NewEventPublisherError(err)
+	}
+
+	return nil
+}
+
+// findById is a private function to find tickets. It returns core service errors
+// for any database access error and for tickets not found in the database.
+func (ts *DefaultTicketService) findById(ctx context.Context, ticketId int64) (*domain.Ticket, error) {
+	ticket, err := ts.ticketRepository.FindById(ctx, ticketId)
+	if err != nil {
+		if err.Error() == "record not found" {
+			return nil, coreerrors.NewTicketNotFoundError()
+		}
+		return nil, coreerrors.NewRepositoryError(err)
+	}
+
+	return ticket, nil
+}
+
+// findRestaurant is a private function to find the restaurant of tickets (with its
+// menu, if requested, to create them). It returns core service errors for any
+// database access error and for restaurants not found in the database.
+func (ts *DefaultTicketService) findRestaurant(ctx context.Context, restaurantId int64, fetchMenu bool) (*domain.Restaurant, error) {
+	restaurant, err := ts.restaurantRepository.FindById(ctx, restaurantId, fetchMenu)
+	if err != nil {
+		if err.Error() == "record not found" {
+			return nil, coreerrors.NewRestaurantNotFoundError()
+		}
+		return nil, coreerrors.NewRepositoryError(err)
+	}
+
+	return restaurant, nil
+}
+
+// findManageableRestaurant is a private function to find the restaurant of tickets
+// like findRestaurant, which also checks that the caller carried by the context is
+// allowed to manage it (so its tickets), returning a ForbiddenError otherwise.
+func (ts *DefaultTicketService) findManageableRestaurant(ctx context.Context, restaurantId int64, fetchMenu bool) (*domain.Restaurant, error) {
+	restaurant, err := ts.findRestaurant(ctx, restaurantId, fetchMenu)
+	if err != nil {
+		return nil, err
+	}
+	if !restaurant.IsManageableBy(domain.IdentityFromContext(ctx)) {
+		return nil, coreerrors.NewForbiddenError()
+	}
+
+	return restaurant, nil
+}
@@ -0,0 +1,687 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"f4allgo-restaurant/internal/core/domain"
+	coreerrors "f4allgo-restaurant/internal/core/service/errors"
+	"f4allgo-restaurant/internal/core/service/mocks"
+	"f4allgo-restaurant/test"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTicketFindById(t *testing.T) {
+	type args struct {
+		ctx      context.Context
+		ticketId int64
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockTicketRepository, *mocks.MockRestaurantRepository)
+		wantTicket       *domain.Ticket
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: ownerCtx, ticketId: 1},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindById(args.ctx, args.ticketId).Return(newTestTicket(domain.TicketStateCreated), nil).Once()
+				mr.EXPECT().FindById(args.ctx, int64(1000), false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantTicket: newTestTicket(domain.TicketStateCreated),
+			wantErr:    false,
+		},
+		{
+			name: "mock an admin of another tenant",
+			args: args{ctx: adminCtx, ticketId: 1},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindById(args.ctx, args.ticketId).Return(newTestTicket(domain.TicketStateCreated), nil).Once()
+				mr.EXPECT().FindById(args.ctx, int64(1000), false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantTicket: newTestTicket(domain.TicketStateCreated),
+			wantErr:    false,
+		},
+		{
+			name: "mock a caller of another tenant",
+			args: args{ctx: anotherUserCtx, ticketId: 1},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindById(args.ctx, args.ticketId).Return(newTestTicket(domain.TicketStateCreated), nil).Once()
+				mr.EXPECT().FindById(args.ctx, int64(1000), false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+		},
+		{
+			name: "mock a ticket not found",
+			args: args{ctx: ownerCtx, ticketId: 1},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, _ *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindById(args.ctx, args.ticketId).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.TicketNotFoundError{},
+		},
+		{
+			name: "mock a TicketRepository failure",
+			args: args{ctx: ownerCtx, ticketId: 1},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, _ *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindById(args.ctx, args.ticketId).Return(nil, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocks.NewMockTicketRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, mt, mr)
+			ts := NewDefaultTicketService(mt, mr, mocks.NewMockDomainEventPublisher(t), test.NewNopTrManager())
+			ticket, err := ts.FindById(tc.args.ctx, tc.args.ticketId)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantTicket, ticket)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestTicketFindByRestaurant(t *testing.T) {
+	accepted := domain.TicketStateAccepted
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		state        *domain.TicketState
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockTicketRepository, *mocks.MockRestaurantRepository)
+		wantTotal        int64
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: ownerCtx, restaurantId: 1000, state: &accepted},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mt.EXPECT().FindByRestaurant(args.ctx, args.restaurantId, args.state, 0, 10).Return([]*domain.Ticket{newTestTicket(accepted)}, 1, nil).Once()
+			},
+			wantTotal: 1,
+			wantErr:   false,
+		},
+		{
+			name: "mock a caller of another tenant",
+			args: args{ctx: anotherUserCtx, restaurantId: 1000},
+			mockExpectations: func(args args, _ *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+		},
+		{
+			name: "mock a restaurant not found",
+			args: args{ctx: ownerCtx, restaurantId: 1000},
+			mockExpectations: func(args args, _ *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+		},
+		{
+			name: "mock a TicketRepository failure",
+			args: args{ctx: ownerCtx, restaurantId: 1000},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, false).Return(newTestRestaurant(), nil).Once()
+				mt.EXPECT().FindByRestaurant(args.ctx, args.restaurantId, args.state, 0, 10).Return(nil, 0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocks.NewMockTicketRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			tc.mockExpectations(tc.args, mt, mr)
+			ts := NewDefaultTicketService(mt, mr, mocks.NewMockDomainEventPublisher(t), test.NewNopTrManager())
+			tickets, total, err := ts.FindByRestaurant(tc.args.ctx, tc.args.restaurantId, tc.args.state, 0, 10)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Len(t, tickets, int(tc.wantTotal))
+				assert.Equal(t, tc.wantTotal, total)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+		})
+	}
+}
+
+func TestTicketCreate(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		orderId      int64
+		lineItems    []*domain.TicketLineItem
+	}
+	testcases := []struct {
+		name                 string
+		args                 args
+		mockExpectations     func(args, *mocks.MockTicketRepository, *mocks.MockRestaurantRepository, *mocks.MockDomainEventPublisher)
+		wantTicketId         int64
+		wantErr              bool
+		wantErrType          error
+		additionalAssertions func(*mocks.MockTicketRepository, *mocks.MockDomainEventPublisher)
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: ownerCtx, restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mt.EXPECT().Save(args.ctx, mock.Anything).Run(func(_ context.Context, ticket *domain.Ticket) {
+					ticket.Id = 1
+				}).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.MatchedBy(func(e *domain.TicketCreated) bool {
+					return e.Ticket.Id == 1 && e.Ticket.RestaurantId == 1000 && e.Ticket.OrderId == 7 &&
+						e.Ticket.State == domain.TicketStateCreated && e.Ticket.LineItems[0].GetName() == "item1.1"
+				})).Return(nil).Once()
+			},
+			wantTicketId: 1,
+			wantErr:      false,
+		},
+		{
+			name: "mock a restaurant not found",
+			args: args{ctx: ownerCtx, restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, _ *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(nil, errors.New("record not found")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RestaurantNotFoundError{},
+		},
+		{
+			name: "mock a caller of another tenant",
+			args: args{ctx: anotherUserCtx, restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, _ *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.ForbiddenError{},
+			additionalAssertions: func(mt *mocks.MockTicketRepository, mp *mocks.MockDomainEventPublisher) {
+				mt.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a line item referring to an unknown menu item",
+			args: args{ctx: ownerCtx, restaurantId: 1000, orderId: 7, lineItems: []*domain.TicketLineItem{domain.NewTicketLineItem(99, "", 1)}},
+			mockExpectations: func(args args, _ *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+			additionalAssertions: func(mt *mocks.MockTicketRepository, mp *mocks.MockDomainEventPublisher) {
+				mt.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a line item referring to a not available menu item",
+			args: args{ctx: ownerCtx, restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, _ *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				r := newTestRestaurant()
+				r.SetItemAvailability(1, false, nil)
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(r, nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+		},
+		{
+			name: "mock a TicketRepository failure",
+			args: args{ctx: ownerCtx, restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository, _ *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mt.EXPECT().Save(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+			additionalAssertions: func(_ *mocks.MockTicketRepository, mp *mocks.MockDomainEventPublisher) {
+				mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			args: args{ctx: ownerCtx, restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository, mp *mocks.MockDomainEventPublisher) {
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mt.EXPECT().Save(args.ctx, mock.Anything).Return(nil).Once()
+				mp.EXPECT().Publish(args.ctx, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocks.NewMockTicketRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mt, mr, mp)
+			ts := NewDefaultTicketService(mt, mr, mp, test.NewNopTrManager())
+			ticketId, err := ts.Create(tc.args.ctx, tc.args.restaurantId, tc.args.orderId, tc.args.lineItems)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantTicketId, ticketId)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			if tc.additionalAssertions != nil {
+				tc.additionalAssertions(mt, mp)
+			}
+		})
+	}
+}
+
+func TestTicketCreatePending(t *testing.T) {
+	type args struct {
+		ctx          context.Context
+		restaurantId int64
+		orderId      int64
+		lineItems    []*domain.TicketLineItem
+	}
+	testcases := []struct {
+		name             string
+		args             args
+		mockExpectations func(args, *mocks.MockTicketRepository, *mocks.MockRestaurantRepository)
+		wantTicketId     int64
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "mock a successful execution",
+			args: args{ctx: context.Background(), restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindByOrderId(args.ctx, args.orderId).Return(nil, errors.New("record not found")).Once()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+				mt.EXPECT().Save(args.ctx, mock.MatchedBy(func(ticket *domain.Ticket) bool {
+					return ticket.State == domain.TicketStateCreatePending && ticket.OrderId == 7
+				})).Run(func(_ context.Context, ticket *domain.Ticket) {
+					ticket.Id = 1
+				}).Return(nil).Once()
+			},
+			wantTicketId: 1,
+			wantErr:      false,
+		},
+		{
+			name: "mock an order that already has a ticket",
+			args: args{ctx: context.Background(), restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, _ *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindByOrderId(args.ctx, args.orderId).Return(newTestTicket(domain.TicketStateCreatePending), nil).Once()
+			},
+			wantTicketId: 1,
+			wantErr:      false,
+		},
+		{
+			name: "mock a line item referring to an unknown menu item",
+			args: args{ctx: context.Background(), restaurantId: 1000, orderId: 7, lineItems: []*domain.TicketLineItem{domain.NewTicketLineItem(99, "", 1)}},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, mr *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindByOrderId(args.ctx, args.orderId).Return(nil, errors.New("record not found")).Once()
+				mr.EXPECT().FindById(args.ctx, args.restaurantId, true).Return(newTestRestaurant(), nil).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+		},
+		{
+			name: "mock a TicketRepository failure when finding the ticket of the order",
+			args: args{ctx: context.Background(), restaurantId: 1000, orderId: 7, lineItems: newTestTicketLineItems()},
+			mockExpectations: func(args args, mt *mocks.MockTicketRepository, _ *mocks.MockRestaurantRepository) {
+				mt.EXPECT().FindByOrderId(args.ctx, args.orderId).Return(nil, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocks.NewMockTicketRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			tc.mockExpectations(tc.args, mt, mr)
+			ts := NewDefaultTicketService(mt, mr, mp, test.NewNopTrManager())
+			ticketId, err := ts.CreatePending(tc.args.ctx, tc.args.restaurantId, tc.args.orderId, tc.args.lineItems)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantTicketId, ticketId)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+			}
+			mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTicketLifecycle(t *testing.T) {
+	readyBy := time.Now().Add(30 * time.Minute)
+	testcases := []struct {
+		name             string
+		from             domain.TicketState
+		operation        func(*DefaultTicketService, context.Context, int64) error
+		mockExpectations func(*mocks.MockTicketRepository, *mocks.MockDomainEventPublisher)
+		wantState        domain.TicketState
+		wantEventType    string
+		wantErr          bool
+		wantErrType      error
+	}{
+		{
+			name: "confirm the creation of a pending ticket",
+			from: domain.TicketStateCreatePending,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.ConfirmCreate(ctx, ticketId)
+			},
+			wantState:     domain.TicketStateCreated,
+			wantEventType: "TicketCreated",
+		},
+		{
+			name: "confirm the creation of an already confirmed ticket",
+			from: domain.TicketStateCreated,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.ConfirmCreate(ctx, ticketId)
+			},
+			mockExpectations: func(*mocks.MockTicketRepository, *mocks.MockDomainEventPublisher) {},
+			wantState:        domain.TicketStateCreated,
+		},
+		{
+			name: "confirm the creation of a cancelled ticket",
+			from: domain.TicketStateCancelled,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.ConfirmCreate(ctx, ticketId)
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.InvalidTicketStateError{},
+		},
+		{
+			name: "cancel the creation of a pending ticket",
+			from: domain.TicketStateCreatePending,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.CancelCreate(ctx, ticketId)
+			},
+			wantState:     domain.TicketStateCancelled,
+			wantEventType: "TicketCancelled",
+		},
+		{
+			name: "cancel the creation of an already cancelled ticket",
+			from: domain.TicketStateCancelled,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.CancelCreate(ctx, ticketId)
+			},
+			mockExpectations: func(*mocks.MockTicketRepository, *mocks.MockDomainEventPublisher) {},
+			wantState:        domain.TicketStateCancelled,
+		},
+		{
+			name: "cancel the creation of a confirmed ticket",
+			from: domain.TicketStateCreated,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.CancelCreate(ctx, ticketId)
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.InvalidTicketStateError{},
+		},
+		{
+			name: "accept a pending ticket",
+			from: domain.TicketStateCreatePending,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Accept(ctx, ticketId, readyBy)
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.InvalidTicketStateError{},
+		},
+		{
+			name: "accept a created ticket",
+			from: domain.TicketStateCreated,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Accept(ctx, ticketId, readyBy)
+			},
+			wantState:     domain.TicketStateAccepted,
+			wantEventType: "TicketAccepted",
+		},
+		{
+			name: "accept a ticket with a ready by instant in the past",
+			from: domain.TicketStateCreated,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Accept(ctx, ticketId, time.Now().Add(-time.Minute))
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.CoreError{},
+		},
+		{
+			name: "reject a created ticket",
+			from: domain.TicketStateCreated,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Reject(ctx, ticketId)
+			},
+			wantState:     domain.TicketStateCancelled,
+			wantEventType: "TicketRejected",
+		},
+		{
+			name: "reject an accepted ticket",
+			from: domain.TicketStateAccepted,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Reject(ctx, ticketId)
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.InvalidTicketStateError{},
+		},
+		{
+			name: "start preparing an accepted ticket",
+			from: domain.TicketStateAccepted,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.StartPreparing(ctx, ticketId)
+			},
+			wantState:     domain.TicketStatePreparing,
+			wantEventType: "TicketPreparationStarted",
+		},
+		{
+			name: "start preparing a created ticket",
+			from: domain.TicketStateCreated,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.StartPreparing(ctx, ticketId)
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.InvalidTicketStateError{},
+		},
+		{
+			name: "mark a ticket being prepared as ready for pickup",
+			from: domain.TicketStatePreparing,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.MarkReadyForPickup(ctx, ticketId)
+			},
+			wantState:     domain.TicketStateReadyForPickup,
+			wantEventType: "TicketReadyForPickup",
+		},
+		{
+			name: "pick up a ticket ready for pickup",
+			from: domain.TicketStateReadyForPickup,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.PickUp(ctx, ticketId)
+			},
+			wantState:     domain.TicketStatePickedUp,
+			wantEventType: "TicketPickedUp",
+		},
+		{
+			name: "pick up a ticket being prepared",
+			from: domain.TicketStatePreparing,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.PickUp(ctx, ticketId)
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.InvalidTicketStateError{},
+		},
+		{
+			name: "cancel an accepted ticket",
+			from: domain.TicketStateAccepted,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Cancel(ctx, ticketId)
+			},
+			wantState:     domain.TicketStateCancelled,
+			wantEventType: "TicketCancelled",
+		},
+		{
+			name: "cancel a ticket being prepared",
+			from: domain.TicketStatePreparing,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Cancel(ctx, ticketId)
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.InvalidTicketStateError{},
+		},
+		{
+			name: "mock a TicketRepository failure",
+			from: domain.TicketStateCreated,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Cancel(ctx, ticketId)
+			},
+			mockExpectations: func(mt *mocks.MockTicketRepository, _ *mocks.MockDomainEventPublisher) {
+				mt.EXPECT().UpdateState(mock.Anything, mock.Anything).Return(0, errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.RepositoryError{},
+		},
+		{
+			name: "mock a DomainEventPublisher failure",
+			from: domain.TicketStateCreated,
+			operation: func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+				return ts.Cancel(ctx, ticketId)
+			},
+			mockExpectations: func(mt *mocks.MockTicketRepository, mp *mocks.MockDomainEventPublisher) {
+				mt.EXPECT().UpdateState(mock.Anything, mock.Anything).Return(1, nil).Once()
+				mp.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("error")).Once()
+			},
+			wantErr:     true,
+			wantErrType: &coreerrors.EventPublisherError{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := ownerCtx
+			ticket := newTestTicket(tc.from)
+			mt := mocks.NewMockTicketRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			mt.EXPECT().FindById(ctx, ticket.Id).Return(ticket, nil).Once()
+			// The operations of the saga don't check the restaurant of the ticket.
+			mr.EXPECT().FindById(ctx, ticket.RestaurantId, false).Return(newTestRestaurant(), nil).Maybe()
+			if tc.mockExpectations != nil {
+				tc.mockExpectations(mt, mp)
+			} else if !tc.wantErr {
+				mt.EXPECT().UpdateState(ctx, ticket).Return(1, nil).Once()
+				mp.EXPECT().Publish(ctx, mock.MatchedBy(func(e domain.DomainEvent) bool {
+					return e.GetType() == tc.wantEventType
+				})).Return(nil).Once()
+			}
+			ts := NewDefaultTicketService(mt, mr, mp, test.NewNopTrManager())
+			err := tc.operation(ts, ctx, ticket.Id)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantState, ticket.State)
+			} else {
+				assert.Error(t, err)
+				assert.IsType(t, tc.wantErrType, err)
+				if tc.mockExpectations == nil {
+					mt.AssertNotCalled(t, "UpdateState", mock.Anything, mock.Anything)
+					mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+				}
+			}
+		})
+	}
+}
+
+func TestTicketLifecycleOfAnotherTenant(t *testing.T) {
+	operations := map[string]func(*DefaultTicketService, context.Context, int64) error{
+		"Accept": func(ts *DefaultTicketService, ctx context.Context, ticketId int64) error {
+			return ts.Accept(ctx, ticketId, time.Now().Add(time.Hour))
+		},
+		"Reject":             (*DefaultTicketService).Reject,
+		"StartPreparing":     (*DefaultTicketService).StartPreparing,
+		"MarkReadyForPickup": (*DefaultTicketService).MarkReadyForPickup,
+		"PickUp":             (*DefaultTicketService).PickUp,
+		"Cancel":             (*DefaultTicketService).Cancel,
+	}
+	for name, operation := range operations {
+		t.Run("mock a caller of another tenant on "+name, func(t *testing.T) {
+			ticket := newTestTicket(domain.TicketStateCreated)
+			mt := mocks.NewMockTicketRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			mt.EXPECT().FindById(anotherUserCtx, ticket.Id).Return(ticket, nil).Once()
+			mr.EXPECT().FindById(anotherUserCtx, ticket.RestaurantId, false).Return(newTestRestaurant(), nil).Once()
+			ts := NewDefaultTicketService(mt, mr, mp, test.NewNopTrManager())
+			err := operation(ts, anotherUserCtx, ticket.Id)
+			assert.IsType(t, &coreerrors.ForbiddenError{}, err)
+			assert.Equal(t, domain.TicketStateCreated, ticket.State)
+			mt.AssertNotCalled(t, "UpdateState", mock.Anything, mock.Anything)
+			mp.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// --------------------------------------------------------------------------------
+// Utility functions to create tickets.
+// --------------------------------------------------------------------------------
+
+func newTestTicket(state domain.TicketState) *domain.Ticket {
+	return &domain.Ticket{Id: 1, RestaurantId: 1000, OrderId: 7, State: state, LineItems: newTestTicketLineItems()}
+}
+
+func newTestTicketLineItems() []*domain.TicketLineItem {
+	return []*domain.TicketLineItem{domain.NewTicketLineItem(1, "item1.1", 2), domain.NewTicketLineItem(3, "item1.3", 1)}
+}
+
+func TestTicketAuthorizationPolicy(t *testing.T) {
+	operations := []struct {
+		name       string
+		permission domain.Permission
+		call       func(*DefaultTicketService) error
+	}{
+		{name: "FindById", permission: domain.PermissionReadTicket, call: func(ts *DefaultTicketService) error {
+			_, err := ts.FindById(ownerCtx, 1)
+			return err
+		}},
+		{name: "FindByRestaurant", permission: domain.PermissionReadTicket, call: func(ts *DefaultTicketService) error {
+			_, _, err := ts.FindByRestaurant(ownerCtx, 1, nil, 0, 10)
+			return err
+		}},
+		{name: "Create", permission: domain.PermissionUpdateTicket, call: func(ts *DefaultTicketService) error {
+			_, err := ts.Create(ownerCtx, 1, 1, nil)
+			return err
+		}},
+		{name: "Accept", permission: domain.PermissionUpdateTicket, call: func(ts *DefaultTicketService) error {
+			return ts.Accept(ownerCtx, 1, time.Now().Add(time.Hour))
+		}},
+		{name: "Cancel", permission: domain.PermissionUpdateTicket, call: func(ts *DefaultTicketService) error {
+			return ts.Cancel(ownerCtx, 1)
+		}},
+	}
+	identity := domain.IdentityFromContext(ownerCtx)
+	for _, op := range operations {
+		t.Run("mock a denied "+op.name, func(t *testing.T) {
+			mt := mocks.NewMockTicketRepository(t)
+			mr := mocks.NewMockRestaurantRepository(t)
+			mp := mocks.NewMockDomainEventPublisher(t)
+			ma := mocks.NewMockAuthorizationPolicy(t)
+			ma.EXPECT().IsAllowed(ownerCtx, identity, op.permission).Return(false, nil).Once()
+			ts := NewDefaultTicketService(mt, mr, mp, test.NewNopTrManager()).WithAuthorizationPolicy(ma)
+			assert.IsType(t, &coreerrors.ForbiddenError{}, op.call(ts))
+		})
+	}
+}
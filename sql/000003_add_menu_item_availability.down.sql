@@ -0,0 +1,3 @@
+ALTER TABLE menu_item DROP COLUMN available_until;
+
+ALTER TABLE menu_item DROP COLUMN available;
@@ -0,0 +1,2 @@
+ALTER TABLE menu_item ADD COLUMN available       BOOLEAN                  NOT NULL DEFAULT true;
+ALTER TABLE menu_item ADD COLUMN available_until TIMESTAMP with time zone;
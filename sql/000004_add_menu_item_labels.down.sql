@@ -0,0 +1,3 @@
+ALTER TABLE menu_item DROP COLUMN dietary_tags;
+
+ALTER TABLE menu_item DROP COLUMN allergens;
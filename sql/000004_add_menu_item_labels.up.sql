@@ -0,0 +1,2 @@
+ALTER TABLE menu_item ADD COLUMN allergens    JSONB;
+ALTER TABLE menu_item ADD COLUMN dietary_tags JSONB;
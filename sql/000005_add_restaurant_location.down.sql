@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS restaurant_location_idx;
+
+ALTER TABLE restaurant DROP COLUMN longitude;
+ALTER TABLE restaurant DROP COLUMN latitude;
+
+DROP EXTENSION IF EXISTS earthdistance;
+DROP EXTENSION IF EXISTS cube;
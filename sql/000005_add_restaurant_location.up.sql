@@ -0,0 +1,8 @@
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+ALTER TABLE restaurant ADD COLUMN latitude  DOUBLE PRECISION;
+ALTER TABLE restaurant ADD COLUMN longitude DOUBLE PRECISION;
+
+CREATE INDEX restaurant_location_idx ON restaurant USING gist (ll_to_earth(latitude, longitude))
+    WHERE latitude IS NOT NULL AND longitude IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE ticket_line_item;
+
+DROP TABLE ticket;
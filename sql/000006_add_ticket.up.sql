@@ -0,0 +1,25 @@
+CREATE TABLE ticket (
+    id                  BIGSERIAL                PRIMARY KEY,
+    restaurant_id       BIGINT                   NOT NULL,
+    order_id            BIGINT                   NOT NULL UNIQUE,
+    state               VARCHAR(20)              NOT NULL,
+    ready_by            TIMESTAMP with time zone,
+    created_at          TIMESTAMP with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at         TIMESTAMP with time zone,
+    preparing_at        TIMESTAMP with time zone,
+    ready_for_pickup_at TIMESTAMP with time zone,
+    picked_up_at        TIMESTAMP with time zone,
+    cancelled_at        TIMESTAMP with time zone
+);
+
+CREATE INDEX idx_ticket_restaurant_id ON ticket (restaurant_id, state);
+
+CREATE TABLE ticket_line_item (
+    ticket_id    BIGINT       NOT NULL,
+    menu_item_id INTEGER      NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    quantity     INTEGER      NOT NULL,
+    PRIMARY KEY (ticket_id, menu_item_id)
+);
+
+ALTER TABLE ticket_line_item ADD CONSTRAINT fk_ticket_id FOREIGN KEY (ticket_id) REFERENCES ticket(id);
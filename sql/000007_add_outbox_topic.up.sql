@@ -0,0 +1,3 @@
+-- Outbox rows with a topic are sent to it instead of the topic derived from the
+-- event type (e.g. replies to saga commands sent to the channel of the sender).
+ALTER TABLE outbox ADD COLUMN topic VARCHAR(255);
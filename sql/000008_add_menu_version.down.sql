@@ -0,0 +1,3 @@
+DROP TABLE menu_version;
+
+ALTER TABLE restaurant DROP COLUMN menu_version;
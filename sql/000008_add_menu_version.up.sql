@@ -0,0 +1,28 @@
+-- Immutable snapshots of the menus of the restaurants. A new version is written on
+-- every change of a menu, so the menu in effect at any instant can be retrieved.
+ALTER TABLE restaurant ADD COLUMN menu_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE menu_version (
+    restaurant_id BIGINT                   NOT NULL,
+    version       INTEGER                  NOT NULL,
+    valid_from    TIMESTAMP with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    items         JSONB                    NOT NULL,
+    PRIMARY KEY (restaurant_id, version)
+);
+
+ALTER TABLE menu_version ADD CONSTRAINT fk_menu_version_restaurant_id FOREIGN KEY (restaurant_id) REFERENCES restaurant(id) ON DELETE CASCADE;
+
+-- The current menus become the first version, valid from the migration onwards.
+INSERT INTO menu_version (restaurant_id, version, items)
+SELECT restaurant_id, 1, jsonb_agg(jsonb_build_object(
+    'id', id,
+    'name', name,
+    'price', price,
+    'available', available,
+    'availableUntil', available_until,
+    'allergens', allergens,
+    'dietaryTags', dietary_tags) ORDER BY id)
+FROM menu_item
+GROUP BY restaurant_id;
+
+UPDATE restaurant SET menu_version = 1 WHERE id IN (SELECT restaurant_id FROM menu_version);
@@ -0,0 +1,14 @@
+-- Menus prepared in advance that replace the menu of a restaurant once they are
+-- effective. They are deleted when activated or cancelled.
+CREATE TABLE scheduled_menu (
+    id             BIGSERIAL                PRIMARY KEY,
+    restaurant_id  BIGINT                   NOT NULL,
+    effective_from TIMESTAMP with time zone NOT NULL,
+    items          JSONB                    NOT NULL,
+    created_at     TIMESTAMP with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_scheduled_menu_effective_from ON scheduled_menu (effective_from);
+CREATE INDEX idx_scheduled_menu_restaurant_id ON scheduled_menu (restaurant_id, effective_from);
+
+ALTER TABLE scheduled_menu ADD CONSTRAINT fk_scheduled_menu_restaurant_id FOREIGN KEY (restaurant_id) REFERENCES restaurant(id) ON DELETE CASCADE;
@@ -0,0 +1,13 @@
+-- Menus offered by a restaurant during some time windows of the day (e.g. breakfast).
+-- The windows are stored as minutes since midnight and never overlap within a
+-- restaurant (enforced by the domain).
+CREATE TABLE daypart (
+    restaurant_id BIGINT      NOT NULL,
+    name          VARCHAR(50) NOT NULL,
+    position      SMALLINT    NOT NULL,
+    windows       JSONB       NOT NULL,
+    items         JSONB       NOT NULL,
+    PRIMARY KEY (restaurant_id, name)
+);
+
+ALTER TABLE daypart ADD CONSTRAINT fk_daypart_restaurant_id FOREIGN KEY (restaurant_id) REFERENCES restaurant(id) ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+ALTER TABLE menu_item DROP COLUMN translations;
+ALTER TABLE menu_item DROP COLUMN description;
+
+ALTER TABLE restaurant DROP COLUMN description_translations;
+ALTER TABLE restaurant DROP COLUMN description;
+ALTER TABLE restaurant DROP COLUMN default_locale;
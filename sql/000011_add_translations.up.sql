@@ -0,0 +1,6 @@
+ALTER TABLE restaurant ADD COLUMN default_locale           VARCHAR(35) NOT NULL DEFAULT 'en';
+ALTER TABLE restaurant ADD COLUMN description              TEXT        NOT NULL DEFAULT '';
+ALTER TABLE restaurant ADD COLUMN description_translations JSONB;
+
+ALTER TABLE menu_item ADD COLUMN description  TEXT NOT NULL DEFAULT '';
+ALTER TABLE menu_item ADD COLUMN translations JSONB;
@@ -0,0 +1,3 @@
+DROP INDEX restaurant_tenant_id_idx;
+
+ALTER TABLE restaurant DROP COLUMN tenant_id;
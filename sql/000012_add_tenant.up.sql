@@ -0,0 +1,3 @@
+ALTER TABLE restaurant ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX restaurant_tenant_id_idx ON restaurant (tenant_id);
@@ -0,0 +1,4 @@
+DROP INDEX restaurant_zip_idx;
+DROP INDEX restaurant_state_idx;
+DROP INDEX restaurant_city_idx;
+DROP INDEX restaurant_name_prefix_idx;
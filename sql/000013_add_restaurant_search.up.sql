@@ -0,0 +1,4 @@
+CREATE INDEX restaurant_name_prefix_idx ON restaurant (lower(name) text_pattern_ops);
+CREATE INDEX restaurant_city_idx ON restaurant (lower(city));
+CREATE INDEX restaurant_state_idx ON restaurant (lower(state));
+CREATE INDEX restaurant_zip_idx ON restaurant (zip);
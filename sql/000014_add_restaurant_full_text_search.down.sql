@@ -0,0 +1,6 @@
+DROP TRIGGER restaurant_search_menu_item_trg ON menu_item;
+DROP TRIGGER restaurant_search_restaurant_trg ON restaurant;
+DROP FUNCTION restaurant_search_on_menu_item;
+DROP FUNCTION restaurant_search_on_restaurant;
+DROP FUNCTION refresh_restaurant_search;
+DROP TABLE restaurant_search;
@@ -0,0 +1,57 @@
+-- The full-text search documents of the restaurants, kept in their own table so
+-- that the triggers maintaining them never touch the restaurant rows. The 'simple'
+-- configuration is used (no stemming nor stop words) since the texts can be in any
+-- language. The content is the plain text the highlights are extracted from.
+CREATE TABLE restaurant_search (
+    restaurant_id BIGINT   PRIMARY KEY,
+    content       TEXT     NOT NULL,
+    document      TSVECTOR NOT NULL
+);
+
+ALTER TABLE restaurant_search ADD CONSTRAINT fk_restaurant_search_restaurant_id FOREIGN KEY (restaurant_id) REFERENCES restaurant(id) ON DELETE CASCADE;
+CREATE INDEX restaurant_search_document_idx ON restaurant_search USING GIN (document);
+
+-- Rebuilds the search document of a restaurant: the name weighs the most, then the
+-- names of the menu items and finally the address.
+CREATE FUNCTION refresh_restaurant_search(p_restaurant_id BIGINT) RETURNS VOID AS $$
+    INSERT INTO restaurant_search (restaurant_id, content, document)
+    SELECT r.id,
+           concat_ws(' · ', r.name, m.names, concat_ws(', ', r.street, r.city, r.state, r.zip)),
+           setweight(to_tsvector('simple', r.name), 'A') ||
+           setweight(to_tsvector('simple', coalesce(m.names, '')), 'B') ||
+           setweight(to_tsvector('simple', concat_ws(' ', r.street, r.city, r.state, r.zip)), 'C')
+    FROM restaurant r
+    LEFT JOIN LATERAL (SELECT string_agg(name, ', ' ORDER BY id) AS names FROM menu_item WHERE restaurant_id = r.id) m ON true
+    WHERE r.id = p_restaurant_id
+    ON CONFLICT (restaurant_id) DO UPDATE SET content = EXCLUDED.content, document = EXCLUDED.document;
+$$ LANGUAGE sql;
+
+CREATE FUNCTION restaurant_search_on_restaurant() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_restaurant_search(NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION restaurant_search_on_menu_item() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM refresh_restaurant_search(OLD.restaurant_id);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.restaurant_id <> OLD.restaurant_id) THEN
+        PERFORM refresh_restaurant_search(NEW.restaurant_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER restaurant_search_restaurant_trg
+    AFTER INSERT OR UPDATE OF name, street, city, state, zip ON restaurant
+    FOR EACH ROW EXECUTE FUNCTION restaurant_search_on_restaurant();
+
+CREATE TRIGGER restaurant_search_menu_item_trg
+    AFTER INSERT OR UPDATE OF restaurant_id, name OR DELETE ON menu_item
+    FOR EACH ROW EXECUTE FUNCTION restaurant_search_on_menu_item();
+
+-- Index the restaurants registered before this migration.
+SELECT refresh_restaurant_search(id) FROM restaurant;
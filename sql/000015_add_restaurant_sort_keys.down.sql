@@ -0,0 +1,4 @@
+DROP INDEX restaurant_zip_id_idx;
+DROP INDEX restaurant_state_id_idx;
+DROP INDEX restaurant_city_id_idx;
+DROP INDEX restaurant_name_id_idx;
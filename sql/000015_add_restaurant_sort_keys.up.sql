@@ -0,0 +1,6 @@
+-- Indexes on the sort keys of the listings, so that the keyset conditions of the
+-- cursors and the sort itself can be resolved with an index scan.
+CREATE INDEX restaurant_name_id_idx ON restaurant (name, id);
+CREATE INDEX restaurant_city_id_idx ON restaurant (city, id);
+CREATE INDEX restaurant_state_id_idx ON restaurant (state, id);
+CREATE INDEX restaurant_zip_id_idx ON restaurant (zip, id);
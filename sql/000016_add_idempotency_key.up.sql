@@ -0,0 +1,12 @@
+-- Responses of the idempotent requests, stored under the key given by the caller
+-- along with the hash of the request, so that retries get the same response.
+CREATE TABLE idempotency_key (
+    scope        VARCHAR(255)             NOT NULL,
+    key          VARCHAR(255)             NOT NULL,
+    request_hash VARCHAR(64)              NOT NULL,
+    response     BYTEA                    NOT NULL,
+    created_at   TIMESTAMP with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_key_created_at ON idempotency_key (created_at);
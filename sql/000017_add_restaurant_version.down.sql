@@ -0,0 +1 @@
+ALTER TABLE restaurant DROP COLUMN version;
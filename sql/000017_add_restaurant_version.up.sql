@@ -0,0 +1 @@
+ALTER TABLE restaurant ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
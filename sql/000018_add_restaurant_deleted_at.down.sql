@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_restaurant_deleted_at;
+ALTER TABLE restaurant DROP COLUMN deleted_at;
@@ -0,0 +1,5 @@
+-- Deleted restaurants are kept (with the instant they were deleted) until they are
+-- purged, so that they can be restored meanwhile. The partial index only covers the
+-- deleted ones, which are the ones looked up by the purge.
+ALTER TABLE restaurant ADD COLUMN deleted_at TIMESTAMPTZ;
+CREATE INDEX idx_restaurant_deleted_at ON restaurant (deleted_at) WHERE deleted_at IS NOT NULL;
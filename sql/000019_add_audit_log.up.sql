@@ -0,0 +1,17 @@
+-- Audit log of the mutations of the restaurants: the caller that made each one, the
+-- request it was part of and the fields of the restaurant it changed. The entries
+-- don't reference the restaurants, so that they are kept when the restaurants are
+-- purged.
+CREATE TABLE audit_log (
+    id            BIGSERIAL                PRIMARY KEY,
+    restaurant_id BIGINT                   NOT NULL,
+    operation     VARCHAR(50)              NOT NULL,
+    actor         VARCHAR(255)             NOT NULL,
+    tenant_id     VARCHAR(255)             NOT NULL,
+    changes       JSONB                    NOT NULL,
+    request_id    VARCHAR(255)             NOT NULL,
+    source        VARCHAR(10)              NOT NULL,
+    created_at    TIMESTAMP with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_restaurant_id ON audit_log (restaurant_id, created_at);
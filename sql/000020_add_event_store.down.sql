@@ -0,0 +1,2 @@
+DROP TABLE event_store_snapshot;
+DROP TABLE event_store;
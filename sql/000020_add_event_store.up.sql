@@ -0,0 +1,28 @@
+-- Event store used by the event-sourced implementation of the restaurant repository.
+-- The events of every aggregate are numbered by the version it reaches with them (and
+-- by their position within the change, which may raise several of them), so that two
+-- concurrent changes of an aggregate can't append the same version (the last one to
+-- commit is rejected). The events don't reference the restaurants, since the
+-- relational tables are only kept as their read model.
+CREATE TABLE event_store (
+    id             BIGSERIAL                PRIMARY KEY,
+    aggregate_type VARCHAR(50)              NOT NULL,
+    aggregate_id   BIGINT                   NOT NULL,
+    version        BIGINT                   NOT NULL,
+    position       SMALLINT                 NOT NULL DEFAULT 0,
+    event_type     VARCHAR(50)              NOT NULL,
+    payload        JSONB                    NOT NULL,
+    created_at     TIMESTAMP with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (aggregate_type, aggregate_id, version, position)
+);
+
+-- Latest snapshot of every aggregate, taken every few events so that they can be
+-- rebuilt replaying only the events appended after it.
+CREATE TABLE event_store_snapshot (
+    aggregate_type VARCHAR(50)              NOT NULL,
+    aggregate_id   BIGINT                   NOT NULL,
+    version        BIGINT                   NOT NULL,
+    state          JSONB                    NOT NULL,
+    created_at     TIMESTAMP with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (aggregate_type, aggregate_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE restaurant ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key ids of the keys published in the static test key set (jwks.json). Their
+// private keys are only meant to sign tokens in tests and local environments.
+const (
+	RsaKeyId = "test-rsa"
+	EcKeyId  = "test-ec"
+)
+
+// JwksFile returns the path of the static test key set.
+func JwksFile() string {
+	return filepath.Join(testDir(), "jwks.json")
+}
+
+// SignToken signs the claims with the private key of the test key set identified
+// by kid, so the token can be verified against JwksFile.
+func SignToken(kid string, claims jwt.MapClaims) (string, error) {
+	var method jwt.SigningMethod
+	var key any
+	switch kid {
+	case EcKeyId:
+		data, err := os.ReadFile(filepath.Join(testDir(), "jwks_ec.pem"))
+		if err != nil {
+			return "", err
+		}
+		if key, err = jwt.ParseECPrivateKeyFromPEM(data); err != nil {
+			return "", err
+		}
+		method = jwt.SigningMethodES256
+	default:
+		data, err := os.ReadFile(filepath.Join(testDir(), "jwks_rsa.pem"))
+		if err != nil {
+			return "", err
+		}
+		if key, err = jwt.ParseRSAPrivateKeyFromPEM(data); err != nil {
+			return "", err
+		}
+		method = jwt.SigningMethodRS256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
+}
+
+// testDir returns the directory of this package, where the test resources live.
+func testDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}
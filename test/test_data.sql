@@ -1,20 +1,38 @@
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
-INSERT INTO restaurant (id, name, city, state, street, zip) VALUES (1000, 'restaurant1', 'city1', 'state1', 'street1', 'zip1');
+INSERT INTO restaurant (id, tenant_id, name, city, state, street, zip, menu_version) VALUES (1000, 'tenant1', 'restaurant1', 'city1', 'state1', 'street1', 'zip1', 2);
 INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (1000, 1, 'item1.1', '13.14');
 INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (1000, 2, 'item1.2', '14.15');
 INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (1000, 3, 'item1.3', '15.16');
 
-INSERT INTO restaurant (id, name, city, state, street, zip) VALUES (2000, 'restaurant2', 'city2', 'state2', 'street2', 'zip2');
-INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (2000, 1, 'item2.1', '13.14');
+INSERT INTO restaurant (id, tenant_id, name, city, state, street, zip, menu_version, default_locale, description, description_translations) VALUES (2000, 'tenant2', 'restaurant2', 'city2', 'state2', 'street2', 'zip2', 1, 'es-ES', 'descripción2', '{"en-GB": "description2"}');
+INSERT INTO menu_item (restaurant_id, id, name, price, description, translations) VALUES (2000, 1, 'artículo2.1', '13.14', 'descripción2.1', '{"en-GB": {"name": "item2.1", "description": "description2.1"}}');
 INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (2000, 2, 'item2.2', '14.15');
 INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (2000, 3, 'item2.3', '15.16');
 
-INSERT INTO restaurant (id, name, city, state, street, zip) VALUES (3000, 'restaurant3', 'city3', 'state3', 'street3', 'zip3');
+INSERT INTO restaurant (id, tenant_id, name, city, state, street, zip, menu_version) VALUES (3000, 'tenant1', 'restaurant3', 'city3', 'state3', 'street3', 'zip3', 1);
 INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (3000, 1, 'item3.1', '13.14');
 INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (3000, 2, 'item3.2', '14.15');
 INSERT INTO menu_item (restaurant_id, id, name, price) VALUES (3000, 3, 'item3.3', '15.16');
 
+INSERT INTO menu_version (restaurant_id, version, valid_from, items) VALUES (1000, 1, '2024-01-01 00:00:00+00', '[{"id": 1, "name": "item1.1", "price": "12.14", "available": true}, {"id": 2, "name": "item1.2", "price": "13.15", "available": true}]');
+INSERT INTO menu_version (restaurant_id, version, valid_from, items) VALUES (1000, 2, '2024-06-01 00:00:00+00', '[{"id": 1, "name": "item1.1", "price": "13.14", "available": true}, {"id": 2, "name": "item1.2", "price": "14.15", "available": true}, {"id": 3, "name": "item1.3", "price": "15.16", "available": true}]');
+INSERT INTO menu_version (restaurant_id, version, valid_from, items) VALUES (2000, 1, '2024-01-01 00:00:00+00', '[{"id": 1, "name": "item2.1", "price": "13.14", "available": true}, {"id": 2, "name": "item2.2", "price": "14.15", "available": true}, {"id": 3, "name": "item2.3", "price": "15.16", "available": true}]');
+INSERT INTO menu_version (restaurant_id, version, valid_from, items) VALUES (3000, 1, '2024-01-01 00:00:00+00', '[{"id": 1, "name": "item3.1", "price": "13.14", "available": true}, {"id": 2, "name": "item3.2", "price": "14.15", "available": true}, {"id": 3, "name": "item3.3", "price": "15.16", "available": true}]');
+
+INSERT INTO scheduled_menu (id, restaurant_id, effective_from, items) VALUES (1000, 1000, '2024-01-01 12:00:00+00', '[{"id": 1, "name": "item1.1", "price": "11.14", "available": true}, {"id": 2, "name": "item1.2", "price": "12.15", "available": true}]');
+INSERT INTO scheduled_menu (id, restaurant_id, effective_from, items) VALUES (2000, 1000, '2099-01-01 12:00:00+00', '[{"id": 1, "name": "item1.1", "price": "21.14", "available": true}, {"id": 2, "name": "item1.2", "price": "22.15", "available": true}]');
+
+INSERT INTO daypart (restaurant_id, name, position, windows, items) VALUES (1000, 'breakfast', 0, '[{"start": 420, "end": 660}]', '[{"id": 1, "name": "coffee", "price": "1.50", "available": true}, {"id": 2, "name": "toast", "price": "2.50", "available": true}]');
+INSERT INTO daypart (restaurant_id, name, position, windows, items) VALUES (1000, 'dinner', 1, '[{"start": 1200, "end": 1440}, {"start": 0, "end": 60}]', '[{"id": 1, "name": "steak", "price": "21.50", "available": true}, {"id": 2, "name": "wine", "price": "5.50", "available": true}]');
+
+INSERT INTO ticket (id, restaurant_id, order_id, state, created_at) VALUES (1000, 1000, 1000, 'created', '2024-01-01 10:00:00+00');
+INSERT INTO ticket_line_item (ticket_id, menu_item_id, name, quantity) VALUES (1000, 1, 'item1.1', 2);
+INSERT INTO ticket_line_item (ticket_id, menu_item_id, name, quantity) VALUES (1000, 3, 'item1.3', 1);
+
+INSERT INTO ticket (id, restaurant_id, order_id, state, ready_by, created_at, accepted_at) VALUES (2000, 1000, 2000, 'accepted', '2024-01-01 11:00:00+00', '2024-01-01 10:00:00+00', '2024-01-01 10:05:00+00');
+INSERT INTO ticket_line_item (ticket_id, menu_item_id, name, quantity) VALUES (2000, 2, 'item1.2', 1);
+
 INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, payload) VALUES (uuid_generate_v4(), 'restaurant', '1', 'RestaurantCreated', E'\\xDEADBEEF');
 INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, payload) VALUES (uuid_generate_v4(), 'restaurant', '2', 'RestaurantUpdated', E'\\xDEADBEEF');
 INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, payload) VALUES (uuid_generate_v4(), 'restaurant', '3', 'RestaurantDeleted', E'\\xDEADBEEF');
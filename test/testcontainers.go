@@ -21,6 +21,25 @@ func InitApplicationDatabase(ctx context.Context) (*postgres.PostgresContainer,
 		postgres.WithInitScripts(
 			filepath.Join(root.Path, "sql/000001_create_schema.up.sql"),
 			filepath.Join(root.Path, "sql/000002_add_outbox.up.sql"),
+			filepath.Join(root.Path, "sql/000003_add_menu_item_availability.up.sql"),
+			filepath.Join(root.Path, "sql/000004_add_menu_item_labels.up.sql"),
+			filepath.Join(root.Path, "sql/000005_add_restaurant_location.up.sql"),
+			filepath.Join(root.Path, "sql/000006_add_ticket.up.sql"),
+			filepath.Join(root.Path, "sql/000007_add_outbox_topic.up.sql"),
+			filepath.Join(root.Path, "sql/000008_add_menu_version.up.sql"),
+			filepath.Join(root.Path, "sql/000009_add_scheduled_menu.up.sql"),
+			filepath.Join(root.Path, "sql/000010_add_daypart.up.sql"),
+			filepath.Join(root.Path, "sql/000011_add_translations.up.sql"),
+			filepath.Join(root.Path, "sql/000012_add_tenant.up.sql"),
+			filepath.Join(root.Path, "sql/000013_add_restaurant_search.up.sql"),
+			filepath.Join(root.Path, "sql/000014_add_restaurant_full_text_search.up.sql"),
+			filepath.Join(root.Path, "sql/000015_add_restaurant_sort_keys.up.sql"),
+			filepath.Join(root.Path, "sql/000016_add_idempotency_key.up.sql"),
+			filepath.Join(root.Path, "sql/000017_add_restaurant_version.up.sql"),
+			filepath.Join(root.Path, "sql/000018_add_restaurant_deleted_at.up.sql"),
+			filepath.Join(root.Path, "sql/000019_add_audit_log.up.sql"),
+			filepath.Join(root.Path, "sql/000020_add_event_store.up.sql"),
+			filepath.Join(root.Path, "sql/000021_add_restaurant_time_zone.up.sql"),
 			filepath.Join(root.Path, "test/test_data.sql"),
 		),
 		postgres.WithDatabase("dbname"),